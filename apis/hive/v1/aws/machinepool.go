@@ -1,28 +1,268 @@
 package aws
 
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
 // MachinePoolPlatform stores the configuration for a machine pool
 // installed on AWS.
 type MachinePoolPlatform struct {
 	// Zones is list of availability zones that can be used.
 	Zones []string `json:"zones,omitempty"`
 
+	// ZoneIDs is a list of IDs of availability zones that can be used, e.g. use1-az1, instead of their names. The names
+	// of availability zones map to different physical zones in each account, whereas their IDs identify the same
+	// physical zones in all accounts, so that pools of clusters in different accounts can be placed in the same
+	// physical zones. The IDs are resolved to the names of the zones in the account of the cluster, which are used
+	// wherever zones are named, such as in InstanceTypesByZone. Cannot be set together with Zones.
+	// +optional
+	ZoneIDs []string `json:"zoneIDs,omitempty"`
+
+	// SingleZone places all the machines of the pool in one availability zone, e.g. to keep the members of a
+	// latency-sensitive workload close together, at the cost of losing the whole pool when that zone fails. The zone is
+	// the first one listed in Zones or ZoneIDs, or otherwise the first by name of the zones the pool would use, such as
+	// the first zone of the region with a subnet of the pool.
+	// +optional
+	SingleZone bool `json:"singleZone,omitempty"`
+
 	// Subnets is the list of subnets to which to attach the machines.
 	// There must be exactly one private subnet for each availability zone used.
 	// If public subnets are specified, there must be exactly one private and one public subnet specified for each availability zone.
 	Subnets []string `json:"subnets,omitempty"`
 
+	// SubnetSelection configures how a subnet is selected when more than one private or public subnet is specified
+	// for an availability zone. When not set, specifying more than one private or public subnet for an availability
+	// zone is an error.
+	// +optional
+	SubnetSelection *SubnetSelection `json:"subnetSelection,omitempty"`
+
+	// PrivateSubnets lists subnets of Subnets which are private, whatever their route tables and tags suggest. Subnets
+	// are otherwise classified as public when their route table has a route to an internet gateway or they have the
+	// kubernetes.io/role/elb tag, which misclassifies subnets in some topologies, e.g. with egress through a transit
+	// gateway.
+	// +optional
+	PrivateSubnets []string `json:"privateSubnets,omitempty"`
+
+	// PublicIP controls whether the machines of the pool are assigned public IP addresses. When not set, the subnets of
+	// the machines decide whether they get public IP addresses. Machines without public IP addresses in subnets whose
+	// outbound traffic is not routed through a NAT gateway or another egress device likely have no outbound
+	// connectivity, which the NoOutboundConnectivity condition warns about.
+	// +optional
+	PublicIP *bool `json:"publicIP,omitempty"`
+
+	// FailureDomains declares the failure domains of the pool, each an availability zone with the subnet and optionally
+	// the instance type of its machines. One MachineSet is generated for each failure domain, instead of for the zones
+	// and subnets the pool would otherwise find in the region or in its Subnets. The zones of the failure domains must
+	// be distinct. Cannot be set together with Zones, ZoneIDs, SingleZone, Subnets, Edge or InstanceTypesByZone.
+	// +optional
+	FailureDomains []FailureDomain `json:"failureDomains,omitempty"`
+
+	// SpreadPolicy controls how evenly the replicas of the pool must be spread across its availability zones.
+	// BestEffortEven, the default, splits the replicas as evenly as possible, the first zones getting one more replica
+	// when they cannot be split evenly. StrictEven requires the replicas, or the minimum and maximum replicas of each
+	// zone when auto-scaling, to be the same in every zone, so that no zone holds a larger share of the machines; no
+	// MachineSets are generated otherwise, and the UnevenZoneSpread condition says why.
+	// +kubebuilder:validation:Enum=BestEffortEven;StrictEven
+	// +optional
+	SpreadPolicy SpreadPolicy `json:"spreadPolicy,omitempty"`
+
+	// ResourceNames overrides the names by which the generated MachineSets reference the existing IAM instance
+	// profile, private subnets and security group of the cluster, for clusters whose resources are not named as the
+	// installer names them, such as adopted clusters. The named resources must exist.
+	// +optional
+	ResourceNames *ResourceNames `json:"resourceNames,omitempty"`
+
+	// IAMInstanceProfileARN is the ARN of the IAM instance profile of the machines, e.g.
+	// arn:aws:iam::123456789012:instance-profile/workers, for profiles which cannot be referenced by name. Overrides the
+	// IAM instance profile of ResourceNames, with which it cannot be set. Defaults to the profile named by the installer.
+	// +optional
+	IAMInstanceProfileARN string `json:"iamInstanceProfileARN,omitempty"`
+
 	// InstanceType defines the ec2 instance type.
 	// eg. m4-large
-	InstanceType string `json:"type"`
+	// Required unless InstanceTypeSelector is set.
+	// +optional
+	InstanceType string `json:"type,omitempty"`
+
+	// InstanceTypeSelector selects the ec2 instance type from an instance family and size, so that the family can be
+	// changed without hard-coding full instance types. Ignored when InstanceType is set.
+	// +optional
+	InstanceTypeSelector *InstanceTypeSelector `json:"instanceTypeSelector,omitempty"`
+
+	// InstanceTypesByZone maps availability zones to the ec2 instance type to use in them instead of the instance type
+	// of the pool, e.g. where only older instance families are offered. Zones not listed use the instance type of the
+	// pool.
+	// +optional
+	InstanceTypesByZone map[string]string `json:"instanceTypesByZone,omitempty"`
 
 	// EC2RootVolume defines the storage for ec2 instance.
 	EC2RootVolume `json:"rootVolume"`
 
+	// AdditionalBlockDevices are EBS volumes attached to the machines in addition to the root volume, e.g. for
+	// container storage. Each volume is encrypted independently of the root volume, with its own KMS key.
+	// +optional
+	AdditionalBlockDevices []BlockDevice `json:"additionalBlockDevices,omitempty"`
+
 	// SpotMarketOptions allows users to configure instances to be run using AWS Spot instances.
 	// +optional
 	SpotMarketOptions *SpotMarketOptions `json:"spotMarketOptions,omitempty"`
+
+	// MarketType is the purchasing option of the instances: on-demand, spot or capacity-block. SpotMarketOptions are
+	// only valid with the spot market type, and spot instances with the default options can be requested with the
+	// market type alone. Capacity blocks are not supported, as the machine API cannot launch instances into capacity
+	// reservations. Defaults to spot when SpotMarketOptions are set, and to on-demand otherwise.
+	// +kubebuilder:validation:Enum=on-demand;spot;capacity-block
+	// +optional
+	MarketType MarketType `json:"marketType,omitempty"`
+
+	// SpotFallbackToOnDemand requests that the pool fall back to on-demand instances when spot capacity is unavailable,
+	// keeping a base of on-demand capacity with spot instances above it. The machine API has no mixed-instances policy
+	// and launches each MachineSet with a single instance type and purchasing option, so the fallback cannot be
+	// generated: the pool keeps launching only spot instances and the SpotFallbackUnavailable condition says so. Only
+	// valid for spot instances.
+	// +optional
+	SpotFallbackToOnDemand bool `json:"spotFallbackToOnDemand,omitempty"`
+
+	// Edge places the machines only in edge zones, i.e. Local Zones and Wavelength Zones, separately from the workers
+	// in the availability zones of the region. Edge pools must specify subnets; the zones of the pool default to the
+	// edge zones of those subnets. The machines get the node-role.kubernetes.io/edge label and a NoSchedule taint with
+	// the same key, unless the pool sets its own taint with that key, so that regular workloads are not scheduled on
+	// them by default.
+	// +optional
+	Edge bool `json:"edge,omitempty"`
+
+	// CredentialsSecretRef refers to a secret in the namespace of the MachinePool with the credentials of the AWS
+	// account Hive uses to look up the resources of the pool, such as its subnets, instead of the credentials of the
+	// ClusterDeployment, for pools whose resources are in another account. Cannot be set together with
+	// CredentialsAssumeRole.
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+
+	// CredentialsAssumeRole is an IAM role, e.g. in another AWS account, that Hive assumes with the credentials of the
+	// Hive service provider to look up the resources of the pool, instead of using the credentials of the
+	// ClusterDeployment. Cannot be set together with CredentialsSecretRef.
+	// +optional
+	CredentialsAssumeRole *AssumeRole `json:"credentialsAssumeRole,omitempty"`
+}
+
+// InstanceTypeSelector selects an ec2 instance type from an instance family and size.
+type InstanceTypeSelector struct {
+	// Family is the instance family.
+	// eg. c5
+	Family string `json:"family"`
+
+	// Size is the instance size within the family.
+	// eg. 4xlarge
+	Size string `json:"size"`
 }
 
+// BlockDevice defines an additional EBS volume of an ec2 instance.
+type BlockDevice struct {
+	// DeviceName is the device name exposed to the machine, e.g. /dev/xvdb.
+	DeviceName string `json:"deviceName"`
+	// IOPS defines the iops for the storage.
+	// +optional
+	IOPS int `json:"iops,omitempty"`
+	// Size defines the size of the storage in GiB.
+	Size int `json:"size"`
+	// Type defines the type of the storage.
+	Type string `json:"type"`
+	// Encrypted controls whether the volume is encrypted. Defaults to true. When false, the volume is only
+	// encrypted if EBS encryption by default is enabled for the account, and no KMS key may be set.
+	// +optional
+	Encrypted *bool `json:"encrypted,omitempty"`
+	// KMSKeyARN is the KMS key that will be used to encrypt the volume, by its ARN or by an alias such as
+	// alias/ebs-data. If no key is provided the default KMS key for the account will be used.
+	// +optional
+	KMSKeyARN string `json:"kmsKeyARN,omitempty"`
+}
+
+// FailureDomain is an availability zone in which a MachineSet of a machine pool places its machines.
+type FailureDomain struct {
+	// Zone is the name of the availability zone.
+	Zone string `json:"zone"`
+
+	// Subnet is the ID of the subnet of the machines, which must be in the availability zone.
+	Subnet string `json:"subnet"`
+
+	// InstanceType is the ec2 instance type of the machines in the failure domain instead of the instance type of the
+	// pool.
+	// +optional
+	InstanceType string `json:"instanceType,omitempty"`
+}
+
+// SpreadPolicy is a policy for spreading the replicas of a MachinePool across its availability zones.
+type SpreadPolicy string
+
+const (
+	// SpreadPolicyBestEffortEven splits the replicas as evenly as possible, giving the replicas which cannot be split
+	// evenly to the first zones.
+	SpreadPolicyBestEffortEven SpreadPolicy = "BestEffortEven"
+
+	// SpreadPolicyStrictEven requires the replicas to be split evenly across the zones.
+	SpreadPolicyStrictEven SpreadPolicy = "StrictEven"
+)
+
+// SubnetSelectionPolicy is a policy for selecting one of several subnets specified for the same availability zone.
+type SubnetSelectionPolicy string
+
+const (
+	// SubnetSelectionPolicyLowestID selects the subnet with the lowest subnet ID.
+	SubnetSelectionPolicyLowestID SubnetSelectionPolicy = "LowestID"
+
+	// SubnetSelectionPolicyTagPriority selects the subnet with the lowest integer value of the priority tag. Subnets
+	// without a valid value for the tag are selected last, and ties are broken by the lowest subnet ID.
+	SubnetSelectionPolicyTagPriority SubnetSelectionPolicy = "TagPriority"
+)
+
+// SubnetSelection configures how a subnet is selected when more than one private or public subnet is specified for
+// an availability zone.
+type SubnetSelection struct {
+	// Policy is the policy used to select the subnet.
+	// +kubebuilder:validation:Enum=LowestID;TagPriority
+	Policy SubnetSelectionPolicy `json:"policy"`
+
+	// PriorityTagKey is the key of the subnet tag holding the priority of the subnet. Lower values have higher
+	// priority. Required when the policy is TagPriority.
+	// +optional
+	PriorityTagKey string `json:"priorityTagKey,omitempty"`
+}
+
+// ResourceNames are templates of the names of the existing AWS resources of a cluster used by the machines of a
+// machine pool. In each template, {infraID} is replaced with the infrastructure ID of the cluster. Only the template of
+// PrivateSubnet may use {zone}, which is replaced with the availability zone of the machines, as the IAM instance
+// profile and security group are shared by the machines of all zones.
+type ResourceNames struct {
+	// IAMInstanceProfile is the name of the IAM instance profile of the machines. Defaults to {infraID}-worker-profile.
+	// It cannot use {zone}.
+	// +optional
+	IAMInstanceProfile string `json:"iamInstanceProfile,omitempty"`
+
+	// PrivateSubnet is the Name tag of the private subnets of the machines, which is only used when the pool does not
+	// list its subnets. Defaults to {infraID}-private-{zone}.
+	// +optional
+	PrivateSubnet string `json:"privateSubnet,omitempty"`
+
+	// SecurityGroup is the Name tag of the security group of the machines. Defaults to {infraID}-worker-sg. It cannot
+	// use {zone}, and is not used by pools with the preserve-security-groups annotation.
+	// +optional
+	SecurityGroup string `json:"securityGroup,omitempty"`
+}
+
+// MarketType is the purchasing option of EC2 instances.
+type MarketType string
+
+const (
+	// OnDemandMarketType is for on-demand instances.
+	OnDemandMarketType MarketType = "on-demand"
+
+	// SpotMarketType is for spot instances.
+	SpotMarketType MarketType = "spot"
+
+	// CapacityBlockMarketType is for instances in capacity blocks for ML, which are capacity reservations.
+	CapacityBlockMarketType MarketType = "capacity-block"
+)
+
 // SpotMarketOptions defines the options available to a user when configuring
 // Machines to run on Spot instances.
 // Most users should provide an empty struct.
@@ -31,6 +271,14 @@ type SpotMarketOptions struct {
 	// Default: On-Demand price
 	// +optional
 	MaxPrice *string `json:"maxPrice,omitempty"`
+
+	// InstanceInterruptionBehavior is the behavior when a Spot instance is interrupted.
+	// Machines use one-time spot requests, which are always terminated on interruption, so the only valid value
+	// is terminate.
+	// Default: terminate
+	// +kubebuilder:validation:Enum=terminate
+	// +optional
+	InstanceInterruptionBehavior string `json:"instanceInterruptionBehavior,omitempty"`
 }
 
 // EC2RootVolume defines the storage for an ec2 instance.
@@ -41,8 +289,14 @@ type EC2RootVolume struct {
 	Size int `json:"size"`
 	// Type defines the type of the storage.
 	Type string `json:"type"`
+	// Encrypted controls whether the volume is encrypted. Defaults to true. When false, the volume is only
+	// encrypted if EBS encryption by default is enabled for the account, and no KMS key may be set.
+	// +optional
+	Encrypted *bool `json:"encrypted,omitempty"`
 	// The KMS key that will be used to encrypt the EBS volume.
 	// If no key is provided the default KMS key for the account will be used.
+	// The key may be given by its ARN or by an alias, such as alias/ebs-worker, which is resolved to its ARN in the
+	// region and account of the cluster.
 	// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_GetEbsDefaultKmsKeyId.html
 	// +optional
 	KMSKeyARN string `json:"kmsKeyARN,omitempty"`
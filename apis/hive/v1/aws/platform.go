@@ -20,6 +20,16 @@ type Platform struct {
 	// Region specifies the AWS region where the cluster will be created.
 	Region string `json:"region"`
 
+	// Partition is the AWS partition containing the region, e.g. aws-us-gov for GovCloud or aws-iso for C2S.
+	// Defaults to the partition the region belongs to.
+	// +optional
+	Partition string `json:"partition,omitempty"`
+
+	// ServiceEndpoints overrides the default endpoints of the AWS services used to manage the cluster.
+	// There must be only one ServiceEndpoint for a service.
+	// +optional
+	ServiceEndpoints []ServiceEndpoint `json:"serviceEndpoints,omitempty"`
+
 	// UserTags specifies additional tags for AWS resources created for the cluster.
 	// +optional
 	UserTags map[string]string `json:"userTags,omitempty"`
@@ -31,6 +41,20 @@ type Platform struct {
 	PrivateLink *PrivateLinkAccess `json:"privateLink,omitempty"`
 }
 
+// ServiceEndpoint stores the configuration for services to
+// override existing defaults of AWS Services.
+type ServiceEndpoint struct {
+	// Name is the name of the AWS service, e.g. ec2 or sts.
+	// This must be provided and cannot be empty.
+	Name string `json:"name"`
+
+	// URL is fully qualified URI with scheme https, that overrides the default generated
+	// endpoint for a client.
+	// This must be provided and cannot be empty.
+	// +kubebuilder:validation:Pattern=`^https://`
+	URL string `json:"url"`
+}
+
 // PlatformStatus contains the observed state on AWS platform.
 type PlatformStatus struct {
 	PrivateLink *PrivateLinkAccessStatus `json:"privateLink,omitempty"`
@@ -4,6 +4,10 @@
 
 package aws
 
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AssumeRole) DeepCopyInto(out *AssumeRole) {
 	*out = *in
@@ -20,9 +24,35 @@ func (in *AssumeRole) DeepCopy() *AssumeRole {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockDevice) DeepCopyInto(out *BlockDevice) {
+	*out = *in
+	if in.Encrypted != nil {
+		in, out := &in.Encrypted, &out.Encrypted
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockDevice.
+func (in *BlockDevice) DeepCopy() *BlockDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EC2RootVolume) DeepCopyInto(out *EC2RootVolume) {
 	*out = *in
+	if in.Encrypted != nil {
+		in, out := &in.Encrypted, &out.Encrypted
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -36,6 +66,38 @@ func (in *EC2RootVolume) DeepCopy() *EC2RootVolume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailureDomain) DeepCopyInto(out *FailureDomain) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailureDomain.
+func (in *FailureDomain) DeepCopy() *FailureDomain {
+	if in == nil {
+		return nil
+	}
+	out := new(FailureDomain)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceTypeSelector) DeepCopyInto(out *InstanceTypeSelector) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceTypeSelector.
+func (in *InstanceTypeSelector) DeepCopy() *InstanceTypeSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceTypeSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachinePoolPlatform) DeepCopyInto(out *MachinePoolPlatform) {
 	*out = *in
@@ -44,17 +106,76 @@ func (in *MachinePoolPlatform) DeepCopyInto(out *MachinePoolPlatform) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ZoneIDs != nil {
+		in, out := &in.ZoneIDs, &out.ZoneIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Subnets != nil {
 		in, out := &in.Subnets, &out.Subnets
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	out.EC2RootVolume = in.EC2RootVolume
+	if in.SubnetSelection != nil {
+		in, out := &in.SubnetSelection, &out.SubnetSelection
+		*out = new(SubnetSelection)
+		**out = **in
+	}
+	if in.PrivateSubnets != nil {
+		in, out := &in.PrivateSubnets, &out.PrivateSubnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PublicIP != nil {
+		in, out := &in.PublicIP, &out.PublicIP
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FailureDomains != nil {
+		in, out := &in.FailureDomains, &out.FailureDomains
+		*out = make([]FailureDomain, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceNames != nil {
+		in, out := &in.ResourceNames, &out.ResourceNames
+		*out = new(ResourceNames)
+		**out = **in
+	}
+	if in.InstanceTypeSelector != nil {
+		in, out := &in.InstanceTypeSelector, &out.InstanceTypeSelector
+		*out = new(InstanceTypeSelector)
+		**out = **in
+	}
+	if in.InstanceTypesByZone != nil {
+		in, out := &in.InstanceTypesByZone, &out.InstanceTypesByZone
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.EC2RootVolume.DeepCopyInto(&out.EC2RootVolume)
+	if in.AdditionalBlockDevices != nil {
+		in, out := &in.AdditionalBlockDevices, &out.AdditionalBlockDevices
+		*out = make([]BlockDevice, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.SpotMarketOptions != nil {
 		in, out := &in.SpotMarketOptions, &out.SpotMarketOptions
 		*out = new(SpotMarketOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CredentialsAssumeRole != nil {
+		in, out := &in.CredentialsAssumeRole, &out.CredentialsAssumeRole
+		*out = new(AssumeRole)
+		**out = **in
+	}
 	return
 }
 
@@ -77,6 +198,11 @@ func (in *Platform) DeepCopyInto(out *Platform) {
 		*out = new(AssumeRole)
 		**out = **in
 	}
+	if in.ServiceEndpoints != nil {
+		in, out := &in.ServiceEndpoints, &out.ServiceEndpoints
+		*out = make([]ServiceEndpoint, len(*in))
+		copy(*out, *in)
+	}
 	if in.UserTags != nil {
 		in, out := &in.UserTags, &out.UserTags
 		*out = make(map[string]string, len(*in))
@@ -156,6 +282,38 @@ func (in *PrivateLinkAccessStatus) DeepCopy() *PrivateLinkAccessStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceEndpoint) DeepCopyInto(out *ServiceEndpoint) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceEndpoint.
+func (in *ServiceEndpoint) DeepCopy() *ServiceEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceNames) DeepCopyInto(out *ResourceNames) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceNames.
+func (in *ResourceNames) DeepCopy() *ResourceNames {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceNames)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SpotMarketOptions) DeepCopyInto(out *SpotMarketOptions) {
 	*out = *in
@@ -177,6 +335,22 @@ func (in *SpotMarketOptions) DeepCopy() *SpotMarketOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetSelection) DeepCopyInto(out *SubnetSelection) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetSelection.
+func (in *SubnetSelection) DeepCopy() *SubnetSelection {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetSelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VPCEndpointService) DeepCopyInto(out *VPCEndpointService) {
 	*out = *in
@@ -1,5 +1,9 @@
 package azure
 
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
 // MachinePool stores the configuration for a machine pool installed
 // on Azure.
 type MachinePool struct {
@@ -13,8 +17,97 @@ type MachinePool struct {
 
 	// OSDisk defines the storage for instance.
 	OSDisk `json:"osDisk"`
+
+	// AvailabilitySet is the name of the availability set in which to place the machines, to spread them across
+	// fault domains in regions without availability zones. Cannot be used together with zones.
+	// +optional
+	AvailabilitySet string `json:"availabilitySet,omitempty"`
+
+	// Placement chooses how the machines are spread: across the availability zones of the region in a MachineSet per
+	// zone, or in a single MachineSet without zones whose machines the machine API places in an availability set,
+	// for regions without availability zones. Auto, the default, uses availability zones when the region supports them
+	// for the instance type, and an availability set otherwise.
+	// +kubebuilder:validation:Enum="";Auto;Zones;AvailabilitySet
+	// +optional
+	Placement Placement `json:"placement,omitempty"`
+
+	// SpotVMOptions allows the machines of the pool to run on Azure Spot VMs.
+	// +optional
+	SpotVMOptions *SpotVMOptions `json:"spotVMOptions,omitempty"`
+
+	// AcceleratedNetworking enables accelerated networking on the network interfaces of the machines. The instance
+	// type must support accelerated networking.
+	// +optional
+	AcceleratedNetworking bool `json:"acceleratedNetworking,omitempty"`
+
+	// BootDiagnostics configures where the boot diagnostics of the machines, their serial console output and
+	// screenshots, are stored. Setting it requests boot diagnostics like the bootDiagnostics field of the MachinePool,
+	// which stores them in a storage account managed by Azure.
+	// +optional
+	BootDiagnostics *BootDiagnostics `json:"bootDiagnostics,omitempty"`
+}
+
+// Placement is how the machines of an Azure MachinePool are spread.
+type Placement string
+
+const (
+	// PlacementAuto places the machines in availability zones when the region supports them, and in an availability
+	// set otherwise.
+	PlacementAuto Placement = "Auto"
+	// PlacementZones places the machines in availability zones.
+	PlacementZones Placement = "Zones"
+	// PlacementAvailabilitySet places the machines in an availability set, without availability zones.
+	PlacementAvailabilitySet Placement = "AvailabilitySet"
+)
+
+// SpotVMOptions defines the options for running machines on Azure Spot VMs.
+type SpotVMOptions struct {
+	// MaxPrice is the maximum price per hour the user is willing to pay for the Spot VMs.
+	// Default: the pay-as-you-go price
+	// +optional
+	MaxPrice *resource.Quantity `json:"maxPrice,omitempty"`
+
+	// EvictionPolicy is what happens to a Spot VM when it is evicted.
+	// The valid values are Deallocate and Delete.
+	// Default: Deallocate
+	// +kubebuilder:validation:Enum=Deallocate;Delete
+	// +optional
+	EvictionPolicy EvictionPolicy `json:"evictionPolicy,omitempty"`
+}
+
+// EvictionPolicy is the policy applied to a Spot VM when it is evicted.
+type EvictionPolicy string
+
+const (
+	// EvictionPolicyDeallocate stops an evicted Spot VM, keeping its disks.
+	EvictionPolicyDeallocate EvictionPolicy = "Deallocate"
+	// EvictionPolicyDelete deletes an evicted Spot VM and its disks.
+	EvictionPolicyDelete EvictionPolicy = "Delete"
+)
+
+// BootDiagnostics defines the storage of the boot diagnostics of the machines on Azure.
+type BootDiagnostics struct {
+	// StorageAccountType is the type of the storage account of the boot diagnostics: Managed, a storage account
+	// managed by Azure, or UserManaged, the storage account of StorageAccountURI.
+	// +kubebuilder:validation:Enum=Managed;UserManaged
+	StorageAccountType BootDiagnosticsStorageAccountType `json:"storageAccountType"`
+
+	// StorageAccountURI is the blob service endpoint of the user-managed storage account of the boot diagnostics,
+	// e.g. https://mystorageaccount.blob.core.windows.net/. Required for, and only allowed with, UserManaged.
+	// +optional
+	StorageAccountURI string `json:"storageAccountURI,omitempty"`
 }
 
+// BootDiagnosticsStorageAccountType is the type of the storage account of the boot diagnostics of Azure machines.
+type BootDiagnosticsStorageAccountType string
+
+const (
+	// BootDiagnosticsStorageAccountManaged stores the boot diagnostics in a storage account managed by Azure.
+	BootDiagnosticsStorageAccountManaged BootDiagnosticsStorageAccountType = "Managed"
+	// BootDiagnosticsStorageAccountUserManaged stores the boot diagnostics in a storage account of the user.
+	BootDiagnosticsStorageAccountUserManaged BootDiagnosticsStorageAccountType = "UserManaged"
+)
+
 // OSDisk defines the disk for machines on Azure.
 type OSDisk struct {
 	// DiskSizeGB defines the size of disk in GB.
@@ -38,4 +131,24 @@ func (a *MachinePool) Set(required *MachinePool) {
 	if required.OSDisk.DiskSizeGB != 0 {
 		a.OSDisk.DiskSizeGB = required.OSDisk.DiskSizeGB
 	}
+
+	if required.AvailabilitySet != "" {
+		a.AvailabilitySet = required.AvailabilitySet
+	}
+
+	if required.Placement != "" {
+		a.Placement = required.Placement
+	}
+
+	if required.SpotVMOptions != nil {
+		a.SpotVMOptions = required.SpotVMOptions
+	}
+
+	if required.AcceleratedNetworking {
+		a.AcceleratedNetworking = required.AcceleratedNetworking
+	}
+
+	if required.BootDiagnostics != nil {
+		a.BootDiagnostics = required.BootDiagnostics
+	}
 }
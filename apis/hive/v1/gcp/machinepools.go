@@ -13,6 +13,72 @@ type MachinePool struct {
 	//
 	// +optional
 	OSDisk OSDisk `json:"osDisk"`
+
+	// ServiceAccount is the service account and access scopes to attach to the instances.
+	// Defaults to the worker service account created by the installer.
+	//
+	// +optional
+	ServiceAccount *ServiceAccount `json:"serviceAccount,omitempty"`
+
+	// NetworkTags is a list of network tags to add to the instances, in addition to those set by the installer, e.g.
+	// to match firewall rules. Tags must be 1-63 characters long, start with a lowercase letter and contain only
+	// lowercase letters, digits and dashes, and must not end with a dash.
+	//
+	// +optional
+	NetworkTags []string `json:"networkTags,omitempty"`
+
+	// ShieldedInstanceConfig configures the shielded VM options of the instances.
+	//
+	// +optional
+	ShieldedInstanceConfig *ShieldedInstanceConfig `json:"shieldedInstanceConfig,omitempty"`
+
+	// ConfidentialCompute enables confidential computing, which encrypts the memory of the instances. Only supported
+	// on machine types of the n2d, c2d and c3d families.
+	// The valid values are Enabled and Disabled. Defaults to Disabled.
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	// +optional
+	ConfidentialCompute FeatureState `json:"confidentialCompute,omitempty"`
+}
+
+// FeatureState is whether an optional feature of the instances is enabled.
+type FeatureState string
+
+const (
+	// FeatureStateEnabled enables the feature.
+	FeatureStateEnabled FeatureState = "Enabled"
+
+	// FeatureStateDisabled disables the feature.
+	FeatureStateDisabled FeatureState = "Disabled"
+)
+
+// ShieldedInstanceConfig defines the shielded VM options of instances on GCP. Options that are not set use the GCP
+// defaults for the image.
+type ShieldedInstanceConfig struct {
+	// SecureBoot controls whether the instances boot with secure boot.
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	// +optional
+	SecureBoot FeatureState `json:"secureBoot,omitempty"`
+
+	// VirtualizedTrustedPlatformModule controls whether the instances have a virtual trusted platform module (vTPM).
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	// +optional
+	VirtualizedTrustedPlatformModule FeatureState `json:"virtualizedTrustedPlatformModule,omitempty"`
+
+	// IntegrityMonitoring controls whether the boot integrity of the instances is monitored. Requires the
+	// virtualized trusted platform module.
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	// +optional
+	IntegrityMonitoring FeatureState `json:"integrityMonitoring,omitempty"`
+}
+
+// ServiceAccount describes a GCP service account and the access scopes granted to it.
+type ServiceAccount struct {
+	// Email is the email address of the service account.
+	Email string `json:"email"`
+
+	// Scopes is the list of access scopes granted to the service account.
+	// eg. https://www.googleapis.com/auth/cloud-platform
+	Scopes []string `json:"scopes"`
 }
 
 // OSDisk defines the disk for machines on GCP.
@@ -89,6 +89,10 @@ type HiveConfigSpec struct {
 	// +optional
 	ControllersConfig *ControllersConfig `json:"controllersConfig,omitempty"`
 
+	// MachinePoolConfig is used to configure the machinepool controller.
+	// +optional
+	MachinePoolConfig *MachinePoolControllerConfig `json:"machinePoolConfig,omitempty"`
+
 	// AWSPrivateLink defines the configuration for the aws-private-link controller.
 	// It provides 3 major pieces of information required by the controller,
 	// 1. The Credentials that should be used to create AWS PrivateLink resources other than
@@ -589,6 +593,87 @@ type ControllersConfig struct {
 	Controllers []SpecificControllerConfig `json:"controllers,omitempty"`
 }
 
+// MachinePoolControllerConfig contains the configuration for the machinepool controller.
+type MachinePoolControllerConfig struct {
+	// AWSAMISources are the sources of the AMIs of AWS MachinePools without a boot image in their spec, in the order
+	// they are tried: ImageIDOverride for the image-id-override annotation of the MachinePool, RHCOSStream for the RHCOS
+	// stream metadata of its rhcos-stream-configmap annotation, and Cluster for the AMI of the master machines of the
+	// cluster. Sources which provide no AMI for a MachinePool are skipped, and sources missing from the list are never
+	// used. The source which provided the AMI is recorded in the bootImage status of the MachinePool. Defaults to
+	// ImageIDOverride, RHCOSStream, Cluster.
+	// +optional
+	AWSAMISources []BootImageSource `json:"awsAMISources,omitempty"`
+
+	// AWSAPIRateLimit limits the rate of the AWS API calls made by the machinepool controller to describe the
+	// resources used by MachinePools. The limit applies separately to each AWS account and is shared by the
+	// MachinePools of all clusters in that account. The calls are not rate limited when this is not set.
+	// +optional
+	AWSAPIRateLimit *APIRateLimit `json:"awsAPIRateLimit,omitempty"`
+
+	// AWSQuotaCheck enables checking the machines of AWS MachinePools against the EC2 vCPU and EBS storage quotas of
+	// their account with the Service Quotas API, which sets the InsufficientQuota condition of the MachinePools whose
+	// machines likely exceed a quota. The check makes extra AWS API calls on each reconcile, and requires the
+	// servicequotas:GetServiceQuota and servicequotas:GetAWSDefaultServiceQuota permissions.
+	// +optional
+	AWSQuotaCheck bool `json:"awsQuotaCheck,omitempty"`
+
+	// AWSRetry configures how the machinepool controller retries AWS describe calls failing with transient errors,
+	// such as throttling, within a reconcile.
+	// +optional
+	AWSRetry *AWSRetryConfig `json:"awsRetry,omitempty"`
+
+	// AWSSpotInstancesVersions is the range of the versions of the clusters whose AWS MachinePools may use spot
+	// instances, in the syntax of semver ranges, e.g. ">=4.4.0 <5.0.0". Pre-release versions are compared by their
+	// major, minor and patch versions only. Individual MachinePools can bypass the range with the
+	// bypass-spot-instances-version-gate annotation. Defaults to ">=4.5.0".
+	// +optional
+	AWSSpotInstancesVersions string `json:"awsSpotInstancesVersions,omitempty"`
+
+	// AWSSubnetIPCheck enables checking that the private subnets of AWS MachinePools have enough available IP
+	// addresses for the machines the MachinePools add in them, which sets the InsufficientSubnetIPs condition of the
+	// MachinePools whose machines likely exceed the available addresses. The check is only a warning, and makes extra
+	// AWS API calls on each reconcile.
+	// +optional
+	AWSSubnetIPCheck bool `json:"awsSubnetIPCheck,omitempty"`
+
+	// ConfigurationErrorRequeueInterval is how long the machinepool controller waits before reconciling a MachinePool
+	// again when its MachineSets cannot be generated because of a configuration error which requires user action, such
+	// as invalid subnets. Changes to the MachinePool are reconciled immediately regardless. Transient errors are retried
+	// with the backoff of the controller instead. A zero interval only reconciles such MachinePools when they change.
+	// Defaults to 1h.
+	// +optional
+	ConfigurationErrorRequeueInterval *metav1.Duration `json:"configurationErrorRequeueInterval,omitempty"`
+
+	// AdditionalTags are tags added to the machines of every MachinePool, such as a cost center or an environment.
+	// They take precedence over the user tags of the cluster, while the tags reserved by the installer and the
+	// machine API take precedence over them. Only AWS MachinePools are tagged at the moment.
+	// +optional
+	AdditionalTags map[string]string `json:"additionalTags,omitempty"`
+}
+
+// AWSRetryConfig configures the retries of AWS calls failing with transient errors.
+type AWSRetryConfig struct {
+	// Retries is the number of times a call is retried before the reconcile fails. Defaults to 3.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Retries *int32 `json:"retries,omitempty"`
+	// Interval is the interval before the first retry of a call. The interval doubles with each retry. Defaults to
+	// 500ms.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// APIRateLimit is a token bucket rate limit for calls to a cloud API.
+type APIRateLimit struct {
+	// QPS is the sustained number of calls per second allowed.
+	// +kubebuilder:validation:Minimum=1
+	QPS int32 `json:"qps"`
+	// Burst is the maximum number of calls allowed at once. Defaults to QPS.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+}
+
 // +genclient:nonNamespaced
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -3,6 +3,7 @@ package v1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/openshift/hive/apis/hive/v1/aws"
 	"github.com/openshift/hive/apis/hive/v1/azure"
@@ -18,6 +19,46 @@ const (
 	// is intended for very limited use cases we do not recommend pursuing regularly. As such it is not currently
 	// part of our official API.
 	MachinePoolImageIDOverrideAnnotation = "hive.openshift.io/image-id-override"
+
+	// MachinePoolZoneImageIDOverridesAnnotation can be applied to AWS MachinePools to use a different image ID for the
+	// MachineSets generated in some availability zones. The value is a comma-separated list of zone=image-id pairs,
+	// e.g. "us-east-1a=ami-0123,us-east-1b=ami-4567". Zones not listed use the image ID of the cluster, or that of the
+	// image-id-override annotation when it is set. Like that annotation, it is not part of our official API.
+	MachinePoolZoneImageIDOverridesAnnotation = "hive.openshift.io/zone-image-id-overrides"
+
+	// MachinePoolRHCOSStreamConfigMapAnnotation can be applied to AWS MachinePools to resolve the image ID from the
+	// RHCOS stream metadata embedded in a release image, e.g. to try the boot image of a newer release on a canary pool.
+	// The value is the name of a ConfigMap in the namespace of the MachinePool holding the stream metadata under the
+	// "stream" key, such as the coreos-bootimages ConfigMap of the release manifests. When the image ID cannot be
+	// resolved for the region of the cluster, the image ID of the cluster is used and the AMIResolutionFailed
+	// condition is set. The image-id-override annotation takes precedence over this one.
+	MachinePoolRHCOSStreamConfigMapAnnotation = "hive.openshift.io/rhcos-stream-configmap"
+
+	// MachinePoolAvailabilityZoneStatesAnnotation can be applied to AWS MachinePools that do not list their zones to
+	// choose which states of the availability zones of the region are used for the generated MachineSets. The value is
+	// a comma-separated list of zone states, e.g. "available,information". By default only available zones are used,
+	// so that impaired or unavailable zones do not get workers that never provision.
+	MachinePoolAvailabilityZoneStatesAnnotation = "hive.openshift.io/availability-zone-states"
+
+	// MachinePoolPreserveSecurityGroupsAnnotation can be applied to AWS MachinePools with a value of "true" to leave
+	// the security groups of the generated MachineSets as provided by the installer, i.e. filtered by the
+	// <infraID>-<pool name>-sg Name tag, for clusters whose security groups are managed outside of Hive. By default
+	// the security groups are replaced with the <infraID>-worker-sg security group created by the installer.
+	MachinePoolPreserveSecurityGroupsAnnotation = "hive.openshift.io/preserve-security-groups"
+
+	// MachinePoolClusterVersionOverrideAnnotation can be applied to MachinePools to pin the cluster version used by
+	// the actuators when deciding which features are supported by the cluster, instead of the version reported for the
+	// ClusterDeployment. This can avoid spurious UnsupportedConfiguration conditions while the reported version lags
+	// behind during an upgrade. Setting a version the cluster is not actually running can generate MachineSets the
+	// cluster does not support, so the annotation should be removed once the reported version has caught up.
+	MachinePoolClusterVersionOverrideAnnotation = "hive.openshift.io/cluster-version-override"
+
+	// MachinePoolBypassSpotInstancesVersionGateAnnotation can be applied to AWS MachinePools with a value of "true" to
+	// use spot instances whatever the version of the cluster, for custom builds whose versions are wrongly outside the
+	// range of versions supporting spot instances. Unlike the cluster-version-override annotation, it does not affect
+	// the other version-gated features. A cluster which does not actually support spot instances creates on-demand
+	// instances, or none at all, so the annotation should only be used when the cluster is known to support them.
+	MachinePoolBypassSpotInstancesVersionGateAnnotation = "hive.openshift.io/bypass-spot-instances-version-gate"
 )
 
 // MachinePoolSpec defines the desired state of MachinePool
@@ -33,6 +74,7 @@ type MachinePoolSpec struct {
 	// Replicas is the count of machines for this machine pool.
 	// Replicas and autoscaling cannot be used together.
 	// Default is 1, if autoscaling is not used.
+	// Zero keeps a MachineSet with no replicas in each zone of the pool, so that it can be scaled up again quickly.
 	// +optional
 	Replicas *int64 `json:"replicas,omitempty"`
 
@@ -47,6 +89,9 @@ type MachinePoolSpec struct {
 	// Map of label string keys and values that will be applied to the created MachineSet's
 	// MachineSpec. This list will overwrite any modifications made to Node labels on an
 	// ongoing basis.
+	// The machine API copies these labels to the Node of each Machine once the Node has registered, rather than the
+	// kubelet registering the Node with them, so they are briefly missing from new Nodes. Changes only reach the
+	// Nodes of Machines created afterwards, and removed labels are left on existing Nodes.
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
 
@@ -54,14 +99,123 @@ type MachinePoolSpec struct {
 	// This list will overwrite any modifications made to Node taints on an ongoing basis.
 	// +optional
 	Taints []corev1.Taint `json:"taints,omitempty"`
+
+	// UserDataSecretName is the name of the secret in the openshift-machine-api namespace of the remote cluster
+	// containing the user data used to configure the machines in the machine pool. The secret must already exist
+	// in the remote cluster. Defaults to the worker-user-data secret managed by the machine-config-operator.
+	// +optional
+	UserDataSecretName string `json:"userDataSecretName,omitempty"`
+
+	// MergeIgnitionSecretRef references a secret in the namespace of the MachinePool holding an Ignition config under
+	// the ignition key, such as extra systemd units, to merge into the user data of the machines in the machine pool.
+	// Hive creates a <name>-merged-user-data secret in the openshift-machine-api namespace of the remote cluster whose
+	// Ignition config merges the user data of UserDataSecretName, or of the default secret, with this config, and the
+	// machine sets reference that secret instead. The config must use Ignition spec 3, or spec 2 for clusters older
+	// than OpenShift 4.6, like the user data it is merged into. The config is readable by anyone who can read secrets
+	// in the openshift-machine-api namespace of the remote cluster and by the instance metadata service of the
+	// machines, so it must not contain credentials.
+	// +optional
+	MergeIgnitionSecretRef *corev1.LocalObjectReference `json:"mergeIgnitionSecretRef,omitempty"`
+
+	// BootDiagnostics requests that the console output of the machines in the machine pool be available for
+	// debugging machines that fail to boot. On AWS, the EC2 serial console must be enabled for the account and the
+	// instance type must be built on the Nitro system. On Azure, boot diagnostics are not yet supported by the
+	// machine API. On other platforms, the console output of the machines is always available.
+	// +optional
+	BootDiagnostics bool `json:"bootDiagnostics,omitempty"`
+
+	// UpdateStrategy controls how changes to the generated provider specs of the MachineSets, such as a new AMI, are
+	// applied to the MachineSets already in the remote cluster. Defaults to the OnDelete strategy.
+	// +optional
+	UpdateStrategy *MachinePoolUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// DeletePolicy is the policy by which the MachineSets of the machine pool choose the machines to delete when
+	// scaling down, set as the deletePolicy of the MachineSets. The machine API deletes random machines by default.
+	// +kubebuilder:validation:Enum=Random;Newest;Oldest
+	// +optional
+	DeletePolicy MachinePoolDeletePolicy `json:"deletePolicy,omitempty"`
+
+	// BootImage is the image the machines of the machine pool boot from, instead of the image of the master machines
+	// of the cluster, e.g. to use a newer RHCOS boot image on an older cluster. It is the AMI ID on AWS, the image
+	// name or URL on GCP, the Glance image name or ID on OpenStack, and the name of the VM template on vSphere and
+	// oVirt. It is not supported on Azure, where the image is determined by the infrastructure ID of the cluster.
+	// Takes precedence over the image-id-override and rhcos-stream-configmap annotations.
+	// +optional
+	BootImage string `json:"bootImage,omitempty"`
 }
 
+// MachinePoolUpdateStrategyType is a strategy for applying changes to the provider specs of existing MachineSets.
+type MachinePoolUpdateStrategyType string
+
+const (
+	// OnDeleteMachinePoolUpdateStrategyType leaves the provider specs of existing MachineSets unchanged, so that changes
+	// only apply to MachineSets created afterwards, e.g. once the existing ones are deleted.
+	OnDeleteMachinePoolUpdateStrategyType MachinePoolUpdateStrategyType = "OnDelete"
+
+	// ImmediateMachinePoolUpdateStrategyType updates the provider specs of all existing MachineSets at once.
+	ImmediateMachinePoolUpdateStrategyType MachinePoolUpdateStrategyType = "Immediate"
+
+	// RollingMachinePoolUpdateStrategyType updates the provider specs of existing MachineSets a few at a time, waiting
+	// for the updated MachineSets to have all their replicas ready before updating more.
+	RollingMachinePoolUpdateStrategyType MachinePoolUpdateStrategyType = "Rolling"
+)
+
+// MachinePoolUpdateStrategy controls how changes to the provider specs of the MachineSets of a MachinePool are applied
+// to the MachineSets in the remote cluster. The machine API does not replace existing machines when the provider spec
+// of their MachineSet changes: only the machines created afterwards, e.g. when scaling up or replacing a deleted
+// machine, use the new provider spec.
+type MachinePoolUpdateStrategy struct {
+	// Type is the update strategy.
+	// +kubebuilder:validation:Enum=OnDelete;Immediate;Rolling
+	Type MachinePoolUpdateStrategyType `json:"type"`
+
+	// MaxUnavailable is the maximum number of updated MachineSets which do not have all their replicas ready for the
+	// Rolling strategy, either a number of at least 1 or a percentage of the MachineSets of the pool, e.g. 25%, from
+	// 1% to 100%. Percentages are rounded down, but allow at least one MachineSet. No more MachineSets are updated
+	// while that many updated MachineSets are not ready. Defaults to 1.
+	// +kubebuilder:validation:XIntOrString
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// MachinePoolDeletePolicy is the policy by which the MachineSets of a machine pool choose the machines to delete when
+// scaling down. Machines annotated for deletion and unhealthy machines are deleted first with every policy.
+type MachinePoolDeletePolicy string
+
+const (
+	// RandomMachinePoolDeletePolicy deletes random machines.
+	RandomMachinePoolDeletePolicy MachinePoolDeletePolicy = "Random"
+
+	// NewestMachinePoolDeletePolicy deletes the most recently created machines first.
+	NewestMachinePoolDeletePolicy MachinePoolDeletePolicy = "Newest"
+
+	// OldestMachinePoolDeletePolicy deletes the oldest machines first.
+	OldestMachinePoolDeletePolicy MachinePoolDeletePolicy = "Oldest"
+)
+
 // MachinePoolAutoscaling details how the machine pool is to be auto-scaled.
 type MachinePoolAutoscaling struct {
 	// MinReplicas is the minimum number of replicas for the machine pool.
 	MinReplicas int32 `json:"minReplicas"`
 
-	// MaxReplicas is the maximum number of replicas for the machine pool.
+	// MaxReplicas is the maximum number of replicas for the machine pool. The replicas are split across the
+	// MachineSets of the pool, and MachineSets whose share is zero are kept at zero replicas without a
+	// MachineAutoscaler.
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// ZoneReplicas overrides the minimum and maximum replicas of the MachineSets of the pool in specific availability
+	// zones, keyed by zone. The rest of the replicas of the pool are split across the MachineSets of the other zones, so
+	// the minimum and maximum replicas of the overrides must not add up to more than those of the pool.
+	// +optional
+	ZoneReplicas map[string]MachinePoolZoneAutoscaling `json:"zoneReplicas,omitempty"`
+}
+
+// MachinePoolZoneAutoscaling details how the MachineSet of a machine pool in an availability zone is auto-scaled.
+type MachinePoolZoneAutoscaling struct {
+	// MinReplicas is the minimum number of replicas in the zone.
+	MinReplicas int32 `json:"minReplicas"`
+
+	// MaxReplicas is the maximum number of replicas in the zone.
 	MaxReplicas int32 `json:"maxReplicas"`
 }
 
@@ -91,11 +245,140 @@ type MachinePoolStatus struct {
 	// MachineSets is the status of the machine sets for the machine pool on the remote cluster.
 	MachineSets []MachineSetStatus `json:"machineSets,omitempty"`
 
+	// GeneratedMachineSets is the number of machine sets generated for the machine pool in the most recent reconcile.
+	// +optional
+	GeneratedMachineSets int32 `json:"generatedMachineSets,omitempty"`
+
+	// GeneratedReplicas is the total number of replicas of the machine sets generated for the machine pool in the
+	// most recent reconcile.
+	// +optional
+	GeneratedReplicas int32 `json:"generatedReplicas,omitempty"`
+
+	// GeneratedZoneReplicas is the number of replicas of the machine sets generated for the machine pool in each
+	// availability zone in the most recent reconcile, showing how the replicas are spread across the zones. The
+	// minimum replicas of each zone are reported for auto-scaling pools.
+	// +optional
+	GeneratedZoneReplicas map[string]int32 `json:"generatedZoneReplicas,omitempty"`
+
+	// ImageIDs is the image ID used for the machine sets generated in each availability zone of the machine pool in
+	// the most recent reconcile. Only reported for AWS.
+	// +optional
+	ImageIDs map[string]string `json:"imageIDs,omitempty"`
+
+	// SubnetsByAvailabilityZone is the ID of the subnet used for the machine sets generated in each availability zone
+	// of the machine pool in the most recent reconcile, as selected from the subnets or failure domains of the machine
+	// pool. Not reported for machine pools whose machine sets use the subnets named by the installer, and cleared while
+	// the configuration of the machine pool is invalid. Only reported for AWS.
+	// +optional
+	SubnetsByAvailabilityZone map[string]string `json:"subnetsByAvailabilityZone,omitempty"`
+
+	// UserDataSecret is the user data secret referenced by the machine sets generated for the machine pool in the
+	// most recent reconcile.
+	// +optional
+	UserDataSecret *UserDataSecretStatus `json:"userDataSecret,omitempty"`
+
+	// BootImage is the image used for the machine sets generated for the machine pool in the most recent reconcile,
+	// and where it comes from. On AWS, zones with an image of their own in the zone-image-id-overrides annotation use
+	// that image instead, as reported by ImageIDs. Not reported for Azure.
+	// +optional
+	BootImage *BootImageStatus `json:"bootImage,omitempty"`
+
+	// PrunedMachineSets is the list of machine sets most recently deleted from the remote cluster because they are
+	// no longer generated for the machine pool, such as when a zone is removed from the machine pool.
+	// +optional
+	PrunedMachineSets []string `json:"prunedMachineSets,omitempty"`
+
+	// Capabilities lists the features of machine pools on the platform of the cluster and whether the machine pool
+	// can use them with the version of the cluster, as of the most recent reconcile. Only reported for AWS.
+	// +optional
+	Capabilities []MachinePoolCapability `json:"capabilities,omitempty"`
+
 	// Conditions includes more detailed status for the cluster deployment
 	// +optional
 	Conditions []MachinePoolCondition `json:"conditions,omitempty"`
 }
 
+// UserDataSecretStatus identifies the user data secret selected for the machine sets of a machine pool.
+type UserDataSecretStatus struct {
+	// Name is the name of the secret in the openshift-machine-api namespace of the remote cluster.
+	Name string `json:"name"`
+
+	// ClusterVersion is the version of the cluster when the secret was selected, as used by the actuators when
+	// deciding which features are supported by the cluster.
+	// +optional
+	ClusterVersion string `json:"clusterVersion,omitempty"`
+}
+
+// BootImageSource is where the boot image of the machine sets of a machine pool comes from.
+type BootImageSource string
+
+const (
+	// SpecBootImageSource is the BootImage of the MachinePool spec.
+	SpecBootImageSource BootImageSource = "Spec"
+
+	// ImageIDOverrideBootImageSource is the image-id-override annotation of the MachinePool.
+	ImageIDOverrideBootImageSource BootImageSource = "ImageIDOverride"
+
+	// RHCOSStreamBootImageSource is the RHCOS stream metadata in the ConfigMap of the rhcos-stream-configmap
+	// annotation of the MachinePool.
+	RHCOSStreamBootImageSource BootImageSource = "RHCOSStream"
+
+	// ClusterBootImageSource is the image of the cluster, i.e. that of its master machines or of its boot images.
+	ClusterBootImageSource BootImageSource = "Cluster"
+)
+
+// BootImageStatus identifies the boot image of the machine sets of a machine pool.
+type BootImageStatus struct {
+	// Image is the boot image, in the form of the BootImage of the MachinePool spec for the platform.
+	Image string `json:"image"`
+
+	// Source is where the boot image comes from.
+	Source BootImageSource `json:"source"`
+}
+
+// MachinePoolCapabilityName identifies a feature of machine pools.
+type MachinePoolCapabilityName string
+
+const (
+	// SpotInstancesCapability is the use of spot instances through the SpotMarketOptions of the platform.
+	SpotInstancesCapability MachinePoolCapabilityName = "SpotInstances"
+
+	// ScaleToZeroCapability is auto-scaling with zero minimum replicas.
+	ScaleToZeroCapability MachinePoolCapabilityName = "ScaleToZero"
+
+	// IgnitionV3UserDataCapability is the merging of user data with Ignition spec 3.
+	IgnitionV3UserDataCapability MachinePoolCapabilityName = "IgnitionV3UserData"
+
+	// GP3RootVolumesCapability is the use of gp3 root volumes.
+	GP3RootVolumesCapability MachinePoolCapabilityName = "GP3RootVolumes"
+
+	// CapacityReservationsCapability is launching the machines into capacity reservations.
+	CapacityReservationsCapability MachinePoolCapabilityName = "CapacityReservations"
+
+	// NetworkInterfacesCapability is attaching additional network interfaces to the machines.
+	NetworkInterfacesCapability MachinePoolCapabilityName = "NetworkInterfaces"
+
+	// LicenseConfigurationsCapability is associating the machines with license configurations.
+	LicenseConfigurationsCapability MachinePoolCapabilityName = "LicenseConfigurations"
+
+	// SpotFallbackToOnDemandCapability is falling back from spot to on-demand instances when spot capacity is
+	// unavailable.
+	SpotFallbackToOnDemandCapability MachinePoolCapabilityName = "SpotFallbackToOnDemand"
+)
+
+// MachinePoolCapability is a feature of machine pools and whether a machine pool can use it.
+type MachinePoolCapability struct {
+	// Name identifies the feature.
+	Name MachinePoolCapabilityName `json:"name"`
+
+	// Supported is whether the machine pool can use the feature.
+	Supported bool `json:"supported"`
+
+	// Message explains why the feature is not supported.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // MachineSetStatus is the status of a machineset in the remote cluster.
 type MachineSetStatus struct {
 	// Name is the name of the machine set.
@@ -165,6 +448,108 @@ const (
 	// UnsupportedConfigurationMachinePoolCondition is true when the configuration of the MachinePool is unsupported
 	// by the cluster.
 	UnsupportedConfigurationMachinePoolCondition MachinePoolConditionType = "UnsupportedConfiguration"
+
+	// InvalidConfigurationMachinePoolCondition is true when the configuration of the MachinePool is invalid.
+	InvalidConfigurationMachinePoolCondition MachinePoolConditionType = "InvalidConfiguration"
+
+	// UserDataSecretNotFoundMachinePoolCondition is true when the user data secret used by the MachinePool does not
+	// exist in the remote cluster.
+	UserDataSecretNotFoundMachinePoolCondition MachinePoolConditionType = "UserDataSecretNotFound"
+
+	// LabelsAndTaintsNotAppliedMachinePoolCondition is true when the labels or taints of the MachinePool have not
+	// been applied to all of the MachineSets for the machine pool in the remote cluster.
+	LabelsAndTaintsNotAppliedMachinePoolCondition MachinePoolConditionType = "LabelsAndTaintsNotApplied"
+
+	// NoUsableZonesMachinePoolCondition is true when there are no availability zones in which the MachinePool can
+	// create machines, either because none were found in the region or because none of the availability zones of the
+	// region have one of the subnets of the MachinePool.
+	NoUsableZonesMachinePoolCondition MachinePoolConditionType = "NoUsableZones"
+
+	// InstanceTypeNotResolvedMachinePoolCondition is true when the instance type of the MachinePool could not be
+	// resolved, such as when no instance type is set or the instance type, or the one matched by its instance type
+	// selector, does not exist in the region.
+	InstanceTypeNotResolvedMachinePoolCondition MachinePoolConditionType = "InstanceTypeNotResolved"
+
+	// InstanceTypeNotOfferedInZonesMachinePoolCondition is true when some of the instance types the MachinePool
+	// overrides for specific availability zones are not offered in those zones.
+	InstanceTypeNotOfferedInZonesMachinePoolCondition MachinePoolConditionType = "InstanceTypeNotOfferedInZones"
+
+	// InstanceStoreVolumesNotManagedMachinePoolCondition is true when the instance type of the MachinePool has instance
+	// store volumes, which Hive attaches to the machines but does not initialize. The volumes must be configured by
+	// other means, such as a MachineConfig, to be used.
+	InstanceStoreVolumesNotManagedMachinePoolCondition MachinePoolConditionType = "InstanceStoreVolumesNotManaged"
+
+	// SpotMaxPriceTooLowMachinePoolCondition is true when the maximum price of the spot instances of the MachinePool
+	// is below the current spot price of its instance type in some of its availability zones, so that no instances can
+	// be launched there until the spot price drops.
+	SpotMaxPriceTooLowMachinePoolCondition MachinePoolConditionType = "SpotMaxPriceTooLow"
+
+	// SpotFallbackUnavailableMachinePoolCondition is true when the MachinePool requests falling back from spot to
+	// on-demand instances, which the MachineSets of the machine API cannot express, so that its machines stay spot
+	// instances and none are launched while spot capacity is unavailable.
+	SpotFallbackUnavailableMachinePoolCondition MachinePoolConditionType = "SpotFallbackUnavailable"
+
+	// UnevenZoneSpreadMachinePoolCondition is true when the replicas of an AWS MachinePool with the StrictEven spread
+	// policy cannot be split evenly across its availability zones, in which case no MachineSets are generated.
+	UnevenZoneSpreadMachinePoolCondition MachinePoolConditionType = "UnevenZoneSpread"
+
+	// UnauthorizedCredentialsMachinePoolCondition is true when the AWS credentials of a MachinePool with its own
+	// credentials are not authorized to describe the availability zones and subnets of the pool, in which case no
+	// MachineSets are generated.
+	UnauthorizedCredentialsMachinePoolCondition MachinePoolConditionType = "UnauthorizedCredentials"
+
+	// AMIResolutionFailedMachinePoolCondition is true when the image ID of the MachinePool could not be resolved from
+	// the RHCOS stream metadata it references, in which case the image ID of the cluster is used instead.
+	AMIResolutionFailedMachinePoolCondition MachinePoolConditionType = "AMIResolutionFailed"
+
+	// AMIUpdateInProgressMachinePoolCondition is true when some of the MachineSets of the MachinePool in the remote
+	// cluster do not use the AMI currently resolved for the MachinePool, e.g. after the image ID override changed, and
+	// are being updated by the Immediate or Rolling update strategy. With the OnDelete strategy the existing
+	// MachineSets keep their AMI, so the condition is false with the AMIUpdatePending reason until they are replaced.
+	AMIUpdateInProgressMachinePoolCondition MachinePoolConditionType = "AMIUpdateInProgress"
+
+	// InsufficientQuotaMachinePoolCondition is true when the machines of the MachinePool likely exceed the EC2 vCPU
+	// or EBS storage quotas of the AWS account in the region of the cluster, so that some of them may never be
+	// launched. Only the machines of the MachinePool itself are compared to the quotas, so it may be false while other
+	// machines of the account use up the quotas. Only checked when AWSQuotaCheck is enabled in HiveConfig.
+	InsufficientQuotaMachinePoolCondition MachinePoolConditionType = "InsufficientQuota"
+
+	// InsufficientSubnetIPsMachinePoolCondition is true when the machines the MachinePool adds in some availability
+	// zones likely exceed the available IP addresses of the private subnets of those zones, so that some of them may
+	// never be launched. Only checked when AWSSubnetIPCheck is enabled in HiveConfig.
+	InsufficientSubnetIPsMachinePoolCondition MachinePoolConditionType = "InsufficientSubnetIPs"
+
+	// NoOutboundConnectivityMachinePoolCondition is true when an AWS MachinePool disables the public IP addresses of
+	// its machines while the subnets of some of its availability zones route no outbound traffic through a NAT gateway
+	// or another egress device, so that the machines likely cannot reach the internet, e.g. to pull images.
+	NoOutboundConnectivityMachinePoolCondition MachinePoolConditionType = "NoOutboundConnectivity"
+
+	// MachineSetDriftCorrectedMachinePoolCondition is true when Hive has restored the IAM instance profile or security
+	// groups of MachineSets of an AWS MachinePool which were modified in the remote cluster. It stays true, naming the
+	// MachineSets most recently restored, as a record that they were modified outside of Hive. It is false while no
+	// such modification has been found.
+	MachineSetDriftCorrectedMachinePoolCondition MachinePoolConditionType = "MachineSetDriftCorrected"
+
+	// VersionGatedFeaturesUnavailableMachinePoolCondition is true when some features of MachinePools are unavailable
+	// because the version of the cluster is too old for them, such as spot instances on AWS clusters older than 4.5,
+	// listing the features and the versions making them available. All of them are unavailable while the version of
+	// the cluster cannot be determined. It is informational: each feature is gated, or
+	// reported by its own condition when the MachinePool uses it, whether or not the MachinePool uses it.
+	VersionGatedFeaturesUnavailableMachinePoolCondition MachinePoolConditionType = "VersionGatedFeaturesUnavailable"
+
+	// ResourcesNotFoundMachinePoolCondition is true when the IAM instance profile, private subnets or security group
+	// named by the resourceNames of an AWS MachinePool do not exist, so that its machines could not be launched.
+	ResourcesNotFoundMachinePoolCondition MachinePoolConditionType = "ResourcesNotFound"
+
+	// InvalidUpdateStrategyMachinePoolCondition is true when the update strategy of a MachinePool is invalid, such as
+	// a malformed maxUnavailable percentage or a maxUnavailable set for a strategy other than Rolling, so that its
+	// MachineSets are not synced.
+	InvalidUpdateStrategyMachinePoolCondition MachinePoolConditionType = "InvalidUpdateStrategy"
+
+	// InvalidPlatformMachinePoolCondition is true when no MachineSets can be generated for the MachinePool on the
+	// platform of its ClusterDeployment, because the platforms of the two differ or the installed ClusterDeployment has
+	// no cluster metadata. The MachinePool is not reconciled further until the condition is resolved.
+	InvalidPlatformMachinePoolCondition MachinePoolConditionType = "InvalidPlatform"
 )
 
 // +genclient
@@ -14,6 +14,12 @@ type MachinePool struct {
 	// The instances use ephemeral disks if not set.
 	// +optional
 	RootVolume *RootVolume `json:"rootVolume,omitempty"`
+
+	// AdditionalNetworkIDs contains IDs of additional networks the instances in the machine pool are attached to,
+	// in addition to the primary network of the cluster. Allowed address pairs are not created for the additional
+	// networks.
+	// +optional
+	AdditionalNetworkIDs []string `json:"additionalNetworkIDs,omitempty"`
 }
 
 // Set sets the values from `required` to `a`.
@@ -33,6 +39,10 @@ func (o *MachinePool) Set(required *MachinePool) {
 		o.RootVolume.Size = required.RootVolume.Size
 		o.RootVolume.Type = required.RootVolume.Type
 	}
+
+	if required.AdditionalNetworkIDs != nil {
+		o.AdditionalNetworkIDs = append(required.AdditionalNetworkIDs[:0:0], required.AdditionalNetworkIDs...)
+	}
 }
 
 // RootVolume defines the storage for an instance.
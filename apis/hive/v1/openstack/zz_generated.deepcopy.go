@@ -16,6 +16,11 @@ func (in *MachinePool) DeepCopyInto(out *MachinePool) {
 		*out = new(RootVolume)
 		**out = **in
 	}
+	if in.AdditionalNetworkIDs != nil {
+		in, out := &in.AdditionalNetworkIDs, &out.AdditionalNetworkIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -18,8 +18,25 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIRateLimit) DeepCopyInto(out *APIRateLimit) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIRateLimit.
+func (in *APIRateLimit) DeepCopy() *APIRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(APIRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSAssociatedVPC) DeepCopyInto(out *AWSAssociatedVPC) {
 	*out = *in
@@ -217,6 +234,32 @@ func (in *AWSResourceTag) DeepCopy() *AWSResourceTag {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSRetryConfig) DeepCopyInto(out *AWSRetryConfig) {
+	*out = *in
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSRetryConfig.
+func (in *AWSRetryConfig) DeepCopy() *AWSRetryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSRetryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSServiceProviderCredentials) DeepCopyInto(out *AWSServiceProviderCredentials) {
 	*out = *in
@@ -347,6 +390,22 @@ func (in *BackupReference) DeepCopy() *BackupReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootImageStatus) DeepCopyInto(out *BootImageStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootImageStatus.
+func (in *BootImageStatus) DeepCopy() *BootImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BootImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateBundleSpec) DeepCopyInto(out *CertificateBundleSpec) {
 	*out = *in
@@ -2342,6 +2401,11 @@ func (in *HiveConfigSpec) DeepCopyInto(out *HiveConfigSpec) {
 		*out = new(ControllersConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MachinePoolConfig != nil {
+		in, out := &in.MachinePoolConfig, &out.MachinePoolConfig
+		*out = new(MachinePoolControllerConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AWSPrivateLink != nil {
 		in, out := &in.AWSPrivateLink, &out.AWSPrivateLink
 		*out = new(AWSPrivateLinkConfig)
@@ -2457,6 +2521,13 @@ func (in *MachinePool) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachinePoolAutoscaling) DeepCopyInto(out *MachinePoolAutoscaling) {
 	*out = *in
+	if in.ZoneReplicas != nil {
+		in, out := &in.ZoneReplicas, &out.ZoneReplicas
+		*out = make(map[string]MachinePoolZoneAutoscaling, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -2470,6 +2541,22 @@ func (in *MachinePoolAutoscaling) DeepCopy() *MachinePoolAutoscaling {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachinePoolCapability) DeepCopyInto(out *MachinePoolCapability) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachinePoolCapability.
+func (in *MachinePoolCapability) DeepCopy() *MachinePoolCapability {
+	if in == nil {
+		return nil
+	}
+	out := new(MachinePoolCapability)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachinePoolCondition) DeepCopyInto(out *MachinePoolCondition) {
 	*out = *in
@@ -2488,6 +2575,49 @@ func (in *MachinePoolCondition) DeepCopy() *MachinePoolCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachinePoolControllerConfig) DeepCopyInto(out *MachinePoolControllerConfig) {
+	*out = *in
+	if in.AWSAMISources != nil {
+		in, out := &in.AWSAMISources, &out.AWSAMISources
+		*out = make([]BootImageSource, len(*in))
+		copy(*out, *in)
+	}
+	if in.AWSAPIRateLimit != nil {
+		in, out := &in.AWSAPIRateLimit, &out.AWSAPIRateLimit
+		*out = new(APIRateLimit)
+		**out = **in
+	}
+	if in.AWSRetry != nil {
+		in, out := &in.AWSRetry, &out.AWSRetry
+		*out = new(AWSRetryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigurationErrorRequeueInterval != nil {
+		in, out := &in.ConfigurationErrorRequeueInterval, &out.ConfigurationErrorRequeueInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.AdditionalTags != nil {
+		in, out := &in.AdditionalTags, &out.AdditionalTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachinePoolControllerConfig.
+func (in *MachinePoolControllerConfig) DeepCopy() *MachinePoolControllerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MachinePoolControllerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachinePoolList) DeepCopyInto(out *MachinePoolList) {
 	*out = *in
@@ -2672,7 +2802,7 @@ func (in *MachinePoolSpec) DeepCopyInto(out *MachinePoolSpec) {
 	if in.Autoscaling != nil {
 		in, out := &in.Autoscaling, &out.Autoscaling
 		*out = new(MachinePoolAutoscaling)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	in.Platform.DeepCopyInto(&out.Platform)
 	if in.Labels != nil {
@@ -2689,6 +2819,16 @@ func (in *MachinePoolSpec) DeepCopyInto(out *MachinePoolSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MergeIgnitionSecretRef != nil {
+		in, out := &in.MergeIgnitionSecretRef, &out.MergeIgnitionSecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(MachinePoolUpdateStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2712,6 +2852,47 @@ func (in *MachinePoolStatus) DeepCopyInto(out *MachinePoolStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.GeneratedZoneReplicas != nil {
+		in, out := &in.GeneratedZoneReplicas, &out.GeneratedZoneReplicas
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImageIDs != nil {
+		in, out := &in.ImageIDs, &out.ImageIDs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SubnetsByAvailabilityZone != nil {
+		in, out := &in.SubnetsByAvailabilityZone, &out.SubnetsByAvailabilityZone
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.UserDataSecret != nil {
+		in, out := &in.UserDataSecret, &out.UserDataSecret
+		*out = new(UserDataSecretStatus)
+		**out = **in
+	}
+	if in.BootImage != nil {
+		in, out := &in.BootImage, &out.BootImage
+		*out = new(BootImageStatus)
+		**out = **in
+	}
+	if in.PrunedMachineSets != nil {
+		in, out := &in.PrunedMachineSets, &out.PrunedMachineSets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]MachinePoolCapability, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]MachinePoolCondition, len(*in))
@@ -2732,6 +2913,43 @@ func (in *MachinePoolStatus) DeepCopy() *MachinePoolStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachinePoolUpdateStrategy) DeepCopyInto(out *MachinePoolUpdateStrategy) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachinePoolUpdateStrategy.
+func (in *MachinePoolUpdateStrategy) DeepCopy() *MachinePoolUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(MachinePoolUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachinePoolZoneAutoscaling) DeepCopyInto(out *MachinePoolZoneAutoscaling) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachinePoolZoneAutoscaling.
+func (in *MachinePoolZoneAutoscaling) DeepCopy() *MachinePoolZoneAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(MachinePoolZoneAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineSetStatus) DeepCopyInto(out *MachineSetStatus) {
 	*out = *in
@@ -3615,6 +3833,22 @@ func (in *SyncStatus) DeepCopy() *SyncStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserDataSecretStatus) DeepCopyInto(out *UserDataSecretStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserDataSecretStatus.
+func (in *UserDataSecretStatus) DeepCopy() *UserDataSecretStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UserDataSecretStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VSphereClusterDeprovision) DeepCopyInto(out *VSphereClusterDeprovision) {
 	*out = *in
@@ -27,6 +27,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/elb/elbiface"
 	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
 	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/aws/aws-sdk-go/service/route53/route53iface"
@@ -63,7 +65,13 @@ type Client interface {
 	DescribeAvailabilityZones(*ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error)
 	DescribeSubnets(*ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error)
 	DescribeRouteTables(*ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error)
+	DescribeImages(*ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error)
 	DescribeInstances(*ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error)
+	DescribeInstanceTypes(*ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error)
+	DescribeInstanceTypeOfferings(*ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error)
+	GetSerialConsoleAccessStatus(*ec2.GetSerialConsoleAccessStatusInput) (*ec2.GetSerialConsoleAccessStatusOutput, error)
+	DescribeSpotPriceHistory(*ec2.DescribeSpotPriceHistoryInput) (*ec2.DescribeSpotPriceHistoryOutput, error)
+	DescribeSecurityGroups(*ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error)
 	StopInstances(*ec2.StopInstancesInput) (*ec2.StopInstancesOutput, error)
 	TerminateInstances(*ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error)
 	StartInstances(*ec2.StartInstancesInput) (*ec2.StartInstancesOutput, error)
@@ -107,6 +115,16 @@ type Client interface {
 
 	// STS
 	GetCallerIdentity(input *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error)
+
+	// IAM
+	GetInstanceProfile(*iam.GetInstanceProfileInput) (*iam.GetInstanceProfileOutput, error)
+
+	// KMS
+	DescribeKey(*DescribeKeyInput) (*DescribeKeyOutput, error)
+
+	// Service Quotas
+	GetServiceQuota(*GetServiceQuotaInput) (*GetServiceQuotaOutput, error)
+	GetAWSDefaultServiceQuota(*GetServiceQuotaInput) (*GetServiceQuotaOutput, error)
 }
 
 type awsClient struct {
@@ -117,7 +135,10 @@ type awsClient struct {
 	s3Client      s3iface.S3API
 	s3Uploader    *s3manager.Uploader
 	stsClient     stsiface.STSAPI
+	iamClient     iamiface.IAMAPI
 	tagClient     *resourcegroupstaggingapi.ResourceGroupsTaggingAPI
+	kmsClient     *kmsClient
+	quotasClient  *serviceQuotasClient
 }
 
 func (c *awsClient) DescribeAvailabilityZones(input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
@@ -135,6 +156,36 @@ func (c *awsClient) DescribeRouteTables(input *ec2.DescribeRouteTablesInput) (*e
 	return c.ec2Client.DescribeRouteTables(input)
 }
 
+func (c *awsClient) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	metricAWSAPICalls.WithLabelValues("DescribeImages").Inc()
+	return c.ec2Client.DescribeImages(input)
+}
+
+func (c *awsClient) DescribeInstanceTypes(input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	metricAWSAPICalls.WithLabelValues("DescribeInstanceTypes").Inc()
+	return c.ec2Client.DescribeInstanceTypes(input)
+}
+
+func (c *awsClient) DescribeInstanceTypeOfferings(input *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	metricAWSAPICalls.WithLabelValues("DescribeInstanceTypeOfferings").Inc()
+	return c.ec2Client.DescribeInstanceTypeOfferings(input)
+}
+
+func (c *awsClient) GetSerialConsoleAccessStatus(input *ec2.GetSerialConsoleAccessStatusInput) (*ec2.GetSerialConsoleAccessStatusOutput, error) {
+	metricAWSAPICalls.WithLabelValues("GetSerialConsoleAccessStatus").Inc()
+	return c.ec2Client.GetSerialConsoleAccessStatus(input)
+}
+
+func (c *awsClient) DescribeSpotPriceHistory(input *ec2.DescribeSpotPriceHistoryInput) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+	metricAWSAPICalls.WithLabelValues("DescribeSpotPriceHistory").Inc()
+	return c.ec2Client.DescribeSpotPriceHistory(input)
+}
+
+func (c *awsClient) DescribeSecurityGroups(input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	metricAWSAPICalls.WithLabelValues("DescribeSecurityGroups").Inc()
+	return c.ec2Client.DescribeSecurityGroups(input)
+}
+
 func (c *awsClient) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
 	metricAWSAPICalls.WithLabelValues("DescribeInstances").Inc()
 	return c.ec2Client.DescribeInstances(input)
@@ -298,6 +349,26 @@ func (c *awsClient) GetCallerIdentity(input *sts.GetCallerIdentityInput) (*sts.G
 	return c.stsClient.GetCallerIdentity(input)
 }
 
+func (c *awsClient) GetInstanceProfile(input *iam.GetInstanceProfileInput) (*iam.GetInstanceProfileOutput, error) {
+	metricAWSAPICalls.WithLabelValues("GetInstanceProfile").Inc()
+	return c.iamClient.GetInstanceProfile(input)
+}
+
+func (c *awsClient) DescribeKey(input *DescribeKeyInput) (*DescribeKeyOutput, error) {
+	metricAWSAPICalls.WithLabelValues("DescribeKey").Inc()
+	return c.kmsClient.DescribeKey(input)
+}
+
+func (c *awsClient) GetServiceQuota(input *GetServiceQuotaInput) (*GetServiceQuotaOutput, error) {
+	metricAWSAPICalls.WithLabelValues("GetServiceQuota").Inc()
+	return c.quotasClient.GetServiceQuota(input)
+}
+
+func (c *awsClient) GetAWSDefaultServiceQuota(input *GetServiceQuotaInput) (*GetServiceQuotaOutput, error) {
+	metricAWSAPICalls.WithLabelValues("GetAWSDefaultServiceQuota").Inc()
+	return c.quotasClient.GetAWSDefaultServiceQuota(input)
+}
+
 // Options provides the means to control how a client is created and what
 // configuration values will be loaded.
 //
@@ -305,6 +376,14 @@ type Options struct {
 	// Region helps create the clients with correct endpoints.
 	Region string
 
+	// Partition is the AWS partition expected to contain the Region, e.g. aws-us-gov.
+	// When set, creating the client fails if the Region is not in the Partition.
+	Partition string
+
+	// ServiceEndpoints overrides the default endpoints of AWS services, e.g. for
+	// GovCloud or isolated regions.
+	ServiceEndpoints []hivev1aws.ServiceEndpoint
+
 	// CredentialsSource defines how the credentials will be loaded.
 	// It supports various methods of sourcing credentials. But if none
 	// of the supported sources are configured such that they can be used,
@@ -392,25 +471,45 @@ type AssumeRoleCredentialsSource struct {
 //    ```
 //
 func New(kubeClient client.Client, options Options) (Client, error) {
+	if err := ValidateRegionInPartition(options.Region, options.Partition); err != nil {
+		return nil, err
+	}
+
 	source := options.CredentialsSource
 	switch {
 	case source.Secret != nil && source.Secret.Ref != nil && source.Secret.Ref.Name != "":
-		return NewClient(kubeClient, source.Secret.Ref.Name, source.Secret.Namespace, options.Region)
+		return newClient(kubeClient, source.Secret.Ref.Name, source.Secret.Namespace, options)
 	case source.AssumeRole != nil && source.AssumeRole.Role != nil && source.AssumeRole.Role.RoleARN != "":
 		return newClientAssumeRole(kubeClient,
 			source.AssumeRole.SecretRef.Name, source.AssumeRole.SecretRef.Namespace,
 			source.AssumeRole.Role,
-			options.Region,
+			options,
 		)
 	}
 
-	return NewClientFromSecret(nil, options.Region)
+	return newClientFromSecret(nil, options)
+}
+
+// ValidateRegionInPartition returns an error if the region is not in the given AWS partition.
+// An empty partition matches any region.
+func ValidateRegionInPartition(region, partition string) error {
+	if partition == "" {
+		return nil
+	}
+	p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region)
+	if !ok {
+		return fmt.Errorf("region %s is not in any known partition", region)
+	}
+	if p.ID() != partition {
+		return fmt.Errorf("region %s is in partition %s, not %s", region, p.ID(), partition)
+	}
+	return nil
 }
 
 func newClientAssumeRole(kubeClient client.Client,
 	serviceProviderSecretName, serviceProviderSecretNamespace string,
 	role *hivev1aws.AssumeRole,
-	region string,
+	options Options,
 ) (Client, error) {
 	var secret *corev1.Secret
 	if serviceProviderSecretName != "" {
@@ -426,7 +525,7 @@ func newClientAssumeRole(kubeClient client.Client,
 		}
 	}
 
-	sess, err := NewSessionFromSecret(secret, region)
+	sess, err := newSessionFromSecret(secret, options)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create AWS session")
 	}
@@ -450,10 +549,14 @@ func newClientAssumeRole(kubeClient client.Client,
 // Pass a nil client, and empty secret name and namespace to load credentials from the standard
 // AWS environment variables.
 func NewClient(kubeClient client.Client, secretName, namespace, region string) (Client, error) {
+	return newClient(kubeClient, secretName, namespace, Options{Region: region})
+}
+
+func newClient(kubeClient client.Client, secretName, namespace string, options Options) (Client, error) {
 
 	// Special case to not use a secret to gather credentials.
 	if secretName == "" {
-		return NewClientFromSecret(nil, region)
+		return newClientFromSecret(nil, options)
 	}
 
 	secret := &corev1.Secret{}
@@ -467,7 +570,7 @@ func NewClient(kubeClient client.Client, secretName, namespace, region string) (
 		return nil, err
 	}
 
-	return NewClientFromSecret(secret, region)
+	return newClientFromSecret(secret, options)
 }
 
 // NewClientFromSecret creates our client wrapper object for the actual AWS clients we use.
@@ -477,7 +580,11 @@ func NewClient(kubeClient client.Client, secretName, namespace, region string) (
 //
 // Pass a nil secret to load credentials from the standard AWS environment variables.
 func NewClientFromSecret(secret *corev1.Secret, region string) (Client, error) {
-	s, err := NewSessionFromSecret(secret, region)
+	return newClientFromSecret(secret, Options{Region: region})
+}
+
+func newClientFromSecret(secret *corev1.Secret, options Options) (Client, error) {
+	s, err := newSessionFromSecret(secret, options)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create AWS session")
 	}
@@ -493,17 +600,24 @@ func newClientFromSession(s *session.Session, cfgs ...*aws.Config) (Client, erro
 		s3Uploader:    s3manager.NewUploader(s),
 		route53Client: route53.New(s, cfgs...),
 		stsClient:     sts.New(s, cfgs...),
+		iamClient:     iam.New(s, cfgs...),
 		tagClient:     resourcegroupstaggingapi.New(s, cfgs...),
+		kmsClient:     newKMSClient(s, cfgs...),
+		quotasClient:  newServiceQuotasClient(s, cfgs...),
 	}, nil
 }
 
 // NewSessionFromSecret creates a new AWS session using the configuration in the secret. If the secret
 // was nil, it initializes a new session using configuration of the envionment.
 func NewSessionFromSecret(secret *corev1.Secret, region string) (*session.Session, error) {
+	return newSessionFromSecret(secret, Options{Region: region})
+}
+
+func newSessionFromSecret(secret *corev1.Secret, clientOptions Options) (*session.Session, error) {
 	options := session.Options{
 		Config: aws.Config{
-			Region:           aws.String(region),
-			EndpointResolver: endpoints.ResolverFunc(awsChinaEndpointResolver),
+			Region:           aws.String(clientOptions.Region),
+			EndpointResolver: newEndpointResolver(clientOptions.ServiceEndpoints),
 		},
 		SharedConfigState: session.SharedConfigEnable,
 	}
@@ -553,6 +667,32 @@ func awsCLIConfigFromSecret(secret *corev1.Secret) []byte {
 	return buf.Bytes()
 }
 
+// newEndpointResolver returns an endpoint resolver that uses the given service endpoints in place of the defaults.
+func newEndpointResolver(serviceEndpoints []hivev1aws.ServiceEndpoint) endpoints.Resolver {
+	if len(serviceEndpoints) == 0 {
+		return endpoints.ResolverFunc(awsChinaEndpointResolver)
+	}
+	urls := make(map[string]string, len(serviceEndpoints))
+	for _, e := range serviceEndpoints {
+		urls[e.Name] = e.URL
+	}
+	return endpoints.ResolverFunc(func(service, region string, optFns ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		url, ok := urls[service]
+		if !ok {
+			return awsChinaEndpointResolver(service, region, optFns...)
+		}
+		// Keep signing with the default signing region of the service, if there is one.
+		signingRegion := region
+		if def, err := endpoints.DefaultResolver().EndpointFor(service, region); err == nil && def.SigningRegion != "" {
+			signingRegion = def.SigningRegion
+		}
+		return endpoints.ResolvedEndpoint{
+			URL:           url,
+			SigningRegion: signingRegion,
+		}, nil
+	})
+}
+
 func awsChinaEndpointResolver(service, region string, optFns ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
 	if service != route53.EndpointsID || region != constants.AWSChinaRoute53Region {
 		return endpoints.DefaultResolver().EndpointFor(service, region, optFns...)
@@ -0,0 +1,242 @@
+// Package fake provides a fake awsclient.Client with canned responses for use in tests.
+package fake
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/hive/pkg/awsclient"
+)
+
+// Client is a fake awsclient.Client which answers the EC2 describe calls from canned availability zones, subnets,
+// route tables and instance types, applying the IDs and filters of each request to them the way AWS does. Errors can be
+// injected per method. Calling a method of the interface that the fake does not implement panics, so tests fail
+// loudly when the code under test makes an unexpected call.
+type Client struct {
+	awsclient.Client
+
+	// AvailabilityZones are the availability zones described by DescribeAvailabilityZones.
+	AvailabilityZones []*ec2.AvailabilityZone
+	// Subnets are the subnets described by DescribeSubnets.
+	Subnets []*ec2.Subnet
+	// RouteTables are the route tables described by DescribeRouteTables.
+	RouteTables []*ec2.RouteTable
+	// InstanceTypes are the instance types described by DescribeInstanceTypes.
+	InstanceTypes []*ec2.InstanceTypeInfo
+
+	// Errors are returned by the methods named by their keys, e.g. "DescribeSubnets", instead of a response.
+	Errors map[string]error
+
+	// Calls counts the calls made to each method by name.
+	Calls map[string]int
+}
+
+var _ awsclient.Client = &Client{}
+
+// call records a call to the named method and returns the error injected for it, if any.
+func (c *Client) call(method string) error {
+	if c.Calls == nil {
+		c.Calls = map[string]int{}
+	}
+	c.Calls[method]++
+	return c.Errors[method]
+}
+
+// DescribeAvailabilityZones returns the availability zones matching the zone IDs, zone names and filters of the
+// input. Zones which are not opted in to are only returned when all availability zones are requested.
+func (c *Client) DescribeAvailabilityZones(input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	if err := c.call("DescribeAvailabilityZones"); err != nil {
+		return nil, err
+	}
+	zoneIDs := sets.NewString(aws.StringValueSlice(input.ZoneIds)...)
+	zoneNames := sets.NewString(aws.StringValueSlice(input.ZoneNames)...)
+	output := &ec2.DescribeAvailabilityZonesOutput{}
+	for _, zone := range c.AvailabilityZones {
+		if zoneIDs.Len() > 0 && !zoneIDs.Has(aws.StringValue(zone.ZoneId)) ||
+			zoneNames.Len() > 0 && !zoneNames.Has(aws.StringValue(zone.ZoneName)) {
+			continue
+		}
+		if !aws.BoolValue(input.AllAvailabilityZones) &&
+			aws.StringValue(zone.OptInStatus) == ec2.AvailabilityZoneOptInStatusNotOptedIn {
+			continue
+		}
+		match, err := matchFilters(input.Filters, func(name string) ([]string, error) {
+			switch name {
+			case "zone-id":
+				return []string{aws.StringValue(zone.ZoneId)}, nil
+			case "zone-name":
+				return []string{aws.StringValue(zone.ZoneName)}, nil
+			case "region-name":
+				return []string{aws.StringValue(zone.RegionName)}, nil
+			case "state":
+				return []string{aws.StringValue(zone.State)}, nil
+			case "opt-in-status":
+				return []string{aws.StringValue(zone.OptInStatus)}, nil
+			case "zone-type":
+				return []string{aws.StringValue(zone.ZoneType)}, nil
+			}
+			return nil, fmt.Errorf("unsupported availability zone filter %s", name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			output.AvailabilityZones = append(output.AvailabilityZones, zone)
+		}
+	}
+	return output, nil
+}
+
+// DescribeSubnets returns the subnets matching the subnet IDs and filters of the input. Like AWS, it fails with an
+// InvalidSubnetID.NotFound error when a subnet ID is not found.
+func (c *Client) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	if err := c.call("DescribeSubnets"); err != nil {
+		return nil, err
+	}
+	subnetIDs := aws.StringValueSlice(input.SubnetIds)
+	requested := sets.NewString(subnetIDs...)
+	found := sets.NewString()
+	output := &ec2.DescribeSubnetsOutput{}
+	for _, subnet := range c.Subnets {
+		if len(subnetIDs) > 0 && !requested.Has(aws.StringValue(subnet.SubnetId)) {
+			continue
+		}
+		match, err := matchFilters(input.Filters, func(name string) ([]string, error) {
+			switch {
+			case name == "subnet-id":
+				return []string{aws.StringValue(subnet.SubnetId)}, nil
+			case name == "vpc-id":
+				return []string{aws.StringValue(subnet.VpcId)}, nil
+			case name == "availability-zone":
+				return []string{aws.StringValue(subnet.AvailabilityZone)}, nil
+			case strings.HasPrefix(name, "tag:"):
+				return tagValues(subnet.Tags, strings.TrimPrefix(name, "tag:")), nil
+			}
+			return nil, fmt.Errorf("unsupported subnet filter %s", name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			found.Insert(aws.StringValue(subnet.SubnetId))
+			output.Subnets = append(output.Subnets, subnet)
+		}
+	}
+	var missing []string
+	for _, id := range subnetIDs {
+		if !found.Has(id) {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, awserr.New("InvalidSubnetID.NotFound", fmt.Sprintf("The subnet ID '%s' does not exist", strings.Join(missing, ",")), nil)
+	}
+	return output, nil
+}
+
+// DescribeRouteTables returns the route tables matching the route table IDs and filters of the input.
+func (c *Client) DescribeRouteTables(input *ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error) {
+	if err := c.call("DescribeRouteTables"); err != nil {
+		return nil, err
+	}
+	routeTableIDs := sets.NewString(aws.StringValueSlice(input.RouteTableIds)...)
+	output := &ec2.DescribeRouteTablesOutput{}
+	for _, routeTable := range c.RouteTables {
+		if routeTableIDs.Len() > 0 && !routeTableIDs.Has(aws.StringValue(routeTable.RouteTableId)) {
+			continue
+		}
+		match, err := matchFilters(input.Filters, func(name string) ([]string, error) {
+			switch {
+			case name == "route-table-id":
+				return []string{aws.StringValue(routeTable.RouteTableId)}, nil
+			case name == "vpc-id":
+				return []string{aws.StringValue(routeTable.VpcId)}, nil
+			case name == "association.subnet-id":
+				var values []string
+				for _, association := range routeTable.Associations {
+					values = append(values, aws.StringValue(association.SubnetId))
+				}
+				return values, nil
+			case name == "association.main":
+				values := []string{"false"}
+				for _, association := range routeTable.Associations {
+					if aws.BoolValue(association.Main) {
+						values = []string{"true"}
+					}
+				}
+				return values, nil
+			case strings.HasPrefix(name, "tag:"):
+				return tagValues(routeTable.Tags, strings.TrimPrefix(name, "tag:")), nil
+			}
+			return nil, fmt.Errorf("unsupported route table filter %s", name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			output.RouteTables = append(output.RouteTables, routeTable)
+		}
+	}
+	return output, nil
+}
+
+// DescribeInstanceTypes returns the instance types named in the input, or all of them when none are named. Like AWS,
+// it fails with an InvalidInstanceType error when an instance type is not found.
+func (c *Client) DescribeInstanceTypes(input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	if err := c.call("DescribeInstanceTypes"); err != nil {
+		return nil, err
+	}
+	if len(input.InstanceTypes) == 0 {
+		return &ec2.DescribeInstanceTypesOutput{InstanceTypes: c.InstanceTypes}, nil
+	}
+	output := &ec2.DescribeInstanceTypesOutput{}
+	var missing []string
+	for _, name := range aws.StringValueSlice(input.InstanceTypes) {
+		found := false
+		for _, instanceType := range c.InstanceTypes {
+			if aws.StringValue(instanceType.InstanceType) == name {
+				output.InstanceTypes = append(output.InstanceTypes, instanceType)
+				found = true
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, awserr.New("InvalidInstanceType", fmt.Sprintf("The following supplied instance types do not exist: [%s]", strings.Join(missing, ", ")), nil)
+	}
+	return output, nil
+}
+
+// matchFilters returns whether a resource matches all the filters, that is whether one of the values of the resource
+// for the name of each filter is one of the values of the filter. The values of the resource are returned by the
+// given function, which fails for filter names the fake does not support.
+func matchFilters(filters []*ec2.Filter, values func(name string) ([]string, error)) (bool, error) {
+	for _, filter := range filters {
+		resourceValues, err := values(aws.StringValue(filter.Name))
+		if err != nil {
+			return false, err
+		}
+		if !sets.NewString(aws.StringValueSlice(filter.Values)...).HasAny(resourceValues...) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// tagValues returns the value of the tag with the given key, if any.
+func tagValues(tags []*ec2.Tag, key string) []string {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) == key {
+			return []string{aws.StringValue(tag.Value)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package awsclient
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/private/protocol/jsonrpc"
+)
+
+// The KMS package of the AWS SDK is not vendored, and only DescribeKey is needed to resolve the aliases of the keys
+// encrypting volumes, so kmsClient implements that call over the JSON RPC protocol of KMS in the same way as the
+// generated SDK clients.
+
+const (
+	// kmsServiceName is the name of the KMS service, used to look up its endpoints.
+	kmsServiceName = "kms"
+
+	// KMSNotFoundExceptionCode is the error code of KMS when the requested key or alias does not exist.
+	KMSNotFoundExceptionCode = "NotFoundException"
+)
+
+// DescribeKeyInput is the input of the KMS DescribeKey call.
+type DescribeKeyInput struct {
+	_ struct{} `type:"structure"`
+
+	// KeyId identifies the key by its ID, its ARN, an alias name prefixed with alias/, or an alias ARN.
+	KeyId *string `min:"1" type:"string" required:"true"`
+}
+
+// DescribeKeyOutput is the output of the KMS DescribeKey call.
+type DescribeKeyOutput struct {
+	_ struct{} `type:"structure"`
+
+	KeyMetadata *KeyMetadata `type:"structure"`
+}
+
+// KeyMetadata is the subset of the metadata of a KMS key used by Hive.
+type KeyMetadata struct {
+	_ struct{} `type:"structure"`
+
+	// Arn is the ARN of the key.
+	Arn *string `min:"20" type:"string"`
+
+	// KeyId is the ID of the key.
+	KeyId *string `min:"1" type:"string" required:"true"`
+
+	// KeyState is the state of the key, e.g. Enabled or PendingDeletion.
+	KeyState *string `type:"string"`
+}
+
+type kmsClient struct {
+	*client.Client
+}
+
+func newKMSClient(p client.ConfigProvider, cfgs ...*aws.Config) *kmsClient {
+	c := p.ClientConfig(kmsServiceName, cfgs...)
+	svc := &kmsClient{
+		Client: client.New(
+			*c.Config,
+			metadata.ClientInfo{
+				ServiceName:   kmsServiceName,
+				ServiceID:     "KMS",
+				SigningName:   c.SigningName,
+				SigningRegion: c.SigningRegion,
+				PartitionID:   c.PartitionID,
+				Endpoint:      c.Endpoint,
+				APIVersion:    "2014-11-01",
+				JSONVersion:   "1.1",
+				TargetPrefix:  "TrentService",
+			},
+			c.Handlers,
+		),
+	}
+	svc.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+	svc.Handlers.Build.PushBackNamed(jsonrpc.BuildHandler)
+	svc.Handlers.Unmarshal.PushBackNamed(jsonrpc.UnmarshalHandler)
+	svc.Handlers.UnmarshalMeta.PushBackNamed(jsonrpc.UnmarshalMetaHandler)
+	svc.Handlers.UnmarshalError.PushBackNamed(jsonrpc.UnmarshalErrorHandler)
+	return svc
+}
+
+func (c *kmsClient) DescribeKey(input *DescribeKeyInput) (*DescribeKeyOutput, error) {
+	op := &request.Operation{
+		Name:       "DescribeKey",
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+	if input == nil {
+		input = &DescribeKeyInput{}
+	}
+	output := &DescribeKeyOutput{}
+	req := c.NewRequest(op, input, output)
+	return output, req.Send()
+}
@@ -0,0 +1,108 @@
+package awsclient
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/private/protocol/jsonrpc"
+)
+
+// The Service Quotas package of the AWS SDK is not vendored, and only the calls getting a quota are needed to check
+// the quotas used by MachinePools, so serviceQuotasClient implements them over the JSON RPC protocol of Service Quotas
+// in the same way as the generated SDK clients.
+
+const (
+	// serviceQuotasServiceName is the name of the Service Quotas service, used to look up its endpoints.
+	serviceQuotasServiceName = "servicequotas"
+
+	// ServiceQuotasNoSuchResourceExceptionCode is the error code of Service Quotas when the requested quota does not
+	// exist, such as when GetServiceQuota is called for a quota that has never been changed from its default value.
+	ServiceQuotasNoSuchResourceExceptionCode = "NoSuchResourceException"
+)
+
+// GetServiceQuotaInput is the input of the Service Quotas GetServiceQuota and GetAWSDefaultServiceQuota calls.
+type GetServiceQuotaInput struct {
+	_ struct{} `type:"structure"`
+
+	// QuotaCode identifies the quota, e.g. L-1216C47A.
+	QuotaCode *string `min:"1" type:"string" required:"true"`
+
+	// ServiceCode identifies the service of the quota, e.g. ec2.
+	ServiceCode *string `min:"1" type:"string" required:"true"`
+}
+
+// GetServiceQuotaOutput is the output of the Service Quotas GetServiceQuota and GetAWSDefaultServiceQuota calls.
+type GetServiceQuotaOutput struct {
+	_ struct{} `type:"structure"`
+
+	Quota *ServiceQuota `type:"structure"`
+}
+
+// ServiceQuota is the subset of the details of a quota used by Hive.
+type ServiceQuota struct {
+	_ struct{} `type:"structure"`
+
+	// QuotaCode identifies the quota.
+	QuotaCode *string `min:"1" type:"string"`
+
+	// QuotaName is the name of the quota.
+	QuotaName *string `type:"string"`
+
+	// Value is the value of the quota.
+	Value *float64 `type:"double"`
+}
+
+type serviceQuotasClient struct {
+	*client.Client
+}
+
+func newServiceQuotasClient(p client.ConfigProvider, cfgs ...*aws.Config) *serviceQuotasClient {
+	c := p.ClientConfig(serviceQuotasServiceName, cfgs...)
+	svc := &serviceQuotasClient{
+		Client: client.New(
+			*c.Config,
+			metadata.ClientInfo{
+				ServiceName:   serviceQuotasServiceName,
+				ServiceID:     "Service Quotas",
+				SigningName:   c.SigningName,
+				SigningRegion: c.SigningRegion,
+				PartitionID:   c.PartitionID,
+				Endpoint:      c.Endpoint,
+				APIVersion:    "2019-06-24",
+				JSONVersion:   "1.1",
+				TargetPrefix:  "ServiceQuotasV20190624",
+			},
+			c.Handlers,
+		),
+	}
+	svc.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+	svc.Handlers.Build.PushBackNamed(jsonrpc.BuildHandler)
+	svc.Handlers.Unmarshal.PushBackNamed(jsonrpc.UnmarshalHandler)
+	svc.Handlers.UnmarshalMeta.PushBackNamed(jsonrpc.UnmarshalMetaHandler)
+	svc.Handlers.UnmarshalError.PushBackNamed(jsonrpc.UnmarshalErrorHandler)
+	return svc
+}
+
+func (c *serviceQuotasClient) GetServiceQuota(input *GetServiceQuotaInput) (*GetServiceQuotaOutput, error) {
+	return c.getQuota("GetServiceQuota", input)
+}
+
+func (c *serviceQuotasClient) GetAWSDefaultServiceQuota(input *GetServiceQuotaInput) (*GetServiceQuotaOutput, error) {
+	return c.getQuota("GetAWSDefaultServiceQuota", input)
+}
+
+func (c *serviceQuotasClient) getQuota(name string, input *GetServiceQuotaInput) (*GetServiceQuotaOutput, error) {
+	op := &request.Operation{
+		Name:       name,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+	if input == nil {
+		input = &GetServiceQuotaInput{}
+	}
+	output := &GetServiceQuotaOutput{}
+	req := c.NewRequest(op, input, output)
+	return output, req.Send()
+}
@@ -12,6 +12,7 @@ const (
 	PlatformAgentBaremetal = "agent-baremetal"
 	PlatformGCP            = "gcp"
 	PlatformOpenStack      = "openstack"
+	PlatformOvirt          = "ovirt"
 	PlatformUnknown        = "unknown"
 	PlatformVSphere        = "vsphere"
 
@@ -356,6 +357,54 @@ const (
 	HiveReleaseImageVerificationConfigMapNamespaceEnvVar = "HIVE_RELEASE_IMAGE_VERIFICATION_CONFIGMAP_NS"
 	HiveReleaseImageVerificationConfigMapNameEnvVar      = "HIVE_RELEASE_IMAGE_VERIFICATION_CONFIGMAP_NAME"
 
+	// MachinePoolAWSRetriesEnvVar is the environment variable specifying how many times the machinepool controller
+	// retries an AWS describe call that fails with a transient error before failing the reconcile.
+	MachinePoolAWSRetriesEnvVar = "MACHINEPOOL_AWS_RETRIES"
+
+	// MachinePoolAWSRetryIntervalEnvVar is the environment variable specifying the interval, as a duration such as
+	// "500ms", before the machinepool controller first retries a failed AWS describe call. The interval doubles on
+	// each subsequent retry.
+	MachinePoolAWSRetryIntervalEnvVar = "MACHINEPOOL_AWS_RETRY_INTERVAL"
+
+	// MachinePoolAWSAPIQPSEnvVar is the environment variable specifying the sustained rate, in calls per second, of
+	// the AWS describe calls the machinepool controller makes in each AWS account. The calls are not rate limited
+	// when it is not set.
+	MachinePoolAWSAPIQPSEnvVar = "MACHINEPOOL_AWS_API_QPS"
+
+	// MachinePoolAWSAPIBurstEnvVar is the environment variable specifying the burst of AWS describe calls allowed by
+	// the rate limit set with MachinePoolAWSAPIQPSEnvVar. Defaults to the QPS.
+	MachinePoolAWSAPIBurstEnvVar = "MACHINEPOOL_AWS_API_BURST"
+
+	// MachinePoolAWSAMISourcesEnvVar is the environment variable specifying the comma-separated sources of the AMIs of
+	// AWS MachinePools without a boot image, in the order they are tried, such as "RHCOSStream,Cluster". Defaults to
+	// "ImageIDOverride,RHCOSStream,Cluster".
+	MachinePoolAWSAMISourcesEnvVar = "MACHINEPOOL_AWS_AMI_SOURCES"
+
+	// MachinePoolAWSQuotaCheckEnvVar is the environment variable which, when set to "true", enables the check of the
+	// machines of AWS MachinePools against the EC2 and EBS quotas of their account.
+	MachinePoolAWSQuotaCheckEnvVar = "MACHINEPOOL_AWS_QUOTA_CHECK"
+
+	// MachinePoolAWSSubnetIPCheckEnvVar is the environment variable which, when set to "true", enables the check of the
+	// available IP addresses of the private subnets of AWS MachinePools.
+	MachinePoolAWSSubnetIPCheckEnvVar = "MACHINEPOOL_AWS_SUBNET_IP_CHECK"
+
+	// MachinePoolAWSSpotInstancesVersionsEnvVar is the environment variable specifying the semver range of the versions
+	// of the clusters whose AWS MachinePools may use spot instances, such as ">=4.4.0 <5.0.0". Defaults to ">=4.5.0".
+	MachinePoolAWSSpotInstancesVersionsEnvVar = "MACHINEPOOL_AWS_SPOT_INSTANCES_VERSIONS"
+
+	// MachinePoolReadOnlyStatusEnvVar is the environment variable which, when set to "true", prevents the actuators of
+	// the machinepool controller from persisting the conditions they compute on the status of MachinePools.
+	MachinePoolReadOnlyStatusEnvVar = "MACHINEPOOL_READ_ONLY_STATUS"
+
+	// MachinePoolConfigurationErrorRequeueIntervalEnvVar is the environment variable specifying the interval, as a
+	// duration such as "1h", after which the machinepool controller reconciles a MachinePool again when its
+	// MachineSets cannot be generated because of a configuration error. Zero disables the requeue.
+	MachinePoolConfigurationErrorRequeueIntervalEnvVar = "MACHINEPOOL_CONFIGURATION_ERROR_REQUEUE_INTERVAL"
+
+	// MachinePoolAdditionalTagsEnvVar is the environment variable specifying, as a JSON object of keys to values, the
+	// tags the machinepool controller adds to the machines of every MachinePool.
+	MachinePoolAdditionalTagsEnvVar = "MACHINEPOOL_ADDITIONAL_TAGS"
+
 	// HiveConfigName is the one and only name for a HiveConfig supported in the cluster. Any others will be ignored.
 	HiveConfigName = "hive"
 
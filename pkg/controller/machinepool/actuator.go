@@ -1,8 +1,12 @@
 package machinepool
 
 import (
+	"context"
+
 	log "github.com/sirupsen/logrus"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
 	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
@@ -19,4 +23,96 @@ type Actuator interface {
 	// or not, and an error. The boolean may be set in situations where we have not encountered an error, but still need
 	// to wait before we can proceed with reconciling. (e.g. obtaining a pool name lease)
 	GenerateMachineSets(*hivev1.ClusterDeployment, *hivev1.MachinePool, log.FieldLogger) (msets []*machineapi.MachineSet, proceed bool, genError error)
+
+	// Validate checks the configuration of a MachinePool without generating MachineSets or modifying the MachinePool.
+	// Returns the conditions resulting from the validation, and the errors found. Problems with the configuration are
+	// returned as *ValidationError so that callers such as webhooks can report them; any other error means the
+	// configuration could not be validated.
+	Validate(*hivev1.ClusterDeployment, *hivev1.MachinePool) ([]hivev1.MachinePoolCondition, []error)
+
+	// CleanupResources removes the cloud resources created by the actuator for a MachinePool that is being deleted,
+	// before the finalizer of the MachinePool is removed. It must be idempotent and succeed when the actuator did not
+	// create any resources for the MachinePool.
+	CleanupResources(*hivev1.ClusterDeployment, *hivev1.MachinePool, log.FieldLogger) error
+
+	// Capabilities returns the features of MachinePools on the platform of the actuator and whether the given
+	// MachinePool can use them with the version of its cluster, so that clients need not know which features each
+	// platform and version supports. Returns nil if the actuator does not report its capabilities.
+	Capabilities(*hivev1.ClusterDeployment, *hivev1.MachinePool) []hivev1.MachinePoolCapability
+}
+
+// ValidationError is a problem with the configuration of a MachinePool found by Validate. Type and Reason are those of
+// the MachinePool condition that reports the problem.
+type ValidationError struct {
+	Type    hivev1.MachinePoolConditionType
+	Reason  string
+	Message string
+}
+
+// Error satisfies the error interface.
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// noopValidator provides a default Validate implementation for actuators that do not yet validate the MachinePool
+// configuration separately from generating MachineSets.
+type noopValidator struct{}
+
+// Validate satisfies the Actuator interface and reports no conditions.
+func (noopValidator) Validate(*hivev1.ClusterDeployment, *hivev1.MachinePool) ([]hivev1.MachinePoolCondition, []error) {
+	return nil, nil
+}
+
+// noopCleaner provides a default CleanupResources implementation for actuators that do not create cloud resources of
+// their own.
+type noopCleaner struct{}
+
+// CleanupResources satisfies the Actuator interface and removes nothing.
+func (noopCleaner) CleanupResources(*hivev1.ClusterDeployment, *hivev1.MachinePool, log.FieldLogger) error {
+	return nil
+}
+
+// readOnlyStatusClient is a client which discards status writes. Actuators built with it still record the conditions
+// they compute on the MachinePool they are given, but leave it to the caller to decide whether to persist them.
+type readOnlyStatusClient struct {
+	client.Client
+}
+
+// Status satisfies the client.StatusClient interface and returns a status writer which does nothing.
+func (readOnlyStatusClient) Status() client.StatusWriter {
+	return noopStatusWriter{}
+}
+
+type noopStatusWriter struct{}
+
+func (noopStatusWriter) Update(context.Context, client.Object, ...client.UpdateOption) error {
+	return nil
+}
+
+func (noopStatusWriter) Patch(context.Context, client.Object, client.Patch, ...client.PatchOption) error {
+	return nil
+}
+
+// noCapabilities provides a default Capabilities implementation for actuators that do not yet report the features of
+// their platform.
+type noCapabilities struct{}
+
+// Capabilities satisfies the Actuator interface and reports no capabilities.
+func (noCapabilities) Capabilities(*hivev1.ClusterDeployment, *hivev1.MachinePool) []hivev1.MachinePoolCapability {
+	return nil
+}
+
+// poolBootImage returns the boot image of the MachinePool spec, or the image of the cluster returned by clusterImage
+// when the spec does not set one, along with the source of the image.
+func poolBootImage(pool *hivev1.MachinePool, clusterImage func() (string, error)) (string, hivev1.BootImageSource, error) {
+	if pool.Spec.BootImage != "" {
+		return pool.Spec.BootImage, hivev1.SpecBootImageSource, nil
+	}
+	image, err := clusterImage()
+	return image, hivev1.ClusterBootImageSource, err
+}
+
+// setBootImageStatus records the boot image of the MachineSets generated for the MachinePool in its status.
+func setBootImageStatus(pool *hivev1.MachinePool, image string, source hivev1.BootImageSource) {
+	pool.Status.BootImage = &hivev1.BootImageStatus{Image: image, Source: source}
 }
@@ -0,0 +1,65 @@
+package machinepool
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/hive/pkg/constants"
+)
+
+// actuatorConstructor builds the Actuator of a platform for a MachinePool. The reconciler provides the client, scheme
+// and the state shared between reconciles, such as caches and rate limiters. Each constructor reads the credentials of
+// its platform from the ClusterDeployment, as their form differs between platforms. The context is that of the
+// reconcile, so that the actuator stops waiting on its cloud API when the reconcile is cancelled.
+type actuatorConstructor func(
+	ctx context.Context,
+	r *ReconcileMachinePool,
+	cd *hivev1.ClusterDeployment,
+	pool *hivev1.MachinePool,
+	masterMachine *machineapi.Machine,
+	remoteMachineSets []machineapi.MachineSet,
+	remoteClusterAPIClient client.Client,
+	logger log.FieldLogger,
+) (Actuator, error)
+
+// actuatorConstructors maps platform names, as returned by clusterPlatform, to the constructor of their Actuator.
+var actuatorConstructors = map[string]actuatorConstructor{}
+
+// registerActuator registers the Actuator constructor of a platform. It is meant to be called from the init function of
+// the file implementing the actuator, and panics if the platform already has a constructor.
+func registerActuator(platform string, constructor actuatorConstructor) {
+	if _, ok := actuatorConstructors[platform]; ok {
+		panic(fmt.Sprintf("actuator already registered for platform %s", platform))
+	}
+	actuatorConstructors[platform] = constructor
+}
+
+// clusterPlatform returns the name of the platform of a ClusterDeployment under which its Actuator is registered.
+func clusterPlatform(cd *hivev1.ClusterDeployment) string {
+	switch {
+	case cd.Spec.Platform.AWS != nil:
+		return constants.PlatformAWS
+	case cd.Spec.Platform.Azure != nil:
+		return constants.PlatformAzure
+	case cd.Spec.Platform.GCP != nil:
+		return constants.PlatformGCP
+	case cd.Spec.Platform.OpenStack != nil:
+		return constants.PlatformOpenStack
+	case cd.Spec.Platform.VSphere != nil:
+		return constants.PlatformVSphere
+	case cd.Spec.Platform.Ovirt != nil:
+		return constants.PlatformOvirt
+	case cd.Spec.Platform.BareMetal != nil:
+		return constants.PlatformBaremetal
+	case cd.Spec.Platform.AgentBareMetal != nil:
+		return constants.PlatformAgentBaremetal
+	}
+	return constants.PlatformUnknown
+}
@@ -0,0 +1,36 @@
+package machinepool
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hivev1baremetal "github.com/openshift/hive/apis/hive/v1/baremetal"
+	"github.com/openshift/hive/pkg/constants"
+)
+
+func TestActuatorRegistry(t *testing.T) {
+	for _, platform := range []string{
+		constants.PlatformAWS,
+		constants.PlatformAzure,
+		constants.PlatformGCP,
+		constants.PlatformOpenStack,
+		constants.PlatformOvirt,
+		constants.PlatformVSphere,
+	} {
+		assert.Contains(t, actuatorConstructors, platform, "no actuator registered for platform %s", platform)
+	}
+
+	assert.Panics(t, func() {
+		registerActuator(constants.PlatformAWS, newAWSActuatorForCluster)
+	}, "registering a second actuator for a platform should panic")
+
+	cd := testClusterDeployment()
+	cd.Spec.Platform = hivev1.Platform{BareMetal: &hivev1baremetal.Platform{}}
+	r := &ReconcileMachinePool{}
+	_, err := r.createActuator(context.Background(), cd, testMachinePool(), nil, nil, nil, log.StandardLogger())
+	assert.EqualError(t, err, "unsupported platform")
+}
@@ -2,20 +2,34 @@ package machinepool
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/blang/semver/v4"
+	"github.com/coreos/stream-metadata-go/stream"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	awsprovider "sigs.k8s.io/cluster-api-provider-aws/pkg/apis"
 	awsproviderv1beta1 "sigs.k8s.io/cluster-api-provider-aws/pkg/apis/awsprovider/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -25,105 +39,753 @@ import (
 	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hivev1aws "github.com/openshift/hive/apis/hive/v1/aws"
 	"github.com/openshift/hive/pkg/awsclient"
+	"github.com/openshift/hive/pkg/constants"
 	controllerutils "github.com/openshift/hive/pkg/controller/utils"
 )
 
 // AWSActuator encapsulates the pieces necessary to be able to generate
 // a list of MachineSets to sync to the remote cluster.
 type AWSActuator struct {
+	noopCleaner
+
 	client    client.Client
 	awsClient awsclient.Client
 	logger    log.FieldLogger
 	region    string
 	amiID     string
+	// amiSource is where amiID comes from.
+	amiSource hivev1.BootImageSource
+	// zoneAMIIDs are the AMIs to use instead of amiID in some availability zones.
+	zoneAMIIDs map[string]string
+	// zoneStates are the states of the availability zones of the region used when the pool does not list its zones.
+	zoneStates []string
+	// additionalTags are the tags added to the machines of every MachinePool, as configured in HiveConfig.
+	additionalTags map[string]string
+	// kmsKeyARN is the ARN of the KMS key encrypting the root volumes, with an alias set in the pool resolved.
+	kmsKeyARN string
+	// deviceKMSKeyARNs are the ARNs of the KMS keys encrypting the additional block devices of the pool, in the order
+	// of the devices, with aliases set in the pool resolved.
+	deviceKMSKeyARNs []string
+	// instanceTypes are the descriptions of the instance types looked up by the actuator, nil for those not offered in
+	// the region.
+	instanceTypes map[string]*ec2.InstanceTypeInfo
+	// routeTables is a reference to the reconciler's cache of the route tables in each VPC.
+	routeTables *routeTableCache
+	// quotaCheck enables checking the machines of the pool against the EC2 and EBS quotas of the account.
+	quotaCheck bool
+	// subnetIPCheck enables checking the available IP addresses of the private subnets of the pool.
+	subnetIPCheck bool
+	// versionGatedFeatures are the version-gated features as configured, or nil for the default ones.
+	versionGatedFeatures []versionGatedFeature
+	// zoneMachines are the numbers of machines of the MachineSets of the pool in the remote cluster by availability
+	// zone, which already have their IP addresses. Only set when subnetIPCheck is enabled.
+	zoneMachines map[string]int64
+}
+
+// awsActuatorOptions are the caches and settings of the reconciler which NewAWSActuator builds the actuator with. The
+// caches, rate limiters and singleflight are shared by the AWS actuators of all reconciles.
+type awsActuatorOptions struct {
+	routeTables  *routeTableCache
+	kmsKeys      *kmsKeyCache
+	images       *imageCache
+	rateLimiters *awsRateLimiters
+	retryBackoff wait.Backoff
+	sharedCalls  *awsSingleflight
+
+	additionalTags       map[string]string
+	quotaCheck           bool
+	subnetIPCheck        bool
+	amiSources           []hivev1.BootImageSource
+	versionGatedFeatures []versionGatedFeature
 }
 
 var (
 	_ Actuator = &AWSActuator{}
 
-	// reg is a regex used to fetch condition message from error when subnets specified in the MachinePool are invalid
-	reg = regexp.MustCompile(`^InvalidSubnetID\.NotFound:\s+([^\t]+)\t`)
+	// reg is a regex used to fetch condition message from error when subnets specified in the MachinePool are invalid.
+	// It matches the InvalidSubnet* family of error codes (e.g. InvalidSubnetID.NotFound, InvalidSubnetID.Malformed,
+	// InvalidSubnet.Range) and strips the trailing status code and request id, if any.
+	reg = regexp.MustCompile(`(?s)^InvalidSubnet[\w.]*:\s+(.+?)\s*(?:status code: \d+.*)?$`)
 
 	versionsSupportingSpotInstances = semver.MustParseRange(">=4.5.0")
+
+	// edgeZoneRegex matches the names of AWS edge zones, which are named after their parent region rather than a
+	// parent availability zone: Local Zones (e.g. us-west-2-lax-1a) and Wavelength Zones (e.g. us-east-1-wl1-bos-wlz-1).
+	edgeZoneRegex = regexp.MustCompile(`^[a-z]{2}(?:-gov)?-[a-z]+-\d+-(?:[a-z]+-\d+[a-z]|wl\d+-[a-z0-9]+-wlz-\d+)$`)
+
+	// spotMaxPriceRegex matches decimal numbers, such as 0.5, .5 or 1.
+	spotMaxPriceRegex = regexp.MustCompile(`^(?:\d+(?:\.\d*)?|\.\d+)$`)
+
+	// kmsKeyAliasRegex matches the aliases of KMS keys, which may be given instead of their ARN.
+	kmsKeyAliasRegex = regexp.MustCompile(`^alias/[a-zA-Z0-9/_-]+$`)
+
+	// defaultZoneStates are the states of the availability zones used when the availability-zone-states annotation is
+	// not set.
+	defaultZoneStates = []string{ec2.AvailabilityZoneStateAvailable}
+
+	// defaultAWSAMISources are the sources of the AMIs of pools without a boot image, in the order they are tried, used
+	// when the MACHINEPOOL_AWS_AMI_SOURCES environment variable is not set.
+	defaultAWSAMISources = []hivev1.BootImageSource{
+		hivev1.ImageIDOverrideBootImageSource,
+		hivev1.RHCOSStreamBootImageSource,
+		hivev1.ClusterBootImageSource,
+	}
 )
 
 func addAWSProviderToScheme(scheme *runtime.Scheme) error {
 	return awsprovider.AddToScheme(scheme)
 }
 
-// NewAWSActuator is the constructor for building a AWSActuator
+func init() {
+	registerActuator(constants.PlatformAWS, newAWSActuatorForCluster)
+}
+
+// newAWSActuatorForCluster is the registered actuatorConstructor of AWS. The credentials are those of the pool when it
+// has its own, or else those of the ClusterDeployment, as returned by awsCredentialsSource. The region is that of the
+// master machine of the cluster, so that the actuator reports when the cluster is not in the region of the
+// ClusterDeployment.
+func newAWSActuatorForCluster(
+	ctx context.Context,
+	r *ReconcileMachinePool,
+	cd *hivev1.ClusterDeployment,
+	pool *hivev1.MachinePool,
+	masterMachine *machineapi.Machine,
+	remoteMachineSets []machineapi.MachineSet,
+	remoteClusterAPIClient client.Client,
+	logger log.FieldLogger,
+) (Actuator, error) {
+	region := masterMachineRegion(masterMachine, r.scheme, logger)
+	options := awsActuatorOptions{
+		routeTables:  r.routeTables,
+		kmsKeys:      r.kmsKeys,
+		images:       r.images,
+		rateLimiters: r.awsRateLimiters,
+		retryBackoff: r.awsRetryBackoff,
+		sharedCalls:  r.awsSingleflight,
+
+		additionalTags:       r.additionalTags,
+		quotaCheck:           r.awsQuotaCheck,
+		subnetIPCheck:        r.awsSubnetIPCheck,
+		amiSources:           r.awsAMISources,
+		versionGatedFeatures: r.versionGatedFeatures,
+	}
+	return NewAWSActuator(ctx, r.actuatorClient(), awsCredentialsSource(cd, pool), cd.Spec.Platform.AWS, region, pool, masterMachine, remoteClusterAPIClient, remoteMachineSets, options, r.scheme, logger)
+}
+
+// NewAWSActuator is the constructor for building a AWSActuator. The region is the region in which the actuator looks
+// up the resources of the pool, or empty for the region of the platform of the ClusterDeployment. A region other than
+// that of the ClusterDeployment is reported by the InvalidConfiguration condition, and no MachineSets are generated.
+// The context bounds the waits of the AWS client for the rate limit and between retries.
 func NewAWSActuator(
+	ctx context.Context,
 	client client.Client,
 	credentials awsclient.CredentialsSource,
+	platform *hivev1aws.Platform,
 	region string,
 	pool *hivev1.MachinePool,
 	masterMachine *machineapi.Machine,
+	remoteClusterAPIClient client.Client,
+	remoteMachineSets []machineapi.MachineSet,
+	options awsActuatorOptions,
 	scheme *runtime.Scheme,
 	logger log.FieldLogger,
 ) (*AWSActuator, error) {
-	awsClient, err := awsclient.New(client, awsclient.Options{Region: region, CredentialsSource: credentials})
-	if err != nil {
-		logger.WithError(err).Warn("failed to create AWS client")
-		return nil, err
+	if region == "" {
+		region = platform.Region
 	}
-	amiID := pool.Annotations[hivev1.MachinePoolImageIDOverrideAnnotation]
-	if amiID != "" {
-		log.Infof("using AMI override from %s annotation: %s", hivev1.MachinePoolImageIDOverrideAnnotation, amiID)
-	} else {
-		amiID, err = getAWSAMIID(masterMachine, scheme, logger)
+	// The AWS client cannot be created for a region outside the configured partition or other than that of the
+	// ClusterDeployment, which is reported by resolveAWSPoolConfiguration instead.
+	var awsClient awsclient.Client
+	if region == platform.Region && awsclient.ValidateRegionInPartition(region, platform.Partition) == nil {
+		var err error
+		awsClient, err = awsclient.New(client, awsclient.Options{
+			Region:            region,
+			Partition:         platform.Partition,
+			ServiceEndpoints:  platform.ServiceEndpoints,
+			CredentialsSource: credentials,
+		})
 		if err != nil {
-			logger.WithError(err).Warn("failed to get AMI ID")
+			logger.WithError(err).Warn("failed to create AWS client")
 			return nil, err
 		}
+		// Each request is logged, including the retries.
+		awsClient = newLoggingAWSClient(awsClient, clock.RealClock{}, logger)
+		// Each retry waits for the rate limit of the account again.
+		awsClient, err = options.rateLimiters.wrap(ctx, awsClient, credentials)
+		if err != nil {
+			logger.WithError(err).Warn("failed to rate limit AWS client")
+			return nil, err
+		}
+		awsClient = newRetryingAWSClient(ctx, awsClient, options.retryBackoff, logger)
+		// The pools of the cluster share the result of a call, including its retries.
+		awsClient = options.sharedCalls.wrap(awsClient, pool.Namespace, pool.Spec.ClusterDeploymentRef.Name, credentials)
+	}
+	getAccount := func() (string, error) {
+		return options.rateLimiters.getAccount(awsClient, credentials)
+	}
+	// The AMI is resolved first so that an AMI override can be validated with the rest of the configuration, but errors
+	// resolving it are only returned once the InvalidConfiguration condition is set.
+	amiID, amiSource, amiErr := resolveAMIID(client, pool, masterMachine, remoteClusterAPIClient, region, options.amiSources, scheme, logger)
+	var imageIDOverride string
+	if amiErr == nil && amiSource == hivev1.ImageIDOverrideBootImageSource {
+		imageIDOverride = amiID
+	}
+	config, err := resolveAWSPoolConfiguration(awsClient, getAccount, options.kmsKeys, options.images, pool, platform, region, imageIDOverride, logger)
+	status, reason, message := corev1.ConditionFalse, "ValidConfiguration", "The configuration is valid"
+	updateCheck := controllerutils.UpdateConditionNever
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		status, reason, message = corev1.ConditionTrue, validationErr.Reason, validationErr.Message
+		updateCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+	case err != nil:
+		// The configuration may still be valid once the AWS API recovers, so the condition is left as it is.
+		return nil, err
+	}
+	conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+		pool.Status.Conditions,
+		hivev1.InvalidConfigurationMachinePoolCondition,
+		status,
+		reason,
+		message,
+		updateCheck,
+	)
+	if changed {
+		pool.Status.Conditions = conds
+		if err := client.Status().Update(ctx, pool); err != nil {
+			return nil, errors.Wrap(err, "could not update MachinePool status")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if amiErr != nil {
+		return nil, amiErr
 	}
 	actuator := &AWSActuator{
-		client:    client,
-		awsClient: awsClient,
-		logger:    logger,
-		region:    region,
-		amiID:     amiID,
+		client:           client,
+		awsClient:        awsClient,
+		logger:           logger,
+		region:           region,
+		amiID:            amiID,
+		amiSource:        amiSource,
+		zoneAMIIDs:       config.zoneAMIIDs,
+		zoneStates:       config.zoneStates,
+		kmsKeyARN:        config.kmsKeyARN,
+		deviceKMSKeyARNs: config.deviceKMSKeyARNs,
+		routeTables:      options.routeTables,
+		additionalTags:   options.additionalTags,
+		quotaCheck:       options.quotaCheck,
+		subnetIPCheck:    options.subnetIPCheck,
+
+		versionGatedFeatures: options.versionGatedFeatures,
+	}
+	if options.subnetIPCheck {
+		actuator.zoneMachines = poolZoneMachines(pool, remoteMachineSets, scheme, logger)
 	}
 	return actuator, nil
 }
 
+// awsPoolConfiguration is the configuration of an AWS MachinePool resolved by resolveAWSPoolConfiguration.
+type awsPoolConfiguration struct {
+	// zoneAMIIDs are the AMI IDs by availability zone of the zone-image-id-overrides annotation.
+	zoneAMIIDs map[string]string
+	// zoneStates are the availability zone states of the availability-zone-states annotation.
+	zoneStates []string
+	// kmsKeyARN is the ARN of the KMS key of the root volume, with an alias resolved to its key.
+	kmsKeyARN string
+	// deviceKMSKeyARNs are the ARNs of the KMS keys of the additional block devices, with aliases resolved to their keys.
+	deviceKMSKeyARNs []string
+}
+
+// resolveAWSPoolConfiguration validates the configuration of the pool against the platform and region of the cluster,
+// and resolves its annotations and KMS key aliases. Every problem found is reported by a single *ValidationError;
+// any other error is from the AWS API, which the validation cannot complete without.
+func resolveAWSPoolConfiguration(awsClient awsclient.Client, getAccount func() (string, error), kmsKeys *kmsKeyCache, images *imageCache, pool *hivev1.MachinePool, platform *hivev1aws.Platform, region, imageIDOverride string, logger log.FieldLogger) (*awsPoolConfiguration, error) {
+	var problems []*ValidationError
+	invalid := func(reason string, err error) {
+		problems = append(problems, &ValidationError{
+			Type:    hivev1.InvalidConfigurationMachinePoolCondition,
+			Reason:  reason,
+			Message: err.Error(),
+		})
+	}
+	config := &awsPoolConfiguration{}
+	var err error
+	config.zoneAMIIDs, err = parseZoneAMIIDs(pool.Annotations[hivev1.MachinePoolZoneImageIDOverridesAnnotation])
+	if err != nil {
+		logger.WithError(err).Warn("could not parse zone image ID overrides")
+		invalid("InvalidZoneImageIDOverrides", err)
+	}
+	config.zoneStates, err = parseZoneStates(pool.Annotations[hivev1.MachinePoolAvailabilityZoneStatesAnnotation])
+	if err != nil {
+		logger.WithError(err).Warn("could not parse availability zone states")
+		invalid("InvalidAvailabilityZoneStates", err)
+	}
+	if poolPlatform := pool.Spec.Platform.AWS; poolPlatform != nil && poolPlatform.SpotMarketOptions != nil && poolPlatform.SpotMarketOptions.MaxPrice != nil {
+		if _, err := parseSpotMaxPrice(*poolPlatform.SpotMarketOptions.MaxPrice); err != nil {
+			logger.WithError(err).Warn("invalid spot max price")
+			invalid("InvalidSpotMaxPrice", err)
+		}
+	}
+	if poolPlatform := pool.Spec.Platform.AWS; poolPlatform != nil && poolPlatform.IAMInstanceProfileARN != "" {
+		if err := validateIAMInstanceProfileARN(poolPlatform.IAMInstanceProfileARN); err != nil {
+			logger.WithError(err).Warn("invalid IAM instance profile ARN")
+			invalid("InvalidIAMInstanceProfileARN", err)
+		}
+	}
+	if poolPlatform := pool.Spec.Platform.AWS; poolPlatform != nil && poolPlatform.SpotMarketOptions != nil &&
+		poolPlatform.MarketType != "" && poolPlatform.MarketType != hivev1aws.SpotMarketType {
+		err := fmt.Errorf("spot market options are only valid with the %s market type, not %s", hivev1aws.SpotMarketType, poolPlatform.MarketType)
+		logger.WithError(err).Warn("inconsistent market type")
+		invalid("InconsistentMarketType", err)
+	}
+	if err := awsclient.ValidateRegionInPartition(platform.Region, platform.Partition); err != nil {
+		logger.WithError(err).Warn("region does not match the configured partition")
+		invalid("RegionPartitionMismatch", err)
+	}
+	if region != platform.Region {
+		err := fmt.Errorf("region %s of the MachinePool does not match region %s of the ClusterDeployment", region, platform.Region)
+		logger.WithError(err).Warn("region does not match the region of the ClusterDeployment")
+		invalid("RegionMismatch", err)
+	}
+	if poolPlatform := pool.Spec.Platform.AWS; poolPlatform != nil {
+		config.kmsKeyARN = poolPlatform.EC2RootVolume.KMSKeyARN
+		for _, device := range poolPlatform.AdditionalBlockDevices {
+			config.deviceKMSKeyARNs = append(config.deviceKMSKeyARNs, device.KMSKeyARN)
+		}
+	}
+	// The KMS keys of the root volume and of each additional block device are resolved independently, as each may be
+	// given by its own alias. Without an AWS client, the region is invalid and already reported.
+	volumeKMSKeys := []*string{&config.kmsKeyARN}
+	for i := range config.deviceKMSKeyARNs {
+		volumeKMSKeys = append(volumeKMSKeys, &config.deviceKMSKeyARNs[i])
+	}
+	for _, kmsKey := range volumeKMSKeys {
+		if awsClient == nil || !isKMSKeyAlias(*kmsKey) {
+			continue
+		}
+		keyARN, err := resolveKMSKeyAlias(awsClient, getAccount, kmsKeys, region, *kmsKey)
+		var validationErr *ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			logger.WithError(err).Warn("invalid KMS key alias")
+			problems = append(problems, validationErr)
+		case err != nil:
+			logger.WithError(err).Warn("could not resolve KMS key alias")
+			return nil, err
+		}
+		*kmsKey = keyARN
+	}
+	if awsClient != nil && imageIDOverride != "" {
+		err := checkImageIDOverride(awsClient, getAccount, images, region, imageIDOverride)
+		var validationErr *ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			logger.WithError(err).Warn("invalid AMI override")
+			problems = append(problems, validationErr)
+		case err != nil:
+			logger.WithError(err).Warn("could not describe AMI override")
+			return nil, err
+		}
+	}
+	switch len(problems) {
+	case 0:
+		return config, nil
+	case 1:
+		return nil, problems[0]
+	}
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.Message
+	}
+	return nil, &ValidationError{
+		Type:    hivev1.InvalidConfigurationMachinePoolCondition,
+		Reason:  "MultipleInvalidSettings",
+		Message: strings.Join(messages, "; "),
+	}
+}
+
+// checkImageIDOverride returns a *ValidationError if the AMI of the image-id-override annotation does not exist in the
+// region, is not accessible to the AWS account returned by getAccount or is not available, using images to cache the
+// available AMIs, and any other error if the AMI could not be described. AWS does not distinguish AMIs of other regions
+// from AMIs not shared with the account, so both are reported as not found.
+func checkImageIDOverride(awsClient awsclient.Client, getAccount func() (string, error), images *imageCache, region, imageID string) error {
+	account, err := getAccount()
+	if err != nil {
+		return errors.Wrapf(err, "could not describe AMI %s in region %s", imageID, region)
+	}
+	available, err := images.imageAvailable(awsClient, account, region, imageID)
+	if awsErr, ok := err.(awserr.Error); ok {
+		switch awsErr.Code() {
+		case "InvalidAMIID.NotFound", "InvalidAMIID.Unavailable", "InvalidAMIID.Malformed":
+			return &ValidationError{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Reason: "ImageIDOverrideNotFound",
+				Message: fmt.Sprintf("AMI %s of the %s annotation does not exist in region %s or is not accessible to the account",
+					imageID, hivev1.MachinePoolImageIDOverrideAnnotation, region),
+			}
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not describe AMI %s in region %s", imageID, region)
+	}
+	if !available {
+		return &ValidationError{
+			Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+			Reason: "ImageIDOverrideUnavailable",
+			Message: fmt.Sprintf("AMI %s of the %s annotation is not available in region %s",
+				imageID, hivev1.MachinePoolImageIDOverrideAnnotation, region),
+		}
+	}
+	return nil
+}
+
+// validateIAMInstanceProfileARN returns an error if the given string is not the ARN of an IAM instance profile.
+func validateIAMInstanceProfileARN(profileARN string) error {
+	parsed, err := arn.Parse(profileARN)
+	if err != nil {
+		return fmt.Errorf("invalid IAM instance profile ARN %q: %v", profileARN, err)
+	}
+	if parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "instance-profile/") {
+		return fmt.Errorf("invalid IAM instance profile ARN %q: not the ARN of an IAM instance profile", profileARN)
+	}
+	return nil
+}
+
+// isKMSKeyAlias returns whether the KMS key of a root volume is given by an alias, such as alias/ebs-worker, rather
+// than by an ARN.
+func isKMSKeyAlias(kmsKey string) bool {
+	return strings.HasPrefix(kmsKey, kmsKeyAliasPrefix)
+}
+
+// resolveKMSKeyAlias returns the ARN of the KMS key that the given alias resolves to in the region and the AWS
+// account returned by getAccount, using kmsKeys to cache the key. Returns a *ValidationError if the alias is invalid
+// or does not exist, and any other error if the alias could not be resolved.
+func resolveKMSKeyAlias(awsClient awsclient.Client, getAccount func() (string, error), kmsKeys *kmsKeyCache, region, alias string) (string, error) {
+	if !kmsKeyAliasRegex.MatchString(alias) {
+		return "", &ValidationError{
+			Type:    hivev1.InvalidConfigurationMachinePoolCondition,
+			Reason:  "KMSKeyAliasNotResolved",
+			Message: fmt.Sprintf("invalid KMS key alias %q", alias),
+		}
+	}
+	partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region)
+	if !ok {
+		return "", &ValidationError{
+			Type:    hivev1.InvalidConfigurationMachinePoolCondition,
+			Reason:  "KMSKeyAliasNotResolved",
+			Message: fmt.Sprintf("could not resolve KMS key alias %s: region %s is not in any known partition", alias, region),
+		}
+	}
+	account, err := getAccount()
+	if err != nil {
+		return "", errors.Wrapf(err, "could not resolve KMS key alias %s in region %s", alias, region)
+	}
+	aliasARN := arn.ARN{
+		Partition: partition.ID(),
+		Service:   "kms",
+		Region:    region,
+		AccountID: account,
+		Resource:  alias,
+	}.String()
+	keyARN, err := kmsKeys.getKeyARN(awsClient, aliasARN)
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == awsclient.KMSNotFoundExceptionCode {
+		return "", &ValidationError{
+			Type:    hivev1.InvalidConfigurationMachinePoolCondition,
+			Reason:  "KMSKeyAliasNotFound",
+			Message: fmt.Sprintf("KMS key alias %s does not exist in region %s", alias, region),
+		}
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "could not resolve KMS key alias %s in region %s", alias, region)
+	}
+	return keyARN, nil
+}
+
+// parseSpotMaxPrice parses the max price of spot instances, which must be a positive decimal number of dollars per
+// hour.
+func parseSpotMaxPrice(maxPrice string) (float64, error) {
+	if !spotMaxPriceRegex.MatchString(maxPrice) {
+		return 0, errors.Errorf("invalid spot max price %q: must be a decimal number", maxPrice)
+	}
+	price, err := strconv.ParseFloat(maxPrice, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid spot max price %q", maxPrice)
+	}
+	if price <= 0 {
+		return 0, errors.Errorf("invalid spot max price %q: must be positive", maxPrice)
+	}
+	return price, nil
+}
+
+// parseZoneAMIIDs parses the value of the zone-image-id-overrides annotation, a comma-separated list of zone=image-id
+// pairs, into the AMI IDs by availability zone.
+func parseZoneAMIIDs(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	zoneAMIIDs := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("invalid %s annotation: expected zone=image-id, got %q",
+				hivev1.MachinePoolZoneImageIDOverridesAnnotation, pair)
+		}
+		if _, ok := zoneAMIIDs[parts[0]]; ok {
+			return nil, errors.Errorf("invalid %s annotation: zone %s is listed more than once",
+				hivev1.MachinePoolZoneImageIDOverridesAnnotation, parts[0])
+		}
+		zoneAMIIDs[parts[0]] = parts[1]
+	}
+	return zoneAMIIDs, nil
+}
+
+// parseZoneStates parses the value of the availability-zone-states annotation, a comma-separated list of availability
+// zone states. Returns the default states when the annotation is not set.
+func parseZoneStates(value string) ([]string, error) {
+	if value == "" {
+		return defaultZoneStates, nil
+	}
+	validStates := sets.NewString(ec2.AvailabilityZoneState_Values()...)
+	var zoneStates []string
+	for _, state := range strings.Split(value, ",") {
+		state = strings.TrimSpace(state)
+		if !validStates.Has(state) {
+			return nil, errors.Errorf("invalid %s annotation: unknown zone state %q, expected one of %s",
+				hivev1.MachinePoolAvailabilityZoneStatesAnnotation, state, strings.Join(validStates.List(), ", "))
+		}
+		zoneStates = append(zoneStates, state)
+	}
+	return zoneStates, nil
+}
+
+// awsValidationConditions are the MachinePool conditions set by AWSActuator validation.
+var awsValidationConditions = []hivev1.MachinePoolConditionType{
+	hivev1.UnsupportedConfigurationMachinePoolCondition,
+	hivev1.InvalidSubnetsMachinePoolCondition,
+	hivev1.NoUsableZonesMachinePoolCondition,
+	hivev1.InstanceTypeNotResolvedMachinePoolCondition,
+	hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition,
+	hivev1.ResourcesNotFoundMachinePoolCondition,
+	hivev1.UnevenZoneSpreadMachinePoolCondition,
+	hivev1.UnauthorizedCredentialsMachinePoolCondition,
+}
+
+// awsValidationResult holds the values determined while validating a MachinePool that are needed to generate its
+// MachineSets.
+type awsValidationResult struct {
+	instanceType string
+	zones        []string
+	subnets      map[string]string
+}
+
+// Validate satisfies the Actuator interface and runs the spot market, instance type, zone, subnet and AMI checks for the
+// MachinePool without generating MachineSets or updating the MachinePool. Returns the resulting conditions, a
+// *ValidationError for each condition reporting a problem, and any error preventing the configuration from being
+// validated.
+func (a *AWSActuator) Validate(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool) ([]hivev1.MachinePoolCondition, []error) {
+	pool = pool.DeepCopy()
+	_, err := a.validate(cd, pool, a.logger)
+	var conds []hivev1.MachinePoolCondition
+	var errs []error
+	for _, condType := range awsValidationConditions {
+		cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, condType)
+		if cond == nil {
+			continue
+		}
+		conds = append(conds, *cond)
+		if cond.Status == corev1.ConditionTrue {
+			errs = append(errs, &ValidationError{
+				Type:    cond.Type,
+				Reason:  cond.Reason,
+				Message: cond.Message,
+			})
+		}
+	}
+	// An error not reported by any condition means the configuration could not be validated.
+	if err != nil && len(errs) == 0 {
+		errs = append(errs, err)
+	}
+	return conds, errs
+}
+
+// Capabilities satisfies the Actuator interface. Spot instances and Ignition spec 3 user data depend on the version of
+// the cluster, whereas capacity reservations, additional network interfaces, license configurations and falling back
+// from spot to on-demand instances cannot be set in the AWS provider config of the machine API of any version.
+func (a *AWSActuator) Capabilities(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool) []hivev1.MachinePoolCapability {
+	return []hivev1.MachinePoolCapability{
+		versionGatedCapability(a.versionGatedFeatures, cd, pool, hivev1.SpotInstancesCapability),
+		{Name: hivev1.ScaleToZeroCapability, Supported: true},
+		versionGatedCapability(a.versionGatedFeatures, cd, pool, hivev1.IgnitionV3UserDataCapability),
+		{Name: hivev1.GP3RootVolumesCapability, Supported: true},
+		{
+			Name:    hivev1.CapacityReservationsCapability,
+			Message: "The AWS provider config of the machine API has no capacity reservation",
+		},
+		{
+			Name:    hivev1.NetworkInterfacesCapability,
+			Message: "The AWS provider config of the machine API has no additional network interfaces",
+		},
+		{
+			Name:    hivev1.LicenseConfigurationsCapability,
+			Message: "The AWS provider config of the machine API has no license specifications",
+		},
+		{
+			Name:    hivev1.SpotFallbackToOnDemandCapability,
+			Message: "The machine API has no mixed-instances policy to fall back from spot to on-demand instances",
+		},
+	}
+}
+
 // GenerateMachineSets satisfies the Actuator interface and will take a clusterDeployment and return a list of MachineSets
 // to sync to the remote cluster.
 func (a *AWSActuator) GenerateMachineSets(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, logger log.FieldLogger) ([]*machineapi.MachineSet, bool, error) {
+	origPool := pool.DeepCopy()
+	validation, err := a.validate(cd, pool, logger)
+	if isConfigurationError(err) {
+		// The subnets reported for an earlier configuration no longer say which subnets the MachineSets would use.
+		pool.Status.SubnetsByAvailabilityZone = nil
+	}
+	if !reflect.DeepEqual(origPool.Status.Conditions, pool.Status.Conditions) ||
+		!reflect.DeepEqual(origPool.Status.ImageIDs, pool.Status.ImageIDs) ||
+		!reflect.DeepEqual(origPool.Status.SubnetsByAvailabilityZone, pool.Status.SubnetsByAvailabilityZone) {
+		if err := a.client.Status().Update(context.Background(), pool); err != nil {
+			return nil, false, errors.Wrap(err, "could not update MachinePool status")
+		}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	computePool := baseMachinePool(pool)
+	computePool.Platform.AWS = &installertypesaws.MachinePool{
+		AMIID:        a.amiID,
+		InstanceType: validation.instanceType,
+		EC2RootVolume: installertypesaws.EC2RootVolume{
+			IOPS:      pool.Spec.Platform.AWS.EC2RootVolume.IOPS,
+			Size:      pool.Spec.Platform.AWS.EC2RootVolume.Size,
+			Type:      pool.Spec.Platform.AWS.EC2RootVolume.Type,
+			KMSKeyARN: a.kmsKeyARN,
+		},
+		Zones: validation.zones,
+	}
+
+	// The user tags of the cluster are merged into the generated MachineSets in updateProviderConfig, as the installer
+	// rejects user tags clobbering the tags it reserves.
+	installerMachineSets, err := installaws.MachineSets(
+		cd.Spec.ClusterMetadata.InfraID,
+		cd.Spec.Platform.AWS.Region,
+		validation.subnets,
+		computePool,
+		pool.Spec.Name,
+		workerUserData(pool),
+		nil,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "no subnet for zone") {
+			conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+				pool.Status.Conditions,
+				hivev1.InvalidSubnetsMachinePoolCondition,
+				corev1.ConditionTrue,
+				"NoSubnetForAvailabilityZone",
+				err.Error(),
+				controllerutils.UpdateConditionIfReasonOrMessageChange,
+			)
+			if changed {
+				pool.Status.Conditions = conds
+				if err := a.client.Status().Update(context.Background(), pool); err != nil {
+					return nil, false, err
+				}
+			}
+			return nil, false, &ValidationError{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Reason:  "NoSubnetForAvailabilityZone",
+				Message: err.Error(),
+			}
+		}
+
+		return nil, false, errors.Wrap(err, "failed to generate machinesets")
+	}
+
+	// Re-use existing AWS resources for generated MachineSets.
+	for _, ms := range installerMachineSets {
+		a.updateProviderConfig(ms, cd.Spec.ClusterMetadata.InfraID, pool, withAdditionalTags(cd.Spec.Platform.AWS.UserTags, a.additionalTags))
+	}
+
+	setBootImageStatus(pool, a.amiID, a.amiSource)
+	return installerMachineSets, true, nil
+}
+
+// validate checks the MachinePool configuration, recording the outcome in the conditions of the given pool without
+// persisting them. Problems with the configuration which require user action are returned as *ValidationError.
+func (a *AWSActuator) validate(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, logger log.FieldLogger) (*awsValidationResult, error) {
 	if cd.Spec.ClusterMetadata == nil {
-		return nil, false, errors.New("ClusterDeployment does not have cluster metadata")
+		return nil, errors.New("ClusterDeployment does not have cluster metadata")
 	}
 	if cd.Spec.Platform.AWS == nil {
-		return nil, false, errors.New("ClusterDeployment is not for AWS")
+		return nil, errors.New("ClusterDeployment is not for AWS")
 	}
 	if pool.Spec.Platform.AWS == nil {
-		return nil, false, errors.New("MachinePool is not for AWS")
+		return nil, errors.New("MachinePool is not for AWS")
 	}
-	clusterVersion, err := getClusterVersion(cd)
+	if a.amiID == "" {
+		return nil, errors.New("no AMI ID available for MachinePool")
+	}
+	if err := a.setUnauthorizedCredentialsCondition(pool, logger); err != nil {
+		return nil, err
+	}
+	// Version-gated features are disabled when the version of the cluster cannot be determined, rather than failing
+	// pools which do not use them.
+	clusterVersion, err := getClusterVersion(cd, pool)
 	if err != nil {
-		return nil, false, fmt.Errorf("Unable to get cluster version: %v", err)
+		logger.WithError(err).Warn("could not determine the cluster version, disabling version-gated features")
 	}
 
-	if isUsingUnsupportedSpotMarketOptions(pool, clusterVersion, logger) {
+	var unsupportedReason, unsupportedMessage string
+	switch {
+	case isUsingUnsupportedSpotMarketOptions(pool, clusterVersion, a.versionGatedFeatures, logger):
 		logger.WithField("clusterVersion", clusterVersion).Debug("cluster does not support spot instances")
-		conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+		unsupportedReason = "UnsupportedSpotMarketOptions"
+		unsupportedMessage = "The version of the cluster does not support using spot instances"
+		if clusterVersion == "" {
+			unsupportedMessage = "Spot instances are disabled until the version of the cluster is known"
+		}
+	case pool.Spec.Platform.AWS.MarketType == hivev1aws.CapacityBlockMarketType:
+		// The AWS provider config of the machine API has no capacity reservation to launch the instances into.
+		unsupportedReason = "UnsupportedMarketType"
+		unsupportedMessage = "The machine API does not support launching instances into capacity blocks"
+	}
+	if unsupportedReason == "" && pool.Spec.BootDiagnostics {
+		unsupportedReason, unsupportedMessage, err = a.checkSerialConsole(pool, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if unsupportedReason == "" {
+		unsupportedReason, unsupportedMessage, err = a.checkRootDeviceType(pool, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if unsupportedReason != "" {
+		pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
 			pool.Status.Conditions,
 			hivev1.UnsupportedConfigurationMachinePoolCondition,
 			corev1.ConditionTrue,
-			"UnsupportedSpotMarketOptions",
-			"The version of the cluster does not support using spot instances",
+			unsupportedReason,
+			unsupportedMessage,
 			controllerutils.UpdateConditionIfReasonOrMessageChange,
 		)
-		if changed {
-			pool.Status.Conditions = conds
-			if err := a.client.Status().Update(context.Background(), pool); err != nil {
-				return nil, false, errors.Wrap(err, "could not update MachinePool status")
-			}
+		return nil, &ValidationError{
+			Type:    hivev1.UnsupportedConfigurationMachinePoolCondition,
+			Reason:  unsupportedReason,
+			Message: unsupportedMessage,
 		}
-		return nil, false, nil
 	}
-	statusChanged := false
-	pool.Status.Conditions, statusChanged = controllerutils.SetMachinePoolConditionWithChangeCheck(
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
 		pool.Status.Conditions,
 		hivev1.UnsupportedConfigurationMachinePoolCondition,
 		corev1.ConditionFalse,
@@ -132,121 +794,918 @@ func (a *AWSActuator) GenerateMachineSets(cd *hivev1.ClusterDeployment, pool *hi
 		controllerutils.UpdateConditionIfReasonOrMessageChange,
 	)
 
-	computePool := baseMachinePool(pool)
-	computePool.Platform.AWS = &installertypesaws.MachinePool{
-		AMIID:        a.amiID,
-		InstanceType: pool.Spec.Platform.AWS.InstanceType,
-		EC2RootVolume: installertypesaws.EC2RootVolume{
-			IOPS:      pool.Spec.Platform.AWS.EC2RootVolume.IOPS,
-			Size:      pool.Spec.Platform.AWS.EC2RootVolume.Size,
-			Type:      pool.Spec.Platform.AWS.EC2RootVolume.Type,
-			KMSKeyARN: pool.Spec.Platform.AWS.EC2RootVolume.KMSKeyARN,
-		},
-		Zones: pool.Spec.Platform.AWS.Zones,
+	instanceType, err := a.resolveInstanceType(pool, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.setInstanceStoreCondition(pool, instanceType, logger); err != nil {
+		return nil, err
+	}
+
+	var zones, subnetSelections []string
+	var subnets map[string]string
+	if len(pool.Spec.Platform.AWS.FailureDomains) > 0 {
+		zones, subnets, err = a.failureDomainZones(pool)
+	} else {
+		zones, subnets, subnetSelections, err = a.zonesAndSubnets(cd, pool, logger)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.NoUsableZonesMachinePoolCondition,
+		corev1.ConditionFalse,
+		"UsableZones",
+		fmt.Sprintf("Using availability zones: %s", strings.Join(zones, ", ")),
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	reason, message := "ValidSubnets", "Subnets are valid"
+	if len(subnetSelections) > 0 {
+		reason = "SubnetsSelected"
+		message = fmt.Sprintf("selected one of multiple subnets for availability zones: %s", strings.Join(subnetSelections, "; "))
 	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.InvalidSubnetsMachinePoolCondition,
+		corev1.ConditionFalse,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	if err := a.validateZoneInstanceTypes(pool, zones, logger); err != nil {
+		return nil, err
+	}
+	if err := a.validateResourceNames(pool, cd.Spec.ClusterMetadata.InfraID, zones, logger); err != nil {
+		return nil, err
+	}
+	if err := setZoneSpreadCondition(pool, zones, logger); err != nil {
+		return nil, err
+	}
+	a.setSpotMaxPriceCondition(pool, instanceType, zones, logger)
+	setSpotFallbackCondition(pool, logger)
+	a.setQuotaCondition(pool, instanceType, zones, logger)
+	a.setSubnetIPsCondition(pool, cd.Spec.ClusterMetadata.InfraID, zones, subnets, logger)
+	a.setOutboundConnectivityCondition(pool, cd.Spec.ClusterMetadata.InfraID, zones, subnets, logger)
+	pool.Status.ImageIDs = make(map[string]string, len(zones))
+	for _, zone := range zones {
+		pool.Status.ImageIDs[zone] = a.amiIDForZone(zone)
+	}
+	// Pools without subnets of their own use the subnets the installer names after the zones, which are not reported.
+	pool.Status.SubnetsByAvailabilityZone = nil
+	for _, zone := range zones {
+		if subnet, ok := subnets[zone]; ok {
+			if pool.Status.SubnetsByAvailabilityZone == nil {
+				pool.Status.SubnetsByAvailabilityZone = make(map[string]string, len(zones))
+			}
+			pool.Status.SubnetsByAvailabilityZone[zone] = subnet
+		}
+	}
+
+	return &awsValidationResult{instanceType: instanceType, zones: zones, subnets: subnets}, nil
+}
 
-	if len(computePool.Platform.AWS.Zones) == 0 {
-		zones, err := a.fetchAvailabilityZones()
+// zonesAndSubnets returns the availability zones of a pool without failure domains, the subnets of those zones by zone
+// and a description of each subnet selected from several in a zone. The zones are those listed in the pool, or else
+// those of the region, narrowed to the zones with a subnet when the pool lists its subnets. Sets the NoUsableZones and
+// InvalidSubnets conditions when the zones or subnets of the pool cannot be used.
+func (a *AWSActuator) zonesAndSubnets(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, logger log.FieldLogger) ([]string, map[string]string, []string, error) {
+	edge := pool.Spec.Platform.AWS.Edge
+	zones, err := a.listedZones(pool)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	zonesListed := len(zones) > 0
+	zonesFromRegion := !zonesListed && !edge
+	if zonesFromRegion {
+		zones, err = a.fetchAvailabilityZones()
 		if err != nil {
-			return nil, false, errors.Wrap(err, "compute pool not providing list of zones and failed to fetch list of zones")
+			return nil, nil, nil, errors.Wrap(err, "compute pool not providing list of zones and failed to fetch list of zones")
 		}
 		if len(zones) == 0 {
-			return nil, false, fmt.Errorf("zero zones returned for region %s", cd.Spec.Platform.AWS.Region)
+			message := fmt.Sprintf("zero zones returned for region %s", cd.Spec.Platform.AWS.Region)
+			pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+				pool.Status.Conditions,
+				hivev1.NoUsableZonesMachinePoolCondition,
+				corev1.ConditionTrue,
+				"NoZonesInRegion",
+				message,
+				controllerutils.UpdateConditionIfReasonOrMessageChange,
+			)
+			return nil, nil, nil, &ValidationError{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Reason:  "NoZonesInRegion",
+				Message: message,
+			}
 		}
-		computePool.Platform.AWS.Zones = zones
 	}
 
 	subnets := map[string]string{}
+	var subnetSelections []string
 	// Fetching private subnets from the machinepool and then mapping availability zones to subnets
 	if len(pool.Spec.Platform.AWS.Subnets) > 0 {
-		subnetsByAvailabilityZone, err := a.getPrivateSubnetsByAvailabilityZone(pool)
+		subnetsByAvailabilityZone, selections, err := a.getPrivateSubnetsByAvailabilityZone(pool)
 		if err != nil {
-			return nil, false, errors.Wrap(err, "describing subnets")
+			return nil, nil, nil, errors.Wrap(err, "describing subnets")
+		}
+		if edge {
+			zones, err = a.edgeZones(pool, zones, subnetsByAvailabilityZone)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		// When the zones are not listed in the MachinePool, use the zones of the region that have a subnet.
+		if zonesFromRegion {
+			regionZones := zones
+			zones = nil
+			for _, zone := range regionZones {
+				if _, ok := subnetsByAvailabilityZone[zone]; ok {
+					zones = append(zones, zone)
+				}
+			}
+			if len(zones) == 0 {
+				subnetZones := make([]string, 0, len(subnetsByAvailabilityZone))
+				for zone := range subnetsByAvailabilityZone {
+					subnetZones = append(subnetZones, zone)
+				}
+				sort.Strings(subnetZones)
+				message := fmt.Sprintf("none of the availability zones of region %s have a private subnet: region availability zones: %s; subnet availability zones: %s",
+					cd.Spec.Platform.AWS.Region, strings.Join(regionZones, ", "), strings.Join(subnetZones, ", "))
+				pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+					pool.Status.Conditions,
+					hivev1.NoUsableZonesMachinePoolCondition,
+					corev1.ConditionTrue,
+					"NoZonesWithSubnets",
+					message,
+					controllerutils.UpdateConditionIfReasonOrMessageChange,
+				)
+				return nil, nil, nil, &ValidationError{
+					Type:    hivev1.NoUsableZonesMachinePoolCondition,
+					Reason:  "NoZonesWithSubnets",
+					Message: message,
+				}
+			}
+		}
+		if err := checkSubnetsForZones(pool, zones, subnetsByAvailabilityZone); err != nil {
+			return nil, nil, nil, err
 		}
 		subnets = subnetsByAvailabilityZone
+		subnetSelections = selections
+	} else if edge {
+		message := "edge pools must specify the subnets of the edge zones in which to place the machines"
+		pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+			pool.Status.Conditions,
+			hivev1.NoUsableZonesMachinePoolCondition,
+			corev1.ConditionTrue,
+			"NoEdgeSubnets",
+			message,
+			controllerutils.UpdateConditionIfReasonOrMessageChange,
+		)
+		return nil, nil, nil, &ValidationError{
+			Type:    hivev1.NoUsableZonesMachinePoolCondition,
+			Reason:  "NoEdgeSubnets",
+			Message: message,
+		}
+	}
+	// A single zone pool deliberately uses only one of its zones, so that all its machines share an availability zone:
+	// the first zone listed in the pool, or otherwise the first by name of the zones found in AWS.
+	if pool.Spec.Platform.AWS.SingleZone && len(zones) > 1 {
+		if zonesListed {
+			zones = zones[:1]
+		} else {
+			zones = []string{sets.NewString(zones...).List()[0]}
+		}
+		logger.WithField("zone", zones[0]).Debug("using a single availability zone")
 	}
-	// userTags are settings available in the installconfig that we are choosing
-	// to ignore for the timebeing. These empty settings should be updated to feed
-	// from the machinepool / installconfig in the future.
-	userTags := map[string]string{}
+	return zones, subnets, subnetSelections, nil
+}
 
-	installerMachineSets, err := installaws.MachineSets(
-		cd.Spec.ClusterMetadata.InfraID,
-		cd.Spec.Platform.AWS.Region,
-		subnets,
-		computePool,
-		pool.Spec.Name,
-		workerUserDataName,
-		userTags,
+// failureDomainZones returns the availability zones of the failure domains of a pool and their subnets by zone, after
+// checking that the subnet of each failure domain exists in its zone. Sets the InvalidSubnets condition when they do
+// not.
+func (a *AWSActuator) failureDomainZones(pool *hivev1.MachinePool) ([]string, map[string]string, error) {
+	domains := make([]cloudSubnet, len(pool.Spec.Platform.AWS.FailureDomains))
+	for i, domain := range pool.Spec.Platform.AWS.FailureDomains {
+		domains[i] = cloudSubnet{id: domain.Subnet, zone: domain.Zone}
+	}
+	zones, subnets, err := mapFailureDomainSubnets(newAWSSubnetLister(a.awsClient), pool, domains)
+	if err != nil && !isConfigurationError(err) {
+		return nil, nil, errors.Wrap(err, "describing subnets")
+	}
+	return zones, subnets, err
+}
+
+// zoneInstanceTypes returns the instance types the pool uses instead of its own in some availability zones, by zone:
+// those of its failure domains setting an instance type, or else those of its InstanceTypesByZone.
+func zoneInstanceTypes(pool *hivev1.MachinePool) map[string]string {
+	domains := pool.Spec.Platform.AWS.FailureDomains
+	if len(domains) == 0 {
+		return pool.Spec.Platform.AWS.InstanceTypesByZone
+	}
+	instanceTypes := map[string]string{}
+	for _, domain := range domains {
+		if domain.InstanceType != "" {
+			instanceTypes[domain.Zone] = domain.InstanceType
+		}
+	}
+	return instanceTypes
+}
+
+// validateZoneInstanceTypes checks that the instance types the MachinePool overrides for some of the given availability
+// zones are offered in those zones, and sets the InstanceTypeNotOfferedInZones condition accordingly. Overrides for
+// zones the pool does not use are ignored. Returns a *ValidationError when an instance type is not offered.
+func (a *AWSActuator) validateZoneInstanceTypes(pool *hivev1.MachinePool, zones []string, logger log.FieldLogger) error {
+	zoneTypes := zoneInstanceTypes(pool)
+	overrides := map[string]string{}
+	for _, zone := range zones {
+		if instanceType, ok := zoneTypes[zone]; ok {
+			overrides[zone] = instanceType
+		}
+	}
+	if len(overrides) < len(zoneTypes) {
+		logger.WithField("zones", zones).Debug("ignoring instance types of availability zones not used by the pool")
+	}
+	if len(overrides) == 0 {
+		pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+			pool.Status.Conditions,
+			hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition,
+			corev1.ConditionFalse,
+			"NoZoneInstanceTypes",
+			"No instance types are set for specific availability zones",
+			controllerutils.UpdateConditionIfReasonOrMessageChange,
+		)
+		return nil
+	}
+
+	overrideZones := sets.StringKeySet(overrides)
+	instanceTypes := sets.NewString()
+	for _, instanceType := range overrides {
+		instanceTypes.Insert(instanceType)
+	}
+	resp, err := a.awsClient.DescribeInstanceTypeOfferings(&ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: aws.String(ec2.LocationTypeAvailabilityZone),
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("location"),
+				Values: aws.StringSlice(overrideZones.List()),
+			},
+			{
+				Name:   aws.String("instance-type"),
+				Values: aws.StringSlice(instanceTypes.List()),
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "describing instance type offerings")
+	}
+	offered := sets.NewString()
+	for _, offering := range resp.InstanceTypeOfferings {
+		offered.Insert(aws.StringValue(offering.Location) + "=" + aws.StringValue(offering.InstanceType))
+	}
+	var notOffered []string
+	for _, zone := range overrideZones.List() {
+		if pair := zone + "=" + overrides[zone]; !offered.Has(pair) {
+			notOffered = append(notOffered, pair)
+		}
+	}
+	if len(notOffered) > 0 {
+		message := fmt.Sprintf("instance types not offered in availability zones: %s", strings.Join(notOffered, ", "))
+		logger.WithField("notOffered", notOffered).Warn("instance types are not offered in availability zones")
+		pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+			pool.Status.Conditions,
+			hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition,
+			corev1.ConditionTrue,
+			"InstanceTypeNotOffered",
+			message,
+			controllerutils.UpdateConditionIfReasonOrMessageChange,
+		)
+		return &ValidationError{
+			Type:    hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition,
+			Reason:  "InstanceTypeNotOffered",
+			Message: message,
+		}
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition,
+		corev1.ConditionFalse,
+		"InstanceTypesOffered",
+		fmt.Sprintf("The instance types of availability zones %s are offered", strings.Join(overrideZones.List(), ", ")),
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	return nil
+}
+
+// setSpotMaxPriceCondition sets the SpotMaxPriceTooLow condition according to whether the max price of the spot
+// instances of the MachinePool is below the current spot price of the instance type in any of the zones. The check is
+// advisory: the condition is left unchanged when the spot prices cannot be determined.
+func (a *AWSActuator) setSpotMaxPriceCondition(pool *hivev1.MachinePool, instanceType string, zones []string, logger log.FieldLogger) {
+	status, reason, message := corev1.ConditionFalse, "NoSpotMaxPrice", "No maximum spot price is set"
+	if spot := poolSpotMarketOptions(pool); spot != nil && spot.MaxPrice != nil {
+		maxPrice, err := parseSpotMaxPrice(*spot.MaxPrice)
+		if err != nil {
+			// Reported by the InvalidConfiguration condition.
+			return
+		}
+		prices, err := a.currentSpotPrices(instanceType, zones)
+		if err != nil {
+			logger.WithError(err).Warn("could not get the current spot prices")
+			return
+		}
+		var tooLow []string
+		for _, zone := range zones {
+			if price, ok := prices[zone]; ok && price > maxPrice {
+				tooLow = append(tooLow, fmt.Sprintf("%s (%g)", zone, price))
+			}
+		}
+		status, reason, message = corev1.ConditionFalse, "SpotMaxPriceSufficient",
+			fmt.Sprintf("The spot max price %s is not below the current spot price of instance type %s", *spot.MaxPrice, instanceType)
+		if len(tooLow) > 0 {
+			logger.WithField("zones", tooLow).Info("spot max price is below the current spot price")
+			status, reason = corev1.ConditionTrue, "SpotMaxPriceBelowSpotPrice"
+			message = fmt.Sprintf("The spot max price %s is below the current spot price of instance type %s in availability zones: %s",
+				*spot.MaxPrice, instanceType, strings.Join(tooLow, ", "))
+		}
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.SpotMaxPriceTooLowMachinePoolCondition,
+		status,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
 	)
+}
+
+// setSpotFallbackCondition sets the SpotFallbackUnavailable condition according to whether the MachinePool requests
+// falling back from spot to on-demand instances. Each generated MachineSet has a single instance type and purchasing
+// option, and the machine API has no mixed-instances policy to keep a base of on-demand capacity with spot above it, so
+// the MachineSets are generated with spot instances only and the condition records that the fallback is unavailable.
+func setSpotFallbackCondition(pool *hivev1.MachinePool, logger log.FieldLogger) {
+	status, reason, message := corev1.ConditionFalse, "SpotFallbackNotRequested", "Falling back to on-demand instances is not requested"
+	if pool.Spec.Platform.AWS.SpotFallbackToOnDemand {
+		if poolSpotMarketOptions(pool) == nil {
+			reason, message = "NotSpotInstances", "The machines are on-demand instances, so there is nothing to fall back from"
+		} else {
+			logger.Info("falling back from spot to on-demand instances is not supported by the machine API")
+			status, reason = corev1.ConditionTrue, "MixedInstancesPolicyUnsupported"
+			message = "The machine API cannot fall back to on-demand instances: each MachineSet launches a single instance type " +
+				"as spot instances, without a mixed-instances policy, so no machines are launched while spot capacity is unavailable"
+		}
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.SpotFallbackUnavailableMachinePoolCondition,
+		status,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+}
+
+// currentSpotPrices returns the current Linux spot price of the instance type by availability zone.
+func (a *AWSActuator) currentSpotPrices(instanceType string, zones []string) (map[string]float64, error) {
+	resp, err := a.awsClient.DescribeSpotPriceHistory(&ec2.DescribeSpotPriceHistoryInput{
+		InstanceTypes:       []*string{aws.String(instanceType)},
+		ProductDescriptions: []*string{aws.String(ec2.RIProductDescriptionLinuxUnix)},
+		// A start time of now returns only the current price in each zone.
+		StartTime: aws.Time(time.Now()),
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("availability-zone"),
+			Values: aws.StringSlice(zones),
+		}},
+	})
 	if err != nil {
-		if strings.Contains(err.Error(), "no subnet for zone") {
-			conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
-				pool.Status.Conditions,
-				hivev1.InvalidSubnetsMachinePoolCondition,
-				corev1.ConditionTrue,
-				"NoSubnetForAvailabilityZone",
-				err.Error(),
-				controllerutils.UpdateConditionIfReasonOrMessageChange,
-			)
-			if statusChanged || changed {
-				pool.Status.Conditions = conds
-				if err := a.client.Status().Update(context.Background(), pool); err != nil {
-					return nil, false, err
-				}
+		return nil, err
+	}
+	prices := map[string]float64{}
+	latest := map[string]time.Time{}
+	for _, spotPrice := range resp.SpotPriceHistory {
+		zone := aws.StringValue(spotPrice.AvailabilityZone)
+		timestamp := aws.TimeValue(spotPrice.Timestamp)
+		if t, ok := latest[zone]; ok && t.After(timestamp) {
+			continue
+		}
+		price, err := strconv.ParseFloat(aws.StringValue(spotPrice.SpotPrice), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse spot price for availability zone %s", zone)
+		}
+		prices[zone] = price
+		latest[zone] = timestamp
+	}
+	return prices, nil
+}
+
+// resolveInstanceType returns the instance type of the MachinePool, resolving its InstanceTypeSelector when no
+// InstanceType is set, and sets the InstanceTypeNotResolved condition according to whether the instance type is set
+// and exists in the region.
+func (a *AWSActuator) resolveInstanceType(pool *hivev1.MachinePool, logger log.FieldLogger) (string, error) {
+	instanceType := pool.Spec.Platform.AWS.InstanceType
+	var reason, message string
+	switch selector := pool.Spec.Platform.AWS.InstanceTypeSelector; {
+	case instanceType != "":
+		exists, err := a.instanceTypeExists(instanceType)
+		if err != nil {
+			return "", errors.Wrap(err, "describing instance types")
+		}
+		if !exists {
+			reason = "InstanceTypeNotFound"
+			message = fmt.Sprintf("instance type %s does not exist in region %s", instanceType, a.region)
+		}
+	case selector == nil:
+		reason, message = "NoInstanceType", "neither an instance type nor an instance type selector is set"
+	default:
+		instanceType = fmt.Sprintf("%s.%s", selector.Family, selector.Size)
+		exists, err := a.instanceTypeExists(instanceType)
+		if err != nil {
+			return "", errors.Wrap(err, "describing instance types")
+		}
+		if !exists {
+			reason = "InstanceTypeNotFound"
+			message = fmt.Sprintf("instance type %s for family %s and size %s does not exist in region %s",
+				instanceType, selector.Family, selector.Size, a.region)
+		} else {
+			logger.WithField("instanceType", instanceType).Debug("resolved instance type from instance type selector")
+		}
+	}
+	if reason != "" {
+		pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+			pool.Status.Conditions,
+			hivev1.InstanceTypeNotResolvedMachinePoolCondition,
+			corev1.ConditionTrue,
+			reason,
+			message,
+			controllerutils.UpdateConditionIfReasonOrMessageChange,
+		)
+		return "", &ValidationError{
+			Type:    hivev1.InstanceTypeNotResolvedMachinePoolCondition,
+			Reason:  reason,
+			Message: message,
+		}
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.InstanceTypeNotResolvedMachinePoolCondition,
+		corev1.ConditionFalse,
+		"InstanceTypeResolved",
+		fmt.Sprintf("Using instance type %s", instanceType),
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	return instanceType, nil
+}
+
+// instanceTypeExists returns true if the instance type is offered in the region.
+func (a *AWSActuator) instanceTypeExists(instanceType string) (bool, error) {
+	info, err := a.describeInstanceType(instanceType)
+	return info != nil, err
+}
+
+// describeInstanceType returns the description of the instance type, or nil if it is not offered in the region. Each
+// instance type is only described once by the actuator.
+func (a *AWSActuator) describeInstanceType(instanceType string) (*ec2.InstanceTypeInfo, error) {
+	if info, ok := a.instanceTypes[instanceType]; ok {
+		return info, nil
+	}
+	resp, err := a.awsClient.DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{aws.String(instanceType)},
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != "InvalidInstanceType" {
+			return nil, err
+		}
+		resp = &ec2.DescribeInstanceTypesOutput{}
+	}
+	var info *ec2.InstanceTypeInfo
+	if len(resp.InstanceTypes) > 0 {
+		info = resp.InstanceTypes[0]
+	}
+	if a.instanceTypes == nil {
+		a.instanceTypes = map[string]*ec2.InstanceTypeInfo{}
+	}
+	a.instanceTypes[instanceType] = info
+	return info, nil
+}
+
+// setInstanceStoreCondition sets the InstanceStoreVolumesNotManaged condition according to whether the instance type
+// has instance store volumes, and how they are attached to the machines. Hive does not initialize these volumes, so
+// they are only usable when formatted and mounted by other means.
+func (a *AWSActuator) setInstanceStoreCondition(pool *hivev1.MachinePool, instanceType string, logger log.FieldLogger) error {
+	info, err := a.describeInstanceType(instanceType)
+	if err != nil {
+		return errors.Wrap(err, "describing instance types")
+	}
+	status, reason, message := corev1.ConditionFalse, "NoInstanceStoreVolumes", fmt.Sprintf("Instance type %s has no instance store volumes", instanceType)
+	if info != nil && aws.BoolValue(info.InstanceStorageSupported) && info.InstanceStorageInfo != nil {
+		var disks []string
+		for _, disk := range info.InstanceStorageInfo.Disks {
+			disks = append(disks, fmt.Sprintf("%d x %d GB %s", aws.Int64Value(disk.Count), aws.Int64Value(disk.SizeInGB), aws.StringValue(disk.Type)))
+		}
+		attached := "attached as NVMe devices"
+		if aws.StringValue(info.InstanceStorageInfo.NvmeSupport) != ec2.EphemeralNvmeSupportRequired {
+			var deviceNames []string
+			for _, device := range ephemeralBlockDevices(info, pool) {
+				deviceNames = append(deviceNames, aws.StringValue(device.DeviceName))
 			}
+			attached = fmt.Sprintf("mapped to %s", strings.Join(deviceNames, ", "))
 		}
+		logger.WithField("instanceType", instanceType).Debug("instance type has instance store volumes")
+		status, reason = corev1.ConditionTrue, "InstanceStoreVolumesNotManaged"
+		message = fmt.Sprintf("Instance type %s has %d GB of instance store volumes (%s), %s, which Hive does not initialize",
+			instanceType, aws.Int64Value(info.InstanceStorageInfo.TotalSizeInGB), strings.Join(disks, ", "), attached)
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.InstanceStoreVolumesNotManagedMachinePoolCondition,
+		status,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	return nil
+}
 
-		return nil, false, errors.Wrap(err, "failed to generate machinesets")
+// checkSerialConsole returns the reason and message to report when the EC2 serial console cannot be used for the
+// machines of the MachinePool, or empty strings if it can. The serial console must be enabled for the account, and
+// is only available for instance types built on the Nitro system.
+func (a *AWSActuator) checkSerialConsole(pool *hivev1.MachinePool, logger log.FieldLogger) (string, string, error) {
+	status, err := a.awsClient.GetSerialConsoleAccessStatus(&ec2.GetSerialConsoleAccessStatusInput{})
+	if err != nil {
+		return "", "", errors.Wrap(err, "getting serial console access status")
+	}
+	if !aws.BoolValue(status.SerialConsoleAccessEnabled) {
+		logger.Debug("serial console access is not enabled for the AWS account")
+		return "SerialConsoleAccessDisabled",
+			fmt.Sprintf("Boot diagnostics require EC2 serial console access to be enabled for the AWS account in region %s", a.region),
+			nil
+	}
+	instanceType := poolInstanceType(pool)
+	if instanceType == "" {
+		// Reported when resolving the instance type.
+		return "", "", nil
+	}
+	info, err := a.describeInstanceType(instanceType)
+	if err != nil {
+		return "", "", errors.Wrap(err, "describing instance types")
+	}
+	// Unknown instance types are reported when resolving the instance type.
+	if info == nil || aws.StringValue(info.Hypervisor) == ec2.InstanceTypeHypervisorNitro || aws.BoolValue(info.BareMetal) {
+		return "", "", nil
+	}
+	logger.WithField("instanceType", instanceType).Debug("instance type does not support the serial console")
+	return "SerialConsoleUnsupportedInstanceType",
+		fmt.Sprintf("Boot diagnostics require an instance type built on the Nitro system, which %s is not", instanceType),
+		nil
+}
+
+// checkRootDeviceType returns the reason and message to report when the instance type of the MachinePool, or one of
+// the instance types it sets for specific availability zones, cannot boot from an EBS root volume, or empty strings if
+// they all can. The AMIs of the cluster are EBS-backed and the root volume settings of the pool are those of an EBS
+// volume, so instance types only supporting instance store root devices cannot be used.
+func (a *AWSActuator) checkRootDeviceType(pool *hivev1.MachinePool, logger log.FieldLogger) (string, string, error) {
+	instanceTypes := sets.NewString()
+	if instanceType := poolInstanceType(pool); instanceType != "" {
+		instanceTypes.Insert(instanceType)
+	}
+	for _, instanceType := range zoneInstanceTypes(pool) {
+		instanceTypes.Insert(instanceType)
+	}
+	for _, instanceType := range instanceTypes.List() {
+		info, err := a.describeInstanceType(instanceType)
+		if err != nil {
+			return "", "", errors.Wrap(err, "describing instance types")
+		}
+		// Unknown instance types are reported when resolving the instance type. The root device types are not
+		// always described, in which case the instance type is assumed to support EBS.
+		if info == nil || len(info.SupportedRootDeviceTypes) == 0 ||
+			sets.NewString(aws.StringValueSlice(info.SupportedRootDeviceTypes)...).Has(ec2.RootDeviceTypeEbs) {
+			continue
+		}
+		logger.WithField("instanceType", instanceType).Debug("instance type does not support EBS root volumes")
+		return "InstanceStoreRootDevice",
+			fmt.Sprintf("Instance type %s only supports instance store root devices, to which the EBS root volume settings of the pool do not apply", instanceType),
+			nil
+	}
+	return "", "", nil
+}
+
+// poolInstanceType returns the instance type of the MachinePool, or the one its InstanceTypeSelector selects when no
+// InstanceType is set, without checking that it exists.
+func poolInstanceType(pool *hivev1.MachinePool) string {
+	instanceType := pool.Spec.Platform.AWS.InstanceType
+	if selector := pool.Spec.Platform.AWS.InstanceTypeSelector; instanceType == "" && selector != nil {
+		instanceType = fmt.Sprintf("%s.%s", selector.Family, selector.Size)
+	}
+	return instanceType
+}
+
+// ephemeralBlockDevices returns the block device mappings of the instance store volumes of an instance type for the
+// machines of the pool, or nil when the instance type has none. Instance store volumes are only attached to the
+// instances when mapped, except for NVMe volumes, which are always attached and need no mappings. The volumes are
+// mapped to ephemeral0, ephemeral1, ... in order, on the first device names from /dev/sdb which the additional block
+// devices of the pool do not take.
+func ephemeralBlockDevices(info *ec2.InstanceTypeInfo, pool *hivev1.MachinePool) []awsproviderv1beta1.BlockDeviceMappingSpec {
+	if info == nil || !aws.BoolValue(info.InstanceStorageSupported) || info.InstanceStorageInfo == nil ||
+		aws.StringValue(info.InstanceStorageInfo.NvmeSupport) == ec2.EphemeralNvmeSupportRequired {
+		return nil
+	}
+	var count int64
+	for _, disk := range info.InstanceStorageInfo.Disks {
+		count += aws.Int64Value(disk.Count)
+	}
+	// /dev/sdX and /dev/xvdX name the same device.
+	taken := sets.NewString()
+	for _, device := range pool.Spec.Platform.AWS.AdditionalBlockDevices {
+		taken.Insert(strings.TrimPrefix(strings.TrimPrefix(device.DeviceName, "/dev/xvd"), "/dev/sd"))
+	}
+	var devices []awsproviderv1beta1.BlockDeviceMappingSpec
+	letter := 'b'
+	for i := int64(0); i < count; i++ {
+		for letter <= 'z' && taken.Has(string(letter)) {
+			letter++
+		}
+		if letter > 'z' {
+			break
+		}
+		devices = append(devices, awsproviderv1beta1.BlockDeviceMappingSpec{
+			DeviceName:  aws.String(fmt.Sprintf("/dev/sd%c", letter)),
+			VirtualName: aws.String(fmt.Sprintf("ephemeral%d", i)),
+		})
+		letter++
+	}
+	return devices
+}
+
+// rootVolumeEncrypted returns whether the root volumes of the machines in the pool are encrypted.
+func rootVolumeEncrypted(pool *hivev1.MachinePool) bool {
+	return volumeEncrypted(pool.Spec.Platform.AWS.EC2RootVolume.Encrypted)
+}
+
+// volumeEncrypted returns whether a volume with the given encrypted setting is encrypted, which it is by default.
+func volumeEncrypted(encrypted *bool) bool {
+	if encrypted != nil {
+		return *encrypted
+	}
+	return true
+}
+
+// amiIDForZone returns the AMI ID to use for the MachineSet in the given availability zone.
+func (a *AWSActuator) amiIDForZone(zone string) string {
+	if amiID, ok := a.zoneAMIIDs[zone]; ok {
+		return amiID
+	}
+	return a.amiID
+}
+
+// getAWSAMISources returns the sources of the AMIs of AWS MachinePools without a boot image, in the order they are
+// tried, as configured by the MACHINEPOOL_AWS_AMI_SOURCES environment variable.
+func getAWSAMISources() ([]hivev1.BootImageSource, error) {
+	value := os.Getenv(constants.MachinePoolAWSAMISourcesEnvVar)
+	if value == "" {
+		return defaultAWSAMISources, nil
 	}
+	var sources []hivev1.BootImageSource
+	seen := sets.NewString()
+	for _, source := range strings.Split(value, ",") {
+		switch s := hivev1.BootImageSource(strings.TrimSpace(source)); s {
+		case hivev1.ImageIDOverrideBootImageSource, hivev1.RHCOSStreamBootImageSource, hivev1.ClusterBootImageSource:
+			if seen.Has(string(s)) {
+				return nil, errors.Errorf("invalid %s: duplicate source %s", constants.MachinePoolAWSAMISourcesEnvVar, s)
+			}
+			seen.Insert(string(s))
+			sources = append(sources, s)
+		default:
+			return nil, errors.Errorf("invalid %s: unknown source %q", constants.MachinePoolAWSAMISourcesEnvVar, source)
+		}
+	}
+	return sources, nil
+}
 
+// resolveAMIID returns the AMI ID of the MachineSets of the pool and its source: the boot image of the pool when it
+// has one, or else the AMI ID provided by the first of the given sources to provide one. Errors of the cluster source
+// are returned only when no later source provides an AMI ID.
+func resolveAMIID(
+	c client.Client,
+	pool *hivev1.MachinePool,
+	masterMachine *machineapi.Machine,
+	remoteClusterAPIClient client.Client,
+	region string,
+	sources []hivev1.BootImageSource,
+	scheme *runtime.Scheme,
+	logger log.FieldLogger,
+) (string, hivev1.BootImageSource, error) {
+	if amiID := pool.Spec.BootImage; amiID != "" {
+		logger.WithField("ami", amiID).Info("using AMI from the boot image of the pool")
+		return amiID, hivev1.SpecBootImageSource, nil
+	}
+	if len(sources) == 0 {
+		sources = defaultAWSAMISources
+	}
+	var clusterErr error
+	for _, source := range sources {
+		var amiID string
+		switch source {
+		case hivev1.ImageIDOverrideBootImageSource:
+			if amiID = pool.Annotations[hivev1.MachinePoolImageIDOverrideAnnotation]; amiID != "" {
+				logger.Infof("using AMI override from %s annotation: %s", hivev1.MachinePoolImageIDOverrideAnnotation, amiID)
+			}
+		case hivev1.RHCOSStreamBootImageSource:
+			var err error
+			if amiID, err = resolveStreamAMIID(c, pool, region, logger); err != nil {
+				return "", "", err
+			}
+		case hivev1.ClusterBootImageSource:
+			amiID, clusterErr = getAWSAMIID(masterMachine, remoteClusterAPIClient, region, scheme, logger)
+			if clusterErr != nil {
+				logger.WithError(clusterErr).Warn("failed to get AMI ID")
+			}
+		}
+		if amiID != "" {
+			return amiID, source, nil
+		}
+	}
+	if clusterErr != nil {
+		return "", "", clusterErr
+	}
+	return "", "", errors.Errorf("no AMI ID provided by the AMI sources %v", sources)
+}
+
+// resolveStreamAMIID resolves the AMI ID for the region from the RHCOS stream metadata in the ConfigMap named by the
+// rhcos-stream-configmap annotation of the pool, and sets the AMIResolutionFailed condition on the pool accordingly.
+// Returns an empty AMI ID when the pool does not reference stream metadata or the AMI ID cannot be resolved from it,
+// so that the next AMI source is tried instead.
+func resolveStreamAMIID(c client.Client, pool *hivev1.MachinePool, region string, logger log.FieldLogger) (string, error) {
+	status, reason, message := corev1.ConditionFalse, "StreamNotReferenced", "The pool does not reference RHCOS stream metadata"
+	updateCheck := controllerutils.UpdateConditionNever
+	var amiID string
+	if name := pool.Annotations[hivev1.MachinePoolRHCOSStreamConfigMapAnnotation]; name != "" {
+		var err error
+		amiID, err = getStreamAMIID(c, pool.Namespace, name, region)
+		if err != nil {
+			logger.WithError(err).Warn("could not resolve AMI ID from RHCOS stream metadata, trying the next AMI source")
+			status, reason, message = corev1.ConditionTrue, "StreamLookupFailed", err.Error()
+			updateCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+		} else {
+			logger.WithField("ami", amiID).Infof("using AMI from RHCOS stream metadata in ConfigMap %s", name)
+			status, reason, message = corev1.ConditionFalse, "AMIResolved",
+				fmt.Sprintf("Resolved AMI %s from the RHCOS stream metadata in ConfigMap %s", amiID, name)
+			updateCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+		}
+	}
 	conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
 		pool.Status.Conditions,
-		hivev1.InvalidSubnetsMachinePoolCondition,
-		corev1.ConditionFalse,
-		"ValidSubnets",
-		"Subnets are valid",
-		controllerutils.UpdateConditionNever,
+		hivev1.AMIResolutionFailedMachinePoolCondition,
+		status,
+		reason,
+		message,
+		updateCheck,
 	)
-	if statusChanged || changed {
+	if changed {
 		pool.Status.Conditions = conds
-		if err := a.client.Status().Update(context.Background(), pool); err != nil {
-			return nil, false, err
+		if err := c.Status().Update(context.Background(), pool); err != nil {
+			return "", errors.Wrap(err, "could not update MachinePool status")
 		}
 	}
+	return amiID, nil
+}
 
-	// Re-use existing AWS resources for generated MachineSets.
-	for _, ms := range installerMachineSets {
-		a.updateProviderConfig(ms, cd.Spec.ClusterMetadata.InfraID, pool)
+// getStreamAMIID returns the AMI ID for the region from the RHCOS stream metadata stored under the stream key of the
+// named ConfigMap, in the format of the coreos-bootimages ConfigMap embedded in release images.
+func getStreamAMIID(c client.Client, namespace, name, region string) (string, error) {
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, cm); err != nil {
+		return "", errors.Wrapf(err, "could not get RHCOS stream ConfigMap %s", name)
 	}
+	data, ok := cm.Data[rhcosStreamConfigMapKey]
+	if !ok {
+		return "", errors.Errorf("RHCOS stream ConfigMap %s has no %s key", name, rhcosStreamConfigMapKey)
+	}
+	st := &stream.Stream{}
+	if err := json.Unmarshal([]byte(data), st); err != nil {
+		return "", errors.Wrapf(err, "could not parse RHCOS stream metadata in ConfigMap %s", name)
+	}
+	amiID, err := st.GetAMI(rhcosStreamArchitecture, region)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not find AMI in RHCOS stream metadata in ConfigMap %s", name)
+	}
+	return amiID, nil
+}
 
-	return installerMachineSets, true, nil
+// masterMachineRegion returns the region of the master machine of the cluster from its provider spec, or an empty string
+// when it is not known.
+func masterMachineRegion(masterMachine *machineapi.Machine, scheme *runtime.Scheme, logger log.FieldLogger) string {
+	if masterMachine == nil {
+		return ""
+	}
+	providerSpec, err := decodeAWSMachineProviderSpec(masterMachine.Spec.ProviderSpec.Value, scheme)
+	if err != nil {
+		logger.WithError(err).Debug("cannot decode AWSMachineProviderConfig from master machine to get its region")
+		return ""
+	}
+	return providerSpec.Placement.Region
 }
 
-// Get the AMI ID from an existing master machine.
-func getAWSAMIID(masterMachine *machineapi.Machine, scheme *runtime.Scheme, logger log.FieldLogger) (string, error) {
+// Get the AMI ID from an existing master machine. When the master machine does not have an AMI ID, the AMI ID for the
+// region is resolved from the RHCOS stream metadata of the release of the cluster, in the coreos-bootimages ConfigMap of
+// the remote cluster.
+func getAWSAMIID(masterMachine *machineapi.Machine, remoteClusterAPIClient client.Client, region string, scheme *runtime.Scheme, logger log.FieldLogger) (string, error) {
 	providerSpec, err := decodeAWSMachineProviderSpec(masterMachine.Spec.ProviderSpec.Value, scheme)
 	if err != nil {
 		logger.WithError(err).Warn("cannot decode AWSMachineProviderConfig from master machine")
 		return "", errors.Wrap(err, "cannot decode AWSMachineProviderConfig from master machine")
 	}
 	if providerSpec.AMI.ID == nil {
-		logger.Warn("master machine does not have AMI ID set")
-		return "", errors.New("master machine does not have AMI ID set")
+		logger.Info("master machine does not have AMI ID set, resolving AMI ID from the boot images of the cluster")
+		amiID, err := getStreamAMIID(remoteClusterAPIClient, bootImagesConfigMapNamespace, bootImagesConfigMapName, region)
+		if err != nil {
+			logger.WithError(err).Warn("master machine does not have AMI ID set and the boot images of the cluster have no AMI ID")
+			return "", errors.Wrap(err, "master machine does not have AMI ID set")
+		}
+		logger.WithField("ami", amiID).Debug("resolved AMI to use for new machinesets from the boot images of the cluster")
+		return amiID, nil
 	}
 	amiID := *providerSpec.AMI.ID
 	logger.WithField("ami", amiID).Debug("resolved AMI to use for new machinesets")
 	return amiID, nil
 }
 
-// fetchAvailabilityZones fetches availability zones for the AWS region
+// listedZones returns the names of the availability zones listed in the MachinePool, either by name or by ID. Zone IDs
+// are resolved to the names of the zones in the account of the cluster, in the order they are listed. Sets the
+// NoUsableZones condition when a zone ID is not found in the region for the account.
+func (a *AWSActuator) listedZones(pool *hivev1.MachinePool) ([]string, error) {
+	zoneIDs := pool.Spec.Platform.AWS.ZoneIDs
+	if len(zoneIDs) == 0 {
+		return pool.Spec.Platform.AWS.Zones, nil
+	}
+	resp, err := a.awsClient.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
+		// Include edge zones whatever their opt-in status, as the opt-in status of zones listed by name is not checked
+		// either.
+		AllAvailabilityZones: aws.Bool(true),
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("zone-id"),
+				Values: aws.StringSlice(zoneIDs),
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describing availability zones by ID")
+	}
+	zoneNames := make(map[string]string, len(resp.AvailabilityZones))
+	for _, zone := range resp.AvailabilityZones {
+		zoneNames[aws.StringValue(zone.ZoneId)] = aws.StringValue(zone.ZoneName)
+	}
+	var zones, unresolved []string
+	for _, zoneID := range zoneIDs {
+		if name, ok := zoneNames[zoneID]; ok {
+			zones = append(zones, name)
+		} else {
+			unresolved = append(unresolved, zoneID)
+		}
+	}
+	if len(unresolved) == 0 {
+		return zones, nil
+	}
+	message := fmt.Sprintf("availability zone IDs not found in region %s for the account of the cluster: %s", a.region, strings.Join(unresolved, ", "))
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.NoUsableZonesMachinePoolCondition,
+		corev1.ConditionTrue,
+		"UnresolvedZoneIDs",
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	return nil, &ValidationError{
+		Type:    hivev1.NoUsableZonesMachinePoolCondition,
+		Reason:  "UnresolvedZoneIDs",
+		Message: message,
+	}
+}
+
+// fetchAvailabilityZones fetches the availability zones for the AWS region that are in one of the zone states of the
+// actuator and that are either enabled by default or opted in to.
 func (a *AWSActuator) fetchAvailabilityZones() ([]string, error) {
-	zoneFilter := &ec2.Filter{
-		Name:   aws.String("region-name"),
-		Values: []*string{aws.String(a.region)},
+	zoneStates := a.zoneStates
+	if len(zoneStates) == 0 {
+		zoneStates = defaultZoneStates
 	}
 	req := &ec2.DescribeAvailabilityZonesInput{
-		Filters: []*ec2.Filter{zoneFilter},
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("region-name"),
+				Values: []*string{aws.String(a.region)},
+			},
+			{
+				Name:   aws.String("state"),
+				Values: aws.StringSlice(zoneStates),
+			},
+			{
+				Name: aws.String("opt-in-status"),
+				Values: aws.StringSlice([]string{
+					ec2.AvailabilityZoneOptInStatusOptInNotRequired,
+					ec2.AvailabilityZoneOptInStatusOptedIn,
+				}),
+			},
+		},
 	}
 	resp, err := a.awsClient.DescribeAvailabilityZones(req)
 	if err != nil {
@@ -254,6 +1713,10 @@ func (a *AWSActuator) fetchAvailabilityZones() ([]string, error) {
 	}
 	zones := []string{}
 	for _, zone := range resp.AvailabilityZones {
+		// Edge zones are only used when listed explicitly in the MachinePool.
+		if isEdgeZone(*zone.ZoneName) {
+			continue
+		}
 		zones = append(zones, *zone.ZoneName)
 	}
 	return zones, nil
@@ -276,37 +1739,187 @@ func decodeAWSMachineProviderSpec(rawExt *runtime.RawExtension, scheme *runtime.
 	return spec, nil
 }
 
-// updateProviderConfig modifies values in a MachineSet's AWSMachineProviderConfig.
-// Currently we modify the AWSMachineProviderConfig IAMInstanceProfile, Subnet and SecurityGroups such that
-// the values match the worker pool originally created by the installer.
-func (a *AWSActuator) updateProviderConfig(machineSet *machineapi.MachineSet, infraID string, pool *hivev1.MachinePool) {
+// machineSetsWithStaleAMI returns the names of the MachineSets whose AMI differs from that of the generated MachineSet
+// at the same index. MachineSets whose AMI cannot be determined are skipped.
+func machineSetsWithStaleAMI(generatedMachineSets, machineSets []*machineapi.MachineSet, scheme *runtime.Scheme, logger log.FieldLogger) []string {
+	var stale []string
+	for i, ms := range machineSets {
+		if i >= len(generatedMachineSets) {
+			break
+		}
+		desired, err := machineSetAMIID(generatedMachineSets[i], scheme)
+		if err != nil {
+			logger.WithError(err).WithField("machineset", ms.Name).Debug("could not get AMI of generated machineset")
+			continue
+		}
+		observed, err := machineSetAMIID(ms, scheme)
+		if err != nil {
+			logger.WithError(err).WithField("machineset", ms.Name).Debug("could not get AMI of machineset")
+			continue
+		}
+		if desired != observed {
+			logger.WithField("machineset", ms.Name).WithField("desired", desired).WithField("observed", observed).
+				Debug("machineset does not use the AMI of the machine pool")
+			stale = append(stale, ms.Name)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// machineSetAMIID returns the AMI ID of a MachineSet, whose provider spec is either a generated
+// AWSMachineProviderConfig or the raw provider spec read from the remote cluster.
+func machineSetAMIID(ms *machineapi.MachineSet, scheme *runtime.Scheme) (string, error) {
+	providerConfig, err := machineSetAWSProviderConfig(ms, scheme)
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(providerConfig.AMI.ID), nil
+}
+
+// machineSetAWSProviderConfig returns the AWSMachineProviderConfig of a MachineSet, whose provider spec is either a
+// generated AWSMachineProviderConfig or the raw provider spec read from the remote cluster.
+func machineSetAWSProviderConfig(ms *machineapi.MachineSet, scheme *runtime.Scheme) (*awsproviderv1beta1.AWSMachineProviderConfig, error) {
+	value := ms.Spec.Template.Spec.ProviderSpec.Value
+	if value != nil {
+		if providerConfig, ok := value.Object.(*awsproviderv1beta1.AWSMachineProviderConfig); ok {
+			return providerConfig, nil
+		}
+	}
+	return decodeAWSMachineProviderSpec(value, scheme)
+}
+
+// syncAWSMachineSetTags sets the tags in the provider spec of a remote MachineSet to those of the generated
+// MachineSet, leaving the rest of its provider spec unchanged. Tags do not affect the machines already running, so
+// they are applied to the existing MachineSets whatever the update strategy of the pool. Returns whether the tags of
+// the remote MachineSet were changed.
+func syncAWSMachineSetTags(generated, remote *machineapi.MachineSet, scheme *runtime.Scheme) (bool, error) {
+	desired, err := machineSetAWSProviderConfig(generated, scheme)
+	if err != nil {
+		return false, err
+	}
+	observed, err := machineSetAWSProviderConfig(remote, scheme)
+	if err != nil {
+		return false, err
+	}
+	if (len(desired.Tags) == 0 && len(observed.Tags) == 0) || reflect.DeepEqual(desired.Tags, observed.Tags) {
+		return false, nil
+	}
+	providerConfig := observed.DeepCopy()
+	// The type of the decoded provider spec may have been cleared by the decoder.
+	providerConfig.TypeMeta = desired.TypeMeta
+	providerConfig.Tags = desired.Tags
+	remote.Spec.Template.Spec.ProviderSpec = machineapi.ProviderSpec{
+		Value: &runtime.RawExtension{Object: providerConfig},
+	}
+	return true, nil
+}
+
+// syncAWSMachineSetAccess sets the IAM instance profile and security groups in the provider spec of a remote MachineSet
+// to those of the generated MachineSet, leaving the rest of its provider spec unchanged. They grant the machines their
+// permissions and network access, so a change made to them in the remote cluster is reverted rather than kept until the
+// next update of the provider spec. Returns whether the remote MachineSet was changed.
+func syncAWSMachineSetAccess(generated, remote *machineapi.MachineSet, scheme *runtime.Scheme) (bool, error) {
+	desired, err := machineSetAWSProviderConfig(generated, scheme)
+	if err != nil {
+		return false, err
+	}
+	observed, err := machineSetAWSProviderConfig(remote, scheme)
+	if err != nil {
+		return false, err
+	}
+	profileInSync := reflect.DeepEqual(desired.IAMInstanceProfile, observed.IAMInstanceProfile)
+	securityGroupsInSync := (len(desired.SecurityGroups) == 0 && len(observed.SecurityGroups) == 0) ||
+		reflect.DeepEqual(desired.SecurityGroups, observed.SecurityGroups)
+	if profileInSync && securityGroupsInSync {
+		return false, nil
+	}
+	providerConfig := observed.DeepCopy()
+	// The type of the decoded provider spec may have been cleared by the decoder.
+	providerConfig.TypeMeta = desired.TypeMeta
+	providerConfig.IAMInstanceProfile = desired.IAMInstanceProfile
+	providerConfig.SecurityGroups = desired.SecurityGroups
+	remote.Spec.Template.Spec.ProviderSpec = machineapi.ProviderSpec{
+		Value: &runtime.RawExtension{Object: providerConfig},
+	}
+	return true, nil
+}
+
+// updateProviderConfig sets the IAM instance profile, AMI, subnet, security groups, tags and block devices of a
+// MachineSet's AWSMachineProviderConfig from the MachinePool, on top of the values generated by the installer.
+func (a *AWSActuator) updateProviderConfig(machineSet *machineapi.MachineSet, infraID string, pool *hivev1.MachinePool, userTags map[string]string) {
 	providerConfig := machineSet.Spec.Template.Spec.ProviderSpec.Value.Object.(*awsproviderv1beta1.AWSMachineProviderConfig)
 
-	// TODO: assumptions about pre-existing objects by name here is quite dangerous, it's already
-	// broken on us once via renames in the installer. We need to start querying for what exists
-	// here.
-	providerConfig.IAMInstanceProfile = &awsproviderv1beta1.AWSResourceReference{ID: aws.String(fmt.Sprintf("%s-worker-profile", infraID))}
+	// The resources are referenced by the names the installer gives them unless the pool overrides the names, e.g. for
+	// adopted clusters with their own naming, in which case validateResourceNames checks that they exist.
+	names := resourceNameTemplates(pool)
+	zone := providerConfig.Placement.AvailabilityZone
+	if profileARN := pool.Spec.Platform.AWS.IAMInstanceProfileARN; profileARN != "" {
+		providerConfig.IAMInstanceProfile = &awsproviderv1beta1.AWSResourceReference{ARN: aws.String(profileARN)}
+	} else {
+		providerConfig.IAMInstanceProfile = &awsproviderv1beta1.AWSResourceReference{ID: aws.String(expandResourceName(names.IAMInstanceProfile, infraID, ""))}
+	}
+	providerConfig.AMI = awsproviderv1beta1.AWSResourceReference{ID: aws.String(a.amiIDForZone(providerConfig.Placement.AvailabilityZone))}
+	if instanceType, ok := zoneInstanceTypes(pool)[providerConfig.Placement.AvailabilityZone]; ok {
+		providerConfig.InstanceType = instanceType
+	}
 	// Update the subnet filter only if subnet id is absent
 	if providerConfig.Subnet.ID == nil {
 		providerConfig.Subnet = awsproviderv1beta1.AWSResourceReference{
 			Filters: []awsproviderv1beta1.Filter{{
 				Name:   "tag:Name",
-				Values: []string{fmt.Sprintf("%s-private-%s", infraID, providerConfig.Placement.AvailabilityZone)},
+				Values: []string{expandResourceName(names.PrivateSubnet, infraID, zone)},
 			}},
 		}
 	}
 
-	providerConfig.SecurityGroups = []awsproviderv1beta1.AWSResourceReference{{
-		Filters: []awsproviderv1beta1.Filter{{
-			Name:   "tag:Name",
-			Values: []string{fmt.Sprintf("%s-worker-sg", infraID)},
-		}},
-	}}
-	if pool.Spec.Platform.AWS.SpotMarketOptions != nil {
+	if preserveSecurityGroups(pool) {
+		a.logger.WithField("annotation", hivev1.MachinePoolPreserveSecurityGroupsAnnotation).
+			Debug("preserving installer-provided security groups")
+	} else {
+		providerConfig.SecurityGroups = []awsproviderv1beta1.AWSResourceReference{{
+			Filters: []awsproviderv1beta1.Filter{{
+				Name:   "tag:Name",
+				Values: []string{expandResourceName(names.SecurityGroup, infraID, "")},
+			}},
+		}}
+	}
+	// The subnet decides whether the machines get public IP addresses unless the pool does.
+	if publicIP := pool.Spec.Platform.AWS.PublicIP; publicIP != nil {
+		providerConfig.PublicIP = aws.Bool(*publicIP)
+	}
+	providerConfig.Tags = mergeAWSUserTags(providerConfig.Tags, userTags, infraID, a.logger)
+	if spot := poolSpotMarketOptions(pool); spot != nil {
 		providerConfig.SpotMarketOptions = &awsproviderv1beta1.SpotMarketOptions{
-			MaxPrice: pool.Spec.Platform.AWS.SpotMarketOptions.MaxPrice,
+			MaxPrice: spot.MaxPrice,
+		}
+	}
+	// The root volume is encrypted unless the pool opts out, with the default KMS key of the account when the pool
+	// does not set one.
+	if len(providerConfig.BlockDevices) > 0 && providerConfig.BlockDevices[0].EBS != nil {
+		providerConfig.BlockDevices[0].EBS.Encrypted = aws.Bool(rootVolumeEncrypted(pool))
+	}
+	// Each additional block device is encrypted the same way, independently of the root volume and of each other.
+	for i, device := range pool.Spec.Platform.AWS.AdditionalBlockDevices {
+		ebs := &awsproviderv1beta1.EBSBlockDeviceSpec{
+			DeleteOnTermination: aws.Bool(true),
+			Encrypted:           aws.Bool(volumeEncrypted(device.Encrypted)),
+			VolumeSize:          aws.Int64(int64(device.Size)),
+			VolumeType:          aws.String(device.Type),
+		}
+		if device.IOPS > 0 {
+			ebs.Iops = aws.Int64(int64(device.IOPS))
 		}
+		if i < len(a.deviceKMSKeyARNs) && a.deviceKMSKeyARNs[i] != "" {
+			ebs.KMSKey = awsproviderv1beta1.AWSResourceReference{ARN: aws.String(a.deviceKMSKeyARNs[i])}
+		}
+		providerConfig.BlockDevices = append(providerConfig.BlockDevices, awsproviderv1beta1.BlockDeviceMappingSpec{
+			DeviceName: aws.String(device.DeviceName),
+			EBS:        ebs,
+		})
 	}
+	// The instance types were described when validating the pool.
+	providerConfig.BlockDevices = append(providerConfig.BlockDevices, ephemeralBlockDevices(a.instanceTypes[providerConfig.InstanceType], pool)...)
 
 	machineSet.Spec.Template.Spec.ProviderSpec = machineapi.ProviderSpec{
 		Value: &runtime.RawExtension{Object: providerConfig},
@@ -314,126 +1927,521 @@ func (a *AWSActuator) updateProviderConfig(machineSet *machineapi.MachineSet, in
 
 }
 
-// getPrivateSubnetsByAvailabilityZones maps availability zones to private subnet
-func (a *AWSActuator) getPrivateSubnetsByAvailabilityZone(pool *hivev1.MachinePool) (map[string]string, error) {
-	idPointers := make([]*string, len(pool.Spec.Platform.AWS.Subnets))
-	for i, id := range pool.Spec.Platform.AWS.Subnets {
-		idPointers[i] = aws.String(id)
+// preserveSecurityGroups returns whether the pool has the preserve-security-groups annotation.
+func preserveSecurityGroups(pool *hivev1.MachinePool) bool {
+	preserve, err := strconv.ParseBool(pool.Annotations[hivev1.MachinePoolPreserveSecurityGroupsAnnotation])
+	return err == nil && preserve
+}
+
+// resourceNameTemplates returns the templates of the names of the IAM instance profile, private subnets and security
+// group used by the machines of the pool: those of its resource names, or the names given by the installer.
+func resourceNameTemplates(pool *hivev1.MachinePool) hivev1aws.ResourceNames {
+	names := hivev1aws.ResourceNames{
+		IAMInstanceProfile: defaultIAMInstanceProfileName,
+		PrivateSubnet:      defaultPrivateSubnetName,
+		SecurityGroup:      defaultSecurityGroupName,
+	}
+	if overrides := pool.Spec.Platform.AWS.ResourceNames; overrides != nil {
+		if overrides.IAMInstanceProfile != "" {
+			names.IAMInstanceProfile = overrides.IAMInstanceProfile
+		}
+		if overrides.PrivateSubnet != "" {
+			names.PrivateSubnet = overrides.PrivateSubnet
+		}
+		if overrides.SecurityGroup != "" {
+			names.SecurityGroup = overrides.SecurityGroup
+		}
 	}
+	return names
+}
 
-	results, err := a.awsClient.DescribeSubnets(&ec2.DescribeSubnetsInput{SubnetIds: idPointers})
-	if err != nil || len(results.Subnets) == 0 {
-		if strings.Contains(err.Error(), "InvalidSubnet") {
-			conditionMessage := err.Error()
-			if submatches := reg.FindStringSubmatch(err.Error()); submatches != nil {
-				// formatting error message before adding it to condition when
-				// sample error message: InvalidSubnetID.NotFound: The subnet ID 'subnet-1,subnet-2' does not exist\tstatus code: 400, request id: ea8b3bb7-de56-405f-9345-e5690a3ea8b2
-				// message after formatting: The subnet ID 'subnet-1,subnet-2' does not exist
-				conditionMessage = submatches[1]
-			}
-			conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
-				pool.Status.Conditions,
-				hivev1.InvalidSubnetsMachinePoolCondition,
-				corev1.ConditionTrue,
-				"SubnetsNotFound",
-				conditionMessage,
-				controllerutils.UpdateConditionIfReasonOrMessageChange,
-			)
-			if changed {
-				pool.Status.Conditions = conds
-				if err := a.client.Status().Update(context.Background(), pool); err != nil {
-					return nil, err
+// expandResourceName returns the name given by a template of the resource names of a pool for the infrastructure ID
+// of the cluster and an availability zone.
+func expandResourceName(template, infraID, zone string) string {
+	return strings.NewReplacer("{infraID}", infraID, "{zone}", zone).Replace(template)
+}
+
+// validateResourceNames checks that the IAM instance profile, private subnets of the given availability zones and
+// security group named by the resource names of the pool exist, and sets the ResourcesNotFound condition accordingly.
+// The resources named by the installer are not checked, and neither are the subnets of pools listing their subnets or
+// failure domains, the security group of pools preserving their security groups nor the IAM instance profile of pools
+// setting its ARN, as the names are not used for them.
+func (a *AWSActuator) validateResourceNames(pool *hivev1.MachinePool, infraID string, zones []string, logger log.FieldLogger) error {
+	overrides := pool.Spec.Platform.AWS.ResourceNames
+	if overrides == nil {
+		pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+			pool.Status.Conditions,
+			hivev1.ResourcesNotFoundMachinePoolCondition,
+			corev1.ConditionFalse,
+			"DefaultResourceNames",
+			"The resources are named as by the installer",
+			controllerutils.UpdateConditionIfReasonOrMessageChange,
+		)
+		return nil
+	}
+
+	var missing []string
+	if overrides.IAMInstanceProfile != "" && pool.Spec.Platform.AWS.IAMInstanceProfileARN == "" {
+		name := expandResourceName(overrides.IAMInstanceProfile, infraID, "")
+		_, err := a.awsClient.GetInstanceProfile(&iam.GetInstanceProfileInput{InstanceProfileName: aws.String(name)})
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == iam.ErrCodeNoSuchEntityException {
+			missing = append(missing, fmt.Sprintf("IAM instance profile %s", name))
+		} else if err != nil {
+			return errors.Wrapf(err, "getting IAM instance profile %s", name)
+		}
+	}
+	if overrides.PrivateSubnet != "" && len(pool.Spec.Platform.AWS.Subnets) == 0 && len(pool.Spec.Platform.AWS.FailureDomains) == 0 {
+		names := sets.NewString()
+		for _, zone := range zones {
+			names.Insert(expandResourceName(overrides.PrivateSubnet, infraID, zone))
+		}
+		resp, err := a.awsClient.DescribeSubnets(&ec2.DescribeSubnetsInput{
+			Filters: []*ec2.Filter{{Name: aws.String("tag:Name"), Values: aws.StringSlice(names.List())}},
+		})
+		if err != nil {
+			return errors.Wrap(err, "describing subnets by name")
+		}
+		for _, subnet := range resp.Subnets {
+			for _, tag := range subnet.Tags {
+				if aws.StringValue(tag.Key) == "Name" {
+					names.Delete(aws.StringValue(tag.Value))
 				}
 			}
 		}
+		for _, name := range names.List() {
+			missing = append(missing, fmt.Sprintf("subnet %s", name))
+		}
+	}
+	if overrides.SecurityGroup != "" && !preserveSecurityGroups(pool) {
+		name := expandResourceName(overrides.SecurityGroup, infraID, "")
+		resp, err := a.awsClient.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+			Filters: []*ec2.Filter{{Name: aws.String("tag:Name"), Values: []*string{aws.String(name)}}},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "describing security group %s", name)
+		}
+		if len(resp.SecurityGroups) == 0 {
+			missing = append(missing, fmt.Sprintf("security group %s", name))
+		}
+	}
+
+	if len(missing) > 0 {
+		message := fmt.Sprintf("resources named by the resourceNames of the pool not found: %s", strings.Join(missing, ", "))
+		logger.WithField("resources", missing).Info("resources named by the pool not found")
+		pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+			pool.Status.Conditions,
+			hivev1.ResourcesNotFoundMachinePoolCondition,
+			corev1.ConditionTrue,
+			"ResourcesNotFound",
+			message,
+			controllerutils.UpdateConditionIfReasonOrMessageChange,
+		)
+		return &ValidationError{
+			Type:    hivev1.ResourcesNotFoundMachinePoolCondition,
+			Reason:  "ResourcesNotFound",
+			Message: message,
+		}
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.ResourcesNotFoundMachinePoolCondition,
+		corev1.ConditionFalse,
+		"ResourcesFound",
+		"The resources named by the resourceNames of the pool exist",
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	return nil
+}
+
+// mergeAWSUserTags returns the tags of a MachineSet with the user tags added. Keys reserved by the installer and the
+// machine API win over user tags: the kubernetes.io/cluster/<infraID> tag, the Name tag set by the machine API for
+// each instance, and any tag already in the MachineSet. User tags with these keys are ignored. The user tags are
+// added in key order so that the generated MachineSets are stable.
+func mergeAWSUserTags(tags []awsproviderv1beta1.TagSpecification, userTags map[string]string, infraID string, logger log.FieldLogger) []awsproviderv1beta1.TagSpecification {
+	reserved := sets.NewString(fmt.Sprintf("kubernetes.io/cluster/%s", infraID), "Name")
+	for _, tag := range tags {
+		reserved.Insert(tag.Name)
+	}
+	keys := make([]string, 0, len(userTags))
+	for key := range userTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if reserved.Has(key) {
+			logger.WithField("tag", key).Debug("ignoring user tag with a reserved key")
+			continue
+		}
+		tags = append(tags, awsproviderv1beta1.TagSpecification{Name: key, Value: userTags[key]})
+	}
+	return tags
+}
+
+// describeSubnets describes the subnets with the given IDs, in batches of at most maxDescribeSubnetIDs IDs, following
+// the NextToken of the responses until all the subnets of each batch are described.
+func describeSubnets(awsClient awsclient.Client, ids []string) ([]*ec2.Subnet, error) {
+	var subnets []*ec2.Subnet
+	for start := 0; start < len(ids); start += maxDescribeSubnetIDs {
+		end := start + maxDescribeSubnetIDs
+		if end > len(ids) {
+			end = len(ids)
+		}
+		input := &ec2.DescribeSubnetsInput{SubnetIds: aws.StringSlice(ids[start:end])}
+		for {
+			output, err := awsClient.DescribeSubnets(input)
+			if err != nil {
+				return nil, err
+			}
+			subnets = append(subnets, output.Subnets...)
+			if aws.StringValue(output.NextToken) == "" {
+				break
+			}
+			input = &ec2.DescribeSubnetsInput{SubnetIds: input.SubnetIds, NextToken: output.NextToken}
+		}
+	}
+	return subnets, nil
+}
+
+// awsSubnetLister is the subnetLister of the AWS actuator. It keeps the descriptions of the subnets it lists for the
+// checks specific to AWS, such as their VPCs and route tables.
+type awsSubnetLister struct {
+	awsClient awsclient.Client
+	// described are the descriptions of the subnets listed, by ID.
+	described map[string]*ec2.Subnet
+}
+
+func newAWSSubnetLister(awsClient awsclient.Client) *awsSubnetLister {
+	return &awsSubnetLister{
+		awsClient: awsClient,
+		described: map[string]*ec2.Subnet{},
+	}
+}
+
+func (l *awsSubnetLister) listSubnets(ids []string) ([]cloudSubnet, error) {
+	described, err := describeSubnets(l.awsClient, ids)
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidSubnet") {
+			return nil, &subnetsNotFoundError{message: invalidSubnetsMessage(err)}
+		}
 		return nil, err
 	}
+	subnets := make([]cloudSubnet, len(described))
+	for i, s := range described {
+		l.described[aws.StringValue(s.SubnetId)] = s
+		subnets[i] = cloudSubnet{id: aws.StringValue(s.SubnetId), zone: aws.StringValue(s.AvailabilityZone)}
+	}
+	return subnets, nil
+}
+
+// getPrivateSubnetsByAvailabilityZones maps availability zones to private subnet. Also returns a description of each
+// subnet selected according to the SubnetSelection of the pool for an availability zone with multiple subnets.
+func (a *AWSActuator) getPrivateSubnetsByAvailabilityZone(pool *hivev1.MachinePool) (map[string]string, []string, error) {
+	lister := newAWSSubnetLister(a.awsClient)
+	listed, err := resolveSubnets(lister, pool, pool.Spec.Platform.AWS.Subnets)
+	if err == nil && len(listed) == 0 {
+		err = errors.New("no subnets found")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	subnets := make([]*ec2.Subnet, len(listed))
+	for i, s := range listed {
+		subnets[i] = lister.described[s.id]
+	}
 
-	vpc := *results.Subnets[0].VpcId
+	// The route tables used to classify the subnets are those of a single VPC.
+	subnetsByVPC := map[string][]string{}
+	for _, subnet := range subnets {
+		vpc := aws.StringValue(subnet.VpcId)
+		subnetsByVPC[vpc] = append(subnetsByVPC[vpc], aws.StringValue(subnet.SubnetId))
+	}
+	if len(subnetsByVPC) > 1 {
+		vpcSubnets := make([]string, 0, len(subnetsByVPC))
+		for _, vpc := range sets.StringKeySet(subnetsByVPC).List() {
+			sort.Strings(subnetsByVPC[vpc])
+			vpcSubnets = append(vpcSubnets, fmt.Sprintf("%s: %s", vpc, strings.Join(subnetsByVPC[vpc], ", ")))
+		}
+		return nil, nil, invalidSubnetsError(pool, "MultipleVPCs",
+			fmt.Sprintf("subnets must all belong to the same VPC: %s", strings.Join(vpcSubnets, "; ")))
+	}
+	vpc := aws.StringValue(subnets[0].VpcId)
 	if vpc == "" {
-		return nil, errors.Errorf("%s has no VPC", *results.Subnets[0].SubnetId)
+		return nil, nil, errors.Errorf("%s has no VPC", *subnets[0].SubnetId)
 	}
 
-	routeTables, err := a.awsClient.DescribeRouteTables(&ec2.DescribeRouteTablesInput{
-		Filters: []*ec2.Filter{{
-			Name:   aws.String("vpc-id"),
-			Values: []*string{aws.String(vpc)},
-		}},
-	})
+	routeTables, err := a.routeTables.getRouteTables(a.awsClient, vpc)
 	if err != nil {
-		return nil, errors.Wrap(err, "error describing route tables")
+		return nil, nil, errors.Wrap(err, "error describing route tables")
 	}
 
-	var privateSubnets, publicSubnets = map[string]ec2.Subnet{}, map[string]ec2.Subnet{}
-	for _, subnet := range results.Subnets {
-		isPublic, err := isSubnetPublic(routeTables.RouteTables, subnet, a.logger)
+	explicitlyPrivate := sets.NewString(pool.Spec.Platform.AWS.PrivateSubnets...)
+	var privateSubnets, publicSubnets []cloudSubnet
+	for i, subnet := range subnets {
+		isPublic, err := isSubnetPublic(routeTables, subnet, a.logger)
+		if explicitlyPrivate.Has(aws.StringValue(subnet.SubnetId)) {
+			// The classification of the user wins over the heuristic, which may not even be able to classify the subnet.
+			if err == nil && isPublic {
+				a.logger.WithField("subnet", aws.StringValue(subnet.SubnetId)).
+					Warn("subnet listed as private in the machine pool looks public from its route table or tags")
+			}
+			isPublic, err = false, nil
+		}
 		if err != nil {
-			return nil, errors.Wrap(err, "error describing route tables")
+			return nil, nil, errors.Wrap(err, "error describing route tables")
 		}
 		if isPublic {
-			publicSubnets[*subnet.SubnetId] = *subnet
+			publicSubnets = append(publicSubnets, listed[i])
 		} else {
-			privateSubnets[*subnet.SubnetId] = *subnet
+			privateSubnets = append(privateSubnets, listed[i])
 		}
 	}
 
+	selectSubnet := a.awsSubnetSelector(pool, lister)
+	publicSubnetsByAvailabilityZone := map[string]string{}
+	var publicSelections []string
 	if len(publicSubnets) > 0 {
-		_, err := a.validateSubnets(publicSubnets, pool)
+		publicSubnetsByAvailabilityZone, publicSelections, err = mapSubnetsToZones(pool, publicSubnets, selectSubnet)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	subnetsByAvailabilityZone, err := a.validateSubnets(privateSubnets, pool)
+	subnetsByAvailabilityZone, selections, err := mapSubnetsToZones(pool, privateSubnets, selectSubnet)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	selections = append(selections, publicSelections...)
+
+	// Machines in a Wavelength Zone are placed in the public subnet of the zone, which reaches the carrier network
+	// through a carrier gateway, when no private subnet is provided for it.
+	for zone, subnetID := range publicSubnetsByAvailabilityZone {
+		if _, ok := subnetsByAvailabilityZone[zone]; !ok && isEdgeZone(zone) {
+			subnetsByAvailabilityZone[zone] = subnetID
+		}
 	}
 
-	if len(publicSubnets) > 0 && len(publicSubnets) < len(privateSubnets) {
-		conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+	// Private subnets in edge zones reach the internet through the parent region, so only the availability zones of
+	// the region need a public subnet.
+	if len(publicSubnets) > 0 && countRegionZones(publicSubnetsByAvailabilityZone) < countRegionZones(subnetsByAvailabilityZone) {
+		pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
 			pool.Status.Conditions,
 			hivev1.InvalidSubnetsMachinePoolCondition,
 			corev1.ConditionTrue,
 			"InsufficientPublicSubnets",
-			fmt.Sprintf("Public subnet does not exist for each zone with a private subnet"),
+			"Public subnet does not exist for each zone with a private subnet",
 			controllerutils.UpdateConditionIfReasonOrMessageChange,
 		)
-		if changed {
-			pool.Status.Conditions = conds
-			if err := a.client.Status().Update(context.Background(), pool); err != nil {
-				return nil, err
+		return nil, nil, &ValidationError{
+			Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+			Reason:  "InsufficientPublicSubnets",
+			Message: "insufficient public subnets for availability zones and private subnets",
+		}
+	}
+
+	return subnetsByAvailabilityZone, selections, nil
+}
+
+// edgeZones returns the zones of an edge pool: the given zones listed in the pool, which must all be edge zones, or else
+// the edge zones of the subnets of the pool. Sets the NoUsableZones condition when there are none.
+func (a *AWSActuator) edgeZones(pool *hivev1.MachinePool, listedZones []string, subnetsByAvailabilityZone map[string]string) ([]string, error) {
+	var zones, regionZones []string
+	if len(listedZones) > 0 {
+		for _, zone := range listedZones {
+			if isEdgeZone(zone) {
+				zones = append(zones, zone)
+			} else {
+				regionZones = append(regionZones, zone)
+			}
+		}
+	} else {
+		for zone := range subnetsByAvailabilityZone {
+			if isEdgeZone(zone) {
+				zones = append(zones, zone)
 			}
-			return nil, errors.Errorf("insufficient public subnets for availability zones and private subnets")
 		}
+		sort.Strings(zones)
+	}
+	var reason, message string
+	switch {
+	case len(regionZones) > 0:
+		reason = "RegionZonesInEdgePool"
+		message = fmt.Sprintf("edge pools can only use edge zones, not availability zones of the region: %s", strings.Join(regionZones, ", "))
+	case len(zones) == 0:
+		reason = "NoEdgeSubnets"
+		message = "none of the subnets of the edge pool are in an edge zone"
+	default:
+		return zones, nil
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.NoUsableZonesMachinePoolCondition,
+		corev1.ConditionTrue,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	return nil, &ValidationError{
+		Type:    hivev1.NoUsableZonesMachinePoolCondition,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// isEdgeZone returns true if the zone is an AWS Local Zone or Wavelength Zone rather than an availability zone of the
+// region.
+func isEdgeZone(zone string) bool {
+	return edgeZoneRegex.MatchString(zone)
+}
+
+// countRegionZones returns the number of zones in the mapping of zones to subnets that are availability zones of the
+// region rather than edge zones.
+func countRegionZones(subnetsByAvailabilityZone map[string]string) int {
+	count := 0
+	for zone := range subnetsByAvailabilityZone {
+		if !isEdgeZone(zone) {
+			count++
+		}
+	}
+	return count
+}
+
+// invalidSubnetsMessage returns a human-readable message for an InvalidSubnet* error returned by AWS, suitable for use
+// in a condition message.
+func invalidSubnetsMessage(err error) string {
+	if awsErr, ok := err.(awserr.Error); ok && strings.HasPrefix(awsErr.Code(), "InvalidSubnet") && awsErr.Message() != "" {
+		return awsErr.Message()
+	}
+	// sample error message: InvalidSubnetID.NotFound: The subnet ID 'subnet-1,subnet-2' does not exist\tstatus code: 400, request id: ea8b3bb7-de56-405f-9345-e5690a3ea8b2
+	// message after formatting: The subnet ID 'subnet-1,subnet-2' does not exist
+	if submatches := reg.FindStringSubmatch(err.Error()); submatches != nil {
+		return submatches[1]
 	}
+	return err.Error()
+}
+
+// poolSpotMarketOptions returns the spot market options of the machines of the pool, or nil if they are not spot
+// instances. Pools with the spot market type and without spot market options use the default options.
+func poolSpotMarketOptions(pool *hivev1.MachinePool) *hivev1aws.SpotMarketOptions {
+	platform := pool.Spec.Platform.AWS
+	if platform.SpotMarketOptions == nil && platform.MarketType == hivev1aws.SpotMarketType {
+		return &hivev1aws.SpotMarketOptions{}
+	}
+	return platform.SpotMarketOptions
+}
 
-	return subnetsByAvailabilityZone, nil
+// isUsingUnsupportedSpotMarketOptions returns whether the pool uses spot instances which the cluster does not support,
+// according to the given version-gated features, or the default ones when none are given. The version gate is ignored
+// for pools bypassing it with the bypass-spot-instances-version-gate annotation, which is logged as a warning on each
+// reconcile as the cluster may not support spot instances at all.
+func isUsingUnsupportedSpotMarketOptions(pool *hivev1.MachinePool, clusterVersion string, features []versionGatedFeature, logger log.FieldLogger) bool {
+	if poolSpotMarketOptions(pool) == nil {
+		return false
+	}
+	spotInstances := findVersionGatedFeature(features, hivev1.SpotInstancesCapability)
+	if spotInstances.isBypassed(pool) {
+		logger.WithFields(log.Fields{
+			"annotation":     spotInstances.bypassAnnotation,
+			"clusterVersion": clusterVersion,
+		}).Warn("BYPASSING the cluster version gate of spot instances: the cluster may not support them")
+		return false
+	}
+	return !isClusterVersionInRange(clusterVersion, spotInstances.versions, logger)
 }
 
-func isUsingUnsupportedSpotMarketOptions(pool *hivev1.MachinePool, clusterVersion string, logger log.FieldLogger) bool {
-	if pool.Spec.Platform.AWS.SpotMarketOptions == nil {
+// isClusterVersionInRange returns true when the cluster version is in the range. Unknown versions, given as empty
+// strings, and versions that cannot be parsed are not in any range.
+func isClusterVersionInRange(clusterVersion string, versionRange semver.Range, logger log.FieldLogger) bool {
+	if clusterVersion == "" {
 		return false
 	}
 	parsedVersion, err := semver.ParseTolerant(clusterVersion)
 	if err != nil {
 		logger.WithError(err).WithField("clusterVersion", clusterVersion).Warn("could not parse the cluster version")
-		return true
+		return false
 	}
-	// Use only major, minor, and patch so that pre-release versions of 4.5.0 are within the >=4.5.0 range.
+	// Use only major, minor, and patch so that pre-release versions are within ranges such as >=4.5.0.
 	parsedVersion = semver.Version{
 		Major: parsedVersion.Major,
 		Minor: parsedVersion.Minor,
 		Patch: parsedVersion.Patch,
 	}
-	return !versionsSupportingSpotInstances(parsedVersion)
+	return versionRange(parsedVersion)
 }
 
+const (
+	// rhcosStreamConfigMapKey is the key of the RHCOS stream metadata in the ConfigMap named by the
+	// rhcos-stream-configmap annotation, as in the coreos-bootimages ConfigMap of the release manifests.
+	rhcosStreamConfigMapKey = "stream"
+	// rhcosStreamArchitecture is the architecture of the images looked up in the RHCOS stream metadata.
+	rhcosStreamArchitecture = "x86_64"
+	// bootImagesConfigMapNamespace and bootImagesConfigMapName identify the ConfigMap in which the machine config
+	// operator publishes the RHCOS stream metadata of the release of the cluster.
+	bootImagesConfigMapNamespace = "openshift-machine-config-operator"
+	bootImagesConfigMapName      = "coreos-bootimages"
+)
+
+// maxDescribeSubnetIDs is the maximum number of subnet IDs described by a single DescribeSubnets call, as EC2 limits
+// the size of requests.
+const maxDescribeSubnetIDs = 200
+
+const (
+	// defaultIAMInstanceProfileName, defaultPrivateSubnetName and defaultSecurityGroupName are the templates of the
+	// names the installer gives the IAM instance profile, private subnets and security group of the workers.
+	defaultIAMInstanceProfileName = "{infraID}-worker-profile"
+	defaultPrivateSubnetName      = "{infraID}-private-{zone}"
+	defaultSecurityGroupName      = "{infraID}-worker-sg"
+)
+
 // tagNameSubnetPublicELB is the tag name used on a subnet to designate that
 // it should be used for internet ELBs
 const tagNameSubnetPublicELB = "kubernetes.io/role/elb"
 
+// kmsKeyAliasPrefix is the prefix of the aliases of KMS keys.
+const kmsKeyAliasPrefix = "alias/"
+
 // https://github.com/kubernetes/kubernetes/blob/9f036cd43d35a9c41d7ac4ca82398a6d0bef957b/staging/src/k8s.io/legacy-cloud-providers/aws/aws.go#L3376-L3419
 func isSubnetPublic(rt []*ec2.RouteTable, subnet *ec2.Subnet, logger log.FieldLogger) (bool, error) {
+	subnetTable, err := subnetRouteTable(rt, subnet, logger)
+	if err != nil {
+		return false, err
+	}
+
+	for _, route := range subnetTable.Routes {
+		// An egress-only internet gateway only allows outbound IPv6 traffic, like a NAT gateway does for IPv4,
+		// so a subnet whose IPv6 default route uses one is still private.
+		if aws.StringValue(route.EgressOnlyInternetGatewayId) != "" ||
+			strings.HasPrefix(aws.StringValue(route.GatewayId), "eigw") {
+			continue
+		}
+		// There is no direct way in the AWS API to determine if a subnet is public or private.
+		// A public subnet is one which has an internet gateway route, for either IPv4 or IPv6,
+		// we look for the gatewayId and make sure it has the prefix of igw to differentiate
+		// from the default in-subnet route which is called "local"
+		// or other virtual gateway (starting with vgv)
+		// or vpc peering connections (starting with pcx).
+		if strings.HasPrefix(aws.StringValue(route.GatewayId), "igw") {
+			return true, nil
+		}
+		// Subnets in Wavelength Zones reach the carrier network through a carrier gateway instead.
+		if strings.HasPrefix(aws.StringValue(route.CarrierGatewayId), "cagw") {
+			return true, nil
+		}
+	}
+
+	// If we couldn't use the subnet table to figure out whether the subnet is public,
+	// we let the users define whether this subnet should be used for internet-facing things
+	// by looking for tagNameSubnetPublicELB tag.
+	tagVal, subnetHasTag := findTag(subnet.Tags, tagNameSubnetPublicELB)
+	if subnetHasTag && (tagVal == "" || tagVal == "1") {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// subnetRouteTable returns the route table of the subnet among the given route tables of its VPC: the table explicitly
+// associated with the subnet, or else the main route table of the VPC.
+func subnetRouteTable(rt []*ec2.RouteTable, subnet *ec2.Subnet, logger log.FieldLogger) (*ec2.RouteTable, error) {
 	subnetID := aws.StringValue(subnet.SubnetId)
 	var subnetTable *ec2.RouteTable
 	for _, table := range rt {
@@ -461,30 +2469,9 @@ func isSubnetPublic(rt []*ec2.RouteTable, subnet *ec2.Subnet, logger log.FieldLo
 	}
 
 	if subnetTable == nil {
-		return false, fmt.Errorf("could not locate routing table for %s", subnetID)
-	}
-
-	for _, route := range subnetTable.Routes {
-		// There is no direct way in the AWS API to determine if a subnet is public or private.
-		// A public subnet is one which has an internet gateway route
-		// we look for the gatewayId and make sure it has the prefix of igw to differentiate
-		// from the default in-subnet route which is called "local"
-		// or other virtual gateway (starting with vgv)
-		// or vpc peering connections (starting with pcx).
-		if strings.HasPrefix(aws.StringValue(route.GatewayId), "igw") {
-			return true, nil
-		}
-	}
-
-	// If we couldn't use the subnet table to figure out whether the subnet is public,
-	// we let the users define whether this subnet should be used for internet-facing things
-	// by looking for tagNameSubnetPublicELB tag.
-	tagVal, subnetHasTag := findTag(subnet.Tags, tagNameSubnetPublicELB)
-	if subnetHasTag && (tagVal == "" || tagVal == "1") {
-		return true, nil
+		return nil, fmt.Errorf("could not locate routing table for %s", subnetID)
 	}
-
-	return false, nil
+	return subnetTable, nil
 }
 
 // Finds the value for a given tag.
@@ -497,37 +2484,53 @@ func findTag(tags []*ec2.Tag, key string) (string, bool) {
 	return "", false
 }
 
-// validateSubnets ensures there's only one public or private subnet per availability zone, and returns
-// the mapping of subnets by availability zone
-func (a *AWSActuator) validateSubnets(subnets map[string]ec2.Subnet, pool *hivev1.MachinePool) (map[string]string, error) {
-	conflictingSubnets := sets.NewString()
-	subnetsByAvailabilityZone := make(map[string]string, len(subnets))
-	for _, subnet := range subnets {
-		if subnetID, ok := subnetsByAvailabilityZone[*subnet.AvailabilityZone]; ok {
-			conflictingSubnets.Insert(*subnet.SubnetId)
-			conflictingSubnets.Insert(subnetID)
-			continue
+// awsSubnetSelector returns the subnetSelector selecting one of multiple subnets of an availability zone according to
+// the SubnetSelection of the pool, or nil when the pool has none, so that multiple subnets conflict. The subnets are
+// those described by the lister.
+func (a *AWSActuator) awsSubnetSelector(pool *hivev1.MachinePool, lister *awsSubnetLister) subnetSelector {
+	selection := pool.Spec.Platform.AWS.SubnetSelection
+	if selection == nil {
+		return nil
+	}
+	return func(zone string, candidates []cloudSubnet) string {
+		zoneSubnets := make([]ec2.Subnet, len(candidates))
+		for i, candidate := range candidates {
+			zoneSubnets[i] = *lister.described[candidate.id]
 		}
-		subnetsByAvailabilityZone[*subnet.AvailabilityZone] = *subnet.SubnetId
+		subnetID := selectSubnet(zoneSubnets, selection)
+		a.logger.WithField("zone", zone).WithField("subnet", subnetID).WithField("policy", selection.Policy).
+			Info("selected one of multiple subnets for availability zone")
+		return subnetID
 	}
+}
 
-	if len(conflictingSubnets) > 0 {
-		conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
-			pool.Status.Conditions,
-			hivev1.InvalidSubnetsMachinePoolCondition,
-			corev1.ConditionTrue,
-			"MoreThanOneSubnetForZone",
-			fmt.Sprintf("more than one subnet found for some availability zones, conflicting subnets: %s", strings.Join(conflictingSubnets.List(), ", ")),
-			controllerutils.UpdateConditionIfReasonOrMessageChange,
-		)
-		if changed {
-			pool.Status.Conditions = conds
-			if err := a.client.Status().Update(context.Background(), pool); err != nil {
-				return nil, err
+// selectSubnet returns the ID of the subnet selected by the policy from multiple subnets in the same availability zone.
+func selectSubnet(subnets []ec2.Subnet, selection *hivev1aws.SubnetSelection) string {
+	candidates := append([]ec2.Subnet(nil), subnets...)
+	sort.Slice(candidates, func(i, j int) bool {
+		if selection.Policy == hivev1aws.SubnetSelectionPolicyTagPriority {
+			iPriority, iOK := subnetTagPriority(candidates[i], selection.PriorityTagKey)
+			jPriority, jOK := subnetTagPriority(candidates[j], selection.PriorityTagKey)
+			if iOK != jOK {
+				return iOK
+			}
+			if iPriority != jPriority {
+				return iPriority < jPriority
 			}
 		}
+		return aws.StringValue(candidates[i].SubnetId) < aws.StringValue(candidates[j].SubnetId)
+	})
+	return aws.StringValue(candidates[0].SubnetId)
+}
 
-		return nil, errors.Errorf("more than one subnet found for some availability zones, conflicting subnets: %s", strings.Join(conflictingSubnets.List(), ", "))
+// subnetTagPriority returns the integer value of the tag with the given key on the subnet, and false if the subnet
+// has no such tag or its value is not an integer.
+func subnetTagPriority(subnet ec2.Subnet, key string) (int, bool) {
+	for _, tag := range subnet.Tags {
+		if aws.StringValue(tag.Key) == key {
+			priority, err := strconv.Atoi(aws.StringValue(tag.Value))
+			return priority, err == nil
+		}
 	}
-	return subnetsByAvailabilityZone, nil
+	return 0, false
 }
@@ -3,23 +3,31 @@ package machinepool
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
 	awsprovider "sigs.k8s.io/cluster-api-provider-aws/pkg/apis/awsprovider/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
@@ -27,7 +35,10 @@ import (
 	"github.com/openshift/hive/apis"
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
 	awshivev1 "github.com/openshift/hive/apis/hive/v1/aws"
+	"github.com/openshift/hive/pkg/awsclient"
+	fakeaws "github.com/openshift/hive/pkg/awsclient/fake"
 	mockaws "github.com/openshift/hive/pkg/awsclient/mock"
+	"github.com/openshift/hive/pkg/constants"
 	controllerutils "github.com/openshift/hive/pkg/controller/utils"
 )
 
@@ -37,16 +48,26 @@ const (
 
 func TestAWSActuator(t *testing.T) {
 	tests := []struct {
-		name                         string
-		mockAWSClient                func(*mockaws.MockClient)
-		clusterDeployment            *hivev1.ClusterDeployment
-		poolName                     string
-		existing                     []runtime.Object
-		expectedMachineSetReplicas   map[string]int64
+		name                       string
+		mockAWSClient              func(*mockaws.MockClient)
+		clusterDeployment          *hivev1.ClusterDeployment
+		poolName                   string
+		existing                   []runtime.Object
+		zoneAMIIDs                 map[string]string
+		zoneStates                 []string
+		kmsKeyARN                  string
+		expectedMachineSetReplicas map[string]int64
+		expectedImageIDs           map[string]string
+		// expectedSubnets are the subnets by availability zone expected in the status, none when empty.
+		expectedSubnets              map[string]string
 		expectedSubnetIDInMachineSet bool
 		expectedErr                  bool
 		expectedCondition            *hivev1.MachinePoolCondition
 		expectedKMSKey               string
+		expectedUserDataSecret       string
+		expectedInstanceType         string
+		expectedZoneInstanceTypes    map[string]string
+		expectedUnencrypted          bool
 	}{
 		{
 			name:              "generate single machineset for single zone",
@@ -62,6 +83,20 @@ func TestAWSActuator(t *testing.T) {
 				generateAWSMachineSetName("zone1"): 3,
 			},
 		},
+		{
+			name:              "edge zones not used by default",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				testMachinePool(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1", "us-east-1-bos-1a", "us-east-1-wl1-bos-wlz-1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+		},
 		{
 			name:              "generate machinesets across zones",
 			clusterDeployment: testClusterDeployment(),
@@ -78,6 +113,116 @@ func TestAWSActuator(t *testing.T) {
 				generateAWSMachineSetName("zone3"): 1,
 			},
 		},
+		{
+			name:              "generate machinesets across zones listed by ID",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.ZoneIDs = []string{"use1-az2", "use1-az1"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZonesByID(client, []string{"use1-az2", "use1-az1"}, map[string]string{
+					"use1-az1": "zone1",
+					"use1-az2": "zone2",
+				})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone2"): 2,
+				generateAWSMachineSetName("zone1"): 1,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Status:  corev1.ConditionFalse,
+				Reason:  "UsableZones",
+				Message: "Using availability zones: zone2, zone1",
+			},
+		},
+		{
+			name:              "zone IDs not found in the account",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.ZoneIDs = []string{"use1-az1", "use1-az7", "usw2-az1"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZonesByID(client, []string{"use1-az1", "use1-az7", "usw2-az1"}, map[string]string{
+					"use1-az1": "zone1",
+				})
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "UnresolvedZoneIDs",
+				Message: "availability zone IDs not found in region test-region for the account of the cluster: use1-az7, usw2-az1",
+			},
+		},
+		{
+			name:              "generate zero replica machinesets across zones",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Replicas = pointer.Int64Ptr(0)
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1", "zone2", "zone3"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 0,
+				generateAWSMachineSetName("zone2"): 0,
+				generateAWSMachineSetName("zone3"): 0,
+			},
+		},
+		{
+			name:              "zone image ID overrides",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				testMachinePool(),
+			},
+			zoneAMIIDs: map[string]string{"zone2": "ami-zone2"},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1", "zone2", "zone3"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 1,
+				generateAWSMachineSetName("zone2"): 1,
+				generateAWSMachineSetName("zone3"): 1,
+			},
+			expectedImageIDs: map[string]string{
+				"zone1": testAMI,
+				"zone2": "ami-zone2",
+				"zone3": testAMI,
+			},
+		},
+		{
+			name:              "zones in configured states",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				testMachinePool(),
+			},
+			zoneStates: []string{"available", "impaired"},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZonesInStates(client, []string{"zone1", "zone2"}, []string{"available", "impaired"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 2,
+				generateAWSMachineSetName("zone2"): 1,
+			},
+		},
 		{
 			name:              "generate machinesets for specified zones",
 			clusterDeployment: testClusterDeployment(),
@@ -127,6 +272,28 @@ func TestAWSActuator(t *testing.T) {
 				generateAWSMachineSetName("zone3"): 1,
 			},
 			expectedSubnetIDInMachineSet: true,
+			expectedSubnets: map[string]string{
+				"zone1": "subnet-zone1",
+				"zone2": "subnet-zone2",
+				"zone3": "subnet-zone3",
+			},
+		},
+		{
+			name:              "user data secret override",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Zones = []string{"zone1"}
+					pool.Spec.UserDataSecretName = "custom-user-data"
+					return pool
+				}(),
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedUserDataSecret: "custom-user-data",
 		},
 		{
 			name:              "list zones returns zero",
@@ -163,319 +330,4087 @@ func TestAWSActuator(t *testing.T) {
 			},
 		},
 		{
-			name:              "more than one private subnet for availability zone",
+			name:              "subnets of earlier configuration cleared",
 			clusterDeployment: testClusterDeployment(),
 			poolName:          testMachinePool().Name,
 			existing: []runtime.Object{
 				func() *hivev1.MachinePool {
 					pool := testMachinePool()
-					pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2", "zone3"}
-					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2", "subnet-zone3"}
+					pool.Spec.Platform.AWS.Zones = []string{"zone1"}
+					pool.Spec.Platform.AWS.Subnets = []string{"missing-subnet1"}
+					pool.Status.SubnetsByAvailabilityZone = map[string]string{"zone1": "subnet-zone1"}
 					return pool
 				}(),
 			},
 			mockAWSClient: func(client *mockaws.MockClient) {
-				mockDescribeSubnets(client, []string{"zone1", "zone1", "zone2"},
-					[]string{"subnet-zone1", "subnet-zone2", "subnet-zone3"}, []string{}, "vpc-1")
-				mockDescribeRouteTables(client, map[string]bool{
-					"subnet-zone1": false,
-					"subnet-zone2": false,
-					"subnet-zone3": false,
-				}, "vpc-1")
-			},
-			expectedErr: true,
-			expectedCondition: &hivev1.MachinePoolCondition{
-				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
-				Status: corev1.ConditionTrue,
-				Reason: "MoreThanOneSubnetForZone",
+				mockDescribeMissingSubnets(client, []string{"missing-subnet1"})
 			},
+			expectedErr:     true,
+			expectedSubnets: map[string]string{},
 		},
 		{
-			name:              "no private subnet for availability zone",
+			name:              "subnets of installer not reported",
 			clusterDeployment: testClusterDeployment(),
 			poolName:          testMachinePool().Name,
 			existing: []runtime.Object{
 				func() *hivev1.MachinePool {
 					pool := testMachinePool()
-					pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2", "zone3"}
-					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2"}
+					pool.Status.SubnetsByAvailabilityZone = map[string]string{"zone1": "subnet-zone1"}
 					return pool
 				}(),
 			},
 			mockAWSClient: func(client *mockaws.MockClient) {
-				mockDescribeSubnets(client, []string{"zone1", "zone2", "zone3"},
-					[]string{"subnet-zone1", "subnet-zone2"}, []string{}, "vpc-1")
-				mockDescribeRouteTables(client, map[string]bool{
-					"subnet-zone1": false,
-					"subnet-zone2": false,
-				}, "vpc-1")
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
 			},
-			expectedErr: true,
-			expectedCondition: &hivev1.MachinePoolCondition{
-				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
-				Status: corev1.ConditionTrue,
-				Reason: "NoSubnetForAvailabilityZone",
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
 			},
+			expectedSubnets: map[string]string{},
 		},
 		{
-			name:              "no public subnet for availability zone and private subnet",
+			name:              "more than one private subnet for availability zone",
 			clusterDeployment: testClusterDeployment(),
 			poolName:          testMachinePool().Name,
 			existing: []runtime.Object{
 				func() *hivev1.MachinePool {
 					pool := testMachinePool()
-					pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2"}
-					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2", "pubSubnet-zone1"}
+					pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2", "zone3"}
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2", "subnet-zone3"}
 					return pool
 				}(),
 			},
 			mockAWSClient: func(client *mockaws.MockClient) {
-				mockDescribeSubnets(client, []string{"zone1", "zone2"},
-					[]string{"subnet-zone1", "subnet-zone2"}, []string{"pubSubnet-zone1"}, "vpc-1")
+				mockDescribeSubnets(client, []string{"zone1", "zone1", "zone2"},
+					[]string{"subnet-zone1", "subnet-zone2", "subnet-zone3"}, []string{}, "vpc-1")
 				mockDescribeRouteTables(client, map[string]bool{
-					"subnet-zone1":    false,
-					"subnet-zone2":    false,
-					"pubSubnet-zone1": true,
+					"subnet-zone1": false,
+					"subnet-zone2": false,
+					"subnet-zone3": false,
 				}, "vpc-1")
 			},
 			expectedErr: true,
 			expectedCondition: &hivev1.MachinePoolCondition{
 				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
 				Status: corev1.ConditionTrue,
-				Reason: "InsufficientPublicSubnets",
+				Reason: "MoreThanOneSubnetForZone",
 			},
 		},
 		{
-			name:              "public subnets all don't have route tables pointing to igw",
+			name:              "more than one private subnet for availability zone with subnet selection",
 			clusterDeployment: testClusterDeployment(),
 			poolName:          testMachinePool().Name,
 			existing: []runtime.Object{
 				func() *hivev1.MachinePool {
 					pool := testMachinePool()
 					pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2"}
-					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2", "pubSubnet-zone1", "pubSubnet-zone2"}
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone1b", "subnet-zone2"}
+					pool.Spec.Platform.AWS.SubnetSelection = &awshivev1.SubnetSelection{
+						Policy: awshivev1.SubnetSelectionPolicyLowestID,
+					}
 					return pool
 				}(),
 			},
 			mockAWSClient: func(client *mockaws.MockClient) {
-				mockDescribeSubnets(client, []string{"zone1", "zone2"},
-					[]string{"subnet-zone1", "subnet-zone2"}, []string{"pubSubnet-zone1", "pubSubnet-zone2"}, "vpc-1")
+				mockDescribeSubnets(client, []string{"zone1", "zone1", "zone2"},
+					[]string{"subnet-zone1", "subnet-zone1b", "subnet-zone2"}, []string{}, "vpc-1")
 				mockDescribeRouteTables(client, map[string]bool{
-					"subnet-zone1":    false,
-					"subnet-zone2":    false,
-					"pubSubnet-zone1": false,
-					"pubSubnet-zone2": false,
+					"subnet-zone1":  false,
+					"subnet-zone1b": false,
+					"subnet-zone2":  false,
 				}, "vpc-1")
 			},
-			expectedCondition: &hivev1.MachinePoolCondition{
-				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
-				Status: corev1.ConditionFalse,
-				Reason: "ValidSubnets",
-			},
 			expectedMachineSetReplicas: map[string]int64{
 				generateAWSMachineSetName("zone1"): 2,
 				generateAWSMachineSetName("zone2"): 1,
 			},
 			expectedSubnetIDInMachineSet: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Status:  corev1.ConditionFalse,
+				Reason:  "SubnetsSelected",
+				Message: "selected one of multiple subnets for availability zones: zone1: subnet-zone1 (of subnet-zone1, subnet-zone1b)",
+			},
 		},
 		{
-			name:              "public subnets some don't have route tables pointing to igw",
+			name:              "instance type from instance type selector",
 			clusterDeployment: testClusterDeployment(),
 			poolName:          testMachinePool().Name,
 			existing: []runtime.Object{
-				func() *hivev1.MachinePool {
-					pool := testMachinePool()
-					pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2"}
-					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2", "pubSubnet-zone1", "pubSubnet-zone2"}
-					return pool
-				}(),
+				withInstanceTypeSelector(testMachinePool(), "m4", "large"),
 			},
 			mockAWSClient: func(client *mockaws.MockClient) {
-				mockDescribeSubnets(client, []string{"zone1", "zone2"},
-					[]string{"subnet-zone1", "subnet-zone2"}, []string{"pubSubnet-zone1", "pubSubnet-zone2"}, "vpc-1")
-				mockDescribeRouteTables(client, map[string]bool{
-					"subnet-zone1":    false,
-					"subnet-zone2":    false,
-					"pubSubnet-zone1": true,
-					"pubSubnet-zone2": false,
-				}, "vpc-1")
-			},
-			expectedCondition: &hivev1.MachinePoolCondition{
-				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
-				Status: corev1.ConditionFalse,
-				Reason: "ValidSubnets",
+				mockDescribeInstanceTypes(client, "m4.large", true)
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
 			},
 			expectedMachineSetReplicas: map[string]int64{
-				generateAWSMachineSetName("zone1"): 2,
-				generateAWSMachineSetName("zone2"): 1,
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedInstanceType: "m4.large",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InstanceTypeNotResolvedMachinePoolCondition,
+				Status:  corev1.ConditionFalse,
+				Reason:  "InstanceTypeResolved",
+				Message: "Using instance type m4.large",
 			},
-			expectedSubnetIDInMachineSet: true,
 		},
 		{
-			name:              "supported spot market options",
-			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.5.0"),
+			name:              "instance type selector not resolved",
+			clusterDeployment: testClusterDeployment(),
 			poolName:          testMachinePool().Name,
 			existing: []runtime.Object{
-				withSpotMarketOptions(testMachinePool()),
+				withInstanceTypeSelector(testMachinePool(), "m4", "huge"),
 			},
 			mockAWSClient: func(client *mockaws.MockClient) {
-				mockDescribeAvailabilityZones(client, []string{"zone1"})
+				mockDescribeInstanceTypes(client, "m4.huge", false)
 			},
-			expectedMachineSetReplicas: map[string]int64{
-				generateAWSMachineSetName("zone1"): 3,
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InstanceTypeNotResolvedMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "InstanceTypeNotFound",
+				Message: "instance type m4.huge for family m4 and size huge does not exist in region test-region",
 			},
 		},
 		{
-			name:              "unsupported spot market options",
-			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.4.0"),
+			name:              "instance type not found",
+			clusterDeployment: testClusterDeployment(),
 			poolName:          testMachinePool().Name,
 			existing: []runtime.Object{
-				withSpotMarketOptions(testMachinePool()),
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.InstanceType = "m4.bogus"
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeInstanceTypes(client, "m4.bogus", false)
 			},
+			expectedErr: true,
 			expectedCondition: &hivev1.MachinePoolCondition{
-				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
-				Status: corev1.ConditionTrue,
-				Reason: "UnsupportedSpotMarketOptions",
+				Type:    hivev1.InstanceTypeNotResolvedMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "InstanceTypeNotFound",
+				Message: "instance type m4.bogus does not exist in region test-region",
 			},
 		},
 		{
-			name:              "kms key disk encryption",
-			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.5.0"),
+			name:              "no instance type",
+			clusterDeployment: testClusterDeployment(),
 			poolName:          testMachinePool().Name,
 			existing: []runtime.Object{
-				withKMSKey(testMachinePool()),
-			},
-			mockAWSClient: func(client *mockaws.MockClient) {
-				mockDescribeAvailabilityZones(client, []string{"zone1"})
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.InstanceType = ""
+					return pool
+				}(),
 			},
-			expectedMachineSetReplicas: map[string]int64{
-				generateAWSMachineSetName("zone1"): 3,
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InstanceTypeNotResolvedMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "NoInstanceType",
+				Message: "neither an instance type nor an instance type selector is set",
 			},
-			expectedKMSKey: fakeKMSKeyARN,
 		},
 		{
-			name:              "unsupported configuration condition cleared",
-			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.4.0"),
+			name:              "zone instance type overrides",
+			clusterDeployment: testClusterDeployment(),
 			poolName:          testMachinePool().Name,
 			existing: []runtime.Object{
-				func() runtime.Object {
-					mp := testMachinePool()
-					mp.Status.Conditions = []hivev1.MachinePoolCondition{{
-						Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
-						Status: corev1.ConditionTrue,
-					}}
-					return mp
-				}(),
+				withZoneInstanceTypes(testMachinePool(), map[string]string{"zone2": "m5.large", "zone4": "m6.large"}),
 			},
 			mockAWSClient: func(client *mockaws.MockClient) {
-				mockDescribeAvailabilityZones(client, []string{"zone1"})
+				mockDescribeAvailabilityZones(client, []string{"zone1", "zone2", "zone3"})
+				mockDescribeInstanceTypeOfferings(client, []string{"zone2"}, []string{"m5.large"}, map[string]string{"zone2": "m5.large"})
 			},
 			expectedMachineSetReplicas: map[string]int64{
-				generateAWSMachineSetName("zone1"): 3,
+				generateAWSMachineSetName("zone1"): 1,
+				generateAWSMachineSetName("zone2"): 1,
+				generateAWSMachineSetName("zone3"): 1,
 			},
+			expectedZoneInstanceTypes: map[string]string{"zone2": "m5.large"},
 			expectedCondition: &hivev1.MachinePoolCondition{
-				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Type:   hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition,
 				Status: corev1.ConditionFalse,
-				Reason: "ConfigurationSupported",
+				Reason: "InstanceTypesOffered",
 			},
 		},
 		{
-			name:              "malformed cluster version",
-			clusterDeployment: withClusterVersion(testClusterDeployment(), "bad-version"),
+			name:              "failure domains",
+			clusterDeployment: testClusterDeployment(),
 			poolName:          testMachinePool().Name,
 			existing: []runtime.Object{
-				withSpotMarketOptions(testMachinePool()),
+				withFailureDomains(testMachinePool(), []awshivev1.FailureDomain{
+					{Zone: "zone1", Subnet: "subnet-zone1"},
+					{Zone: "zone3", Subnet: "subnet-zone3", InstanceType: "m5.large"},
+				}),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeSubnets(client, []string{"zone1", "zone3"}, []string{"subnet-zone1", "subnet-zone3"}, nil, "vpc-1")
+				mockDescribeInstanceTypeOfferings(client, []string{"zone3"}, []string{"m5.large"}, map[string]string{"zone3": "m5.large"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 2,
+				generateAWSMachineSetName("zone3"): 1,
+			},
+			expectedSubnetIDInMachineSet: true,
+			expectedSubnets:              map[string]string{"zone1": "subnet-zone1", "zone3": "subnet-zone3"},
+			expectedZoneInstanceTypes:    map[string]string{"zone3": "m5.large"},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Status:  corev1.ConditionFalse,
+				Reason:  "UsableZones",
+				Message: "Using availability zones: zone1, zone3",
+			},
+		},
+		{
+			name:              "failure domain subnet in another zone",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withFailureDomains(testMachinePool(), []awshivev1.FailureDomain{
+					{Zone: "zone1", Subnet: "subnet-zone1"},
+					{Zone: "zone2", Subnet: "subnet-zone3"},
+				}),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeSubnets(client, []string{"zone1", "zone3"}, []string{"subnet-zone1", "subnet-zone3"}, nil, "vpc-1")
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "SubnetsNotInFailureDomainZones",
+				Message: "subnets of failure domains not in their availability zones: subnet-zone3 is in zone3, not zone2",
+			},
+		},
+		{
+			name:              "failure domain subnets not found",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withFailureDomains(testMachinePool(), []awshivev1.FailureDomain{
+					{Zone: "zone1", Subnet: "subnet-zone1"},
+				}),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeMissingSubnets(client, []string{"subnet-zone1"})
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "SubnetsNotFound",
+			},
+		},
+		{
+			name:              "zone instance types not offered",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withZoneInstanceTypes(testMachinePool(), map[string]string{"zone1": "m5.large", "zone2": "m6.large", "zone3": "m5.large"}),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1", "zone2", "zone3"})
+				mockDescribeInstanceTypeOfferings(client, []string{"zone1", "zone2", "zone3"}, []string{"m5.large", "m6.large"}, map[string]string{"zone1": "m5.large", "zone2": "m5.large"})
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "InstanceTypeNotOffered",
+				Message: "instance types not offered in availability zones: zone2=m6.large, zone3=m5.large",
+			},
+		},
+		{
+			name:              "no zone instance types",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				testMachinePool(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "NoZoneInstanceTypes",
+			},
+		},
+		{
+			name:              "zones from region limited to zones with subnets",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1", "zone2", "zone3"})
+				mockDescribeSubnets(client, []string{"zone1", "zone2"},
+					[]string{"subnet-zone1", "subnet-zone2"}, []string{}, "vpc-1")
+				mockDescribeRouteTables(client, map[string]bool{
+					"subnet-zone1": false,
+					"subnet-zone2": false,
+				}, "vpc-1")
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 2,
+				generateAWSMachineSetName("zone2"): 1,
+			},
+			expectedSubnetIDInMachineSet: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Status:  corev1.ConditionFalse,
+				Reason:  "UsableZones",
+				Message: "Using availability zones: zone1, zone2",
+			},
+		},
+		{
+			name:              "single zone pool",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.SingleZone = true
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone2", "zone1", "zone3"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Status:  corev1.ConditionFalse,
+				Reason:  "UsableZones",
+				Message: "Using availability zones: zone1",
+			},
+		},
+		{
+			name:              "single zone pool with zones not sorted by name",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.SingleZone = true
+					pool.Spec.Platform.AWS.Zones = []string{"zone3", "zone1"}
+					return pool
+				}(),
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone3"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Status:  corev1.ConditionFalse,
+				Reason:  "UsableZones",
+				Message: "Using availability zones: zone3",
+			},
+		},
+		{
+			name:              "single zone pool with subnets in several zones",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.SingleZone = true
+					pool.Spec.Platform.AWS.Zones = []string{"zone2"}
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2", "subnet-zone3"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeSubnets(client, []string{"zone1", "zone2", "zone3"},
+					[]string{"subnet-zone1", "subnet-zone2", "subnet-zone3"}, []string{}, "vpc-1")
+				mockDescribeRouteTables(client, map[string]bool{
+					"subnet-zone1": false,
+					"subnet-zone2": false,
+					"subnet-zone3": false,
+				}, "vpc-1")
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone2"): 3,
+			},
+			expectedSubnetIDInMachineSet: true,
+		},
+		{
+			name:              "no zones in region",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				testMachinePool(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{})
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "NoZonesInRegion",
+				Message: "zero zones returned for region test-region",
+			},
+		},
+		{
+			name:              "no zones in region with subnets",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone3", "subnet-zone4"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1", "zone2"})
+				mockDescribeSubnets(client, []string{"zone3", "zone4"},
+					[]string{"subnet-zone3", "subnet-zone4"}, []string{}, "vpc-1")
+				mockDescribeRouteTables(client, map[string]bool{
+					"subnet-zone3": false,
+					"subnet-zone4": false,
+				}, "vpc-1")
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.NoUsableZonesMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "NoZonesWithSubnets",
+				Message: "none of the availability zones of region test-region have a private subnet: " +
+					"region availability zones: zone1, zone2; subnet availability zones: zone3, zone4",
+			},
+		},
+		{
+			name:              "edge pool uses edge zones of subnets",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Edge = true
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-lz1"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeSubnets(client, []string{"zone1", "us-east-1-bos-1a"},
+					[]string{"subnet-zone1", "subnet-lz1"}, []string{}, "vpc-1")
+				mockDescribeRouteTables(client, map[string]bool{
+					"subnet-zone1": false,
+					"subnet-lz1":   false,
+				}, "vpc-1")
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("us-east-1-bos-1a"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Status:  corev1.ConditionFalse,
+				Reason:  "UsableZones",
+				Message: "Using availability zones: us-east-1-bos-1a",
+			},
+		},
+		{
+			name:              "edge pool without subnets",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Edge = true
+					return pool
+				}(),
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "NoEdgeSubnets",
+				Message: "edge pools must specify the subnets of the edge zones in which to place the machines",
+			},
+		},
+		{
+			name:              "edge pool without subnets in edge zones",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Edge = true
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeSubnets(client, []string{"zone1"}, []string{"subnet-zone1"}, []string{}, "vpc-1")
+				mockDescribeRouteTables(client, map[string]bool{"subnet-zone1": false}, "vpc-1")
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "NoEdgeSubnets",
+				Message: "none of the subnets of the edge pool are in an edge zone",
+			},
+		},
+		{
+			name:              "edge pool with availability zones of the region",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Edge = true
+					pool.Spec.Platform.AWS.Zones = []string{"zone1", "us-east-1-bos-1a"}
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-lz1"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeSubnets(client, []string{"zone1", "us-east-1-bos-1a"},
+					[]string{"subnet-zone1", "subnet-lz1"}, []string{}, "vpc-1")
+				mockDescribeRouteTables(client, map[string]bool{
+					"subnet-zone1": false,
+					"subnet-lz1":   false,
+				}, "vpc-1")
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.NoUsableZonesMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "RegionZonesInEdgePool",
+				Message: "edge pools can only use edge zones, not availability zones of the region: zone1",
+			},
+		},
+		{
+			name:              "no private subnet for availability zone",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2", "zone3"}
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeSubnets(client, []string{"zone1", "zone2", "zone3"},
+					[]string{"subnet-zone1", "subnet-zone2"}, []string{}, "vpc-1")
+				mockDescribeRouteTables(client, map[string]bool{
+					"subnet-zone1": false,
+					"subnet-zone2": false,
+				}, "vpc-1")
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "NoSubnetForAvailabilityZone",
+				Message: "no private subnet provided for availability zones: zone3",
+			},
+		},
+		{
+			name:              "no private subnet for multiple availability zones",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2", "zone3"}
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone2", "pubSubnet-zone2"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeSubnets(client, []string{"zone2"},
+					[]string{"subnet-zone2"}, []string{"pubSubnet-zone2"}, "vpc-1")
+				mockDescribeRouteTables(client, map[string]bool{
+					"subnet-zone2":    false,
+					"pubSubnet-zone2": true,
+				}, "vpc-1")
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "NoSubnetForAvailabilityZone",
+				Message: "no private subnet provided for availability zones: zone1, zone3",
+			},
+		},
+		{
+			name:              "no public subnet for availability zone and private subnet",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2"}
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2", "pubSubnet-zone1"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeSubnets(client, []string{"zone1", "zone2"},
+					[]string{"subnet-zone1", "subnet-zone2"}, []string{"pubSubnet-zone1"}, "vpc-1")
+				mockDescribeRouteTables(client, map[string]bool{
+					"subnet-zone1":    false,
+					"subnet-zone2":    false,
+					"pubSubnet-zone1": true,
+				}, "vpc-1")
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InsufficientPublicSubnets",
+			},
+		},
+		{
+			name:              "public subnets all don't have route tables pointing to igw",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2"}
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2", "pubSubnet-zone1", "pubSubnet-zone2"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeSubnets(client, []string{"zone1", "zone2"},
+					[]string{"subnet-zone1", "subnet-zone2"}, []string{"pubSubnet-zone1", "pubSubnet-zone2"}, "vpc-1")
+				mockDescribeRouteTables(client, map[string]bool{
+					"subnet-zone1":    false,
+					"subnet-zone2":    false,
+					"pubSubnet-zone1": false,
+					"pubSubnet-zone2": false,
+				}, "vpc-1")
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "ValidSubnets",
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 2,
+				generateAWSMachineSetName("zone2"): 1,
+			},
+			expectedSubnetIDInMachineSet: true,
+		},
+		{
+			name:              "public subnets some don't have route tables pointing to igw",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2"}
+					pool.Spec.Platform.AWS.Subnets = []string{"subnet-zone1", "subnet-zone2", "pubSubnet-zone1", "pubSubnet-zone2"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeSubnets(client, []string{"zone1", "zone2"},
+					[]string{"subnet-zone1", "subnet-zone2"}, []string{"pubSubnet-zone1", "pubSubnet-zone2"}, "vpc-1")
+				mockDescribeRouteTables(client, map[string]bool{
+					"subnet-zone1":    false,
+					"subnet-zone2":    false,
+					"pubSubnet-zone1": true,
+					"pubSubnet-zone2": false,
+				}, "vpc-1")
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "ValidSubnets",
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 2,
+				generateAWSMachineSetName("zone2"): 1,
+			},
+			expectedSubnetIDInMachineSet: true,
+		},
+		{
+			name:              "supported spot market options",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.5.0"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withSpotMarketOptions(testMachinePool()),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+		},
+		{
+			name:              "spot max price below spot price",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.5.0"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withSpotMaxPrice(testMachinePool(), "0.05"),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1", "zone2"})
+				mockDescribeSpotPriceHistory(client, map[string]string{"zone1": "0.04", "zone2": "0.06"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 2,
+				generateAWSMachineSetName("zone2"): 1,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.SpotMaxPriceTooLowMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "SpotMaxPriceBelowSpotPrice",
+				Message: fmt.Sprintf("The spot max price 0.05 is below the current spot price of instance type %s in availability zones: zone2 (0.06)", testInstanceType),
+			},
+		},
+		{
+			name:              "spot max price sufficient",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.5.0"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withSpotMaxPrice(testMachinePool(), "0.05"),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+				mockDescribeSpotPriceHistory(client, map[string]string{"zone1": "0.04"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.SpotMaxPriceTooLowMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "SpotMaxPriceSufficient",
+			},
+		},
+		{
+			name:              "spot price not available",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.5.0"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withSpotMaxPrice(testMachinePool(), "0.05"),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+				client.EXPECT().DescribeSpotPriceHistory(gomock.Any()).Return(nil, awserr.New("UnauthorizedOperation", "not authorized", nil))
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.SpotMaxPriceTooLowMachinePoolCondition,
+				Status: corev1.ConditionUnknown,
+			},
+		},
+		{
+			name:              "unsupported spot market options",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.4.0"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withSpotMarketOptions(testMachinePool()),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnsupportedSpotMarketOptions",
+			},
+		},
+		{
+			name:              "spot market options bypassing version gate",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.4.0"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := withSpotMarketOptions(testMachinePool())
+					pool.Annotations = map[string]string{hivev1.MachinePoolBypassSpotInstancesVersionGateAnnotation: "true"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+		},
+		{
+			name: "spot market options with unknown cluster version",
+			clusterDeployment: func() *hivev1.ClusterDeployment {
+				cd := testClusterDeployment()
+				delete(cd.Labels, constants.VersionMajorMinorPatchLabel)
+				return cd
+			}(),
+			poolName: testMachinePool().Name,
+			existing: []runtime.Object{
+				withSpotMarketOptions(testMachinePool()),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "UnsupportedSpotMarketOptions",
+				Message: "Spot instances are disabled until the version of the cluster is known",
+			},
+		},
+		{
+			name: "on-demand instances with unknown cluster version",
+			clusterDeployment: func() *hivev1.ClusterDeployment {
+				cd := testClusterDeployment()
+				delete(cd.Labels, constants.VersionMajorMinorPatchLabel)
+				return cd
+			}(),
+			poolName: testMachinePool().Name,
+			existing: []runtime.Object{
+				testMachinePool(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "ConfigurationSupported",
+			},
+		},
+		{
+			name:              "spot market options supported by cluster version override",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.4.0"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := withSpotMarketOptions(testMachinePool())
+					pool.Annotations = map[string]string{hivev1.MachinePoolClusterVersionOverrideAnnotation: "4.5.0"}
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "ConfigurationSupported",
+			},
+		},
+		{
+			name:              "spot instance interruption behavior terminate",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.5.0"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withSpotInterruptionBehavior(testMachinePool(), "terminate"),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+		},
+		{
+			name:              "boot diagnostics",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withBootDiagnostics(testMachinePool()),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockGetSerialConsoleAccessStatus(client, true)
+				mockDescribeInstanceTypeHypervisor(client, testInstanceType, ec2.InstanceTypeHypervisorNitro)
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "ConfigurationSupported",
+			},
+		},
+		{
+			name:              "boot diagnostics without serial console access",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withBootDiagnostics(testMachinePool()),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockGetSerialConsoleAccessStatus(client, false)
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "SerialConsoleAccessDisabled",
+			},
+		},
+		{
+			name:              "boot diagnostics with non-Nitro instance type",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withBootDiagnostics(testMachinePool()),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockGetSerialConsoleAccessStatus(client, true)
+				mockDescribeInstanceTypeHypervisor(client, testInstanceType, ec2.InstanceTypeHypervisorXen)
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "SerialConsoleUnsupportedInstanceType",
+			},
+		},
+		{
+			name:              "kms key disk encryption",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.5.0"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withKMSKey(testMachinePool()),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			kmsKeyARN: fakeKMSKeyARN,
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedKMSKey: fakeKMSKeyARN,
+		},
+		{
+			name:              "kms key alias disk encryption",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.5.0"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.EC2RootVolume.KMSKeyARN = "alias/ebs-worker"
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			kmsKeyARN: "arn:aws:kms:test-region:123456789012:alias/ebs-worker",
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedKMSKey: "arn:aws:kms:test-region:123456789012:alias/ebs-worker",
+		},
+		{
+			name:              "root volume encryption disabled",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() *hivev1.MachinePool {
+					pool := testMachinePool()
+					pool.Spec.Platform.AWS.EC2RootVolume.Encrypted = aws.Bool(false)
+					return pool
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedUnencrypted: true,
+		},
+		{
+			name:              "instance store volumes not managed",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				testMachinePool(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeInstanceTypeInstanceStore(client, testInstanceType, 2, 300, ec2.EphemeralNvmeSupportRequired)
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InstanceStoreVolumesNotManagedMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "InstanceStoreVolumesNotManaged",
+				Message: fmt.Sprintf("Instance type %s has 600 GB of instance store volumes (2 x 300 GB ssd), attached as NVMe devices, which Hive does not initialize", testInstanceType),
+			},
+		},
+		{
+			name:              "instance store volumes mapped",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				testMachinePool(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeInstanceTypeInstanceStore(client, testInstanceType, 2, 300, ec2.EphemeralNvmeSupportUnsupported)
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InstanceStoreVolumesNotManagedMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "InstanceStoreVolumesNotManaged",
+				Message: fmt.Sprintf("Instance type %s has 600 GB of instance store volumes (2 x 300 GB ssd), mapped to /dev/sdb, /dev/sdc, which Hive does not initialize", testInstanceType),
+			},
+		},
+		{
+			name:              "instance store root device",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				testMachinePool(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeInstanceTypeRootDeviceTypes(client, testInstanceType, ec2.RootDeviceTypeInstanceStore)
+			},
+			expectedErr: true,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "InstanceStoreRootDevice",
+				Message: fmt.Sprintf("Instance type %s only supports instance store root devices, to which the EBS root volume settings of the pool do not apply", testInstanceType),
+			},
+		},
+		{
+			name:              "instance store and EBS root devices",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				testMachinePool(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeInstanceTypeRootDeviceTypes(client, testInstanceType, ec2.RootDeviceTypeEbs, ec2.RootDeviceTypeInstanceStore)
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "ConfigurationSupported",
+			},
+		},
+		{
+			name:              "no instance store volumes",
+			clusterDeployment: testClusterDeployment(),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				testMachinePool(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InstanceStoreVolumesNotManagedMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "NoInstanceStoreVolumes",
+			},
+		},
+		{
+			name:              "unsupported configuration condition cleared",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "4.4.0"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				func() runtime.Object {
+					mp := testMachinePool()
+					mp.Status.Conditions = []hivev1.MachinePoolCondition{{
+						Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+						Status: corev1.ConditionTrue,
+					}}
+					return mp
+				}(),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAWSMachineSetName("zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "ConfigurationSupported",
+			},
+		},
+		{
+			name:              "malformed cluster version",
+			clusterDeployment: withClusterVersion(testClusterDeployment(), "bad-version"),
+			poolName:          testMachinePool().Name,
+			existing: []runtime.Object{
+				withSpotMarketOptions(testMachinePool()),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnsupportedSpotMarketOptions",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		apis.AddToScheme(scheme.Scheme)
+		t.Run(test.name, func(t *testing.T) {
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			fakeClient := fake.NewFakeClient(test.existing...)
+			awsClient := mockaws.NewMockClient(mockCtrl)
+
+			// set up mock expectations
+			if test.mockAWSClient != nil {
+				test.mockAWSClient(awsClient)
+			}
+			mockDescribeAnyInstanceType(awsClient)
+
+			actuator := &AWSActuator{
+				client:     fakeClient,
+				awsClient:  awsClient,
+				logger:     log.WithField("actuator", "awsactuator"),
+				region:     testRegion,
+				amiID:      testAMI,
+				zoneAMIIDs: test.zoneAMIIDs,
+				zoneStates: test.zoneStates,
+				kmsKeyARN:  test.kmsKeyARN,
+			}
+
+			pool := &hivev1.MachinePool{}
+			err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: test.poolName}, pool)
+			require.NoError(t, err)
+
+			generatedMachineSets, _, err := actuator.GenerateMachineSets(test.clusterDeployment, pool, actuator.logger)
+			if test.expectedErr {
+				assert.Error(t, err, "expected error for test case")
+			} else {
+				expectedInstanceType := test.expectedInstanceType
+				if expectedInstanceType == "" {
+					expectedInstanceType = testInstanceType
+				}
+				validateAWSMachineSets(t, generatedMachineSets, test.expectedMachineSetReplicas, test.expectedSubnetIDInMachineSet, test.expectedKMSKey, expectedInstanceType, test.expectedZoneInstanceTypes, test.zoneAMIIDs)
+				expectedUserDataSecret := test.expectedUserDataSecret
+				if expectedUserDataSecret == "" {
+					expectedUserDataSecret = workerUserDataName
+				}
+				for _, ms := range generatedMachineSets {
+					awsProvider := ms.Spec.Template.Spec.ProviderSpec.Value.Object.(*awsprovider.AWSMachineProviderConfig)
+					if assert.NotNil(t, awsProvider.UserDataSecret, "missing user data secret") {
+						assert.Equal(t, expectedUserDataSecret, awsProvider.UserDataSecret.Name, "unexpected user data secret")
+					}
+					if assert.NotNil(t, awsProvider.BlockDevices[0].EBS.Encrypted, "root volume encryption not set") {
+						assert.Equal(t, !test.expectedUnencrypted, *awsProvider.BlockDevices[0].EBS.Encrypted, "unexpected root volume encryption")
+					}
+				}
+			}
+			if test.expectedCondition != nil {
+				cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, test.expectedCondition.Type)
+				if assert.NotNilf(t, cond, "did not find expected condition type: %v", test.expectedCondition.Type) {
+					assert.Equal(t, test.expectedCondition.Status, cond.Status, "condition found with unexpected status")
+					assert.Equal(t, test.expectedCondition.Reason, cond.Reason, "condition found with unexpected reason")
+					if test.expectedCondition.Message != "" {
+						assert.Equal(t, test.expectedCondition.Message, cond.Message, "condition found with unexpected message")
+					}
+				}
+			}
+			if test.expectedImageIDs != nil {
+				err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: test.poolName}, pool)
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedImageIDs, pool.Status.ImageIDs, "unexpected image IDs in status")
+			}
+			if test.expectedSubnets != nil {
+				err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: test.poolName}, pool)
+				require.NoError(t, err)
+				if len(test.expectedSubnets) == 0 {
+					assert.Empty(t, pool.Status.SubnetsByAvailabilityZone, "unexpected subnets in status")
+				} else {
+					assert.Equal(t, test.expectedSubnets, pool.Status.SubnetsByAvailabilityZone, "unexpected subnets in status")
+				}
+			}
+		})
+	}
+}
+
+func TestAWSActuatorValidate(t *testing.T) {
+	tests := []struct {
+		name               string
+		clusterDeployment  *hivev1.ClusterDeployment
+		pool               *hivev1.MachinePool
+		missingAMI         bool
+		mockAWSClient      func(*mockaws.MockClient)
+		expectedErr        bool
+		expectedErrReasons []string
+		expectedConditions map[hivev1.MachinePoolConditionType]corev1.ConditionStatus
+	}{
+		{
+			name:              "valid configuration",
+			clusterDeployment: testClusterDeployment(),
+			pool:              testMachinePool(),
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeAvailabilityZones(client, []string{"zone1"})
+			},
+			expectedConditions: map[hivev1.MachinePoolConditionType]corev1.ConditionStatus{
+				hivev1.UnsupportedConfigurationMachinePoolCondition: corev1.ConditionFalse,
+				hivev1.InvalidSubnetsMachinePoolCondition:           corev1.ConditionFalse,
+			},
+		},
+		{
+			name:               "unsupported spot market options",
+			clusterDeployment:  withClusterVersion(testClusterDeployment(), "4.4.0"),
+			pool:               withSpotMarketOptions(testMachinePool()),
+			expectedErrReasons: []string{"UnsupportedSpotMarketOptions"},
+			expectedConditions: map[hivev1.MachinePoolConditionType]corev1.ConditionStatus{
+				hivev1.UnsupportedConfigurationMachinePoolCondition: corev1.ConditionTrue,
+				hivev1.InvalidSubnetsMachinePoolCondition:           corev1.ConditionUnknown,
+			},
+		},
+		{
+			name:              "capacity block market type",
+			clusterDeployment: testClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.Platform.AWS.MarketType = awshivev1.CapacityBlockMarketType
+				return pool
+			}(),
+			expectedErrReasons: []string{"UnsupportedMarketType"},
+			expectedConditions: map[hivev1.MachinePoolConditionType]corev1.ConditionStatus{
+				hivev1.UnsupportedConfigurationMachinePoolCondition: corev1.ConditionTrue,
+				hivev1.InvalidSubnetsMachinePoolCondition:           corev1.ConditionUnknown,
+			},
+		},
+		{
+			name:              "subnets not found",
+			clusterDeployment: testClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.Platform.AWS.Zones = []string{"zone1"}
+				pool.Spec.Platform.AWS.Subnets = []string{"missing-subnet1"}
+				return pool
+			}(),
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeMissingSubnets(client, []string{"missing-subnet1"})
+			},
+			expectedErrReasons: []string{"SubnetsNotFound"},
+			expectedConditions: map[hivev1.MachinePoolConditionType]corev1.ConditionStatus{
+				hivev1.UnsupportedConfigurationMachinePoolCondition: corev1.ConditionFalse,
+				hivev1.InvalidSubnetsMachinePoolCondition:           corev1.ConditionTrue,
+			},
+		},
+		{
+			name:              "instance type not found",
+			clusterDeployment: testClusterDeployment(),
+			pool:              withInstanceTypeSelector(testMachinePool(), "m9", "huge"),
+			mockAWSClient: func(client *mockaws.MockClient) {
+				mockDescribeInstanceTypes(client, "m9.huge", false)
+			},
+			expectedErrReasons: []string{"InstanceTypeNotFound"},
+			expectedConditions: map[hivev1.MachinePoolConditionType]corev1.ConditionStatus{
+				hivev1.UnsupportedConfigurationMachinePoolCondition: corev1.ConditionFalse,
+				hivev1.InstanceTypeNotResolvedMachinePoolCondition:  corev1.ConditionTrue,
+			},
+		},
+		{
+			name:              "no AMI",
+			clusterDeployment: testClusterDeployment(),
+			pool:              testMachinePool(),
+			missingAMI:        true,
+			expectedErr:       true,
+			expectedConditions: map[hivev1.MachinePoolConditionType]corev1.ConditionStatus{
+				hivev1.UnsupportedConfigurationMachinePoolCondition: corev1.ConditionUnknown,
+				hivev1.InvalidSubnetsMachinePoolCondition:           corev1.ConditionUnknown,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			apis.AddToScheme(scheme.Scheme)
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			fakeClient := fake.NewFakeClient(test.pool)
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			if test.mockAWSClient != nil {
+				test.mockAWSClient(awsClient)
+			}
+			mockDescribeAnyInstanceType(awsClient)
+
+			amiID := testAMI
+			if test.missingAMI {
+				amiID = ""
+			}
+			actuator := &AWSActuator{
+				client:    fakeClient,
+				awsClient: awsClient,
+				logger:    log.WithField("actuator", "awsactuator"),
+				region:    testRegion,
+				amiID:     amiID,
+			}
+
+			origPool := test.pool.DeepCopy()
+			conds, errs := actuator.Validate(test.clusterDeployment, test.pool)
+			var errReasons []string
+			var otherErr bool
+			for _, err := range errs {
+				if validationErr, ok := err.(*ValidationError); ok {
+					errReasons = append(errReasons, validationErr.Reason)
+				} else {
+					otherErr = true
+				}
+			}
+			assert.Equal(t, test.expectedErrReasons, errReasons, "unexpected validation errors")
+			assert.Equal(t, test.expectedErr, otherErr, "unexpected error result")
+			for condType, status := range test.expectedConditions {
+				cond := controllerutils.FindMachinePoolCondition(conds, condType)
+				if assert.NotNilf(t, cond, "did not find expected condition type: %v", condType) {
+					assert.Equal(t, status, cond.Status, "unexpected status for condition %v", condType)
+				}
+			}
+
+			assert.Equal(t, origPool, test.pool, "Validate must not modify the pool")
+			storedPool := &hivev1.MachinePool{}
+			err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: test.pool.Name}, storedPool)
+			require.NoError(t, err, "could not get machine pool")
+			assert.Equal(t, origPool.Status, storedPool.Status, "Validate must not update the pool status")
+		})
+	}
+}
+
+func TestAWSActuatorLabelsAndTaints(t *testing.T) {
+	apis.AddToScheme(scheme.Scheme)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := testMachinePool()
+	pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2"}
+	pool.Spec.Labels["test-label"] = "test-value"
+	pool.Spec.Taints = append(pool.Spec.Taints, corev1.Taint{
+		Key:    "test-taint",
+		Value:  "test-value",
+		Effect: corev1.TaintEffectNoExecute,
+	})
+
+	fakeClient := fake.NewFakeClient(pool)
+	awsClient := mockaws.NewMockClient(mockCtrl)
+	mockDescribeAnyInstanceType(awsClient)
+	logger := log.WithField("actuator", "awsactuator")
+	actuator := &AWSActuator{
+		client:    fakeClient,
+		awsClient: awsClient,
+		logger:    logger,
+		region:    testRegion,
+		amiID:     testAMI,
+	}
+	r := &ReconcileMachinePool{
+		Client: fakeClient,
+		scheme: scheme.Scheme,
+		logger: logger,
+		actuatorBuilder: func(ctx context.Context, cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, masterMachine *machineapi.Machine, remoteMachineSets []machineapi.MachineSet, remoteClusterAPIClient client.Client, logger log.FieldLogger) (Actuator, error) {
+			return actuator, nil
+		},
+	}
+
+	generatedMachineSets, proceed, err := r.generateMachineSets(context.Background(), pool, testClusterDeployment(), nil, &machineapi.MachineSetList{}, nil, logger)
+	require.NoError(t, err, "unexpected error generating machinesets")
+	require.True(t, proceed, "expected to proceed")
+	require.Len(t, generatedMachineSets, 2, "unexpected number of machinesets")
+
+	for _, ms := range generatedMachineSets {
+		assert.Equal(t, pool.Spec.Labels, ms.Spec.Template.Spec.Labels, "unexpected machine labels for %s", ms.Name)
+		assert.Equal(t, pool.Spec.Taints, ms.Spec.Template.Spec.Taints, "unexpected machine taints for %s", ms.Name)
+		assert.True(t, hasLabelsAndTaints(pool, ms), "labels and taints not applied for %s", ms.Name)
+
+		awsProvider, ok := ms.Spec.Template.Spec.ProviderSpec.Value.Object.(*awsprovider.AWSMachineProviderConfig)
+		if assert.True(t, ok, "failed to convert to AWSMachineProviderConfig") {
+			assert.Equal(t, fmt.Sprintf("%s-worker-profile", testInfraID), *awsProvider.IAMInstanceProfile.ID, "unexpected instance profile")
+		}
+	}
+
+	// Modifying the machineset taints must not modify the pool taints.
+	generatedMachineSets[0].Spec.Template.Spec.Taints[0].Value = "modified"
+	assert.Equal(t, "bar", pool.Spec.Taints[0].Value, "pool taints modified through machineset")
+}
+
+func TestAWSActuatorSecurityGroups(t *testing.T) {
+	cases := []struct {
+		name                  string
+		annotations           map[string]string
+		expectedSecurityGroup string
+	}{
+		{
+			name:                  "default worker security group",
+			expectedSecurityGroup: fmt.Sprintf("%s-worker-sg", testInfraID),
+		},
+		{
+			name:                  "preserve security groups",
+			annotations:           map[string]string{hivev1.MachinePoolPreserveSecurityGroupsAnnotation: "true"},
+			expectedSecurityGroup: fmt.Sprintf("%s-%s-sg", testInfraID, testPoolName),
+		},
+		{
+			name:                  "preserve security groups disabled",
+			annotations:           map[string]string{hivev1.MachinePoolPreserveSecurityGroupsAnnotation: "false"},
+			expectedSecurityGroup: fmt.Sprintf("%s-worker-sg", testInfraID),
+		},
+	}
+	for _, tc := range cases {
+		apis.AddToScheme(scheme.Scheme)
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pool := testMachinePool()
+			pool.Annotations = tc.annotations
+			pool.Spec.Platform.AWS.Zones = []string{"zone1"}
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			mockDescribeAnyInstanceType(awsClient)
+			actuator := &AWSActuator{
+				client:    fake.NewFakeClient(pool),
+				awsClient: awsClient,
+				logger:    log.WithField("actuator", "awsactuator"),
+				region:    testRegion,
+				amiID:     testAMI,
+			}
+
+			generatedMachineSets, proceed, err := actuator.GenerateMachineSets(testClusterDeployment(), pool, actuator.logger)
+			require.NoError(t, err, "unexpected error generating machinesets")
+			require.True(t, proceed, "expected to proceed")
+			require.Len(t, generatedMachineSets, 1, "unexpected number of machinesets")
+
+			awsProvider, ok := generatedMachineSets[0].Spec.Template.Spec.ProviderSpec.Value.Object.(*awsprovider.AWSMachineProviderConfig)
+			if assert.True(t, ok, "failed to convert to AWSMachineProviderConfig") && assert.Len(t, awsProvider.SecurityGroups, 1, "unexpected security groups") {
+				assert.Equal(t, []awsprovider.Filter{{Name: "tag:Name", Values: []string{tc.expectedSecurityGroup}}},
+					awsProvider.SecurityGroups[0].Filters, "unexpected security group filters")
+			}
+		})
+	}
+}
+
+func TestAWSActuatorPublicIP(t *testing.T) {
+	cases := []struct {
+		name     string
+		publicIP *bool
+	}{
+		{
+			name: "public IP not set",
+		},
+		{
+			name:     "public IP enabled",
+			publicIP: aws.Bool(true),
+		},
+		{
+			name:     "public IP disabled",
+			publicIP: aws.Bool(false),
+		},
+	}
+	for _, tc := range cases {
+		apis.AddToScheme(scheme.Scheme)
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.Zones = []string{"zone1"}
+			pool.Spec.Platform.AWS.PublicIP = tc.publicIP
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			mockDescribeAnyInstanceType(awsClient)
+			if tc.publicIP != nil && !*tc.publicIP {
+				awsClient.EXPECT().DescribeSubnets(gomock.Any()).Return(&ec2.DescribeSubnetsOutput{}, nil)
+			}
+			actuator := &AWSActuator{
+				client:    fake.NewFakeClient(pool),
+				awsClient: awsClient,
+				logger:    log.WithField("actuator", "awsactuator"),
+				region:    testRegion,
+				amiID:     testAMI,
+			}
+
+			generatedMachineSets, proceed, err := actuator.GenerateMachineSets(testClusterDeployment(), pool, actuator.logger)
+			require.NoError(t, err, "unexpected error generating machinesets")
+			require.True(t, proceed, "expected to proceed")
+			require.Len(t, generatedMachineSets, 1, "unexpected number of machinesets")
+
+			awsProvider, ok := generatedMachineSets[0].Spec.Template.Spec.ProviderSpec.Value.Object.(*awsprovider.AWSMachineProviderConfig)
+			if assert.True(t, ok, "failed to convert to AWSMachineProviderConfig") {
+				assert.Equal(t, tc.publicIP, awsProvider.PublicIP, "unexpected public IP")
+			}
+		})
+	}
+}
+
+func TestAWSActuatorAdditionalBlockDevices(t *testing.T) {
+	type volume struct {
+		deviceName  string
+		virtualName string
+		encrypted   bool
+		kmsKey      string
+		size        int64
+		volumeType  string
+		iops        int64
+	}
+	cases := []struct {
+		name             string
+		rootEncrypted    *bool
+		kmsKeyARN        string
+		devices          []awshivev1.BlockDevice
+		deviceKMSKeyARNs []string
+		instanceStore    int64
+		expectedVolumes  []volume
+	}{
+		{
+			name:            "no additional block devices",
+			kmsKeyARN:       "arn:aws:kms:test-region:123456789012:key/root",
+			expectedVolumes: []volume{{encrypted: true, kmsKey: "arn:aws:kms:test-region:123456789012:key/root", volumeType: "gp2"}},
+		},
+		{
+			name:      "root and data volumes with their own KMS keys",
+			kmsKeyARN: "arn:aws:kms:test-region:123456789012:key/root",
+			devices: []awshivev1.BlockDevice{
+				{DeviceName: "/dev/xvdb", Size: 100, Type: "gp3", KMSKeyARN: "alias/ebs-data"},
+				{DeviceName: "/dev/xvdc", Size: 200, Type: "io1", IOPS: 1000},
+			},
+			deviceKMSKeyARNs: []string{"arn:aws:kms:test-region:123456789012:key/data", ""},
+			expectedVolumes: []volume{
+				{encrypted: true, kmsKey: "arn:aws:kms:test-region:123456789012:key/root", volumeType: "gp2"},
+				{deviceName: "/dev/xvdb", encrypted: true, kmsKey: "arn:aws:kms:test-region:123456789012:key/data", size: 100, volumeType: "gp3"},
+				{deviceName: "/dev/xvdc", encrypted: true, size: 200, volumeType: "io1", iops: 1000},
+			},
+		},
+		{
+			name:          "unencrypted root volume with encrypted data volume",
+			rootEncrypted: aws.Bool(false),
+			devices: []awshivev1.BlockDevice{
+				{DeviceName: "/dev/xvdb", Size: 100, Type: "gp3", KMSKeyARN: "arn:aws:kms:test-region:123456789012:key/data"},
+			},
+			deviceKMSKeyARNs: []string{"arn:aws:kms:test-region:123456789012:key/data"},
+			expectedVolumes: []volume{
+				{encrypted: false, volumeType: "gp2"},
+				{deviceName: "/dev/xvdb", encrypted: true, kmsKey: "arn:aws:kms:test-region:123456789012:key/data", size: 100, volumeType: "gp3"},
+			},
+		},
+		{
+			name:      "encrypted root volume with unencrypted data volume",
+			kmsKeyARN: "arn:aws:kms:test-region:123456789012:key/root",
+			devices: []awshivev1.BlockDevice{
+				{DeviceName: "/dev/xvdb", Size: 100, Type: "gp3", Encrypted: aws.Bool(false)},
+			},
+			deviceKMSKeyARNs: []string{""},
+			expectedVolumes: []volume{
+				{encrypted: true, kmsKey: "arn:aws:kms:test-region:123456789012:key/root", volumeType: "gp2"},
+				{deviceName: "/dev/xvdb", encrypted: false, size: 100, volumeType: "gp3"},
+			},
+		},
+		{
+			name:          "instance store volumes",
+			rootEncrypted: aws.Bool(false),
+			devices: []awshivev1.BlockDevice{
+				{DeviceName: "/dev/xvdc", Size: 100, Type: "gp3", Encrypted: aws.Bool(false)},
+			},
+			deviceKMSKeyARNs: []string{""},
+			instanceStore:    3,
+			expectedVolumes: []volume{
+				{encrypted: false, volumeType: "gp2"},
+				{deviceName: "/dev/xvdc", encrypted: false, size: 100, volumeType: "gp3"},
+				{deviceName: "/dev/sdb", virtualName: "ephemeral0"},
+				{deviceName: "/dev/sdd", virtualName: "ephemeral1"},
+				{deviceName: "/dev/sde", virtualName: "ephemeral2"},
+			},
+		},
+	}
+	for _, tc := range cases {
+		apis.AddToScheme(scheme.Scheme)
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.Zones = []string{"zone1"}
+			pool.Spec.Platform.AWS.EC2RootVolume.Type = "gp2"
+			pool.Spec.Platform.AWS.EC2RootVolume.Encrypted = tc.rootEncrypted
+			pool.Spec.Platform.AWS.AdditionalBlockDevices = tc.devices
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			if tc.instanceStore > 0 {
+				mockDescribeInstanceTypeInstanceStore(awsClient, testInstanceType, tc.instanceStore, 400, ec2.EphemeralNvmeSupportUnsupported)
+			}
+			mockDescribeAnyInstanceType(awsClient)
+			actuator := &AWSActuator{
+				client:           fake.NewFakeClient(pool),
+				awsClient:        awsClient,
+				logger:           log.WithField("actuator", "awsactuator"),
+				region:           testRegion,
+				amiID:            testAMI,
+				kmsKeyARN:        tc.kmsKeyARN,
+				deviceKMSKeyARNs: tc.deviceKMSKeyARNs,
+			}
+
+			generatedMachineSets, proceed, err := actuator.GenerateMachineSets(testClusterDeployment(), pool, actuator.logger)
+			require.NoError(t, err, "unexpected error generating machinesets")
+			require.True(t, proceed, "expected to proceed")
+			require.Len(t, generatedMachineSets, 1, "unexpected number of machinesets")
+
+			awsProvider, ok := generatedMachineSets[0].Spec.Template.Spec.ProviderSpec.Value.Object.(*awsprovider.AWSMachineProviderConfig)
+			require.True(t, ok, "failed to convert to AWSMachineProviderConfig")
+			volumes := make([]volume, len(awsProvider.BlockDevices))
+			for i, blockDevice := range awsProvider.BlockDevices {
+				if blockDevice.VirtualName != nil {
+					volumes[i] = volume{
+						deviceName:  aws.StringValue(blockDevice.DeviceName),
+						virtualName: aws.StringValue(blockDevice.VirtualName),
+					}
+					continue
+				}
+				require.NotNil(t, blockDevice.EBS, "missing EBS volume of block device %d", i)
+				volumes[i] = volume{
+					deviceName: aws.StringValue(blockDevice.DeviceName),
+					encrypted:  aws.BoolValue(blockDevice.EBS.Encrypted),
+					kmsKey:     aws.StringValue(blockDevice.EBS.KMSKey.ARN),
+					volumeType: aws.StringValue(blockDevice.EBS.VolumeType),
+				}
+				if i > 0 {
+					volumes[i].size = aws.Int64Value(blockDevice.EBS.VolumeSize)
+					volumes[i].iops = aws.Int64Value(blockDevice.EBS.Iops)
+				}
+			}
+			assert.Equal(t, tc.expectedVolumes, volumes, "unexpected block devices")
+		})
+	}
+}
+
+func TestAWSActuatorResourceNames(t *testing.T) {
+	profile := fmt.Sprintf("%s-compute-profile", testInfraID)
+	subnet := fmt.Sprintf("%s-subnet-zone1", testInfraID)
+	securityGroup := fmt.Sprintf("%s-compute-sg", testInfraID)
+	expectGetInstanceProfile := func(client *mockaws.MockClient, err error) {
+		client.EXPECT().GetInstanceProfile(&iam.GetInstanceProfileInput{InstanceProfileName: aws.String(profile)}).
+			Return(&iam.GetInstanceProfileOutput{}, err)
+	}
+	expectDescribeNamedSubnets := func(client *mockaws.MockClient, found bool) {
+		output := &ec2.DescribeSubnetsOutput{}
+		if found {
+			output.Subnets = []*ec2.Subnet{{
+				SubnetId: aws.String("subnet-zone1"),
+				Tags:     []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String(subnet)}},
+			}}
+		}
+		client.EXPECT().DescribeSubnets(&ec2.DescribeSubnetsInput{
+			Filters: []*ec2.Filter{{Name: aws.String("tag:Name"), Values: []*string{aws.String(subnet)}}},
+		}).Return(output, nil)
+	}
+	expectDescribeSecurityGroups := func(client *mockaws.MockClient, found bool) {
+		output := &ec2.DescribeSecurityGroupsOutput{}
+		if found {
+			output.SecurityGroups = []*ec2.SecurityGroup{{GroupId: aws.String("sg-1")}}
+		}
+		client.EXPECT().DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+			Filters: []*ec2.Filter{{Name: aws.String("tag:Name"), Values: []*string{aws.String(securityGroup)}}},
+		}).Return(output, nil)
+	}
+
+	profileARN := "arn:aws:iam::123456789012:instance-profile/workers"
+
+	cases := []struct {
+		name                  string
+		annotations           map[string]string
+		profileARN            string
+		mockAWSClient         func(*mockaws.MockClient)
+		expectedSecurityGroup string
+		expectedStatus        corev1.ConditionStatus
+		expectedReason        string
+		expectedMessage       string
+	}{
+		{
+			name: "resources found",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectGetInstanceProfile(client, nil)
+				expectDescribeNamedSubnets(client, true)
+				expectDescribeSecurityGroups(client, true)
+			},
+			expectedSecurityGroup: securityGroup,
+			expectedStatus:        corev1.ConditionFalse,
+			expectedReason:        "ResourcesFound",
+		},
+		{
+			name: "resources not found",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectGetInstanceProfile(client, awserr.New(iam.ErrCodeNoSuchEntityException, "no such profile", nil))
+				expectDescribeNamedSubnets(client, false)
+				expectDescribeSecurityGroups(client, false)
+			},
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "ResourcesNotFound",
+			expectedMessage: fmt.Sprintf("resources named by the resourceNames of the pool not found: IAM instance profile %s, subnet %s, security group %s",
+				profile, subnet, securityGroup),
+		},
+		{
+			name:        "security group not checked when preserved",
+			annotations: map[string]string{hivev1.MachinePoolPreserveSecurityGroupsAnnotation: "true"},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectGetInstanceProfile(client, nil)
+				expectDescribeNamedSubnets(client, true)
+			},
+			expectedSecurityGroup: fmt.Sprintf("%s-%s-sg", testInfraID, testPoolName),
+			expectedStatus:        corev1.ConditionFalse,
+			expectedReason:        "ResourcesFound",
+		},
+		{
+			name:       "instance profile not checked when its ARN is set",
+			profileARN: profileARN,
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeNamedSubnets(client, true)
+				expectDescribeSecurityGroups(client, true)
+			},
+			expectedSecurityGroup: securityGroup,
+			expectedStatus:        corev1.ConditionFalse,
+			expectedReason:        "ResourcesFound",
+		},
+	}
+	for _, tc := range cases {
+		apis.AddToScheme(scheme.Scheme)
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pool := testMachinePool()
+			pool.Annotations = tc.annotations
+			pool.Spec.Platform.AWS.Zones = []string{"zone1"}
+			pool.Spec.Platform.AWS.ResourceNames = &awshivev1.ResourceNames{
+				IAMInstanceProfile: "{infraID}-compute-profile",
+				PrivateSubnet:      "{infraID}-subnet-{zone}",
+				SecurityGroup:      "{infraID}-compute-sg",
+			}
+			pool.Spec.Platform.AWS.IAMInstanceProfileARN = tc.profileARN
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			mockDescribeAnyInstanceType(awsClient)
+			tc.mockAWSClient(awsClient)
+			actuator := &AWSActuator{
+				client:    fake.NewFakeClient(pool),
+				awsClient: awsClient,
+				logger:    log.WithField("actuator", "awsactuator"),
+				region:    testRegion,
+				amiID:     testAMI,
+			}
+
+			generatedMachineSets, _, err := actuator.GenerateMachineSets(testClusterDeployment(), pool, actuator.logger)
+
+			cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.ResourcesNotFoundMachinePoolCondition)
+			if assert.NotNil(t, cond, "missing ResourcesNotFound condition") {
+				assert.Equal(t, tc.expectedStatus, cond.Status, "unexpected condition status")
+				assert.Equal(t, tc.expectedReason, cond.Reason, "unexpected condition reason")
+				if tc.expectedMessage != "" {
+					assert.Equal(t, tc.expectedMessage, cond.Message, "unexpected condition message")
+				}
+			}
+			if tc.expectedStatus == corev1.ConditionTrue {
+				var validationErr *ValidationError
+				assert.True(t, errors.As(err, &validationErr), "expected a validation error")
+				return
+			}
+			require.NoError(t, err, "unexpected error generating machinesets")
+			require.Len(t, generatedMachineSets, 1, "unexpected number of machinesets")
+			awsProvider, ok := generatedMachineSets[0].Spec.Template.Spec.ProviderSpec.Value.Object.(*awsprovider.AWSMachineProviderConfig)
+			if assert.True(t, ok, "failed to convert to AWSMachineProviderConfig") {
+				if tc.profileARN != "" {
+					assert.Equal(t, &awsprovider.AWSResourceReference{ARN: aws.String(tc.profileARN)}, awsProvider.IAMInstanceProfile, "unexpected instance profile")
+				} else {
+					assert.Equal(t, profile, aws.StringValue(awsProvider.IAMInstanceProfile.ID), "unexpected instance profile")
+				}
+				assert.Equal(t, []awsprovider.Filter{{Name: "tag:Name", Values: []string{subnet}}}, awsProvider.Subnet.Filters, "unexpected subnet filters")
+				if assert.Len(t, awsProvider.SecurityGroups, 1, "unexpected security groups") {
+					assert.Equal(t, []awsprovider.Filter{{Name: "tag:Name", Values: []string{tc.expectedSecurityGroup}}},
+						awsProvider.SecurityGroups[0].Filters, "unexpected security group filters")
+				}
+			}
+		})
+	}
+}
+
+func TestAWSActuatorCapabilities(t *testing.T) {
+	actuator := &AWSActuator{logger: log.WithField("actuator", "awsactuator")}
+
+	// The test cluster is at version 4.4.0, which predates spot instances and Ignition spec 3.
+	capabilities := actuator.Capabilities(testClusterDeployment(), testMachinePool())
+	supported := map[hivev1.MachinePoolCapabilityName]bool{}
+	for _, capability := range capabilities {
+		supported[capability.Name] = capability.Supported
+		if !capability.Supported {
+			assert.NotEmpty(t, capability.Message, "missing message of unsupported capability %s", capability.Name)
+		}
+	}
+	assert.Equal(t, map[hivev1.MachinePoolCapabilityName]bool{
+		hivev1.SpotInstancesCapability:          false,
+		hivev1.ScaleToZeroCapability:            true,
+		hivev1.IgnitionV3UserDataCapability:     false,
+		hivev1.GP3RootVolumesCapability:         true,
+		hivev1.CapacityReservationsCapability:   false,
+		hivev1.NetworkInterfacesCapability:      false,
+		hivev1.LicenseConfigurationsCapability:  false,
+		hivev1.SpotFallbackToOnDemandCapability: false,
+	}, supported, "unexpected capabilities")
+}
+
+func TestAWSActuatorUserTags(t *testing.T) {
+	apis.AddToScheme(scheme.Scheme)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := testMachinePool()
+	pool.Spec.Platform.AWS.Zones = []string{"zone1"}
+	awsClient := mockaws.NewMockClient(mockCtrl)
+	mockDescribeAnyInstanceType(awsClient)
+	actuator := &AWSActuator{
+		client:    fake.NewFakeClient(pool),
+		awsClient: awsClient,
+		logger:    log.WithField("actuator", "awsactuator"),
+		region:    testRegion,
+		amiID:     testAMI,
+	}
+	cd := testClusterDeployment()
+	cd.Spec.Platform.AWS.UserTags = map[string]string{
+		"Name":        "my-worker",
+		"cost-center": "engineering",
+		fmt.Sprintf("kubernetes.io/cluster/%s", testInfraID): "shared",
+	}
+
+	generatedMachineSets, proceed, err := actuator.GenerateMachineSets(cd, pool, actuator.logger)
+	require.NoError(t, err, "unexpected error generating machinesets")
+	require.True(t, proceed, "expected to proceed")
+	require.Len(t, generatedMachineSets, 1, "unexpected number of machinesets")
+
+	awsProvider, ok := generatedMachineSets[0].Spec.Template.Spec.ProviderSpec.Value.Object.(*awsprovider.AWSMachineProviderConfig)
+	if assert.True(t, ok, "failed to convert to AWSMachineProviderConfig") {
+		assert.Equal(t, []awsprovider.TagSpecification{
+			{Name: fmt.Sprintf("kubernetes.io/cluster/%s", testInfraID), Value: "owned"},
+			{Name: "cost-center", Value: "engineering"},
+		}, awsProvider.Tags, "unexpected tags")
+	}
+}
+
+func TestAWSActuatorAdditionalTags(t *testing.T) {
+	apis.AddToScheme(scheme.Scheme)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := testMachinePool()
+	pool.Spec.Platform.AWS.Zones = []string{"zone1"}
+	awsClient := mockaws.NewMockClient(mockCtrl)
+	mockDescribeAnyInstanceType(awsClient)
+	actuator := &AWSActuator{
+		client:    fake.NewFakeClient(pool),
+		awsClient: awsClient,
+		logger:    log.WithField("actuator", "awsactuator"),
+		region:    testRegion,
+		amiID:     testAMI,
+		additionalTags: map[string]string{
+			"Name":        "my-worker",
+			"cost-center": "engineering",
+			"environment": "prod",
+		},
+	}
+	cd := testClusterDeployment()
+	cd.Spec.Platform.AWS.UserTags = map[string]string{
+		"cost-center": "research",
+		"team":        "hive",
+	}
+
+	generatedMachineSets, proceed, err := actuator.GenerateMachineSets(cd, pool, actuator.logger)
+	require.NoError(t, err, "unexpected error generating machinesets")
+	require.True(t, proceed, "expected to proceed")
+	require.Len(t, generatedMachineSets, 1, "unexpected number of machinesets")
+
+	awsProvider, ok := generatedMachineSets[0].Spec.Template.Spec.ProviderSpec.Value.Object.(*awsprovider.AWSMachineProviderConfig)
+	if assert.True(t, ok, "failed to convert to AWSMachineProviderConfig") {
+		assert.Equal(t, []awsprovider.TagSpecification{
+			{Name: fmt.Sprintf("kubernetes.io/cluster/%s", testInfraID), Value: "owned"},
+			{Name: "cost-center", Value: "engineering"},
+			{Name: "environment", Value: "prod"},
+			{Name: "team", Value: "hive"},
+		}, awsProvider.Tags, "unexpected tags")
+	}
+}
+
+func TestAWSActuatorSpotFallback(t *testing.T) {
+	cases := []struct {
+		name                   string
+		marketType             awshivev1.MarketType
+		spotFallbackToOnDemand bool
+		expectSpot             bool
+		expectedStatus         corev1.ConditionStatus
+		expectedReason         string
+	}{
+		{
+			name:           "spot instances without fallback",
+			marketType:     awshivev1.SpotMarketType,
+			expectSpot:     true,
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "SpotFallbackNotRequested",
+		},
+		{
+			name:                   "spot instances with fallback",
+			marketType:             awshivev1.SpotMarketType,
+			spotFallbackToOnDemand: true,
+			expectSpot:             true,
+			expectedStatus:         corev1.ConditionTrue,
+			expectedReason:         "MixedInstancesPolicyUnsupported",
+		},
+		{
+			name:                   "on-demand instances with fallback",
+			spotFallbackToOnDemand: true,
+			expectedStatus:         corev1.ConditionFalse,
+			expectedReason:         "NotSpotInstances",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			apis.AddToScheme(scheme.Scheme)
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.Zones = []string{"zone1", "zone2"}
+			pool.Spec.Platform.AWS.MarketType = tc.marketType
+			pool.Spec.Platform.AWS.SpotFallbackToOnDemand = tc.spotFallbackToOnDemand
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			mockDescribeAnyInstanceType(awsClient)
+			actuator := &AWSActuator{
+				client:    fake.NewFakeClient(pool),
+				awsClient: awsClient,
+				logger:    log.WithField("actuator", "awsactuator"),
+				region:    testRegion,
+				amiID:     testAMI,
+			}
+
+			generatedMachineSets, proceed, err := actuator.GenerateMachineSets(withClusterVersion(testClusterDeployment(), "4.5.0"), pool, actuator.logger)
+			require.NoError(t, err, "unexpected error generating machinesets")
+			require.True(t, proceed, "expected to proceed")
+
+			// The fallback cannot be expressed, so there is still a single MachineSet per zone, with one instance type
+			// and purchasing option, and no additional on-demand MachineSets.
+			require.Len(t, generatedMachineSets, 2, "unexpected number of machinesets")
+			for i, zone := range []string{"zone1", "zone2"} {
+				ms := generatedMachineSets[i]
+				assert.Equal(t, generateAWSMachineSetName(zone), ms.Name, "unexpected machineset name")
+				awsProvider, ok := ms.Spec.Template.Spec.ProviderSpec.Value.Object.(*awsprovider.AWSMachineProviderConfig)
+				if assert.True(t, ok, "failed to convert to AWSMachineProviderConfig") {
+					assert.Equal(t, zone, awsProvider.Placement.AvailabilityZone, "unexpected zone")
+					assert.Equal(t, testInstanceType, awsProvider.InstanceType, "unexpected instance type")
+					if tc.expectSpot {
+						assert.Equal(t, &awsprovider.SpotMarketOptions{}, awsProvider.SpotMarketOptions, "unexpected spot market options")
+					} else {
+						assert.Nil(t, awsProvider.SpotMarketOptions, "unexpected spot market options")
+					}
+				}
+			}
+
+			cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.SpotFallbackUnavailableMachinePoolCondition)
+			require.NotNil(t, cond, "missing SpotFallbackUnavailable condition")
+			assert.Equal(t, tc.expectedStatus, cond.Status, "unexpected condition status")
+			assert.Equal(t, tc.expectedReason, cond.Reason, "unexpected condition reason")
+		})
+	}
+}
+
+func Test_mergeAWSUserTags(t *testing.T) {
+	clusterTag := awsprovider.TagSpecification{Name: fmt.Sprintf("kubernetes.io/cluster/%s", testInfraID), Value: "owned"}
+	cases := []struct {
+		name     string
+		tags     []awsprovider.TagSpecification
+		userTags map[string]string
+		expected []awsprovider.TagSpecification
+	}{
+		{
+			name:     "no user tags",
+			tags:     []awsprovider.TagSpecification{clusterTag},
+			expected: []awsprovider.TagSpecification{clusterTag},
+		},
+		{
+			name:     "user tags added in key order",
+			tags:     []awsprovider.TagSpecification{clusterTag},
+			userTags: map[string]string{"team": "hive", "cost-center": "engineering"},
+			expected: []awsprovider.TagSpecification{
+				clusterTag,
+				{Name: "cost-center", Value: "engineering"},
+				{Name: "team", Value: "hive"},
+			},
+		},
+		{
+			name:     "cluster tag wins",
+			tags:     []awsprovider.TagSpecification{clusterTag},
+			userTags: map[string]string{clusterTag.Name: "shared"},
+			expected: []awsprovider.TagSpecification{clusterTag},
+		},
+		{
+			name:     "cluster tag reserved when not in machineset",
+			userTags: map[string]string{clusterTag.Name: "shared"},
+		},
+		{
+			name:     "name tag reserved",
+			tags:     []awsprovider.TagSpecification{clusterTag},
+			userTags: map[string]string{"Name": "my-worker"},
+			expected: []awsprovider.TagSpecification{clusterTag},
+		},
+		{
+			name:     "existing machineset tag wins",
+			tags:     []awsprovider.TagSpecification{clusterTag, {Name: "team", Value: "installer"}},
+			userTags: map[string]string{"team": "hive"},
+			expected: []awsprovider.TagSpecification{clusterTag, {Name: "team", Value: "installer"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := mergeAWSUserTags(tc.tags, tc.userTags, testInfraID, log.StandardLogger())
+			assert.Equal(t, tc.expected, actual, "unexpected tags")
+		})
+	}
+}
+
+func TestAWSActuatorReadOnlyStatus(t *testing.T) {
+	apis.AddToScheme(scheme.Scheme)
+	fakeClient := fake.NewFakeClient(withSpotMarketOptions(testMachinePool()))
+	actuator := &AWSActuator{
+		client: readOnlyStatusClient{Client: fakeClient},
+		logger: log.WithField("actuator", "awsactuator"),
+		region: testRegion,
+		amiID:  testAMI,
+	}
+
+	pool := &hivev1.MachinePool{}
+	err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: testMachinePool().Name}, pool)
+	require.NoError(t, err)
+
+	_, proceed, err := actuator.GenerateMachineSets(withClusterVersion(testClusterDeployment(), "4.4.0"), pool, actuator.logger)
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr), "expected a validation error")
+	assert.Equal(t, hivev1.UnsupportedConfigurationMachinePoolCondition, validationErr.Type, "unexpected validation error type")
+	assert.False(t, proceed, "expected not to proceed")
+	cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.UnsupportedConfigurationMachinePoolCondition)
+	if assert.NotNil(t, cond, "expected the condition to be returned on the pool") {
+		assert.Equal(t, "UnsupportedSpotMarketOptions", cond.Reason, "unexpected condition reason")
+	}
+
+	persisted := &hivev1.MachinePool{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: testMachinePool().Name}, persisted)
+	require.NoError(t, err)
+	assert.Equal(t, testMachinePool().Status, persisted.Status, "expected the status not to be persisted")
+}
+
+func TestGetAWSAMIID(t *testing.T) {
+	const testBootImagesStream = `{
+  "stream": "rhcos-4.9",
+  "architectures": {
+    "x86_64": {
+      "images": {"aws": {"regions": {"us-east-1": {"release": "49.84.202109241334-0", "image": "ami-bootimages"}}}}
+    }
+  }
+}`
+	masterMachineWithoutAMI := func() *machineapi.Machine {
+		providerSpec := testAWSProviderSpec()
+		providerSpec.AMI = awsprovider.AWSResourceReference{}
+		rawProviderSpec, err := encodeAWSMachineProviderSpec(providerSpec, scheme.Scheme)
+		require.NoError(t, err)
+		ms := testMachine("master1", "master")
+		ms.Spec.ProviderSpec.Value = rawProviderSpec
+		return ms
+	}
+	cases := []struct {
+		name           string
+		masterMachine  *machineapi.Machine
+		remoteExisting []runtime.Object
+		expectedAMIID  string
+		expectError    bool
+	}{
+		{
+			name:          "valid master machine",
+			masterMachine: testMachine("master1", "master"),
+			expectedAMIID: testAMI,
+		},
+		{
+			name: "invalid master machine",
+			masterMachine: func() *machineapi.Machine {
+				ms := testMachine("master1", "master")
+				ms.Spec.ProviderSpec.Value = nil
+				return ms
+			}(),
+			expectError: true,
+		},
+		{
+			name:          "master machine without AMI resolved from boot images",
+			masterMachine: masterMachineWithoutAMI(),
+			remoteExisting: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: bootImagesConfigMapNamespace,
+						Name:      bootImagesConfigMapName,
+					},
+					Data: map[string]string{"stream": testBootImagesStream},
+				},
+			},
+			expectedAMIID: "ami-bootimages",
+		},
+		{
+			name:          "master machine without AMI and no boot images",
+			masterMachine: masterMachineWithoutAMI(),
+			expectError:   true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			machineapi.SchemeBuilder.AddToScheme(scheme)
+			awsprovider.SchemeBuilder.AddToScheme(scheme)
+			corev1.AddToScheme(scheme)
+			remoteClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(tc.remoteExisting...).Build()
+			actualAMIID, actualErr := getAWSAMIID(tc.masterMachine, remoteClient, "us-east-1", scheme, log.StandardLogger())
+			if tc.expectError {
+				assert.Error(t, actualErr, "expected an error")
+			} else {
+				if assert.NoError(t, actualErr, "unexpected error") {
+					assert.Equal(t, tc.expectedAMIID, actualAMIID, "unexpected AMI ID")
+				}
+			}
+		})
+	}
+}
+
+func Test_masterMachineRegion(t *testing.T) {
+	cases := []struct {
+		name           string
+		masterMachine  *machineapi.Machine
+		expectedRegion string
+	}{
+		{
+			name: "master machine with region",
+			masterMachine: func() *machineapi.Machine {
+				providerSpec := testAWSProviderSpec()
+				providerSpec.Placement.Region = "us-east-1"
+				rawProviderSpec, err := encodeAWSMachineProviderSpec(providerSpec, scheme.Scheme)
+				require.NoError(t, err)
+				ms := testMachine("master1", "master")
+				ms.Spec.ProviderSpec.Value = rawProviderSpec
+				return ms
+			}(),
+			expectedRegion: "us-east-1",
+		},
+		{
+			name:          "master machine without region",
+			masterMachine: testMachine("master1", "master"),
+		},
+		{
+			name: "invalid master machine",
+			masterMachine: func() *machineapi.Machine {
+				ms := testMachine("master1", "master")
+				ms.Spec.ProviderSpec.Value = nil
+				return ms
+			}(),
+		},
+		{
+			name: "no master machine",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			awsprovider.SchemeBuilder.AddToScheme(scheme)
+			region := masterMachineRegion(tc.masterMachine, scheme, log.StandardLogger())
+			assert.Equal(t, tc.expectedRegion, region, "unexpected region")
+		})
+	}
+}
+
+// testStream is RHCOS stream metadata with the AMI ami-stream in us-east-1.
+const testStream = `{
+  "stream": "rhcos-4.9",
+  "architectures": {
+    "x86_64": {
+      "images": {
+        "aws": {
+          "regions": {
+            "us-east-1": {"release": "49.84.202109241334-0", "image": "ami-stream"}
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestResolveStreamAMIID(t *testing.T) {
+	cases := []struct {
+		name            string
+		configMapName   string
+		configMap       *corev1.ConfigMap
+		expectedAMIID   string
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "no stream referenced",
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "StreamNotReferenced",
+		},
+		{
+			name:           "AMI resolved",
+			configMapName:  "bootimages",
+			configMap:      testStreamConfigMap("bootimages", map[string]string{"stream": testStream}),
+			expectedAMIID:  "ami-stream",
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "AMIResolved",
+		},
+		{
+			name:           "missing config map",
+			configMapName:  "bootimages",
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "StreamLookupFailed",
+		},
+		{
+			name:            "missing stream key",
+			configMapName:   "bootimages",
+			configMap:       testStreamConfigMap("bootimages", map[string]string{"other": testStream}),
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "StreamLookupFailed",
+			expectedMessage: "RHCOS stream ConfigMap bootimages has no stream key",
+		},
+		{
+			name:           "malformed stream",
+			configMapName:  "bootimages",
+			configMap:      testStreamConfigMap("bootimages", map[string]string{"stream": "{"}),
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "StreamLookupFailed",
+		},
+		{
+			name:            "region not in stream",
+			configMapName:   "bootimages",
+			configMap:       testStreamConfigMap("bootimages", map[string]string{"stream": strings.ReplaceAll(testStream, "us-east-1", "us-west-2")}),
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "StreamLookupFailed",
+			expectedMessage: "could not find AMI in RHCOS stream metadata in ConfigMap bootimages: rhcos-4.9/x86_64: No AWS images in region us-east-1",
+		},
+	}
+	for _, tc := range cases {
+		apis.AddToScheme(scheme.Scheme)
+		t.Run(tc.name, func(t *testing.T) {
+			existing := []runtime.Object{testMachinePool()}
+			if tc.configMap != nil {
+				existing = append(existing, tc.configMap)
+			}
+			fakeClient := fake.NewFakeClient(existing...)
+			pool := &hivev1.MachinePool{}
+			err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: testMachinePool().Name}, pool)
+			require.NoError(t, err)
+			if tc.configMapName != "" {
+				pool.Annotations = map[string]string{hivev1.MachinePoolRHCOSStreamConfigMapAnnotation: tc.configMapName}
+			}
+
+			amiID, err := resolveStreamAMIID(fakeClient, pool, "us-east-1", log.StandardLogger())
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, tc.expectedAMIID, amiID, "unexpected AMI ID")
+
+			err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: testMachinePool().Name}, pool)
+			require.NoError(t, err)
+			cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.AMIResolutionFailedMachinePoolCondition)
+			if assert.NotNil(t, cond, "missing AMIResolutionFailed condition") {
+				assert.Equal(t, tc.expectedStatus, cond.Status, "unexpected condition status")
+				assert.Equal(t, tc.expectedReason, cond.Reason, "unexpected condition reason")
+				if tc.expectedMessage != "" {
+					assert.Equal(t, tc.expectedMessage, cond.Message, "unexpected condition message")
+				}
+			}
+		})
+	}
+}
+
+func Test_describeSubnets(t *testing.T) {
+	ids := make([]string, maxDescribeSubnetIDs+50)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("subnet-%d", i)
+	}
+	subnets := func(ids []string) []*ec2.Subnet {
+		subnets := make([]*ec2.Subnet, len(ids))
+		for i, id := range ids {
+			subnets[i] = &ec2.Subnet{SubnetId: aws.String(id)}
+		}
+		return subnets
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client := mockaws.NewMockClient(mockCtrl)
+	firstBatch := aws.StringSlice(ids[:maxDescribeSubnetIDs])
+	secondBatch := aws.StringSlice(ids[maxDescribeSubnetIDs:])
+	gomock.InOrder(
+		client.EXPECT().DescribeSubnets(&ec2.DescribeSubnetsInput{SubnetIds: firstBatch}).
+			Return(&ec2.DescribeSubnetsOutput{Subnets: subnets(ids[:100]), NextToken: aws.String("page-2")}, nil),
+		client.EXPECT().DescribeSubnets(&ec2.DescribeSubnetsInput{SubnetIds: firstBatch, NextToken: aws.String("page-2")}).
+			Return(&ec2.DescribeSubnetsOutput{Subnets: subnets(ids[100:maxDescribeSubnetIDs])}, nil),
+		client.EXPECT().DescribeSubnets(&ec2.DescribeSubnetsInput{SubnetIds: secondBatch}).
+			Return(&ec2.DescribeSubnetsOutput{Subnets: subnets(ids[maxDescribeSubnetIDs:])}, nil),
+	)
+
+	actual, err := describeSubnets(client, ids)
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, subnets(ids), actual, "unexpected subnets")
+}
+
+func Test_getAWSAMISources(t *testing.T) {
+	cases := []struct {
+		name            string
+		value           string
+		expectErr       bool
+		expectedSources []hivev1.BootImageSource
+	}{
+		{
+			name:            "default",
+			expectedSources: defaultAWSAMISources,
+		},
+		{
+			name:  "custom order",
+			value: "RHCOSStream, Cluster",
+			expectedSources: []hivev1.BootImageSource{
+				hivev1.RHCOSStreamBootImageSource,
+				hivev1.ClusterBootImageSource,
+			},
+		},
+		{
+			name:      "unknown source",
+			value:     "Spec,Cluster",
+			expectErr: true,
+		},
+		{
+			name:      "duplicate source",
+			value:     "Cluster,Cluster",
+			expectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				os.Setenv(constants.MachinePoolAWSAMISourcesEnvVar, tc.value)
+				defer os.Unsetenv(constants.MachinePoolAWSAMISourcesEnvVar)
+			}
+
+			sources, err := getAWSAMISources()
+			if tc.expectErr {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, tc.expectedSources, sources, "unexpected sources")
+		})
+	}
+}
+
+func TestResolveAMIID(t *testing.T) {
+	cases := []struct {
+		name           string
+		bootImage      string
+		override       string
+		stream         bool
+		masterMachine  *machineapi.Machine
+		sources        []hivev1.BootImageSource
+		expectedAMIID  string
+		expectedSource hivev1.BootImageSource
+		expectError    bool
+	}{
+		{
+			name:           "boot image of the pool",
+			bootImage:      "ami-spec",
+			override:       "ami-override",
+			expectedAMIID:  "ami-spec",
+			expectedSource: hivev1.SpecBootImageSource,
+		},
+		{
+			name:           "default order with override",
+			override:       "ami-override",
+			stream:         true,
+			masterMachine:  testMachine("master1", "master"),
+			expectedAMIID:  "ami-override",
+			expectedSource: hivev1.ImageIDOverrideBootImageSource,
+		},
+		{
+			name:           "default order with stream",
+			stream:         true,
+			masterMachine:  testMachine("master1", "master"),
+			expectedAMIID:  "ami-stream",
+			expectedSource: hivev1.RHCOSStreamBootImageSource,
+		},
+		{
+			name:           "default order without override or stream",
+			masterMachine:  testMachine("master1", "master"),
+			expectedAMIID:  testAMI,
+			expectedSource: hivev1.ClusterBootImageSource,
+		},
+		{
+			name:           "cluster first",
+			override:       "ami-override",
+			masterMachine:  testMachine("master1", "master"),
+			sources:        []hivev1.BootImageSource{hivev1.ClusterBootImageSource, hivev1.ImageIDOverrideBootImageSource},
+			expectedAMIID:  testAMI,
+			expectedSource: hivev1.ClusterBootImageSource,
+		},
+		{
+			name: "cluster error falls back to later source",
+			masterMachine: func() *machineapi.Machine {
+				ms := testMachine("master1", "master")
+				ms.Spec.ProviderSpec.Value = nil
+				return ms
+			}(),
+			stream:         true,
+			sources:        []hivev1.BootImageSource{hivev1.ClusterBootImageSource, hivev1.RHCOSStreamBootImageSource},
+			expectedAMIID:  "ami-stream",
+			expectedSource: hivev1.RHCOSStreamBootImageSource,
+		},
+		{
+			name:        "no source provides an AMI",
+			override:    "ami-override",
+			sources:     []hivev1.BootImageSource{hivev1.RHCOSStreamBootImageSource},
+			expectError: true,
+		},
+	}
+	for _, tc := range cases {
+		apis.AddToScheme(scheme.Scheme)
+		t.Run(tc.name, func(t *testing.T) {
+			existing := []runtime.Object{
+				testMachinePool(),
+				testStreamConfigMap("bootimages", map[string]string{"stream": testStream}),
+			}
+			fakeClient := fake.NewFakeClient(existing...)
+			pool := &hivev1.MachinePool{}
+			err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: testMachinePool().Name}, pool)
+			require.NoError(t, err)
+			pool.Spec.BootImage = tc.bootImage
+			pool.Annotations = map[string]string{}
+			if tc.override != "" {
+				pool.Annotations[hivev1.MachinePoolImageIDOverrideAnnotation] = tc.override
+			}
+			if tc.stream {
+				pool.Annotations[hivev1.MachinePoolRHCOSStreamConfigMapAnnotation] = "bootimages"
+			}
+
+			awsScheme := runtime.NewScheme()
+			awsprovider.SchemeBuilder.AddToScheme(awsScheme)
+			amiID, source, err := resolveAMIID(fakeClient, pool, tc.masterMachine, fakeClient, "us-east-1", tc.sources, awsScheme, log.StandardLogger())
+			if tc.expectError {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, tc.expectedAMIID, amiID, "unexpected AMI ID")
+			assert.Equal(t, tc.expectedSource, source, "unexpected AMI source")
+		})
+	}
+}
+
+func testStreamConfigMap(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      name,
+		},
+		Data: data,
+	}
+}
+
+func TestResolveAWSPoolConfiguration(t *testing.T) {
+	cases := []struct {
+		name            string
+		region          string
+		partition       string
+		actuatorRegion  string
+		zoneImageIDs    string
+		zoneStates      string
+		spotMaxPrice    string
+		marketType      awshivev1.MarketType
+		profileARN      string
+		kmsKey          string
+		deviceKMSKeys   []string
+		imageIDOverride string
+		accountErr      error
+		mockAWSClient   func(*mockaws.MockClient)
+		expectError     bool
+		// expectedReason is the reason of the expected validation error, if any.
+		expectedReason     string
+		expectedZoneAMIIDs map[string]string
+		expectedZoneStates []string
+		expectedKMSKeyARN  string
+		// expectedDeviceKMSKeyARNs are the expected KMS key ARNs of the additional block devices.
+		expectedDeviceKMSKeyARNs []string
+	}{
+		{
+			name:   "no partition",
+			region: testRegion,
+		},
+		{
+			name:      "commercial region",
+			region:    "us-east-1",
+			partition: "aws",
+		},
+		{
+			name:      "GovCloud region",
+			region:    "us-gov-west-1",
+			partition: "aws-us-gov",
+		},
+		{
+			name:      "isolated region",
+			region:    "us-iso-east-1",
+			partition: "aws-iso",
+		},
+		{
+			name:           "region not in partition",
+			region:         "us-east-1",
+			partition:      "aws-us-gov",
+			expectError:    true,
+			expectedReason: "RegionPartitionMismatch",
+		},
+		{
+			name:           "unknown partition",
+			region:         "us-gov-west-1",
+			partition:      "aws-unknown",
+			expectError:    true,
+			expectedReason: "RegionPartitionMismatch",
+		},
+		{
+			name:           "region of the actuator matches",
+			region:         "us-east-1",
+			actuatorRegion: "us-east-1",
+		},
+		{
+			name:           "region of the actuator does not match",
+			region:         "us-east-1",
+			actuatorRegion: "us-west-2",
+			expectError:    true,
+			expectedReason: "RegionMismatch",
+		},
+		{
+			name:               "zone image ID overrides",
+			region:             testRegion,
+			zoneImageIDs:       "zone1=ami-zone1, zone2=ami-zone2",
+			expectedZoneAMIIDs: map[string]string{"zone1": "ami-zone1", "zone2": "ami-zone2"},
+		},
+		{
+			name:           "invalid zone image ID overrides",
+			region:         testRegion,
+			zoneImageIDs:   "zone1",
+			expectError:    true,
+			expectedReason: "InvalidZoneImageIDOverrides",
+		},
+		{
+			name:               "availability zone states",
+			region:             testRegion,
+			zoneStates:         "available, information",
+			expectedZoneStates: []string{"available", "information"},
+		},
+		{
+			name:           "invalid availability zone states",
+			region:         testRegion,
+			zoneStates:     "available,broken",
+			expectError:    true,
+			expectedReason: "InvalidAvailabilityZoneStates",
+		},
+		{
+			name:         "valid spot max price",
+			region:       testRegion,
+			spotMaxPrice: "0.25",
+		},
+		{
+			name:           "invalid spot max price",
+			region:         testRegion,
+			spotMaxPrice:   "cheap",
+			expectError:    true,
+			expectedReason: "InvalidSpotMaxPrice",
+		},
+		{
+			name:         "spot market options with spot market type",
+			region:       testRegion,
+			spotMaxPrice: "0.05",
+			marketType:   awshivev1.SpotMarketType,
+		},
+		{
+			name:           "spot market options with on-demand market type",
+			region:         testRegion,
+			spotMaxPrice:   "0.05",
+			marketType:     awshivev1.OnDemandMarketType,
+			expectError:    true,
+			expectedReason: "InconsistentMarketType",
+		},
+		{
+			name:       "valid IAM instance profile ARN",
+			region:     testRegion,
+			profileARN: "arn:aws:iam::123456789012:instance-profile/workers",
+		},
+		{
+			name:           "malformed IAM instance profile ARN",
+			region:         testRegion,
+			profileARN:     "workers",
+			expectError:    true,
+			expectedReason: "InvalidIAMInstanceProfileARN",
+		},
+		{
+			name:           "IAM role ARN as IAM instance profile ARN",
+			region:         testRegion,
+			profileARN:     "arn:aws:iam::123456789012:role/workers",
+			expectError:    true,
+			expectedReason: "InvalidIAMInstanceProfileARN",
+		},
+		{
+			name:              "KMS key ARN",
+			region:            "us-east-1",
+			kmsKey:            "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+			expectedKMSKeyARN: "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+		},
+		{
+			name:   "KMS key alias",
+			region: "us-east-1",
+			kmsKey: "alias/ebs-worker",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeKey(client, "arn:aws:kms:us-east-1:123456789012:alias/ebs-worker", "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab")
+			},
+			expectedKMSKeyARN: "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+		},
+		{
+			name:      "KMS key alias in GovCloud",
+			region:    "us-gov-west-1",
+			partition: "aws-us-gov",
+			kmsKey:    "alias/ebs-worker",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeKey(client, "arn:aws-us-gov:kms:us-gov-west-1:123456789012:alias/ebs-worker", "arn:aws-us-gov:kms:us-gov-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab")
+			},
+			expectedKMSKeyARN: "arn:aws-us-gov:kms:us-gov-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+		},
+		{
+			name:          "KMS key aliases of root volume and additional block devices",
+			region:        "us-east-1",
+			kmsKey:        "alias/ebs-worker",
+			deviceKMSKeys: []string{"alias/ebs-data", "arn:aws:kms:us-east-1:123456789012:key/data", ""},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeKey(client, "arn:aws:kms:us-east-1:123456789012:alias/ebs-worker", "arn:aws:kms:us-east-1:123456789012:key/worker")
+				expectDescribeKey(client, "arn:aws:kms:us-east-1:123456789012:alias/ebs-data", "arn:aws:kms:us-east-1:123456789012:key/data")
+			},
+			expectedKMSKeyARN: "arn:aws:kms:us-east-1:123456789012:key/worker",
+			expectedDeviceKMSKeyARNs: []string{
+				"arn:aws:kms:us-east-1:123456789012:key/data",
+				"arn:aws:kms:us-east-1:123456789012:key/data",
+				"",
+			},
+		},
+		{
+			name:          "KMS key alias of additional block device not found",
+			region:        "us-east-1",
+			deviceKMSKeys: []string{"alias/ebs-data"},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeKey(gomock.Any()).Return(nil, awserr.New(awsclient.KMSNotFoundExceptionCode, "Alias not found", nil))
+			},
+			expectError:    true,
+			expectedReason: "KMSKeyAliasNotFound",
+		},
+		{
+			name:           "invalid KMS key alias",
+			region:         "us-east-1",
+			kmsKey:         "alias/ebs worker",
+			expectError:    true,
+			expectedReason: "KMSKeyAliasNotResolved",
+		},
+		{
+			name:           "KMS key alias in unknown region",
+			region:         testRegion,
+			kmsKey:         "alias/ebs-worker",
+			expectError:    true,
+			expectedReason: "KMSKeyAliasNotResolved",
+		},
+		{
+			name:   "KMS key alias not found",
+			region: "us-east-1",
+			kmsKey: "alias/ebs-worker",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeKey(gomock.Any()).Return(nil, awserr.New(awsclient.KMSNotFoundExceptionCode, "Alias not found", nil))
+			},
+			expectError:    true,
+			expectedReason: "KMSKeyAliasNotFound",
+		},
+		{
+			name:   "KMS key alias not described",
+			region: "us-east-1",
+			kmsKey: "alias/ebs-worker",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeKey(gomock.Any()).Return(nil, awserr.New("KMSInternalException", "internal error", nil))
+			},
+			expectError: true,
+		},
+		{
+			name:        "KMS key alias without account",
+			region:      "us-east-1",
+			kmsKey:      "alias/ebs-worker",
+			accountErr:  errors.New("access denied"),
+			expectError: true,
+		},
+		{
+			name:            "AMI override available",
+			region:          "us-east-1",
+			imageIDOverride: "ami-override",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeImages(client, "ami-override", ec2.ImageStateAvailable)
+			},
+		},
+		{
+			name:            "AMI override not found",
+			region:          "us-east-1",
+			imageIDOverride: "ami-override",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeImages(gomock.Any()).Return(nil, awserr.New("InvalidAMIID.NotFound", "The image id '[ami-override]' does not exist", nil))
+			},
+			expectError:    true,
+			expectedReason: "ImageIDOverrideNotFound",
+		},
+		{
+			name:            "malformed AMI override",
+			region:          "us-east-1",
+			imageIDOverride: "ami override",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeImages(gomock.Any()).Return(nil, awserr.New("InvalidAMIID.Malformed", "Invalid id", nil))
+			},
+			expectError:    true,
+			expectedReason: "ImageIDOverrideNotFound",
+		},
+		{
+			name:            "AMI override deregistered",
+			region:          "us-east-1",
+			imageIDOverride: "ami-override",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeImages(client, "ami-override", ec2.ImageStateDeregistered)
+			},
+			expectError:    true,
+			expectedReason: "ImageIDOverrideUnavailable",
+		},
+		{
+			name:            "AMI override not described",
+			region:          "us-east-1",
+			imageIDOverride: "ami-override",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeImages(gomock.Any()).Return(nil, awserr.New("UnauthorizedOperation", "not authorized", nil))
+			},
+			expectError: true,
+		},
+		{
+			name:            "invalid IAM instance profile ARN and AMI override not found",
+			region:          "us-east-1",
+			imageIDOverride: "ami-override",
+			profileARN:      "workers",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeImages(gomock.Any()).Return(nil, awserr.New("InvalidAMIID.NotFound", "not found", nil))
+			},
+			expectError:    true,
+			expectedReason: "MultipleInvalidSettings",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := testMachinePool()
+			platform := &awshivev1.Platform{Region: tc.region, Partition: tc.partition}
+			pool.Annotations = map[string]string{}
+			if tc.zoneImageIDs != "" {
+				pool.Annotations[hivev1.MachinePoolZoneImageIDOverridesAnnotation] = tc.zoneImageIDs
+			}
+			if tc.zoneStates != "" {
+				pool.Annotations[hivev1.MachinePoolAvailabilityZoneStatesAnnotation] = tc.zoneStates
+			}
+			if tc.spotMaxPrice != "" {
+				pool.Spec.Platform.AWS.SpotMarketOptions = &awshivev1.SpotMarketOptions{MaxPrice: aws.String(tc.spotMaxPrice)}
+			}
+			pool.Spec.Platform.AWS.MarketType = tc.marketType
+			pool.Spec.Platform.AWS.IAMInstanceProfileARN = tc.profileARN
+			pool.Spec.Platform.AWS.EC2RootVolume.KMSKeyARN = tc.kmsKey
+			for i, kmsKey := range tc.deviceKMSKeys {
+				pool.Spec.Platform.AWS.AdditionalBlockDevices = append(pool.Spec.Platform.AWS.AdditionalBlockDevices, awshivev1.BlockDevice{
+					DeviceName: fmt.Sprintf("/dev/xvd%c", 'b'+i),
+					Size:       100,
+					Type:       "gp3",
+					KMSKeyARN:  kmsKey,
+				})
+			}
+			getAccount := func() (string, error) {
+				return "123456789012", tc.accountErr
+			}
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			if tc.mockAWSClient != nil {
+				tc.mockAWSClient(awsClient)
+			}
+			region := tc.actuatorRegion
+			if region == "" {
+				region = tc.region
+			}
+			config, err := resolveAWSPoolConfiguration(awsClient, getAccount, nil, nil, pool, platform, region, tc.imageIDOverride, log.StandardLogger())
+			if !tc.expectError {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, tc.expectedZoneAMIIDs, config.zoneAMIIDs, "unexpected zone AMI IDs")
+				expectedZoneStates := tc.expectedZoneStates
+				if expectedZoneStates == nil {
+					expectedZoneStates = []string{"available"}
+				}
+				assert.Equal(t, expectedZoneStates, config.zoneStates, "unexpected zone states")
+				assert.Equal(t, tc.expectedKMSKeyARN, config.kmsKeyARN, "unexpected KMS key ARN")
+				assert.Equal(t, tc.expectedDeviceKMSKeyARNs, config.deviceKMSKeyARNs, "unexpected additional block device KMS key ARNs")
+				return
+			}
+			require.Error(t, err, "expected an error")
+			var validationErr *ValidationError
+			if tc.expectedReason == "" {
+				assert.False(t, errors.As(err, &validationErr), "unexpected validation error")
+				return
+			}
+			require.True(t, errors.As(err, &validationErr), "expected a validation error")
+			assert.Equal(t, hivev1.InvalidConfigurationMachinePoolCondition, validationErr.Type, "unexpected validation error type")
+			assert.Equal(t, tc.expectedReason, validationErr.Reason, "unexpected validation error reason")
+		})
+	}
+}
+
+func TestNewAWSActuatorInvalidConfiguration(t *testing.T) {
+	apis.AddToScheme(scheme.Scheme)
+	fakeClient := fake.NewFakeClient(testMachinePool())
+	pool := &hivev1.MachinePool{}
+	err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: testMachinePool().Name}, pool)
+	require.NoError(t, err)
+
+	// The AWS client is not created for a region other than that of the ClusterDeployment.
+	platform := &awshivev1.Platform{Region: testRegion}
+	_, err = NewAWSActuator(context.TODO(), fakeClient, awsclient.CredentialsSource{}, platform, "other-region", pool, testMachine("master1", "master"), fake.NewFakeClient(), nil, awsActuatorOptions{}, scheme.Scheme, log.StandardLogger())
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr), "expected a validation error")
+	assert.Equal(t, "RegionMismatch", validationErr.Reason, "unexpected validation error reason")
+
+	persisted := &hivev1.MachinePool{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: testMachinePool().Name}, persisted)
+	require.NoError(t, err)
+	cond := controllerutils.FindMachinePoolCondition(persisted.Status.Conditions, hivev1.InvalidConfigurationMachinePoolCondition)
+	if assert.NotNil(t, cond, "missing InvalidConfiguration condition") {
+		assert.Equal(t, corev1.ConditionTrue, cond.Status, "unexpected condition status")
+		assert.Equal(t, "RegionMismatch", cond.Reason, "unexpected condition reason")
+	}
+}
+
+func Test_parseSpotMaxPrice(t *testing.T) {
+	cases := []struct {
+		maxPrice  string
+		expected  float64
+		expectErr bool
+	}{
+		{maxPrice: "0.5", expected: 0.5},
+		{maxPrice: ".5", expected: 0.5},
+		{maxPrice: "2", expected: 2},
+		{maxPrice: "2.", expected: 2},
+		{maxPrice: "", expectErr: true},
+		{maxPrice: "0", expectErr: true},
+		{maxPrice: "0.000", expectErr: true},
+		{maxPrice: "-0.5", expectErr: true},
+		{maxPrice: "1e3", expectErr: true},
+		{maxPrice: "$0.50", expectErr: true},
+		{maxPrice: "NaN", expectErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.maxPrice, func(t *testing.T) {
+			actual, err := parseSpotMaxPrice(tc.maxPrice)
+			if tc.expectErr {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, tc.expected, actual, "unexpected price")
+		})
+	}
+}
+
+func Test_poolSpotMarketOptions(t *testing.T) {
+	cases := []struct {
+		name       string
+		marketType awshivev1.MarketType
+		options    *awshivev1.SpotMarketOptions
+		expected   *awshivev1.SpotMarketOptions
+	}{
+		{
+			name: "no market type or options",
+		},
+		{
+			name:       "on-demand market type",
+			marketType: awshivev1.OnDemandMarketType,
+		},
+		{
+			name:     "options without market type",
+			options:  &awshivev1.SpotMarketOptions{MaxPrice: aws.String("0.05")},
+			expected: &awshivev1.SpotMarketOptions{MaxPrice: aws.String("0.05")},
+		},
+		{
+			name:       "spot market type without options",
+			marketType: awshivev1.SpotMarketType,
+			expected:   &awshivev1.SpotMarketOptions{},
+		},
+		{
+			name:       "spot market type with options",
+			marketType: awshivev1.SpotMarketType,
+			options:    &awshivev1.SpotMarketOptions{MaxPrice: aws.String("0.05")},
+			expected:   &awshivev1.SpotMarketOptions{MaxPrice: aws.String("0.05")},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.MarketType = tc.marketType
+			pool.Spec.Platform.AWS.SpotMarketOptions = tc.options
+			assert.Equal(t, tc.expected, poolSpotMarketOptions(pool), "unexpected spot market options")
+		})
+	}
+}
+
+func Test_parseZoneAMIIDs(t *testing.T) {
+	cases := []struct {
+		name      string
+		value     string
+		expected  map[string]string
+		expectErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:     "single zone",
+			value:    "zone1=ami-1",
+			expected: map[string]string{"zone1": "ami-1"},
+		},
+		{
+			name:     "multiple zones",
+			value:    "zone1=ami-1, zone2=ami-2",
+			expected: map[string]string{"zone1": "ami-1", "zone2": "ami-2"},
+		},
+		{
+			name:      "missing image ID",
+			value:     "zone1=",
+			expectErr: true,
+		},
+		{
+			name:      "missing separator",
+			value:     "zone1=ami-1,zone2",
+			expectErr: true,
+		},
+		{
+			name:      "duplicate zone",
+			value:     "zone1=ami-1,zone1=ami-2",
+			expectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseZoneAMIIDs(tc.value)
+			if tc.expectErr {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, tc.expected, actual, "unexpected zone AMI IDs")
+		})
+	}
+}
+
+func Test_parseZoneStates(t *testing.T) {
+	cases := []struct {
+		name      string
+		value     string
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:     "empty",
+			expected: []string{"available"},
+		},
+		{
+			name:     "single state",
+			value:    "impaired",
+			expected: []string{"impaired"},
+		},
+		{
+			name:     "multiple states",
+			value:    "available, information",
+			expected: []string{"available", "information"},
+		},
+		{
+			name:      "unknown state",
+			value:     "available,broken",
+			expectErr: true,
+		},
+		{
+			name:      "empty state",
+			value:     "available,",
+			expectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseZoneStates(tc.value)
+			if tc.expectErr {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, tc.expected, actual, "unexpected zone states")
+		})
+	}
+}
+
+func TestValidateZoneInstanceTypes(t *testing.T) {
+	cases := []struct {
+		name           string
+		offerings      map[string]string
+		expectedReason string
+		expectErr      bool
+	}{
+		{
+			name:           "offered",
+			offerings:      map[string]string{"zone1": "m5.large", "zone2": "m6.large"},
+			expectedReason: "InstanceTypesOffered",
+		},
+		{
+			name:           "not offered",
+			offerings:      map[string]string{"zone1": "m5.large"},
+			expectedReason: "InstanceTypeNotOffered",
+			expectErr:      true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			mockDescribeInstanceTypeOfferings(awsClient, []string{"zone1", "zone2"}, []string{"m5.large", "m6.large"}, tc.offerings)
+			actuator := &AWSActuator{awsClient: awsClient}
+			pool := withZoneInstanceTypes(testMachinePool(), map[string]string{"zone1": "m5.large", "zone2": "m6.large"})
+
+			err := actuator.validateZoneInstanceTypes(pool, []string{"zone1", "zone2"}, log.StandardLogger())
+			if tc.expectErr {
+				var validationErr *ValidationError
+				if assert.True(t, errors.As(err, &validationErr), "expected a validation error") {
+					assert.Equal(t, hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition, validationErr.Type, "unexpected validation error type")
+					assert.Equal(t, tc.expectedReason, validationErr.Reason, "unexpected validation error reason")
+				}
+			} else {
+				assert.NoError(t, err, "unexpected error")
+			}
+			cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition)
+			if assert.NotNil(t, cond, "missing InstanceTypeNotOfferedInZones condition") {
+				assert.Equal(t, tc.expectedReason, cond.Reason, "unexpected condition reason")
+			}
+		})
+	}
+}
+
+func TestGetPrivateSubnetsByAvailabilityZone(t *testing.T) {
+	cases := []struct {
+		name                       string
+		subnetIDs                  []string
+		privateSubnetIDs           []string
+		existingConditions         []hivev1.MachinePoolCondition
+		describeSubnetsOutput      []*ec2.Subnet
+		describeSubnetsErr         error
+		routeTables                []*ec2.RouteTable
+		describeRouteTablesErr     error
+		expectedSubnetsByZone      map[string]string
+		expectedErr                string
+		expectedCondition          *hivev1.MachinePoolCondition
+		expectNoDescribeRouteTable bool
+	}{
+		{
+			name:      "single private subnet per zone",
+			subnetIDs: []string{"subnet-zone1", "subnet-zone2", "subnet-zone3"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("subnet-zone2", "zone2", "vpc-1", false),
+				testSubnet("subnet-zone3", "zone3", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("subnet-zone2", false),
+				constructRouteTable("subnet-zone3", false),
+			},
+			expectedSubnetsByZone: map[string]string{
+				"zone1": "subnet-zone1",
+				"zone2": "subnet-zone2",
+				"zone3": "subnet-zone3",
+			},
+		},
+		{
+			name:      "private and public subnet per zone",
+			subnetIDs: []string{"subnet-zone1", "subnet-zone2", "pubSubnet-zone1", "pubSubnet-zone2"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("subnet-zone2", "zone2", "vpc-1", false),
+				testSubnet("pubSubnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("pubSubnet-zone2", "zone2", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("subnet-zone2", false),
+				constructRouteTable("pubSubnet-zone1", true),
+				constructRouteTable("pubSubnet-zone2", true),
+			},
+			expectedSubnetsByZone: map[string]string{
+				"zone1": "subnet-zone1",
+				"zone2": "subnet-zone2",
+			},
+		},
+		{
+			name:      "public subnet identified by elb tag",
+			subnetIDs: []string{"subnet-zone1", "pubSubnet-zone1"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("pubSubnet-zone1", "zone1", "vpc-1", true),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("pubSubnet-zone1", false),
+			},
+			expectedSubnetsByZone: map[string]string{
+				"zone1": "subnet-zone1",
+			},
+		},
+		{
+			name:      "public subnet using main route table",
+			subnetIDs: []string{"subnet-zone1", "pubSubnet-zone1"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("pubSubnet-zone1", "zone1", "vpc-1", false),
+			},
+			routeTables: constructRouteTables(map[string]bool{
+				"subnet-zone1": false,
+			}),
+			expectedSubnetsByZone: map[string]string{
+				"zone1": "subnet-zone1",
+			},
+		},
+		{
+			name:             "subnet listed as private despite internet gateway route",
+			subnetIDs:        []string{"subnet-zone1", "subnet-zone2"},
+			privateSubnetIDs: []string{"subnet-zone2"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("subnet-zone2", "zone2", "vpc-1", true),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("subnet-zone2", true),
+			},
+			expectedSubnetsByZone: map[string]string{
+				"zone1": "subnet-zone1",
+				"zone2": "subnet-zone2",
+			},
+		},
+		{
+			name:             "subnet listed as private without route table",
+			subnetIDs:        []string{"subnet-zone1", "subnet-zone2"},
+			privateSubnetIDs: []string{"subnet-zone2"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("subnet-zone2", "zone2", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+			},
+			expectedSubnetsByZone: map[string]string{
+				"zone1": "subnet-zone1",
+				"zone2": "subnet-zone2",
+			},
+		},
+		{
+			name:      "conflicting private subnets for zone",
+			subnetIDs: []string{"subnet-zone1", "subnet-zone1b", "subnet-zone2"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("subnet-zone1b", "zone1", "vpc-1", false),
+				testSubnet("subnet-zone2", "zone2", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("subnet-zone1b", false),
+				constructRouteTable("subnet-zone2", false),
+			},
+			expectedErr: "more than one subnet found for some availability zones, conflicting subnets: subnet-zone1, subnet-zone1b",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "MoreThanOneSubnetForZone",
+				Message: "more than one subnet found for some availability zones, conflicting subnets: subnet-zone1, subnet-zone1b",
+			},
+		},
+		{
+			name:      "conflicting public subnets for zone",
+			subnetIDs: []string{"subnet-zone1", "pubSubnet-zone1", "pubSubnet-zone1b"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("pubSubnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("pubSubnet-zone1b", "zone1", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("pubSubnet-zone1", true),
+				constructRouteTable("pubSubnet-zone1b", true),
+			},
+			expectedErr: "more than one subnet found for some availability zones, conflicting subnets: pubSubnet-zone1, pubSubnet-zone1b",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "MoreThanOneSubnetForZone",
+			},
+		},
+		{
+			name:      "insufficient public subnets",
+			subnetIDs: []string{"subnet-zone1", "subnet-zone2", "pubSubnet-zone1"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("subnet-zone2", "zone2", "vpc-1", false),
+				testSubnet("pubSubnet-zone1", "zone1", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("subnet-zone2", false),
+				constructRouteTable("pubSubnet-zone1", true),
+			},
+			expectedErr: "insufficient public subnets for availability zones and private subnets",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InsufficientPublicSubnets",
+			},
+		},
+		{
+			name:      "insufficient public subnets with existing condition",
+			subnetIDs: []string{"subnet-zone1", "subnet-zone2", "pubSubnet-zone1"},
+			existingConditions: []hivev1.MachinePoolCondition{{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "InsufficientPublicSubnets",
+				Message: "Public subnet does not exist for each zone with a private subnet",
+			}},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("subnet-zone2", "zone2", "vpc-1", false),
+				testSubnet("pubSubnet-zone1", "zone1", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("subnet-zone2", false),
+				constructRouteTable("pubSubnet-zone1", true),
+			},
+			expectedErr: "insufficient public subnets for availability zones and private subnets",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InsufficientPublicSubnets",
+			},
+		},
+		{
+			name:      "dual-stack private subnet with egress-only internet gateway",
+			subnetIDs: []string{"subnet-zone1", "pubSubnet-zone1"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("pubSubnet-zone1", "zone1", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				{
+					Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String("subnet-zone1")}},
+					Routes: []*ec2.Route{
+						{
+							DestinationCidrBlock: aws.String("0.0.0.0/0"),
+							NatGatewayId:         aws.String("nat-zone1"),
+						},
+						{
+							DestinationIpv6CidrBlock:    aws.String("::/0"),
+							EgressOnlyInternetGatewayId: aws.String("eigw-1"),
+						},
+					},
+				},
+				constructRouteTable("pubSubnet-zone1", true),
+			},
+			expectedSubnetsByZone: map[string]string{
+				"zone1": "subnet-zone1",
+			},
+		},
+		{
+			name:      "private subnet in local zone",
+			subnetIDs: []string{"subnet-zone1", "pubSubnet-zone1", "subnet-lz1"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "us-east-1a", "vpc-1", false),
+				testSubnet("pubSubnet-zone1", "us-east-1a", "vpc-1", false),
+				testSubnet("subnet-lz1", "us-east-1-bos-1a", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("pubSubnet-zone1", true),
+				constructRouteTable("subnet-lz1", false),
+			},
+			expectedSubnetsByZone: map[string]string{
+				"us-east-1a":       "subnet-zone1",
+				"us-east-1-bos-1a": "subnet-lz1",
+			},
+		},
+		{
+			name:      "private and public subnets in local zone",
+			subnetIDs: []string{"subnet-zone1", "pubSubnet-zone1", "subnet-lz1", "pubSubnet-lz1"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "us-east-1a", "vpc-1", false),
+				testSubnet("pubSubnet-zone1", "us-east-1a", "vpc-1", false),
+				testSubnet("subnet-lz1", "us-east-1-bos-1a", "vpc-1", false),
+				testSubnet("pubSubnet-lz1", "us-east-1-bos-1a", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("pubSubnet-zone1", true),
+				constructRouteTable("subnet-lz1", false),
+				constructRouteTable("pubSubnet-lz1", true),
+			},
+			expectedSubnetsByZone: map[string]string{
+				"us-east-1a":       "subnet-zone1",
+				"us-east-1-bos-1a": "subnet-lz1",
+			},
+		},
+		{
+			name:      "private and carrier subnets in wavelength zone",
+			subnetIDs: []string{"subnet-zone1", "pubSubnet-zone1", "subnet-wlz1", "pubSubnet-wlz1"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "us-east-1a", "vpc-1", false),
+				testSubnet("pubSubnet-zone1", "us-east-1a", "vpc-1", false),
+				testSubnet("subnet-wlz1", "us-east-1-wl1-bos-wlz-1", "vpc-1", false),
+				testSubnet("pubSubnet-wlz1", "us-east-1-wl1-bos-wlz-1", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("pubSubnet-zone1", true),
+				constructRouteTable("subnet-wlz1", false),
+				constructCarrierRouteTable("pubSubnet-wlz1"),
+			},
+			expectedSubnetsByZone: map[string]string{
+				"us-east-1a":              "subnet-zone1",
+				"us-east-1-wl1-bos-wlz-1": "subnet-wlz1",
+			},
+		},
+		{
+			name:      "only carrier subnet in wavelength zone",
+			subnetIDs: []string{"subnet-zone1", "pubSubnet-zone1", "pubSubnet-wlz1"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "us-east-1a", "vpc-1", false),
+				testSubnet("pubSubnet-zone1", "us-east-1a", "vpc-1", false),
+				testSubnet("pubSubnet-wlz1", "us-east-1-wl1-bos-wlz-1", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("pubSubnet-zone1", true),
+				constructCarrierRouteTable("pubSubnet-wlz1"),
+			},
+			expectedSubnetsByZone: map[string]string{
+				"us-east-1a":              "subnet-zone1",
+				"us-east-1-wl1-bos-wlz-1": "pubSubnet-wlz1",
+			},
+		},
+		{
+			name:      "conflicting private subnets in local zone",
+			subnetIDs: []string{"subnet-zone1", "subnet-lz1", "subnet-lz1b"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "us-east-1a", "vpc-1", false),
+				testSubnet("subnet-lz1", "us-east-1-bos-1a", "vpc-1", false),
+				testSubnet("subnet-lz1b", "us-east-1-bos-1a", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("subnet-lz1", false),
+				constructRouteTable("subnet-lz1b", false),
+			},
+			expectedErr: "more than one subnet found for some availability zones, conflicting subnets: subnet-lz1, subnet-lz1b",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "MoreThanOneSubnetForZone",
+			},
+		},
+		{
+			name:      "insufficient public subnets for region with local zone",
+			subnetIDs: []string{"subnet-zone1", "subnet-zone2", "pubSubnet-lz1", "subnet-lz1"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "us-east-1a", "vpc-1", false),
+				testSubnet("subnet-zone2", "us-east-1b", "vpc-1", false),
+				testSubnet("pubSubnet-lz1", "us-east-1-bos-1a", "vpc-1", false),
+				testSubnet("subnet-lz1", "us-east-1-bos-1a", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+				constructRouteTable("subnet-zone2", false),
+				constructRouteTable("pubSubnet-lz1", true),
+				constructRouteTable("subnet-lz1", false),
+			},
+			expectedErr: "insufficient public subnets for availability zones and private subnets",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InsufficientPublicSubnets",
+			},
+		},
+		{
+			name:      "subnets in multiple VPCs",
+			subnetIDs: []string{"subnet-zone1", "subnet-zone2", "subnet-zone3", "subnet-zone3b"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("subnet-zone2", "zone2", "vpc-2", false),
+				testSubnet("subnet-zone3", "zone3", "vpc-1", false),
+				testSubnet("subnet-zone3b", "zone3", "vpc-3", false),
+			},
+			expectNoDescribeRouteTable: true,
+			expectedErr:                "subnets must all belong to the same VPC: vpc-1: subnet-zone1, subnet-zone3; vpc-2: subnet-zone2; vpc-3: subnet-zone3b",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "MultipleVPCs",
+				Message: "subnets must all belong to the same VPC: vpc-1: subnet-zone1, subnet-zone3; vpc-2: subnet-zone2; vpc-3: subnet-zone3b",
+			},
+		},
+		{
+			name:                       "subnets not found",
+			subnetIDs:                  []string{"subnet-1", "subnet-2"},
+			describeSubnetsErr:         fmt.Errorf("InvalidSubnetID.NotFound: The subnet ID 'subnet-1,subnet-2' does not exist\tstatus code: 400, request id: ea8b3bb7-de56-405f-9345-e5690a3ea8b2"),
+			expectNoDescribeRouteTable: true,
+			expectedErr:                "The subnet ID 'subnet-1,subnet-2' does not exist",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "SubnetsNotFound",
+				Message: "The subnet ID 'subnet-1,subnet-2' does not exist",
+			},
+		},
+		{
+			name:                       "invalid subnet error without expected formatting",
+			subnetIDs:                  []string{"subnet-1"},
+			describeSubnetsErr:         fmt.Errorf("InvalidSubnets"),
+			expectNoDescribeRouteTable: true,
+			expectedErr:                "InvalidSubnets",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "SubnetsNotFound",
+				Message: "InvalidSubnets",
+			},
+		},
+		{
+			name:                       "describe subnets failure",
+			subnetIDs:                  []string{"subnet-1"},
+			describeSubnetsErr:         fmt.Errorf("RequestLimitExceeded"),
+			expectNoDescribeRouteTable: true,
+			expectedErr:                "RequestLimitExceeded",
+		},
+		{
+			name:                       "no subnets returned",
+			subnetIDs:                  []string{"subnet-1"},
+			expectNoDescribeRouteTable: true,
+			expectedErr:                "no subnets found",
+		},
+		{
+			name:      "subnet without vpc",
+			subnetIDs: []string{"subnet-zone1"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "", false),
+			},
+			expectNoDescribeRouteTable: true,
+			expectedErr:                "subnet-zone1 has no VPC",
+		},
+		{
+			name:      "describe route tables failure",
+			subnetIDs: []string{"subnet-zone1"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+			},
+			describeRouteTablesErr: fmt.Errorf("RequestLimitExceeded"),
+			expectedErr:            "error describing route tables: RequestLimitExceeded",
+		},
+		{
+			name:      "no route table for subnet",
+			subnetIDs: []string{"subnet-zone1", "subnet-zone2"},
+			describeSubnetsOutput: []*ec2.Subnet{
+				testSubnet("subnet-zone1", "zone1", "vpc-1", false),
+				testSubnet("subnet-zone2", "zone2", "vpc-1", false),
+			},
+			routeTables: []*ec2.RouteTable{
+				constructRouteTable("subnet-zone1", false),
+			},
+			expectedErr: "error describing route tables: could not locate routing table for subnet-zone2",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			apis.AddToScheme(scheme.Scheme)
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.Subnets = tc.subnetIDs
+			pool.Spec.Platform.AWS.PrivateSubnets = tc.privateSubnetIDs
+			if tc.existingConditions != nil {
+				pool.Status.Conditions = tc.existingConditions
+			}
+			fakeClient := fake.NewFakeClient(pool)
+			awsClient := mockaws.NewMockClient(mockCtrl)
+
+			idPointers := make([]*string, len(tc.subnetIDs))
+			for i, id := range tc.subnetIDs {
+				idPointers[i] = aws.String(id)
+			}
+			describeSubnetsOutput := &ec2.DescribeSubnetsOutput{Subnets: tc.describeSubnetsOutput}
+			if tc.describeSubnetsErr != nil {
+				describeSubnetsOutput = nil
+			}
+			awsClient.EXPECT().DescribeSubnets(&ec2.DescribeSubnetsInput{SubnetIds: idPointers}).
+				Return(describeSubnetsOutput, tc.describeSubnetsErr)
+			if !tc.expectNoDescribeRouteTable {
+				awsClient.EXPECT().DescribeRouteTables(&ec2.DescribeRouteTablesInput{
+					Filters: []*ec2.Filter{{
+						Name:   aws.String("vpc-id"),
+						Values: []*string{aws.String("vpc-1")},
+					}},
+				}).Return(&ec2.DescribeRouteTablesOutput{RouteTables: tc.routeTables}, tc.describeRouteTablesErr)
+			}
+
+			actuator := &AWSActuator{
+				client:    fakeClient,
+				awsClient: awsClient,
+				logger:    log.WithField("actuator", "awsactuator"),
+				region:    testRegion,
+				amiID:     testAMI,
+			}
+
+			subnetsByZone, _, err := actuator.getPrivateSubnetsByAvailabilityZone(pool)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr, "unexpected error")
+			} else if assert.NoError(t, err, "unexpected error") {
+				assert.Equal(t, tc.expectedSubnetsByZone, subnetsByZone, "unexpected subnets by availability zone")
+			}
+
+			cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.InvalidSubnetsMachinePoolCondition)
+			if tc.expectedCondition == nil {
+				if assert.NotNil(t, cond, "missing InvalidSubnets condition") {
+					assert.Equal(t, corev1.ConditionUnknown, cond.Status, "unexpected change to InvalidSubnets condition")
+				}
+				return
+			}
+			if assert.NotNil(t, cond, "missing InvalidSubnets condition") {
+				assert.Equal(t, tc.expectedCondition.Status, cond.Status, "condition found with unexpected status")
+				assert.Equal(t, tc.expectedCondition.Reason, cond.Reason, "condition found with unexpected reason")
+				if tc.expectedCondition.Message != "" {
+					assert.Equal(t, tc.expectedCondition.Message, cond.Message, "condition found with unexpected message")
+				}
+			}
+		})
+	}
+}
+
+func TestAWSActuatorZonesAndSubnets(t *testing.T) {
+	availabilityZone := func(name, id, state, optInStatus string) *ec2.AvailabilityZone {
+		return &ec2.AvailabilityZone{
+			ZoneName:    aws.String(name),
+			ZoneId:      aws.String(id),
+			RegionName:  aws.String(testRegion),
+			State:       aws.String(state),
+			OptInStatus: aws.String(optInStatus),
+		}
+	}
+	regionZones := []*ec2.AvailabilityZone{
+		availabilityZone("zone3", "tr-az3", "available", "opt-in-not-required"),
+		availabilityZone("zone1", "tr-az1", "available", "opt-in-not-required"),
+		availabilityZone("zone2", "tr-az2", "available", "opt-in-not-required"),
+		availabilityZone("zone4", "tr-az4", "impaired", "opt-in-not-required"),
+		availabilityZone("zone5", "tr-az5", "available", "not-opted-in"),
+		availabilityZone("us-east-1-nyc-1a", "use1-nyc1-az1", "available", "opted-in"),
+	}
+	routeTable := func(subnetID string, public bool) *ec2.RouteTable {
+		rt := constructRouteTable(subnetID, public)
+		rt.VpcId = aws.String("vpc-1")
+		return rt
+	}
+	subnets := []*ec2.Subnet{
+		testSubnet("subnet-priv1", "zone1", "vpc-1", false),
+		testSubnet("subnet-priv2", "zone2", "vpc-1", false),
+		testSubnet("subnet-pub1", "zone1", "vpc-1", false),
+		testSubnet("subnet-pub2", "zone2", "vpc-1", false),
+		testSubnet("subnet-priv6", "zone6", "vpc-1", false),
+		testSubnet("subnet-vpc2", "zone1", "vpc-2", false),
+	}
+	routeTables := []*ec2.RouteTable{
+		routeTable("subnet-priv1", false),
+		routeTable("subnet-priv2", false),
+		routeTable("subnet-pub1", true),
+		routeTable("subnet-pub2", true),
+		routeTable("subnet-priv6", false),
+	}
+	cases := []struct {
+		name              string
+		zones             []string
+		zoneIDs           []string
+		subnets           []string
+		singleZone        bool
+		regionZones       []*ec2.AvailabilityZone
+		errors            map[string]error
+		expectedZones     []string
+		expectedSubnets   map[string]string
+		expectedErr       string
+		expectedCondition *hivev1.MachinePoolCondition
+	}{
+		{
+			name:            "zones of the region",
+			regionZones:     regionZones,
+			expectedZones:   []string{"zone3", "zone1", "zone2"},
+			expectedSubnets: map[string]string{},
+		},
+		{
+			name:            "listed zones",
+			zones:           []string{"zone2", "zone5"},
+			regionZones:     regionZones,
+			expectedZones:   []string{"zone2", "zone5"},
+			expectedSubnets: map[string]string{},
+		},
+		{
+			name:            "zone IDs resolved in listed order",
+			zoneIDs:         []string{"tr-az2", "tr-az5", "tr-az1"},
+			regionZones:     regionZones,
+			expectedZones:   []string{"zone2", "zone5", "zone1"},
+			expectedSubnets: map[string]string{},
+		},
+		{
+			name:        "unresolved zone IDs",
+			zoneIDs:     []string{"tr-az1", "tr-az9"},
+			regionZones: regionZones,
+			expectedErr: "availability zone IDs not found in region test-region for the account of the cluster: tr-az9",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.NoUsableZonesMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnresolvedZoneIDs",
+			},
+		},
+		{
+			name:        "no zones in region",
+			expectedErr: "zero zones returned for region test-region",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.NoUsableZonesMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "NoZonesInRegion",
+			},
+		},
+		{
+			name:        "describing availability zones fails",
+			regionZones: regionZones,
+			errors:      map[string]error{"DescribeAvailabilityZones": errors.New("throttled")},
+			expectedErr: "compute pool not providing list of zones and failed to fetch list of zones: throttled",
+		},
+		{
+			name:            "single zone of the region",
+			singleZone:      true,
+			regionZones:     regionZones,
+			expectedZones:   []string{"zone1"},
+			expectedSubnets: map[string]string{},
+		},
+		{
+			name:            "single listed zone",
+			zones:           []string{"zone3", "zone2"},
+			singleZone:      true,
+			regionZones:     regionZones,
+			expectedZones:   []string{"zone3"},
+			expectedSubnets: map[string]string{},
+		},
+		{
+			name:          "zones of the region narrowed to those with subnets",
+			subnets:       []string{"subnet-priv1", "subnet-priv2", "subnet-pub1", "subnet-pub2"},
+			regionZones:   regionZones,
+			expectedZones: []string{"zone1", "zone2"},
+			expectedSubnets: map[string]string{
+				"zone1": "subnet-priv1",
+				"zone2": "subnet-priv2",
+			},
+		},
+		{
+			name:          "listed zones with private subnets",
+			zones:         []string{"zone2"},
+			subnets:       []string{"subnet-priv1", "subnet-priv2"},
+			regionZones:   regionZones,
+			expectedZones: []string{"zone2"},
+			expectedSubnets: map[string]string{
+				"zone1": "subnet-priv1",
+				"zone2": "subnet-priv2",
+			},
+		},
+		{
+			name:        "no zones of the region with subnets",
+			subnets:     []string{"subnet-priv6"},
+			regionZones: regionZones,
+			expectedErr: "none of the availability zones of region test-region have a private subnet: region availability zones: zone3, zone1, zone2; subnet availability zones: zone6",
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.NoUsableZonesMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "NoZonesWithSubnets",
 			},
+		},
+		{
+			name:        "listed zone without subnet",
+			zones:       []string{"zone1", "zone3"},
+			subnets:     []string{"subnet-priv1", "subnet-priv2"},
+			regionZones: regionZones,
 			expectedCondition: &hivev1.MachinePoolCondition{
-				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
 				Status: corev1.ConditionTrue,
-				Reason: "UnsupportedSpotMarketOptions",
+				Reason: "NoSubnetForAvailabilityZone",
+			},
+		},
+		{
+			name:        "subnets not found",
+			subnets:     []string{"subnet-priv1", "subnet-missing"},
+			regionZones: regionZones,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "SubnetsNotFound",
+				Message: "The subnet ID 'subnet-missing' does not exist",
+			},
+		},
+		{
+			name:        "subnets in multiple VPCs",
+			subnets:     []string{"subnet-priv1", "subnet-vpc2"},
+			regionZones: regionZones,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  "MultipleVPCs",
+				Message: "subnets must all belong to the same VPC: vpc-1: subnet-priv1; vpc-2: subnet-vpc2",
+			},
+		},
+		{
+			name:        "insufficient public subnets",
+			subnets:     []string{"subnet-priv1", "subnet-priv2", "subnet-pub1"},
+			regionZones: regionZones,
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InsufficientPublicSubnets",
 			},
 		},
+		{
+			name:        "describing route tables fails",
+			subnets:     []string{"subnet-priv1"},
+			regionZones: regionZones,
+			errors:      map[string]error{"DescribeRouteTables": errors.New("throttled")},
+			expectedErr: "describing subnets: error describing route tables: throttled",
+		},
 	}
-
-	for _, test := range tests {
-		apis.AddToScheme(scheme.Scheme)
-		t.Run(test.name, func(t *testing.T) {
-
-			mockCtrl := gomock.NewController(t)
-			defer mockCtrl.Finish()
-
-			fakeClient := fake.NewFakeClient(test.existing...)
-			awsClient := mockaws.NewMockClient(mockCtrl)
-
-			// set up mock expectations
-			if test.mockAWSClient != nil {
-				test.mockAWSClient(awsClient)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.Zones = tc.zones
+			pool.Spec.Platform.AWS.ZoneIDs = tc.zoneIDs
+			pool.Spec.Platform.AWS.Subnets = tc.subnets
+			pool.Spec.Platform.AWS.SingleZone = tc.singleZone
+			awsClient := &fakeaws.Client{
+				AvailabilityZones: tc.regionZones,
+				Subnets:           subnets,
+				RouteTables:       routeTables,
+				Errors:            tc.errors,
 			}
-
 			actuator := &AWSActuator{
-				client:    fakeClient,
 				awsClient: awsClient,
 				logger:    log.WithField("actuator", "awsactuator"),
 				region:    testRegion,
-				amiID:     testAMI,
 			}
 
-			pool := &hivev1.MachinePool{}
-			err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: test.poolName}, pool)
-			require.NoError(t, err)
+			zones, subnetsByZone, _, err := actuator.zonesAndSubnets(testClusterDeployment(), pool, actuator.logger)
 
-			generatedMachineSets, _, err := actuator.GenerateMachineSets(test.clusterDeployment, pool, actuator.logger)
-			if test.expectedErr {
-				assert.Error(t, err, "expected error for test case")
-			} else {
-				validateAWSMachineSets(t, generatedMachineSets, test.expectedMachineSetReplicas, test.expectedSubnetIDInMachineSet, test.expectedKMSKey)
-			}
-			if test.expectedCondition != nil {
-				cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, test.expectedCondition.Type)
-				if assert.NotNilf(t, cond, "did not find expected condition type: %v", test.expectedCondition.Type) {
-					assert.Equal(t, test.expectedCondition.Status, cond.Status, "condition found with unexpected status")
-					assert.Equal(t, test.expectedCondition.Reason, cond.Reason, "condition found with unexpected reason")
+			switch {
+			case tc.expectedCondition != nil:
+				var validationErr *ValidationError
+				if assert.True(t, errors.As(err, &validationErr), "expected a validation error") {
+					assert.Equal(t, tc.expectedCondition.Reason, validationErr.Reason, "unexpected validation error reason")
 				}
+				cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, tc.expectedCondition.Type)
+				if assert.NotNil(t, cond, "missing expected condition") {
+					assert.Equal(t, tc.expectedCondition.Status, cond.Status, "unexpected condition status")
+					assert.Equal(t, tc.expectedCondition.Reason, cond.Reason, "unexpected condition reason")
+					if tc.expectedCondition.Message != "" {
+						assert.Equal(t, tc.expectedCondition.Message, cond.Message, "unexpected condition message")
+					}
+				}
+			case tc.expectedErr != "":
+				assert.EqualError(t, err, tc.expectedErr, "unexpected error")
+			default:
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, tc.expectedZones, zones, "unexpected zones")
+				assert.Equal(t, tc.expectedSubnets, subnetsByZone, "unexpected subnets by zone")
 			}
 		})
 	}
 }
 
-func TestGetAWSAMIID(t *testing.T) {
+func Test_selectSubnet(t *testing.T) {
+	withPriority := func(subnet *ec2.Subnet, priority string) *ec2.Subnet {
+		subnet.Tags = append(subnet.Tags, &ec2.Tag{Key: aws.String("subnet-priority"), Value: aws.String(priority)})
+		return subnet
+	}
 	cases := []struct {
-		name          string
-		masterMachine *machineapi.Machine
-		expectError   bool
+		name     string
+		subnets  []*ec2.Subnet
+		policy   awshivev1.SubnetSelectionPolicy
+		expected string
 	}{
 		{
-			name:          "valid master machine",
-			masterMachine: testMachine("master1", "master"),
+			name: "lowest ID",
+			subnets: []*ec2.Subnet{
+				testSubnet("subnet-c", "zone1", "vpc-1", false),
+				testSubnet("subnet-a", "zone1", "vpc-1", false),
+				testSubnet("subnet-b", "zone1", "vpc-1", false),
+			},
+			policy:   awshivev1.SubnetSelectionPolicyLowestID,
+			expected: "subnet-a",
 		},
 		{
-			name: "invalid master machine",
-			masterMachine: func() *machineapi.Machine {
-				ms := testMachine("master1", "master")
-				ms.Spec.ProviderSpec.Value = nil
-				return ms
-			}(),
-			expectError: true,
+			name: "lowest ID ignores priority tag",
+			subnets: []*ec2.Subnet{
+				withPriority(testSubnet("subnet-b", "zone1", "vpc-1", false), "1"),
+				withPriority(testSubnet("subnet-a", "zone1", "vpc-1", false), "2"),
+			},
+			policy:   awshivev1.SubnetSelectionPolicyLowestID,
+			expected: "subnet-a",
+		},
+		{
+			name: "tag priority",
+			subnets: []*ec2.Subnet{
+				withPriority(testSubnet("subnet-a", "zone1", "vpc-1", false), "10"),
+				withPriority(testSubnet("subnet-b", "zone1", "vpc-1", false), "2"),
+				withPriority(testSubnet("subnet-c", "zone1", "vpc-1", false), "5"),
+			},
+			policy:   awshivev1.SubnetSelectionPolicyTagPriority,
+			expected: "subnet-b",
+		},
+		{
+			name: "tag priority prefers tagged subnets",
+			subnets: []*ec2.Subnet{
+				testSubnet("subnet-a", "zone1", "vpc-1", false),
+				withPriority(testSubnet("subnet-b", "zone1", "vpc-1", false), "not-a-number"),
+				withPriority(testSubnet("subnet-c", "zone1", "vpc-1", false), "100"),
+			},
+			policy:   awshivev1.SubnetSelectionPolicyTagPriority,
+			expected: "subnet-c",
+		},
+		{
+			name: "tag priority tie broken by lowest ID",
+			subnets: []*ec2.Subnet{
+				withPriority(testSubnet("subnet-b", "zone1", "vpc-1", false), "1"),
+				withPriority(testSubnet("subnet-a", "zone1", "vpc-1", false), "1"),
+			},
+			policy:   awshivev1.SubnetSelectionPolicyTagPriority,
+			expected: "subnet-a",
 		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			scheme := runtime.NewScheme()
-			machineapi.SchemeBuilder.AddToScheme(scheme)
-			awsprovider.SchemeBuilder.AddToScheme(scheme)
-			actualAMIID, actualErr := getAWSAMIID(tc.masterMachine, scheme, log.StandardLogger())
-			if tc.expectError {
-				assert.Error(t, actualErr, "expected an error")
-			} else {
-				if assert.NoError(t, actualErr, "unexpected error") {
-					assert.Equal(t, testAMI, actualAMIID, "unexpected AMI ID")
-				}
+			subnets := make([]ec2.Subnet, len(tc.subnets))
+			for i, subnet := range tc.subnets {
+				subnets[i] = *subnet
+			}
+			selection := &awshivev1.SubnetSelection{Policy: tc.policy, PriorityTagKey: "subnet-priority"}
+			assert.Equal(t, tc.expected, selectSubnet(subnets, selection), "unexpected subnet selected")
+		})
+	}
+}
+
+func Test_isSubnetPublic(t *testing.T) {
+	cases := []struct {
+		name     string
+		routes   []*ec2.Route
+		expected bool
+	}{
+		{
+			name: "ipv4 internet gateway",
+			routes: []*ec2.Route{{
+				DestinationCidrBlock: aws.String("0.0.0.0/0"),
+				GatewayId:            aws.String("igw-1"),
+			}},
+			expected: true,
+		},
+		{
+			name: "ipv4 nat gateway",
+			routes: []*ec2.Route{{
+				DestinationCidrBlock: aws.String("0.0.0.0/0"),
+				NatGatewayId:         aws.String("nat-1"),
+			}},
+		},
+		{
+			name: "ipv6 internet gateway",
+			routes: []*ec2.Route{{
+				DestinationIpv6CidrBlock: aws.String("::/0"),
+				GatewayId:                aws.String("igw-1"),
+			}},
+			expected: true,
+		},
+		{
+			name: "ipv6 egress-only internet gateway",
+			routes: []*ec2.Route{{
+				DestinationIpv6CidrBlock:    aws.String("::/0"),
+				EgressOnlyInternetGatewayId: aws.String("eigw-1"),
+			}},
+		},
+		{
+			name: "ipv6 egress-only internet gateway as gateway id",
+			routes: []*ec2.Route{{
+				DestinationIpv6CidrBlock: aws.String("::/0"),
+				GatewayId:                aws.String("eigw-1"),
+			}},
+		},
+		{
+			name: "dual-stack private",
+			routes: []*ec2.Route{
+				{
+					DestinationCidrBlock: aws.String("0.0.0.0/0"),
+					NatGatewayId:         aws.String("nat-1"),
+				},
+				{
+					DestinationIpv6CidrBlock:    aws.String("::/0"),
+					EgressOnlyInternetGatewayId: aws.String("eigw-1"),
+				},
+			},
+		},
+		{
+			name: "dual-stack public",
+			routes: []*ec2.Route{
+				{
+					DestinationCidrBlock: aws.String("0.0.0.0/0"),
+					GatewayId:            aws.String("igw-1"),
+				},
+				{
+					DestinationIpv6CidrBlock: aws.String("::/0"),
+					GatewayId:                aws.String("igw-1"),
+				},
+			},
+			expected: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			routeTables := []*ec2.RouteTable{{
+				Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String("subnet-1")}},
+				Routes:       tc.routes,
+			}}
+			public, err := isSubnetPublic(routeTables, testSubnet("subnet-1", "zone1", "vpc-1", false), log.StandardLogger())
+			if assert.NoError(t, err, "unexpected error") {
+				assert.Equal(t, tc.expected, public, "unexpected result")
 			}
 		})
 	}
 }
 
-func validateAWSMachineSets(t *testing.T, mSets []*machineapi.MachineSet, expectedMSReplicas map[string]int64, expectedSubnetID bool, expectedKMSKey string) {
+func Test_isEdgeZone(t *testing.T) {
+	cases := []struct {
+		zone     string
+		expected bool
+	}{
+		{zone: "us-east-1a"},
+		{zone: "us-gov-west-1a"},
+		{zone: "ap-northeast-1d"},
+		{zone: "zone1"},
+		{zone: "us-west-2-lax-1a", expected: true},
+		{zone: "us-east-1-bos-1a", expected: true},
+		{zone: "us-east-1-wl1-bos-wlz-1", expected: true},
+		{zone: "ap-northeast-1-wl1-nrt-wlz-1", expected: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.zone, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isEdgeZone(tc.zone), "unexpected result")
+		})
+	}
+}
+
+func Test_invalidSubnetsMessage(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "not found with tab delimited status",
+			err:      fmt.Errorf("InvalidSubnetID.NotFound: The subnet ID 'subnet-1,subnet-2' does not exist\tstatus code: 400, request id: ea8b3bb7-de56-405f-9345-e5690a3ea8b2"),
+			expected: "The subnet ID 'subnet-1,subnet-2' does not exist",
+		},
+		{
+			name:     "not found with newline delimited status",
+			err:      fmt.Errorf("InvalidSubnetID.NotFound: The subnet ID 'subnet-1' does not exist\n\tstatus code: 400, request id: ea8b3bb7-de56-405f-9345-e5690a3ea8b2"),
+			expected: "The subnet ID 'subnet-1' does not exist",
+		},
+		{
+			name:     "not found without status",
+			err:      fmt.Errorf("InvalidSubnetID.NotFound: The subnet ID 'subnet-1' does not exist"),
+			expected: "The subnet ID 'subnet-1' does not exist",
+		},
+		{
+			name:     "malformed",
+			err:      fmt.Errorf("InvalidSubnetID.Malformed: Invalid id: \"bad-subnet\"\n\tstatus code: 400, request id: ea8b3bb7-de56-405f-9345-e5690a3ea8b2"),
+			expected: "Invalid id: \"bad-subnet\"",
+		},
+		{
+			name:     "range",
+			err:      fmt.Errorf("InvalidSubnet.Range: The CIDR '10.0.0.0/8' is invalid.\tstatus code: 400, request id: ea8b3bb7-de56-405f-9345-e5690a3ea8b2"),
+			expected: "The CIDR '10.0.0.0/8' is invalid.",
+		},
+		{
+			name:     "aws not found error",
+			err:      awserr.NewRequestFailure(awserr.New("InvalidSubnetID.NotFound", "The subnet ID 'subnet-1' does not exist", nil), 400, "ea8b3bb7-de56-405f-9345-e5690a3ea8b2"),
+			expected: "The subnet ID 'subnet-1' does not exist",
+		},
+		{
+			name:     "aws malformed error",
+			err:      awserr.NewRequestFailure(awserr.New("InvalidSubnetID.Malformed", "Invalid id: \"bad-subnet\"", nil), 400, "ea8b3bb7-de56-405f-9345-e5690a3ea8b2"),
+			expected: "Invalid id: \"bad-subnet\"",
+		},
+		{
+			name:     "aws range error",
+			err:      awserr.New("InvalidSubnet.Range", "The CIDR '10.0.0.0/8' is invalid.", nil),
+			expected: "The CIDR '10.0.0.0/8' is invalid.",
+		},
+		{
+			name:     "unrecognized format",
+			err:      fmt.Errorf("InvalidSubnets"),
+			expected: "InvalidSubnets",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, invalidSubnetsMessage(tc.err))
+		})
+	}
+}
+
+func validateAWSMachineSets(t *testing.T, mSets []*machineapi.MachineSet, expectedMSReplicas map[string]int64, expectedSubnetID bool, expectedKMSKey, expectedInstanceType string, expectedZoneInstanceTypes, expectedZoneAMIIDs map[string]string) {
 	assert.Equal(t, len(expectedMSReplicas), len(mSets), "different number of machine sets generated than expected")
 
 	for _, ms := range mSets {
@@ -487,10 +4422,18 @@ func validateAWSMachineSets(t *testing.T, mSets []*machineapi.MachineSet, expect
 		awsProvider, ok := ms.Spec.Template.Spec.ProviderSpec.Value.Object.(*awsprovider.AWSMachineProviderConfig)
 		assert.True(t, ok, "failed to convert to AWSMachineProviderConfig")
 
-		assert.Equal(t, testInstanceType, awsProvider.InstanceType, "unexpected instance type")
+		if instanceType, ok := expectedZoneInstanceTypes[awsProvider.Placement.AvailabilityZone]; ok {
+			assert.Equal(t, instanceType, awsProvider.InstanceType, "unexpected instance type")
+		} else {
+			assert.Equal(t, expectedInstanceType, awsProvider.InstanceType, "unexpected instance type")
+		}
 
+		expectedAMI, ok := expectedZoneAMIIDs[awsProvider.Placement.AvailabilityZone]
+		if !ok {
+			expectedAMI = testAMI
+		}
 		if assert.NotNil(t, awsProvider.AMI.ID, "missing AMI ID") {
-			assert.Equal(t, testAMI, *awsProvider.AMI.ID, "unexpected AMI ID")
+			assert.Equal(t, expectedAMI, *awsProvider.AMI.ID, "unexpected AMI ID")
 		}
 
 		assert.Equal(t, expectedKMSKey, *awsProvider.BlockDevices[0].EBS.KMSKey.ARN)
@@ -504,11 +4447,25 @@ func validateAWSMachineSets(t *testing.T, mSets []*machineapi.MachineSet, expect
 }
 
 func mockDescribeAvailabilityZones(client *mockaws.MockClient, zones []string) {
+	mockDescribeAvailabilityZonesInStates(client, zones, []string{"available"})
+}
+
+func mockDescribeAvailabilityZonesInStates(client *mockaws.MockClient, zones, states []string) {
 	input := &ec2.DescribeAvailabilityZonesInput{
-		Filters: []*ec2.Filter{{
-			Name:   pointer.StringPtr("region-name"),
-			Values: []*string{pointer.StringPtr(testRegion)},
-		}},
+		Filters: []*ec2.Filter{
+			{
+				Name:   pointer.StringPtr("region-name"),
+				Values: []*string{pointer.StringPtr(testRegion)},
+			},
+			{
+				Name:   pointer.StringPtr("state"),
+				Values: aws.StringSlice(states),
+			},
+			{
+				Name:   pointer.StringPtr("opt-in-status"),
+				Values: aws.StringSlice([]string{"opt-in-not-required", "opted-in"}),
+			},
+		},
 	}
 	availabilityZones := make([]*ec2.AvailabilityZone, len(zones))
 	for i := range zones {
@@ -522,6 +4479,44 @@ func mockDescribeAvailabilityZones(client *mockaws.MockClient, zones []string) {
 	client.EXPECT().DescribeAvailabilityZones(input).Return(output, nil)
 }
 
+func mockDescribeAvailabilityZonesByID(client *mockaws.MockClient, zoneIDs []string, zoneNames map[string]string) {
+	input := &ec2.DescribeAvailabilityZonesInput{
+		AllAvailabilityZones: aws.Bool(true),
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("zone-id"),
+				Values: aws.StringSlice(zoneIDs),
+			},
+		},
+	}
+	output := &ec2.DescribeAvailabilityZonesOutput{}
+	for _, zoneID := range zoneIDs {
+		if _, ok := zoneNames[zoneID]; !ok {
+			continue
+		}
+		output.AvailabilityZones = append(output.AvailabilityZones, &ec2.AvailabilityZone{
+			ZoneId:   aws.String(zoneID),
+			ZoneName: aws.String(zoneNames[zoneID]),
+		})
+	}
+	client.EXPECT().DescribeAvailabilityZones(input).Return(output, nil)
+}
+
+func mockDescribeInstanceTypes(client *mockaws.MockClient, instanceType string, exists bool) {
+	input := &ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{aws.String(instanceType)},
+	}
+	if !exists {
+		client.EXPECT().DescribeInstanceTypes(input).
+			Return(nil, awserr.New("InvalidInstanceType", fmt.Sprintf("The following supplied instance types do not exist: [%s]", instanceType), nil))
+		return
+	}
+	output := &ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []*ec2.InstanceTypeInfo{{InstanceType: aws.String(instanceType)}},
+	}
+	client.EXPECT().DescribeInstanceTypes(input).Return(output, nil)
+}
+
 func mockDescribeSubnets(client *mockaws.MockClient, zones []string, privateSubnetIDs []string, pubSubnetIDs []string, vpcID string) {
 	idPointers := make([]*string, 0, len(privateSubnetIDs)+len(pubSubnetIDs))
 	for _, id := range privateSubnetIDs {
@@ -558,6 +4553,117 @@ func mockDescribeSubnets(client *mockaws.MockClient, zones []string, privateSubn
 	client.EXPECT().DescribeSubnets(input).Return(output, nil)
 }
 
+func testSubnet(id, zone, vpc string, elbTag bool) *ec2.Subnet {
+	subnet := &ec2.Subnet{
+		SubnetId:         aws.String(id),
+		AvailabilityZone: aws.String(zone),
+		VpcId:            aws.String(vpc),
+	}
+	if elbTag {
+		subnet.Tags = []*ec2.Tag{{
+			Key:   aws.String(tagNameSubnetPublicELB),
+			Value: aws.String("1"),
+		}}
+	}
+	return subnet
+}
+
+func mockDescribeInstanceTypeHypervisor(client *mockaws.MockClient, instanceType, hypervisor string) {
+	client.EXPECT().DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{aws.String(instanceType)},
+	}).Return(&ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []*ec2.InstanceTypeInfo{{
+			InstanceType: aws.String(instanceType),
+			Hypervisor:   aws.String(hypervisor),
+		}},
+	}, nil)
+}
+
+func mockGetSerialConsoleAccessStatus(client *mockaws.MockClient, enabled bool) {
+	client.EXPECT().GetSerialConsoleAccessStatus(&ec2.GetSerialConsoleAccessStatusInput{}).
+		Return(&ec2.GetSerialConsoleAccessStatusOutput{SerialConsoleAccessEnabled: aws.Bool(enabled)}, nil)
+}
+
+// mockDescribeAnyInstanceType describes any instance type not described by other expectations as one without instance
+// store volumes.
+func mockDescribeAnyInstanceType(client *mockaws.MockClient) {
+	client.EXPECT().DescribeInstanceTypes(gomock.Any()).
+		Return(&ec2.DescribeInstanceTypesOutput{
+			InstanceTypes: []*ec2.InstanceTypeInfo{{InstanceType: aws.String(testInstanceType)}},
+		}, nil).
+		AnyTimes()
+}
+
+func mockDescribeInstanceTypeInstanceStore(client *mockaws.MockClient, instanceType string, count, sizeInGB int64, nvmeSupport string) {
+	client.EXPECT().DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{aws.String(instanceType)},
+	}).Return(&ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []*ec2.InstanceTypeInfo{{
+			InstanceType:             aws.String(instanceType),
+			InstanceStorageSupported: aws.Bool(true),
+			InstanceStorageInfo: &ec2.InstanceStorageInfo{
+				Disks: []*ec2.DiskInfo{{
+					Count:    aws.Int64(count),
+					SizeInGB: aws.Int64(sizeInGB),
+					Type:     aws.String(ec2.DiskTypeSsd),
+				}},
+				NvmeSupport:   aws.String(nvmeSupport),
+				TotalSizeInGB: aws.Int64(count * sizeInGB),
+			},
+		}},
+	}, nil)
+}
+
+func mockDescribeInstanceTypeRootDeviceTypes(client *mockaws.MockClient, instanceType string, rootDeviceTypes ...string) {
+	client.EXPECT().DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{aws.String(instanceType)},
+	}).Return(&ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []*ec2.InstanceTypeInfo{{
+			InstanceType:             aws.String(instanceType),
+			SupportedRootDeviceTypes: aws.StringSlice(rootDeviceTypes),
+		}},
+	}, nil)
+}
+
+func mockDescribeSpotPriceHistory(client *mockaws.MockClient, prices map[string]string) {
+	output := &ec2.DescribeSpotPriceHistoryOutput{}
+	for zone, price := range prices {
+		output.SpotPriceHistory = append(output.SpotPriceHistory, &ec2.SpotPrice{
+			AvailabilityZone: aws.String(zone),
+			InstanceType:     aws.String(testInstanceType),
+			SpotPrice:        aws.String(price),
+			Timestamp:        aws.Time(time.Now()),
+		})
+	}
+	// The input has the current time as its start time.
+	client.EXPECT().DescribeSpotPriceHistory(gomock.Any()).Return(output, nil)
+}
+
+func mockDescribeInstanceTypeOfferings(client *mockaws.MockClient, zones, instanceTypes []string, offerings map[string]string) {
+	input := &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: aws.String(ec2.LocationTypeAvailabilityZone),
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("location"),
+				Values: aws.StringSlice(zones),
+			},
+			{
+				Name:   aws.String("instance-type"),
+				Values: aws.StringSlice(instanceTypes),
+			},
+		},
+	}
+	output := &ec2.DescribeInstanceTypeOfferingsOutput{}
+	for zone, instanceType := range offerings {
+		output.InstanceTypeOfferings = append(output.InstanceTypeOfferings, &ec2.InstanceTypeOffering{
+			Location:     aws.String(zone),
+			LocationType: aws.String(ec2.LocationTypeAvailabilityZone),
+			InstanceType: aws.String(instanceType),
+		})
+	}
+	client.EXPECT().DescribeInstanceTypeOfferings(input).Return(output, nil)
+}
+
 func mockDescribeMissingSubnets(client *mockaws.MockClient, subnetIDs []string) {
 	idPointers := make([]*string, 0, len(subnetIDs))
 	for _, id := range subnetIDs {
@@ -623,6 +4729,17 @@ func constructRouteTable(subnetID string, public bool) *ec2.RouteTable {
 	}
 }
 
+// constructCarrierRouteTable returns the route table of a public subnet in a Wavelength Zone
+func constructCarrierRouteTable(subnetID string) *ec2.RouteTable {
+	return &ec2.RouteTable{
+		Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String(subnetID)}},
+		Routes: []*ec2.Route{{
+			DestinationCidrBlock: aws.String("0.0.0.0/0"),
+			CarrierGatewayId:     aws.String("cagw-" + subnetID),
+		}},
+	}
+}
+
 func generateAWSMachineSetName(zone string) string {
 	return fmt.Sprintf("%s-%s-%s", testInfraID, testPoolName, zone)
 }
@@ -639,11 +4756,47 @@ func encodeAWSMachineProviderSpec(awsProviderSpec *awsprovider.AWSMachineProvide
 	}, nil
 }
 
+func withInstanceTypeSelector(pool *hivev1.MachinePool, family, size string) *hivev1.MachinePool {
+	pool.Spec.Platform.AWS.InstanceType = ""
+	pool.Spec.Platform.AWS.InstanceTypeSelector = &awshivev1.InstanceTypeSelector{
+		Family: family,
+		Size:   size,
+	}
+	return pool
+}
+
+func withBootDiagnostics(pool *hivev1.MachinePool) *hivev1.MachinePool {
+	pool.Spec.BootDiagnostics = true
+	return pool
+}
+
 func withSpotMarketOptions(pool *hivev1.MachinePool) *hivev1.MachinePool {
 	pool.Spec.Platform.AWS.SpotMarketOptions = &awshivev1.SpotMarketOptions{}
 	return pool
 }
 
+func withZoneInstanceTypes(pool *hivev1.MachinePool, instanceTypes map[string]string) *hivev1.MachinePool {
+	pool.Spec.Platform.AWS.InstanceTypesByZone = instanceTypes
+	return pool
+}
+
+func withFailureDomains(pool *hivev1.MachinePool, domains []awshivev1.FailureDomain) *hivev1.MachinePool {
+	pool.Spec.Platform.AWS.FailureDomains = domains
+	return pool
+}
+
+func withSpotMaxPrice(pool *hivev1.MachinePool, maxPrice string) *hivev1.MachinePool {
+	pool.Spec.Platform.AWS.SpotMarketOptions = &awshivev1.SpotMarketOptions{MaxPrice: aws.String(maxPrice)}
+	return pool
+}
+
+func withSpotInterruptionBehavior(pool *hivev1.MachinePool, behavior string) *hivev1.MachinePool {
+	pool.Spec.Platform.AWS.SpotMarketOptions = &awshivev1.SpotMarketOptions{
+		InstanceInterruptionBehavior: behavior,
+	}
+	return pool
+}
+
 func withKMSKey(pool *hivev1.MachinePool) *hivev1.MachinePool {
 	pool.Spec.Platform.AWS.EC2RootVolume.KMSKeyARN = fakeKMSKeyARN
 	return pool
@@ -0,0 +1,130 @@
+package machinepool
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/hive/pkg/awsclient"
+	"github.com/openshift/hive/pkg/constants"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+const (
+	// awsDryRunOperationCode is the error code of EC2 for a dry-run request that would have succeeded.
+	awsDryRunOperationCode = "DryRunOperation"
+)
+
+// awsUnauthorizedCodes are the codes of the AWS errors for credentials which are invalid or not authorized to make a
+// request, including those of STS when a role cannot be assumed.
+var awsUnauthorizedCodes = sets.NewString(
+	"UnauthorizedOperation",
+	"AuthFailure",
+	"AccessDenied",
+	"InvalidClientTokenId",
+	"SignatureDoesNotMatch",
+)
+
+// awsCredentialsSource returns the source of the AWS credentials of the MachinePool: its credentials secret, or the
+// IAM role it assumes with the credentials of the Hive service provider, or else the credentials secret or the role
+// of the ClusterDeployment.
+func awsCredentialsSource(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool) awsclient.CredentialsSource {
+	secretRef := &cd.Spec.Platform.AWS.CredentialsSecretRef
+	role := cd.Spec.Platform.AWS.CredentialsAssumeRole
+	if poolPlatform := pool.Spec.Platform.AWS; hasAWSCredentials(pool) {
+		secretRef, role = poolPlatform.CredentialsSecretRef, poolPlatform.CredentialsAssumeRole
+	}
+	return awsclient.CredentialsSource{
+		Secret: &awsclient.SecretCredentialsSource{
+			Ref:       secretRef,
+			Namespace: cd.Namespace,
+		},
+		AssumeRole: &awsclient.AssumeRoleCredentialsSource{
+			SecretRef: corev1.SecretReference{
+				Namespace: controllerutils.GetHiveNamespace(),
+				Name:      os.Getenv(constants.HiveAWSServiceProviderCredentialsSecretRefEnvVar),
+			},
+			Role: role,
+		},
+	}
+}
+
+// hasAWSCredentials returns whether the MachinePool has its own AWS credentials rather than those of the
+// ClusterDeployment.
+func hasAWSCredentials(pool *hivev1.MachinePool) bool {
+	poolPlatform := pool.Spec.Platform.AWS
+	return poolPlatform != nil && (poolPlatform.CredentialsSecretRef != nil || poolPlatform.CredentialsAssumeRole != nil)
+}
+
+// setUnauthorizedCredentialsCondition sets the UnauthorizedCredentials condition according to whether the AWS
+// credentials of a MachinePool with its own credentials are authorized to describe availability zones and subnets,
+// which is checked with dry-run requests. The credentials of the ClusterDeployment are not checked, as its other
+// controllers report when they cannot be used. Returns a *ValidationError if the credentials are not authorized, and
+// any other error if they could not be checked.
+func (a *AWSActuator) setUnauthorizedCredentialsCondition(pool *hivev1.MachinePool, logger log.FieldLogger) error {
+	status, reason, message := corev1.ConditionFalse, "ClusterCredentials", "The pool uses the AWS credentials of the ClusterDeployment"
+	var validationErr error
+	if hasAWSCredentials(pool) {
+		unauthorized, err := a.unauthorizedCalls()
+		if err != nil {
+			return errors.Wrap(err, "could not check the AWS credentials of the pool")
+		}
+		status, reason, message = corev1.ConditionFalse, "Authorized", "The AWS credentials of the pool are authorized to describe availability zones and subnets"
+		if len(unauthorized) > 0 {
+			logger.WithField("calls", unauthorized).Warn("AWS credentials of the pool are not authorized")
+			status, reason = corev1.ConditionTrue, "Unauthorized"
+			message = fmt.Sprintf("The AWS credentials of the pool are not authorized to describe availability zones and subnets: %s",
+				strings.Join(unauthorized, "; "))
+			validationErr = &ValidationError{
+				Type:    hivev1.UnauthorizedCredentialsMachinePoolCondition,
+				Reason:  reason,
+				Message: message,
+			}
+		}
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.UnauthorizedCredentialsMachinePoolCondition,
+		status,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	return validationErr
+}
+
+// unauthorizedCalls returns a description of each of the DescribeAvailabilityZones and DescribeSubnets calls that the
+// credentials of the AWS client are not authorized to make, as determined by dry-run requests.
+func (a *AWSActuator) unauthorizedCalls() ([]string, error) {
+	var unauthorized []string
+	check := func(call string, err error) error {
+		awsErr, ok := err.(awserr.Error)
+		switch {
+		case err == nil, ok && awsErr.Code() == awsDryRunOperationCode:
+			return nil
+		case ok && awsUnauthorizedCodes.Has(awsErr.Code()):
+			unauthorized = append(unauthorized, fmt.Sprintf("%s: %s", call, awsErr.Code()))
+			return nil
+		}
+		return errors.Wrapf(err, "dry-run %s", call)
+	}
+	_, err := a.awsClient.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{DryRun: aws.Bool(true)})
+	if err := check("DescribeAvailabilityZones", err); err != nil {
+		return nil, err
+	}
+	_, err = a.awsClient.DescribeSubnets(&ec2.DescribeSubnetsInput{DryRun: aws.Bool(true)})
+	if err := check("DescribeSubnets", err); err != nil {
+		return nil, err
+	}
+	return unauthorized, nil
+}
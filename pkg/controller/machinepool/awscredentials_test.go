@@ -0,0 +1,167 @@
+package machinepool
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hivev1aws "github.com/openshift/hive/apis/hive/v1/aws"
+	mockaws "github.com/openshift/hive/pkg/awsclient/mock"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+func Test_awsCredentialsSource(t *testing.T) {
+	clusterRole := &hivev1aws.AssumeRole{RoleARN: "arn:aws:iam::111111111111:role/cluster"}
+	poolRole := &hivev1aws.AssumeRole{RoleARN: "arn:aws:iam::222222222222:role/workers"}
+	cases := []struct {
+		name              string
+		clusterSecret     string
+		clusterRole       *hivev1aws.AssumeRole
+		poolSecretRef     *corev1.LocalObjectReference
+		poolRole          *hivev1aws.AssumeRole
+		expectedSecretRef *corev1.LocalObjectReference
+		expectedRole      *hivev1aws.AssumeRole
+	}{
+		{
+			name:              "credentials secret of the cluster",
+			clusterSecret:     "cluster-creds",
+			expectedSecretRef: &corev1.LocalObjectReference{Name: "cluster-creds"},
+		},
+		{
+			name:              "role of the cluster",
+			clusterRole:       clusterRole,
+			expectedSecretRef: &corev1.LocalObjectReference{},
+			expectedRole:      clusterRole,
+		},
+		{
+			name:              "credentials secret of the pool",
+			clusterSecret:     "cluster-creds",
+			poolSecretRef:     &corev1.LocalObjectReference{Name: "pool-creds"},
+			expectedSecretRef: &corev1.LocalObjectReference{Name: "pool-creds"},
+		},
+		{
+			name:          "role of the pool",
+			clusterSecret: "cluster-creds",
+			poolRole:      poolRole,
+			expectedRole:  poolRole,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cd := testClusterDeployment()
+			cd.Spec.Platform.AWS.CredentialsSecretRef = corev1.LocalObjectReference{Name: tc.clusterSecret}
+			cd.Spec.Platform.AWS.CredentialsAssumeRole = tc.clusterRole
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.CredentialsSecretRef = tc.poolSecretRef
+			pool.Spec.Platform.AWS.CredentialsAssumeRole = tc.poolRole
+
+			source := awsCredentialsSource(cd, pool)
+
+			require.NotNil(t, source.Secret, "missing secret credentials source")
+			assert.Equal(t, tc.expectedSecretRef, source.Secret.Ref, "unexpected credentials secret")
+			assert.Equal(t, testNamespace, source.Secret.Namespace, "unexpected credentials secret namespace")
+			require.NotNil(t, source.AssumeRole, "missing assume role credentials source")
+			assert.Equal(t, tc.expectedRole, source.AssumeRole.Role, "unexpected role")
+		})
+	}
+}
+
+func TestSetUnauthorizedCredentialsCondition(t *testing.T) {
+	dryRunErr := awserr.New(awsDryRunOperationCode, "Request would have succeeded, but DryRun flag is set.", nil)
+	cases := []struct {
+		name            string
+		poolSecretRef   *corev1.LocalObjectReference
+		mockAWSClient   func(*mockaws.MockClient)
+		expectErr       bool
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "credentials of the cluster",
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "ClusterCredentials",
+		},
+		{
+			name:          "authorized credentials of the pool",
+			poolSecretRef: &corev1.LocalObjectReference{Name: "pool-creds"},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{DryRun: aws.Bool(true)}).Return(nil, dryRunErr)
+				client.EXPECT().DescribeSubnets(&ec2.DescribeSubnetsInput{DryRun: aws.Bool(true)}).Return(nil, dryRunErr)
+			},
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "Authorized",
+		},
+		{
+			name:          "credentials of the pool not authorized to describe subnets",
+			poolSecretRef: &corev1.LocalObjectReference{Name: "pool-creds"},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeAvailabilityZones(gomock.Any()).Return(nil, dryRunErr)
+				client.EXPECT().DescribeSubnets(gomock.Any()).Return(nil, awserr.New("UnauthorizedOperation", "You are not authorized to perform this operation.", nil))
+			},
+			expectErr:       true,
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "Unauthorized",
+			expectedMessage: "The AWS credentials of the pool are not authorized to describe availability zones and subnets: DescribeSubnets: UnauthorizedOperation",
+		},
+		{
+			name:          "role of the pool cannot be assumed",
+			poolSecretRef: &corev1.LocalObjectReference{Name: "pool-creds"},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				accessDenied := awserr.New("AccessDenied", "not authorized to perform: sts:AssumeRole", nil)
+				client.EXPECT().DescribeAvailabilityZones(gomock.Any()).Return(nil, accessDenied)
+				client.EXPECT().DescribeSubnets(gomock.Any()).Return(nil, accessDenied)
+			},
+			expectErr:       true,
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "Unauthorized",
+			expectedMessage: "The AWS credentials of the pool are not authorized to describe availability zones and subnets: DescribeAvailabilityZones: AccessDenied; DescribeSubnets: AccessDenied",
+		},
+		{
+			name:          "check error leaves condition unchanged",
+			poolSecretRef: &corev1.LocalObjectReference{Name: "pool-creds"},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeAvailabilityZones(gomock.Any()).Return(nil, awserr.New("RequestExpired", "request expired", nil))
+			},
+			expectErr:      true,
+			expectedStatus: corev1.ConditionUnknown,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			if tc.mockAWSClient != nil {
+				tc.mockAWSClient(awsClient)
+			}
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.CredentialsSecretRef = tc.poolSecretRef
+			actuator := &AWSActuator{awsClient: awsClient}
+
+			err := actuator.setUnauthorizedCredentialsCondition(pool, log.StandardLogger())
+			if tc.expectErr {
+				assert.Error(t, err, "expected error")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+			}
+
+			cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.UnauthorizedCredentialsMachinePoolCondition)
+			require.NotNil(t, cond, "missing UnauthorizedCredentials condition")
+			assert.Equal(t, tc.expectedStatus, cond.Status, "unexpected condition status")
+			assert.Equal(t, tc.expectedReason, cond.Reason, "unexpected condition reason")
+			if tc.expectedMessage != "" {
+				assert.Equal(t, tc.expectedMessage, cond.Message, "unexpected condition message")
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package machinepool
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	log "github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/openshift/hive/pkg/awsclient"
+)
+
+// loggingAWSClient logs each DescribeAvailabilityZones, DescribeSubnets and DescribeRouteTables request made by the
+// AWS actuator at debug level, with the name of the call, a summary of its parameters, its duration and the number of
+// resources returned, so that the calls of each reconcile can be traced when debugging throttling and latency. All
+// other calls are passed through to the wrapped client.
+type loggingAWSClient struct {
+	awsclient.Client
+
+	clock  clock.PassiveClock
+	logger log.FieldLogger
+}
+
+func newLoggingAWSClient(client awsclient.Client, clk clock.PassiveClock, logger log.FieldLogger) awsclient.Client {
+	return &loggingAWSClient{
+		Client: client,
+		clock:  clk,
+		logger: logger,
+	}
+}
+
+func (c *loggingAWSClient) DescribeAvailabilityZones(input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	start := c.clock.Now()
+	output, err := c.Client.DescribeAvailabilityZones(input)
+	var count int
+	if output != nil {
+		count = len(output.AvailabilityZones)
+	}
+	var allZones []string
+	if aws.BoolValue(input.AllAvailabilityZones) {
+		allZones = []string{"true"}
+	}
+	params := summarizeAWSParams(input.Filters, input.DryRun,
+		awsParam{"ZoneNames", aws.StringValueSlice(input.ZoneNames)},
+		awsParam{"ZoneIds", aws.StringValueSlice(input.ZoneIds)},
+		awsParam{"AllAvailabilityZones", allZones},
+	)
+	c.log("DescribeAvailabilityZones", params, start, count, err)
+	return output, err
+}
+
+func (c *loggingAWSClient) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	start := c.clock.Now()
+	output, err := c.Client.DescribeSubnets(input)
+	var count int
+	if output != nil {
+		count = len(output.Subnets)
+	}
+	params := summarizeAWSParams(input.Filters, input.DryRun,
+		awsParam{"SubnetIds", aws.StringValueSlice(input.SubnetIds)},
+	)
+	c.log("DescribeSubnets", params, start, count, err)
+	return output, err
+}
+
+func (c *loggingAWSClient) DescribeRouteTables(input *ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error) {
+	start := c.clock.Now()
+	output, err := c.Client.DescribeRouteTables(input)
+	var count int
+	if output != nil {
+		count = len(output.RouteTables)
+	}
+	params := summarizeAWSParams(input.Filters, input.DryRun,
+		awsParam{"RouteTableIds", aws.StringValueSlice(input.RouteTableIds)},
+	)
+	c.log("DescribeRouteTables", params, start, count, err)
+	return output, err
+}
+
+// log logs a describe call with the given summary of its parameters, which started at start and returned count
+// resources or failed with err.
+func (c *loggingAWSClient) log(call string, params []string, start time.Time, count int, err error) {
+	logger := c.logger.WithFields(log.Fields{
+		"call":     call,
+		"params":   strings.Join(params, " "),
+		"duration": c.clock.Since(start),
+	})
+	if err != nil {
+		logger.WithError(err).Debug("AWS describe call failed")
+		return
+	}
+	logger.WithField("count", count).Debug("AWS describe call")
+}
+
+// awsParam is a list parameter of an AWS describe call, such as the IDs of the resources to describe.
+type awsParam struct {
+	name   string
+	values []string
+}
+
+// summarizeAWSParams returns a summary of the parameters of an AWS describe call: each of the given list parameters
+// that is set, each filter, and whether the call is a dry run, e.g. "SubnetIds=subnet-1,subnet-2 vpc-id=vpc-1".
+func summarizeAWSParams(filters []*ec2.Filter, dryRun *bool, params ...awsParam) []string {
+	var summary []string
+	for _, param := range params {
+		if len(param.values) > 0 {
+			summary = append(summary, fmt.Sprintf("%s=%s", param.name, strings.Join(param.values, ",")))
+		}
+	}
+	for _, filter := range filters {
+		summary = append(summary, fmt.Sprintf("%s=%s", aws.StringValue(filter.Name), strings.Join(aws.StringValueSlice(filter.Values), ",")))
+	}
+	if aws.BoolValue(dryRun) {
+		summary = append(summary, "DryRun")
+	}
+	return summary
+}
@@ -0,0 +1,170 @@
+package machinepool
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/openshift/hive/pkg/awsclient"
+	mockaws "github.com/openshift/hive/pkg/awsclient/mock"
+)
+
+// entriesHook captures the entries logged by a logger.
+type entriesHook struct {
+	entries []*log.Entry
+}
+
+func (h *entriesHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *entriesHook) Fire(entry *log.Entry) error {
+	h.entries = append(h.entries, entry)
+	return nil
+}
+
+func TestLoggingAWSClient(t *testing.T) {
+	describeErr := errors.New("throttled")
+	tests := []struct {
+		name           string
+		mockAWSClient  func(*mockaws.MockClient, *clock.FakeClock)
+		call           func(awsclient.Client) error
+		expectedErr    error
+		expectedFields log.Fields
+	}{
+		{
+			name: "DescribeSubnets",
+			mockAWSClient: func(client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				client.EXPECT().DescribeSubnets(gomock.Any()).DoAndReturn(func(*ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+					fakeClock.Step(200 * time.Millisecond)
+					return &ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{
+						testSubnet("subnet-1", "zone1", "vpc-1", false),
+						testSubnet("subnet-2", "zone2", "vpc-1", false),
+					}}, nil
+				})
+			},
+			call: func(client awsclient.Client) error {
+				_, err := client.DescribeSubnets(&ec2.DescribeSubnetsInput{
+					SubnetIds: aws.StringSlice([]string{"subnet-1", "subnet-2"}),
+					Filters:   []*ec2.Filter{{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-1"})}},
+				})
+				return err
+			},
+			expectedFields: log.Fields{
+				"call":     "DescribeSubnets",
+				"params":   "SubnetIds=subnet-1,subnet-2 vpc-id=vpc-1",
+				"duration": 200 * time.Millisecond,
+				"count":    2,
+			},
+		},
+		{
+			name: "DescribeRouteTables",
+			mockAWSClient: func(client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				client.EXPECT().DescribeRouteTables(gomock.Any()).DoAndReturn(func(*ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error) {
+					fakeClock.Step(time.Second)
+					return &ec2.DescribeRouteTablesOutput{RouteTables: []*ec2.RouteTable{{RouteTableId: aws.String("rtb-1")}}}, nil
+				})
+			},
+			call: func(client awsclient.Client) error {
+				_, err := client.DescribeRouteTables(&ec2.DescribeRouteTablesInput{
+					Filters: []*ec2.Filter{{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-1"})}},
+				})
+				return err
+			},
+			expectedFields: log.Fields{
+				"call":     "DescribeRouteTables",
+				"params":   "vpc-id=vpc-1",
+				"duration": time.Second,
+				"count":    1,
+			},
+		},
+		{
+			name: "DescribeAvailabilityZones",
+			mockAWSClient: func(client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				client.EXPECT().DescribeAvailabilityZones(gomock.Any()).Return(&ec2.DescribeAvailabilityZonesOutput{
+					AvailabilityZones: []*ec2.AvailabilityZone{{ZoneName: aws.String("zone1")}},
+				}, nil)
+			},
+			call: func(client awsclient.Client) error {
+				_, err := client.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
+					ZoneIds:              aws.StringSlice([]string{"use1-az1"}),
+					AllAvailabilityZones: aws.Bool(true),
+				})
+				return err
+			},
+			expectedFields: log.Fields{
+				"call":     "DescribeAvailabilityZones",
+				"params":   "ZoneIds=use1-az1 AllAvailabilityZones=true",
+				"duration": time.Duration(0),
+				"count":    1,
+			},
+		},
+		{
+			name: "dry run",
+			mockAWSClient: func(client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				client.EXPECT().DescribeAvailabilityZones(gomock.Any()).Return(nil, nil)
+			},
+			call: func(client awsclient.Client) error {
+				_, err := client.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{DryRun: aws.Bool(true)})
+				return err
+			},
+			expectedFields: log.Fields{
+				"call":     "DescribeAvailabilityZones",
+				"params":   "DryRun",
+				"duration": time.Duration(0),
+				"count":    0,
+			},
+		},
+		{
+			name: "error",
+			mockAWSClient: func(client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				client.EXPECT().DescribeSubnets(gomock.Any()).DoAndReturn(func(*ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+					fakeClock.Step(3 * time.Second)
+					return nil, describeErr
+				})
+			},
+			call: func(client awsclient.Client) error {
+				_, err := client.DescribeSubnets(&ec2.DescribeSubnetsInput{})
+				return err
+			},
+			expectedErr: describeErr,
+			expectedFields: log.Fields{
+				"call":       "DescribeSubnets",
+				"params":     "",
+				"duration":   3 * time.Second,
+				log.ErrorKey: describeErr,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockClient := mockaws.NewMockClient(mockCtrl)
+			fakeClock := clock.NewFakeClock(time.Now())
+			test.mockAWSClient(mockClient, fakeClock)
+			logger := log.New()
+			logger.SetOutput(io.Discard)
+			logger.SetLevel(log.DebugLevel)
+			hook := &entriesHook{}
+			logger.AddHook(hook)
+
+			err := test.call(newLoggingAWSClient(mockClient, fakeClock, logger))
+			assert.Equal(t, test.expectedErr, err, "unexpected error")
+
+			require.Len(t, hook.entries, 1, "expected a single log entry")
+			assert.Equal(t, log.DebugLevel, hook.entries[0].Level, "unexpected log level")
+			assert.Equal(t, test.expectedFields, hook.entries[0].Data, "unexpected log fields")
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package machinepool
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+// setOutboundConnectivityCondition sets the NoOutboundConnectivity condition according to whether the MachinePool
+// disables the public IP addresses of its machines while the private subnets of some of its availability zones have
+// no egress route: the subnets of the zones by ID, or else the subnets named by the resource names of the pool. The
+// condition is left unchanged when the subnets or their route tables cannot be described, as the machines can be
+// generated regardless.
+func (a *AWSActuator) setOutboundConnectivityCondition(pool *hivev1.MachinePool, infraID string, zones []string, subnets map[string]string, logger log.FieldLogger) {
+	status, reason, message := corev1.ConditionFalse, "PublicIPNotDisabled", "The public IP addresses of the machines are not disabled"
+	if publicIP := pool.Spec.Platform.AWS.PublicIP; publicIP != nil && !*publicIP {
+		withoutEgress, err := a.subnetsWithoutEgress(pool, infraID, zones, subnets, logger)
+		if err != nil {
+			logger.WithError(err).Warn("could not check the egress routes of the subnets")
+			return
+		}
+		status, reason, message = corev1.ConditionFalse, "EgressRouted", "The subnets of the machines route outbound traffic through an egress device"
+		if len(withoutEgress) > 0 {
+			logger.WithField("subnets", withoutEgress).Info("machines without public IP addresses are in subnets without egress routes")
+			status, reason = corev1.ConditionTrue, "NoEgressRoute"
+			message = fmt.Sprintf("The machines have no public IP addresses and likely no outbound connectivity, as some of their subnets route no outbound traffic through a NAT gateway or another egress device: %s",
+				strings.Join(withoutEgress, ", "))
+		}
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.NoOutboundConnectivityMachinePoolCondition,
+		status,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+}
+
+// subnetsWithoutEgress returns a description of the private subnet of each availability zone whose route table has no
+// egress route. Zones whose subnet is not found are skipped, as missing subnets are reported by other conditions.
+func (a *AWSActuator) subnetsWithoutEgress(pool *hivev1.MachinePool, infraID string, zones []string, subnets map[string]string, logger log.FieldLogger) ([]string, error) {
+	zoneSubnets, err := a.describeZoneSubnets(pool, infraID, zones, subnets, logger)
+	if err != nil {
+		return nil, err
+	}
+	vpcRouteTables := map[string][]*ec2.RouteTable{}
+	var withoutEgress []string
+	for _, zone := range zones {
+		subnet, ok := zoneSubnets[zone]
+		if !ok {
+			continue
+		}
+		vpc := aws.StringValue(subnet.VpcId)
+		routeTables, ok := vpcRouteTables[vpc]
+		if !ok {
+			if routeTables, err = a.routeTables.getRouteTables(a.awsClient, vpc); err != nil {
+				return nil, errors.Wrap(err, "describing route tables")
+			}
+			vpcRouteTables[vpc] = routeTables
+		}
+		routeTable, err := subnetRouteTable(routeTables, subnet, logger)
+		if err != nil {
+			return nil, err
+		}
+		if !hasEgressRoute(routeTable) {
+			withoutEgress = append(withoutEgress, fmt.Sprintf("%s (%s)", aws.StringValue(subnet.SubnetId), zone))
+		}
+	}
+	return withoutEgress, nil
+}
+
+// hasEgressRoute returns whether the route table has an active default IPv4 route through which machines without public
+// IP addresses can reach the internet: a route through a NAT gateway or any other target than an internet gateway or a
+// carrier gateway, such as a NAT instance or a transit gateway, as those only carry the traffic of public IP addresses.
+func hasEgressRoute(routeTable *ec2.RouteTable) bool {
+	for _, route := range routeTable.Routes {
+		if aws.StringValue(route.DestinationCidrBlock) != "0.0.0.0/0" || aws.StringValue(route.State) == ec2.RouteStateBlackhole {
+			continue
+		}
+		if strings.HasPrefix(aws.StringValue(route.GatewayId), "igw") || aws.StringValue(route.CarrierGatewayId) != "" {
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package machinepool
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	fakeaws "github.com/openshift/hive/pkg/awsclient/fake"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+func TestSetOutboundConnectivityCondition(t *testing.T) {
+	zones := []string{"zone1", "zone2"}
+	namedSubnet := func(zone string) *ec2.Subnet {
+		subnet := testSubnet("subnet-"+zone, zone, "vpc-1", false)
+		subnet.Tags = []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String(fmt.Sprintf("%s-private-%s", testInfraID, zone))}}
+		return subnet
+	}
+	routeTable := func(id string, route *ec2.Route, subnetIDs ...string) *ec2.RouteTable {
+		table := &ec2.RouteTable{
+			RouteTableId: aws.String(id),
+			VpcId:        aws.String("vpc-1"),
+			Routes:       []*ec2.Route{{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local")}},
+		}
+		if route != nil {
+			route.DestinationCidrBlock = aws.String("0.0.0.0/0")
+			table.Routes = append(table.Routes, route)
+		}
+		for _, subnetID := range subnetIDs {
+			table.Associations = append(table.Associations, &ec2.RouteTableAssociation{SubnetId: aws.String(subnetID)})
+		}
+		return table
+	}
+	cases := []struct {
+		name            string
+		publicIP        *bool
+		subnets         map[string]string
+		routeTables     []*ec2.RouteTable
+		errors          map[string]error
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "public IP not set",
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "PublicIPNotDisabled",
+		},
+		{
+			name:           "public IP enabled",
+			publicIP:       aws.Bool(true),
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "PublicIPNotDisabled",
+		},
+		{
+			name:     "public IP disabled with NAT gateways",
+			publicIP: aws.Bool(false),
+			routeTables: []*ec2.RouteTable{
+				routeTable("rtb-1", &ec2.Route{NatGatewayId: aws.String("nat-1")}, "subnet-zone1"),
+				routeTable("rtb-2", &ec2.Route{NatGatewayId: aws.String("nat-2")}, "subnet-zone2"),
+			},
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "EgressRouted",
+		},
+		{
+			name:     "public IP disabled with transit gateway",
+			publicIP: aws.Bool(false),
+			subnets:  map[string]string{"zone1": "subnet-zone1", "zone2": "subnet-zone2"},
+			routeTables: []*ec2.RouteTable{
+				routeTable("rtb-1", &ec2.Route{TransitGatewayId: aws.String("tgw-1")}, "subnet-zone1", "subnet-zone2"),
+			},
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "EgressRouted",
+		},
+		{
+			name:     "public IP disabled in public subnets",
+			publicIP: aws.Bool(false),
+			subnets:  map[string]string{"zone1": "subnet-zone1", "zone2": "subnet-zone2"},
+			routeTables: []*ec2.RouteTable{
+				routeTable("rtb-1", &ec2.Route{NatGatewayId: aws.String("nat-1")}, "subnet-zone1"),
+				routeTable("rtb-2", &ec2.Route{GatewayId: aws.String("igw-1")}, "subnet-zone2"),
+			},
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "NoEgressRoute",
+			expectedMessage: "The machines have no public IP addresses and likely no outbound connectivity, as some of their subnets route no outbound traffic through a NAT gateway or another egress device: subnet-zone2 (zone2)",
+		},
+		{
+			name:     "public IP disabled with blackholed NAT gateway",
+			publicIP: aws.Bool(false),
+			routeTables: []*ec2.RouteTable{
+				routeTable("rtb-1", &ec2.Route{NatGatewayId: aws.String("nat-1"), State: aws.String(ec2.RouteStateBlackhole)}, "subnet-zone1", "subnet-zone2"),
+			},
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "NoEgressRoute",
+		},
+		{
+			name:     "public IP disabled without default route in main route table",
+			publicIP: aws.Bool(false),
+			routeTables: []*ec2.RouteTable{
+				func() *ec2.RouteTable {
+					table := routeTable("rtb-main", nil)
+					table.Associations = []*ec2.RouteTableAssociation{{Main: aws.Bool(true)}}
+					return table
+				}(),
+			},
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "NoEgressRoute",
+			expectedMessage: "The machines have no public IP addresses and likely no outbound connectivity, as some of their subnets route no outbound traffic through a NAT gateway or another egress device: subnet-zone1 (zone1), subnet-zone2 (zone2)",
+		},
+		{
+			name:           "describe error leaves condition unchanged",
+			publicIP:       aws.Bool(false),
+			errors:         map[string]error{"DescribeRouteTables": errors.New("throttled")},
+			expectedStatus: corev1.ConditionUnknown,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.PublicIP = tc.publicIP
+			actuator := &AWSActuator{
+				awsClient: &fakeaws.Client{
+					Subnets:     []*ec2.Subnet{namedSubnet("zone1"), namedSubnet("zone2")},
+					RouteTables: tc.routeTables,
+					Errors:      tc.errors,
+				},
+			}
+
+			actuator.setOutboundConnectivityCondition(pool, testInfraID, zones, tc.subnets, log.StandardLogger())
+
+			cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.NoOutboundConnectivityMachinePoolCondition)
+			require.NotNil(t, cond, "missing NoOutboundConnectivity condition")
+			assert.Equal(t, tc.expectedStatus, cond.Status, "unexpected condition status")
+			assert.Equal(t, tc.expectedReason, cond.Reason, "unexpected condition reason")
+			if tc.expectedMessage != "" {
+				assert.Equal(t, tc.expectedMessage, cond.Message, "unexpected condition message")
+			}
+		})
+	}
+}
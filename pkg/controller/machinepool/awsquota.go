@@ -0,0 +1,281 @@
+package machinepool
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/hive/pkg/awsclient"
+	"github.com/openshift/hive/pkg/constants"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+const (
+	// ec2ServiceCode and ebsServiceCode are the Service Quotas codes of the services whose quotas limit the machines of
+	// MachinePools.
+	ec2ServiceCode = "ec2"
+	ebsServiceCode = "ebs"
+)
+
+// ec2InstanceQuotaCodes are the codes of the EC2 quotas on the vCPUs of the running on-demand and spot instances of a
+// class of instance types. Empty when instances of the class cannot be spot instances.
+type ec2InstanceQuotaCodes struct {
+	onDemand string
+	spot     string
+}
+
+var (
+	standardInstanceQuotaCodes = ec2InstanceQuotaCodes{onDemand: "L-1216C47A", spot: "L-34B43A08"}
+	gInstanceQuotaCodes        = ec2InstanceQuotaCodes{onDemand: "L-DB2E81BA", spot: "L-3819A6DF"}
+
+	// ec2InstanceQuotaCodesByClass maps the classes of instance types, i.e. the letters their families start with, to
+	// the quotas on their vCPUs. Instance types of other classes are not checked.
+	ec2InstanceQuotaCodesByClass = map[string]ec2InstanceQuotaCodes{
+		"a":   standardInstanceQuotaCodes,
+		"c":   standardInstanceQuotaCodes,
+		"d":   standardInstanceQuotaCodes,
+		"h":   standardInstanceQuotaCodes,
+		"i":   standardInstanceQuotaCodes,
+		"im":  standardInstanceQuotaCodes,
+		"is":  standardInstanceQuotaCodes,
+		"m":   standardInstanceQuotaCodes,
+		"r":   standardInstanceQuotaCodes,
+		"t":   standardInstanceQuotaCodes,
+		"z":   standardInstanceQuotaCodes,
+		"f":   {onDemand: "L-74FC7D96", spot: "L-88CF9481"},
+		"g":   gInstanceQuotaCodes,
+		"vt":  gInstanceQuotaCodes,
+		"inf": {onDemand: "L-1945791B", spot: "L-B5D1601B"},
+		"p":   {onDemand: "L-417A185B", spot: "L-7212CCBC"},
+		"x":   {onDemand: "L-7295265B", spot: "L-E3A00192"},
+		"dl":  {onDemand: "L-6E869C2A"},
+		"u-":  {onDemand: "L-43DA4232"},
+	}
+
+	// ebsStorageQuotaCodes maps EBS volume types to the codes of the quotas on their storage, in TiB.
+	ebsStorageQuotaCodes = map[string]string{
+		"gp2":      "L-D18FCD1D",
+		"gp3":      "L-7A658B76",
+		"io1":      "L-FD252861",
+		"io2":      "L-09BD8365",
+		"st1":      "L-82ACEF56",
+		"sc1":      "L-17AF77E8",
+		"standard": "L-9CF3C2EB",
+	}
+)
+
+// getAWSQuotaCheck returns whether the machines of AWS MachinePools are checked against the quotas of their account,
+// as configured by the MACHINEPOOL_AWS_QUOTA_CHECK environment variable.
+func getAWSQuotaCheck() (bool, error) {
+	value, ok := os.LookupEnv(constants.MachinePoolAWSQuotaCheckEnvVar)
+	if !ok || value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.Errorf("invalid %s: %q", constants.MachinePoolAWSQuotaCheckEnvVar, value)
+	}
+	return enabled, nil
+}
+
+// ec2InstanceQuotaCode returns the code of the EC2 quota on the vCPUs of the running instances of an instance type.
+// Returns false for instance types of unknown classes and for spot instances of classes without a spot quota.
+func ec2InstanceQuotaCode(instanceType string, spot bool) (string, bool) {
+	class := "u-"
+	if !strings.HasPrefix(instanceType, class) {
+		class = strings.ToLower(instanceType)
+		if i := strings.IndexFunc(class, func(r rune) bool { return !unicode.IsLetter(r) }); i >= 0 {
+			class = class[:i]
+		}
+	}
+	codes, ok := ec2InstanceQuotaCodesByClass[class]
+	if !ok {
+		return "", false
+	}
+	if spot {
+		return codes.spot, codes.spot != ""
+	}
+	return codes.onDemand, true
+}
+
+// zoneMaxReplicas returns the maximum number of replicas of the MachineSet of the MachinePool in each of the given
+// availability zones, splitting the replicas of the pool across the zones as the MachineSets are generated. The
+// maximum replicas of auto-scaling pools are used, with the overrides of their zones.
+func zoneMaxReplicas(pool *hivev1.MachinePool, zones []string) map[string]int64 {
+	return zoneReplicas(pool, zones, true)
+}
+
+// zoneReplicas returns the number of replicas of the MachineSet of the MachinePool in each of the given availability
+// zones, splitting the replicas of the pool across the zones as the MachineSets are generated. The minimum or, when
+// max is set, the maximum replicas of auto-scaling pools are used, with the overrides of their zones.
+func zoneReplicas(pool *hivev1.MachinePool, zones []string, max bool) map[string]int64 {
+	total := int64(1)
+	if pool.Spec.Replicas != nil {
+		total = *pool.Spec.Replicas
+	}
+	var overrides map[string]hivev1.MachinePoolZoneAutoscaling
+	if autoscaling := pool.Spec.Autoscaling; autoscaling != nil {
+		total = int64(autoscaling.MinReplicas)
+		if max {
+			total = int64(autoscaling.MaxReplicas)
+		}
+		overrides = autoscaling.ZoneReplicas
+	}
+	replicas := make(map[string]int64, len(zones))
+	var splitZones []string
+	for _, zone := range zones {
+		if override, ok := overrides[zone]; ok {
+			replicas[zone] = int64(override.MinReplicas)
+			if max {
+				replicas[zone] = int64(override.MaxReplicas)
+			}
+			total -= replicas[zone]
+			continue
+		}
+		splitZones = append(splitZones, zone)
+	}
+	if total < 0 {
+		total = 0
+	}
+	for i, zone := range splitZones {
+		replicas[zone] = total / int64(len(splitZones))
+		if int64(i) < total%int64(len(splitZones)) {
+			replicas[zone]++
+		}
+	}
+	return replicas
+}
+
+// setQuotaCondition sets the InsufficientQuota condition according to whether the vCPUs and the volume storage of the
+// machines of the MachinePool in the given availability zones exceed the EC2 and EBS quotas of the account.
+// The condition is left unchanged when the quotas cannot be checked, as the machines can be generated regardless.
+func (a *AWSActuator) setQuotaCondition(pool *hivev1.MachinePool, instanceType string, zones []string, logger log.FieldLogger) {
+	status, reason, message := corev1.ConditionFalse, "QuotaCheckDisabled", "The quotas of the account are not checked"
+	if a.quotaCheck {
+		exceeded, err := a.exceededQuotas(pool, instanceType, zones, logger)
+		if err != nil {
+			logger.WithError(err).Warn("could not check the quotas of the account")
+			return
+		}
+		status, reason, message = corev1.ConditionFalse, "SufficientQuota", "The machines are within the quotas of the account"
+		if len(exceeded) > 0 {
+			logger.WithField("quotas", exceeded).Info("machines likely exceed the quotas of the account")
+			status, reason = corev1.ConditionTrue, "QuotaExceeded"
+			message = fmt.Sprintf("The machines likely exceed the quotas of the account: %s", strings.Join(exceeded, "; "))
+		}
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.InsufficientQuotaMachinePoolCondition,
+		status,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+}
+
+// exceededQuotas returns a description of each EC2 vCPU quota and EBS storage quota exceeded by the machines of the
+// MachinePool in the given availability zones on their own.
+func (a *AWSActuator) exceededQuotas(pool *hivev1.MachinePool, instanceType string, zones []string, logger log.FieldLogger) ([]string, error) {
+	replicas := zoneMaxReplicas(pool, zones)
+	spot := poolSpotMarketOptions(pool) != nil
+	vcpus := map[string]int64{}
+	var machines int64
+	for _, zone := range zones {
+		if replicas[zone] == 0 {
+			continue
+		}
+		machines += replicas[zone]
+		zoneInstanceType := instanceType
+		if override, ok := zoneInstanceTypes(pool)[zone]; ok {
+			zoneInstanceType = override
+		}
+		code, ok := ec2InstanceQuotaCode(zoneInstanceType, spot)
+		if !ok {
+			logger.WithField("instanceType", zoneInstanceType).Debug("no known vCPU quota for instance type")
+			continue
+		}
+		info, err := a.describeInstanceType(zoneInstanceType)
+		if err != nil {
+			return nil, errors.Wrap(err, "describing instance types")
+		}
+		if info == nil || info.VCpuInfo == nil {
+			// Instance types which are not offered are reported by other conditions.
+			continue
+		}
+		vcpus[code] += replicas[zone] * aws.Int64Value(info.VCpuInfo.DefaultVCpus)
+	}
+
+	var exceeded []string
+	for _, code := range sets.StringKeySet(vcpus).List() {
+		quota, err := a.getServiceQuota(ec2ServiceCode, code)
+		if err != nil {
+			return nil, err
+		}
+		if requested := float64(vcpus[code]); requested > aws.Float64Value(quota.Value) {
+			exceeded = append(exceeded, fmt.Sprintf("%g vCPUs requested of %s, which is %g",
+				requested, quotaName(quota, code), aws.Float64Value(quota.Value)))
+		}
+	}
+	// The storage of each volume type covers the root volume and the additional block devices of every machine.
+	rootVolume := pool.Spec.Platform.AWS.EC2RootVolume
+	volumeSizes := map[string]int64{rootVolume.Type: int64(rootVolume.Size)}
+	for _, device := range pool.Spec.Platform.AWS.AdditionalBlockDevices {
+		volumeSizes[device.Type] += int64(device.Size)
+	}
+	for _, volumeType := range sets.StringKeySet(volumeSizes).List() {
+		code, ok := ebsStorageQuotaCodes[volumeType]
+		if !ok || machines == 0 {
+			continue
+		}
+		quota, err := a.getServiceQuota(ebsServiceCode, code)
+		if err != nil {
+			return nil, err
+		}
+		// The storage quotas are in TiB while volume sizes are in GiB.
+		if requested := float64(machines*volumeSizes[volumeType]) / 1024; requested > aws.Float64Value(quota.Value) {
+			exceeded = append(exceeded, fmt.Sprintf("%g TiB requested of %s, which is %g",
+				requested, quotaName(quota, code), aws.Float64Value(quota.Value)))
+		}
+	}
+	return exceeded, nil
+}
+
+// getServiceQuota returns the quota with the given code of the service in the account, or its default value when the
+// quota has not been changed for the account.
+func (a *AWSActuator) getServiceQuota(serviceCode, quotaCode string) (*awsclient.ServiceQuota, error) {
+	input := &awsclient.GetServiceQuotaInput{
+		ServiceCode: aws.String(serviceCode),
+		QuotaCode:   aws.String(quotaCode),
+	}
+	resp, err := a.awsClient.GetServiceQuota(input)
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == awsclient.ServiceQuotasNoSuchResourceExceptionCode {
+		resp, err = a.awsClient.GetAWSDefaultServiceQuota(input)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting quota %s of service %s", quotaCode, serviceCode)
+	}
+	if resp.Quota == nil || resp.Quota.Value == nil {
+		return nil, errors.Errorf("no value for quota %s of service %s", quotaCode, serviceCode)
+	}
+	return resp.Quota, nil
+}
+
+// quotaName returns the name of a quota followed by its code, or only its code when the quota has no name.
+func quotaName(quota *awsclient.ServiceQuota, code string) string {
+	if name := aws.StringValue(quota.QuotaName); name != "" {
+		return fmt.Sprintf("%s (%s)", name, code)
+	}
+	return code
+}
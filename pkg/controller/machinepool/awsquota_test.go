@@ -0,0 +1,236 @@
+package machinepool
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hivev1aws "github.com/openshift/hive/apis/hive/v1/aws"
+	"github.com/openshift/hive/pkg/awsclient"
+	mockaws "github.com/openshift/hive/pkg/awsclient/mock"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+func expectGetServiceQuota(client *mockaws.MockClient, serviceCode, quotaCode string, value float64) *gomock.Call {
+	return client.EXPECT().GetServiceQuota(&awsclient.GetServiceQuotaInput{
+		ServiceCode: aws.String(serviceCode),
+		QuotaCode:   aws.String(quotaCode),
+	}).Return(&awsclient.GetServiceQuotaOutput{
+		Quota: &awsclient.ServiceQuota{
+			QuotaCode: aws.String(quotaCode),
+			QuotaName: aws.String("quota " + quotaCode),
+			Value:     aws.Float64(value),
+		},
+	}, nil)
+}
+
+func Test_ec2InstanceQuotaCode(t *testing.T) {
+	cases := []struct {
+		instanceType string
+		spot         bool
+		expected     string
+		expectedOK   bool
+	}{
+		{instanceType: "m5.xlarge", expected: "L-1216C47A", expectedOK: true},
+		{instanceType: "m5.xlarge", spot: true, expected: "L-34B43A08", expectedOK: true},
+		{instanceType: "im4gn.large", expected: "L-1216C47A", expectedOK: true},
+		{instanceType: "inf1.xlarge", expected: "L-1945791B", expectedOK: true},
+		{instanceType: "g4dn.xlarge", expected: "L-DB2E81BA", expectedOK: true},
+		{instanceType: "u-6tb1.metal", expected: "L-43DA4232", expectedOK: true},
+		{instanceType: "u-6tb1.metal", spot: true},
+		{instanceType: "mac1.metal"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.instanceType, func(t *testing.T) {
+			actual, ok := ec2InstanceQuotaCode(tc.instanceType, tc.spot)
+			assert.Equal(t, tc.expectedOK, ok, "unexpected known quota")
+			assert.Equal(t, tc.expected, actual, "unexpected quota code")
+		})
+	}
+}
+
+func Test_zoneMaxReplicas(t *testing.T) {
+	cases := []struct {
+		name        string
+		replicas    *int64
+		autoscaling *hivev1.MachinePoolAutoscaling
+		expected    map[string]int64
+	}{
+		{
+			name:     "default replicas",
+			expected: map[string]int64{"zone1": 1, "zone2": 0, "zone3": 0},
+		},
+		{
+			name:     "replicas",
+			replicas: pointer.Int64(5),
+			expected: map[string]int64{"zone1": 2, "zone2": 2, "zone3": 1},
+		},
+		{
+			name:        "autoscaling",
+			autoscaling: &hivev1.MachinePoolAutoscaling{MinReplicas: 3, MaxReplicas: 9},
+			expected:    map[string]int64{"zone1": 3, "zone2": 3, "zone3": 3},
+		},
+		{
+			name: "autoscaling with zone replicas",
+			autoscaling: &hivev1.MachinePoolAutoscaling{
+				MinReplicas: 3,
+				MaxReplicas: 9,
+				ZoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+					"zone2": {MinReplicas: 1, MaxReplicas: 5},
+				},
+			},
+			expected: map[string]int64{"zone1": 2, "zone2": 5, "zone3": 2},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := testMachinePool()
+			pool.Spec.Replicas = tc.replicas
+			pool.Spec.Autoscaling = tc.autoscaling
+			assert.Equal(t, tc.expected, zoneMaxReplicas(pool, []string{"zone1", "zone2", "zone3"}))
+		})
+	}
+}
+
+func TestSetQuotaCondition(t *testing.T) {
+	cases := []struct {
+		name            string
+		quotaCheck      bool
+		configurePool   func(*hivev1.MachinePool)
+		mockAWSClient   func(*mockaws.MockClient)
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "quota check disabled",
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "QuotaCheckDisabled",
+		},
+		{
+			name:       "sufficient quota",
+			quotaCheck: true,
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectGetServiceQuota(client, "ec2", "L-1216C47A", 64)
+				expectGetServiceQuota(client, "ebs", "L-7A658B76", 50)
+			},
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "SufficientQuota",
+		},
+		{
+			name:       "vCPU quota exceeded",
+			quotaCheck: true,
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectGetServiceQuota(client, "ec2", "L-1216C47A", 32)
+				expectGetServiceQuota(client, "ebs", "L-7A658B76", 50)
+			},
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "QuotaExceeded",
+			expectedMessage: "The machines likely exceed the quotas of the account: 48 vCPUs requested of quota L-1216C47A (L-1216C47A), which is 32",
+		},
+		{
+			name:       "spot vCPU and storage quotas exceeded",
+			quotaCheck: true,
+			configurePool: func(pool *hivev1.MachinePool) {
+				pool.Spec.Platform.AWS.SpotMarketOptions = &hivev1aws.SpotMarketOptions{}
+				pool.Spec.Platform.AWS.EC2RootVolume.Size = 1024
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectGetServiceQuota(client, "ec2", "L-34B43A08", 32)
+				expectGetServiceQuota(client, "ebs", "L-7A658B76", 2)
+			},
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "QuotaExceeded",
+			expectedMessage: "The machines likely exceed the quotas of the account: 48 vCPUs requested of quota L-34B43A08 (L-34B43A08), which is 32; " +
+				"3 TiB requested of quota L-7A658B76 (L-7A658B76), which is 2",
+		},
+		{
+			name:       "storage quotas of additional block devices exceeded",
+			quotaCheck: true,
+			configurePool: func(pool *hivev1.MachinePool) {
+				pool.Spec.Platform.AWS.AdditionalBlockDevices = []hivev1aws.BlockDevice{
+					{DeviceName: "/dev/xvdb", Size: 904, Type: "gp3"},
+					{DeviceName: "/dev/xvdc", Size: 512, Type: "st1"},
+				}
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectGetServiceQuota(client, "ec2", "L-1216C47A", 64)
+				expectGetServiceQuota(client, "ebs", "L-7A658B76", 2)
+				expectGetServiceQuota(client, "ebs", "L-82ACEF56", 2)
+			},
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "QuotaExceeded",
+			expectedMessage: "The machines likely exceed the quotas of the account: 3 TiB requested of quota L-7A658B76 (L-7A658B76), which is 2",
+		},
+		{
+			name:       "default quota",
+			quotaCheck: true,
+			mockAWSClient: func(client *mockaws.MockClient) {
+				input := &awsclient.GetServiceQuotaInput{ServiceCode: aws.String("ec2"), QuotaCode: aws.String("L-1216C47A")}
+				client.EXPECT().GetServiceQuota(input).
+					Return(nil, awserr.New(awsclient.ServiceQuotasNoSuchResourceExceptionCode, "no such quota", nil))
+				client.EXPECT().GetAWSDefaultServiceQuota(input).
+					Return(&awsclient.GetServiceQuotaOutput{Quota: &awsclient.ServiceQuota{Value: aws.Float64(5)}}, nil)
+				expectGetServiceQuota(client, "ebs", "L-7A658B76", 50)
+			},
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "QuotaExceeded",
+			expectedMessage: "The machines likely exceed the quotas of the account: 48 vCPUs requested of L-1216C47A, which is 5",
+		},
+		{
+			name:       "quota error leaves condition unchanged",
+			quotaCheck: true,
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().GetServiceQuota(gomock.Any()).Return(nil, awserr.New("AccessDeniedException", "access denied", nil))
+			},
+			expectedStatus: corev1.ConditionUnknown,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			if tc.mockAWSClient != nil {
+				tc.mockAWSClient(awsClient)
+			}
+
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.InstanceType = "m5.xlarge"
+			pool.Spec.Platform.AWS.EC2RootVolume = hivev1aws.EC2RootVolume{Size: 120, Type: "gp3"}
+			if tc.configurePool != nil {
+				tc.configurePool(pool)
+			}
+			actuator := &AWSActuator{
+				awsClient:  awsClient,
+				quotaCheck: tc.quotaCheck,
+				instanceTypes: map[string]*ec2.InstanceTypeInfo{
+					"m5.xlarge": {
+						InstanceType: aws.String("m5.xlarge"),
+						VCpuInfo:     &ec2.VCpuInfo{DefaultVCpus: aws.Int64(16)},
+					},
+				},
+			}
+
+			actuator.setQuotaCondition(pool, "m5.xlarge", []string{"zone1", "zone2", "zone3"}, log.StandardLogger())
+
+			cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.InsufficientQuotaMachinePoolCondition)
+			require.NotNil(t, cond, "missing InsufficientQuota condition")
+			assert.Equal(t, tc.expectedStatus, cond.Status, "unexpected condition status")
+			assert.Equal(t, tc.expectedReason, cond.Reason, "unexpected condition reason")
+			if tc.expectedMessage != "" {
+				assert.Equal(t, tc.expectedMessage, cond.Message, "unexpected condition message")
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package machinepool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	"github.com/openshift/hive/pkg/awsclient"
+	"github.com/openshift/hive/pkg/constants"
+)
+
+// awsRateLimiters holds a token bucket rate limiter for the AWS describe calls made in each AWS account. It is shared
+// by the actuators created for each reconcile so that all the MachinePools in an account are throttled together. It
+// is safe for concurrent use.
+type awsRateLimiters struct {
+	limit rate.Limit
+	burst int
+
+	mu sync.Mutex
+	// limiters are the rate limiters by AWS account ID.
+	limiters map[string]*rate.Limiter
+	// accounts are the AWS account IDs by credentials secret, so that the account of each cluster is only looked up
+	// once.
+	accounts map[string]string
+}
+
+func newAWSRateLimiters(qps float64, burst int) *awsRateLimiters {
+	return &awsRateLimiters{
+		limit:    rate.Limit(qps),
+		burst:    burst,
+		limiters: map[string]*rate.Limiter{},
+		accounts: map[string]string{},
+	}
+}
+
+// wrap returns a client which waits for the rate limiter of the AWS account of the given credentials before each
+// describe call. A nil awsRateLimiters does not limit the calls.
+func (l *awsRateLimiters) wrap(ctx context.Context, client awsclient.Client, credentials awsclient.CredentialsSource) (awsclient.Client, error) {
+	if l == nil {
+		return client, nil
+	}
+	account, err := l.getAccount(client, credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &rateLimitedAWSClient{
+		Client:  client,
+		ctx:     ctx,
+		limiter: l.getLimiter(account),
+	}, nil
+}
+
+// getAccount returns the ID of the AWS account of the given credentials, which are chosen in the same order as by
+// awsclient.New. The account is taken from the ARN when a role is assumed, and is otherwise looked up with the client
+// the first time the credentials secret is seen. A nil awsRateLimiters looks the account up every time.
+func (l *awsRateLimiters) getAccount(client awsclient.Client, credentials awsclient.CredentialsSource) (string, error) {
+	var key string
+	switch {
+	case credentials.Secret != nil && credentials.Secret.Ref != nil && credentials.Secret.Ref.Name != "":
+		if l == nil {
+			break
+		}
+		key = fmt.Sprintf("%s/%s", credentials.Secret.Namespace, credentials.Secret.Ref.Name)
+		l.mu.Lock()
+		account, ok := l.accounts[key]
+		l.mu.Unlock()
+		if ok {
+			return account, nil
+		}
+	case credentials.AssumeRole != nil && credentials.AssumeRole.Role != nil && credentials.AssumeRole.Role.RoleARN != "":
+		roleARN, err := arn.Parse(credentials.AssumeRole.Role.RoleARN)
+		if err != nil {
+			return "", errors.Wrap(err, "could not parse role ARN")
+		}
+		return roleARN.AccountID, nil
+	}
+	output, err := client.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", errors.Wrap(err, "could not get AWS account")
+	}
+	account := aws.StringValue(output.Account)
+	if key != "" {
+		l.mu.Lock()
+		l.accounts[key] = account
+		l.mu.Unlock()
+	}
+	return account, nil
+}
+
+func (l *awsRateLimiters) getLimiter(account string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limiter, ok := l.limiters[account]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[account] = limiter
+	}
+	return limiter
+}
+
+// rateLimitedAWSClient waits for a rate limiter before each of the AWS describe calls made by the AWS actuator. All
+// other calls are passed through to the wrapped client.
+type rateLimitedAWSClient struct {
+	awsclient.Client
+
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (c *rateLimitedAWSClient) DescribeAvailabilityZones(input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	if err := c.wait("DescribeAvailabilityZones"); err != nil {
+		return nil, err
+	}
+	return c.Client.DescribeAvailabilityZones(input)
+}
+
+func (c *rateLimitedAWSClient) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	if err := c.wait("DescribeSubnets"); err != nil {
+		return nil, err
+	}
+	return c.Client.DescribeSubnets(input)
+}
+
+func (c *rateLimitedAWSClient) DescribeRouteTables(input *ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error) {
+	if err := c.wait("DescribeRouteTables"); err != nil {
+		return nil, err
+	}
+	return c.Client.DescribeRouteTables(input)
+}
+
+func (c *rateLimitedAWSClient) DescribeInstanceTypes(input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	if err := c.wait("DescribeInstanceTypes"); err != nil {
+		return nil, err
+	}
+	return c.Client.DescribeInstanceTypes(input)
+}
+
+func (c *rateLimitedAWSClient) DescribeInstanceTypeOfferings(input *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	if err := c.wait("DescribeInstanceTypeOfferings"); err != nil {
+		return nil, err
+	}
+	return c.Client.DescribeInstanceTypeOfferings(input)
+}
+
+func (c *rateLimitedAWSClient) GetSerialConsoleAccessStatus(input *ec2.GetSerialConsoleAccessStatusInput) (*ec2.GetSerialConsoleAccessStatusOutput, error) {
+	if err := c.wait("GetSerialConsoleAccessStatus"); err != nil {
+		return nil, err
+	}
+	return c.Client.GetSerialConsoleAccessStatus(input)
+}
+
+func (c *rateLimitedAWSClient) DescribeSpotPriceHistory(input *ec2.DescribeSpotPriceHistoryInput) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+	if err := c.wait("DescribeSpotPriceHistory"); err != nil {
+		return nil, err
+	}
+	return c.Client.DescribeSpotPriceHistory(input)
+}
+
+func (c *rateLimitedAWSClient) DescribeSecurityGroups(input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	if err := c.wait("DescribeSecurityGroups"); err != nil {
+		return nil, err
+	}
+	return c.Client.DescribeSecurityGroups(input)
+}
+
+func (c *rateLimitedAWSClient) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	if err := c.wait("DescribeImages"); err != nil {
+		return nil, err
+	}
+	return c.Client.DescribeImages(input)
+}
+
+func (c *rateLimitedAWSClient) GetInstanceProfile(input *iam.GetInstanceProfileInput) (*iam.GetInstanceProfileOutput, error) {
+	if err := c.wait("GetInstanceProfile"); err != nil {
+		return nil, err
+	}
+	return c.Client.GetInstanceProfile(input)
+}
+
+func (c *rateLimitedAWSClient) DescribeKey(input *awsclient.DescribeKeyInput) (*awsclient.DescribeKeyOutput, error) {
+	if err := c.wait("DescribeKey"); err != nil {
+		return nil, err
+	}
+	return c.Client.DescribeKey(input)
+}
+
+func (c *rateLimitedAWSClient) GetServiceQuota(input *awsclient.GetServiceQuotaInput) (*awsclient.GetServiceQuotaOutput, error) {
+	if err := c.wait("GetServiceQuota"); err != nil {
+		return nil, err
+	}
+	return c.Client.GetServiceQuota(input)
+}
+
+func (c *rateLimitedAWSClient) GetAWSDefaultServiceQuota(input *awsclient.GetServiceQuotaInput) (*awsclient.GetServiceQuotaOutput, error) {
+	if err := c.wait("GetAWSDefaultServiceQuota"); err != nil {
+		return nil, err
+	}
+	return c.Client.GetAWSDefaultServiceQuota(input)
+}
+
+// wait blocks until the rate limiter allows the call and records the time spent waiting.
+func (c *rateLimitedAWSClient) wait(call string) error {
+	start := time.Now()
+	err := c.limiter.Wait(c.ctx)
+	metricAWSRateLimitWaitSeconds.WithLabelValues(call).Observe(time.Since(start).Seconds())
+	return errors.Wrapf(err, "waiting for AWS rate limit for %s", call)
+}
+
+// getAWSRateLimiters returns the rate limiters for AWS describe calls, as configured by the MACHINEPOOL_AWS_API_QPS
+// and MACHINEPOOL_AWS_API_BURST environment variables. Returns nil when the calls are not rate limited.
+func getAWSRateLimiters() (*awsRateLimiters, error) {
+	value, ok := os.LookupEnv(constants.MachinePoolAWSAPIQPSEnvVar)
+	if !ok {
+		return nil, nil
+	}
+	qps, err := strconv.Atoi(value)
+	if err != nil || qps < 1 {
+		return nil, errors.Errorf("invalid %s: %q", constants.MachinePoolAWSAPIQPSEnvVar, value)
+	}
+	burst := qps
+	if value, ok := os.LookupEnv(constants.MachinePoolAWSAPIBurstEnvVar); ok {
+		if burst, err = strconv.Atoi(value); err != nil || burst < 1 {
+			return nil, errors.Errorf("invalid %s: %q", constants.MachinePoolAWSAPIBurstEnvVar, value)
+		}
+	}
+	return newAWSRateLimiters(float64(qps), burst), nil
+}
@@ -0,0 +1,267 @@
+package machinepool
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	corev1 "k8s.io/api/core/v1"
+
+	hivev1aws "github.com/openshift/hive/apis/hive/v1/aws"
+	"github.com/openshift/hive/pkg/awsclient"
+	mockaws "github.com/openshift/hive/pkg/awsclient/mock"
+	"github.com/openshift/hive/pkg/constants"
+)
+
+func secretCredentials(namespace, name string) awsclient.CredentialsSource {
+	return awsclient.CredentialsSource{
+		Secret: &awsclient.SecretCredentialsSource{
+			Namespace: namespace,
+			Ref:       &corev1.LocalObjectReference{Name: name},
+		},
+	}
+}
+
+func roleCredentials(roleARN string) awsclient.CredentialsSource {
+	return awsclient.CredentialsSource{
+		Secret: &awsclient.SecretCredentialsSource{
+			Ref: &corev1.LocalObjectReference{},
+		},
+		AssumeRole: &awsclient.AssumeRoleCredentialsSource{
+			Role: &hivev1aws.AssumeRole{RoleARN: roleARN},
+		},
+	}
+}
+
+func expectGetCallerIdentity(client *mockaws.MockClient, account string) *gomock.Call {
+	return client.EXPECT().GetCallerIdentity(gomock.Any()).
+		Return(&sts.GetCallerIdentityOutput{Account: aws.String(account)}, nil)
+}
+
+func TestAWSRateLimiters(t *testing.T) {
+	tests := []struct {
+		name          string
+		credentials   []awsclient.CredentialsSource
+		mockAWSClient func(*mockaws.MockClient)
+		expectShared  bool
+	}{
+		{
+			name: "same secret",
+			credentials: []awsclient.CredentialsSource{
+				secretCredentials("ns1", "creds"),
+				secretCredentials("ns1", "creds"),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectGetCallerIdentity(client, "111111111111").Times(1)
+			},
+			expectShared: true,
+		},
+		{
+			name: "different secrets in the same account",
+			credentials: []awsclient.CredentialsSource{
+				secretCredentials("ns1", "creds"),
+				secretCredentials("ns2", "creds"),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectGetCallerIdentity(client, "111111111111").Times(2)
+			},
+			expectShared: true,
+		},
+		{
+			name: "different accounts",
+			credentials: []awsclient.CredentialsSource{
+				secretCredentials("ns1", "creds"),
+				secretCredentials("ns2", "creds"),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				gomock.InOrder(
+					expectGetCallerIdentity(client, "111111111111"),
+					expectGetCallerIdentity(client, "222222222222"),
+				)
+			},
+		},
+		{
+			name: "assumed role in the same account",
+			credentials: []awsclient.CredentialsSource{
+				secretCredentials("ns1", "creds"),
+				roleCredentials("arn:aws:iam::111111111111:role/hive"),
+			},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectGetCallerIdentity(client, "111111111111").Times(1)
+			},
+			expectShared: true,
+		},
+		{
+			name: "assumed roles in different accounts",
+			credentials: []awsclient.CredentialsSource{
+				roleCredentials("arn:aws:iam::111111111111:role/hive"),
+				roleCredentials("arn:aws:iam::222222222222:role/hive"),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			if test.mockAWSClient != nil {
+				test.mockAWSClient(awsClient)
+			}
+
+			limiters := newAWSRateLimiters(1, 1)
+			var rateLimiters []*rate.Limiter
+			for _, credentials := range test.credentials {
+				client, err := limiters.wrap(context.Background(), awsClient, credentials)
+				require.NoError(t, err, "unexpected error")
+				rateLimiters = append(rateLimiters, client.(*rateLimitedAWSClient).limiter)
+			}
+			if test.expectShared {
+				assert.Same(t, rateLimiters[0], rateLimiters[1], "expected the rate limiter to be shared")
+			} else {
+				assert.NotSame(t, rateLimiters[0], rateLimiters[1], "expected separate rate limiters")
+			}
+		})
+	}
+}
+
+func TestNilAWSRateLimitersGetAccount(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	awsClient := mockaws.NewMockClient(mockCtrl)
+	expectGetCallerIdentity(awsClient, "111111111111").Times(2)
+
+	var limiters *awsRateLimiters
+	for i := 0; i < 2; i++ {
+		account, err := limiters.getAccount(awsClient, secretCredentials("ns1", "creds"))
+		require.NoError(t, err, "unexpected error")
+		assert.Equal(t, "111111111111", account, "unexpected account")
+	}
+	account, err := limiters.getAccount(awsClient, roleCredentials("arn:aws:iam::222222222222:role/hive"))
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, "222222222222", account, "unexpected account")
+}
+
+func TestRateLimitedAWSClient(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	awsClient := mockaws.NewMockClient(mockCtrl)
+	output := &ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{testSubnet("subnet-1", "zone1", "vpc-1", false)}}
+	awsClient.EXPECT().DescribeSubnets(gomock.Any()).Return(output, nil).Times(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &rateLimitedAWSClient{
+		Client:  awsClient,
+		ctx:     ctx,
+		limiter: rate.NewLimiter(rate.Limit(0.001), 1),
+	}
+
+	actual, err := client.DescribeSubnets(&ec2.DescribeSubnetsInput{})
+	require.NoError(t, err, "unexpected error within the burst")
+	assert.Equal(t, output, actual, "unexpected output")
+
+	// The burst is used up, so the next call waits until the context is done and is not made.
+	cancel()
+	_, err = client.DescribeSubnets(&ec2.DescribeSubnetsInput{})
+	assert.Error(t, err, "expected an error waiting for the rate limit")
+}
+
+func Test_getAWSRateLimiters(t *testing.T) {
+	cases := []struct {
+		name          string
+		qps           string
+		burst         string
+		expectNil     bool
+		expectedLimit rate.Limit
+		expectedBurst int
+		expectErr     bool
+	}{
+		{
+			name:      "not configured",
+			expectNil: true,
+		},
+		{
+			name:          "qps only",
+			qps:           "5",
+			expectedLimit: 5,
+			expectedBurst: 5,
+		},
+		{
+			name:          "qps and burst",
+			qps:           "5",
+			burst:         "20",
+			expectedLimit: 5,
+			expectedBurst: 20,
+		},
+		{
+			name:      "invalid qps",
+			qps:       "0",
+			expectErr: true,
+		},
+		{
+			name:      "invalid burst",
+			qps:       "5",
+			burst:     "many",
+			expectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for envVar, value := range map[string]string{
+				constants.MachinePoolAWSAPIQPSEnvVar:   tc.qps,
+				constants.MachinePoolAWSAPIBurstEnvVar: tc.burst,
+			} {
+				if value != "" {
+					os.Setenv(envVar, value)
+					defer os.Unsetenv(envVar)
+				}
+			}
+
+			limiters, err := getAWSRateLimiters()
+			if tc.expectErr {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			if tc.expectNil {
+				assert.Nil(t, limiters, "expected no rate limiting")
+				return
+			}
+			if assert.NotNil(t, limiters, "expected rate limiting") {
+				assert.Equal(t, tc.expectedLimit, limiters.limit, "unexpected limit")
+				assert.Equal(t, tc.expectedBurst, limiters.burst, "unexpected burst")
+			}
+		})
+	}
+}
+
+func TestRateLimitedAWSClientDescribeImages(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	awsClient := mockaws.NewMockClient(mockCtrl)
+	output := &ec2.DescribeImagesOutput{Images: []*ec2.Image{{ImageId: aws.String("ami-1"), State: aws.String(ec2.ImageStateAvailable)}}}
+	awsClient.EXPECT().DescribeImages(gomock.Any()).Return(output, nil).Times(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &rateLimitedAWSClient{
+		Client:  awsClient,
+		ctx:     ctx,
+		limiter: rate.NewLimiter(rate.Limit(0.001), 1),
+	}
+
+	actual, err := client.DescribeImages(&ec2.DescribeImagesInput{ImageIds: aws.StringSlice([]string{"ami-1"})})
+	require.NoError(t, err, "unexpected error within the burst")
+	assert.Equal(t, output, actual, "unexpected output")
+
+	// DescribeImages shares the rate limit of the other describe calls, so it is not made once the burst is used up.
+	cancel()
+	_, err = client.DescribeImages(&ec2.DescribeImagesInput{ImageIds: aws.StringSlice([]string{"ami-1"})})
+	assert.Error(t, err, "expected an error waiting for the rate limit")
+}
@@ -0,0 +1,226 @@
+package machinepool
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/hive/pkg/awsclient"
+	"github.com/openshift/hive/pkg/constants"
+)
+
+const (
+	defaultAWSRetries       = 3
+	defaultAWSRetryInterval = 500 * time.Millisecond
+)
+
+// retryingAWSClient retries the AWS describe calls made by the AWS actuator when they fail with a transient error,
+// so that throttling and server errors are absorbed within a reconcile instead of failing it. All other calls are
+// passed through to the wrapped client.
+type retryingAWSClient struct {
+	awsclient.Client
+
+	ctx     context.Context
+	backoff wait.Backoff
+	logger  log.FieldLogger
+}
+
+func newRetryingAWSClient(ctx context.Context, client awsclient.Client, backoff wait.Backoff, logger log.FieldLogger) awsclient.Client {
+	return &retryingAWSClient{
+		Client:  client,
+		ctx:     ctx,
+		backoff: backoff,
+		logger:  logger,
+	}
+}
+
+func (c *retryingAWSClient) DescribeAvailabilityZones(input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	var output *ec2.DescribeAvailabilityZonesOutput
+	err := c.retry("DescribeAvailabilityZones", func() (err error) {
+		output, err = c.Client.DescribeAvailabilityZones(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	var output *ec2.DescribeSubnetsOutput
+	err := c.retry("DescribeSubnets", func() (err error) {
+		output, err = c.Client.DescribeSubnets(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) DescribeRouteTables(input *ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error) {
+	var output *ec2.DescribeRouteTablesOutput
+	err := c.retry("DescribeRouteTables", func() (err error) {
+		output, err = c.Client.DescribeRouteTables(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) DescribeInstanceTypes(input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	var output *ec2.DescribeInstanceTypesOutput
+	err := c.retry("DescribeInstanceTypes", func() (err error) {
+		output, err = c.Client.DescribeInstanceTypes(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) DescribeInstanceTypeOfferings(input *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	var output *ec2.DescribeInstanceTypeOfferingsOutput
+	err := c.retry("DescribeInstanceTypeOfferings", func() (err error) {
+		output, err = c.Client.DescribeInstanceTypeOfferings(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) GetSerialConsoleAccessStatus(input *ec2.GetSerialConsoleAccessStatusInput) (*ec2.GetSerialConsoleAccessStatusOutput, error) {
+	var output *ec2.GetSerialConsoleAccessStatusOutput
+	err := c.retry("GetSerialConsoleAccessStatus", func() (err error) {
+		output, err = c.Client.GetSerialConsoleAccessStatus(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) DescribeSpotPriceHistory(input *ec2.DescribeSpotPriceHistoryInput) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+	var output *ec2.DescribeSpotPriceHistoryOutput
+	err := c.retry("DescribeSpotPriceHistory", func() (err error) {
+		output, err = c.Client.DescribeSpotPriceHistory(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) DescribeSecurityGroups(input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	var output *ec2.DescribeSecurityGroupsOutput
+	err := c.retry("DescribeSecurityGroups", func() (err error) {
+		output, err = c.Client.DescribeSecurityGroups(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	var output *ec2.DescribeImagesOutput
+	err := c.retry("DescribeImages", func() (err error) {
+		output, err = c.Client.DescribeImages(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) GetInstanceProfile(input *iam.GetInstanceProfileInput) (*iam.GetInstanceProfileOutput, error) {
+	var output *iam.GetInstanceProfileOutput
+	err := c.retry("GetInstanceProfile", func() (err error) {
+		output, err = c.Client.GetInstanceProfile(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) DescribeKey(input *awsclient.DescribeKeyInput) (*awsclient.DescribeKeyOutput, error) {
+	var output *awsclient.DescribeKeyOutput
+	err := c.retry("DescribeKey", func() (err error) {
+		output, err = c.Client.DescribeKey(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) GetServiceQuota(input *awsclient.GetServiceQuotaInput) (*awsclient.GetServiceQuotaOutput, error) {
+	var output *awsclient.GetServiceQuotaOutput
+	err := c.retry("GetServiceQuota", func() (err error) {
+		output, err = c.Client.GetServiceQuota(input)
+		return
+	})
+	return output, err
+}
+
+func (c *retryingAWSClient) GetAWSDefaultServiceQuota(input *awsclient.GetServiceQuotaInput) (*awsclient.GetServiceQuotaOutput, error) {
+	var output *awsclient.GetServiceQuotaOutput
+	err := c.retry("GetAWSDefaultServiceQuota", func() (err error) {
+		output, err = c.Client.GetAWSDefaultServiceQuota(input)
+		return
+	})
+	return output, err
+}
+
+// retry calls fn until it succeeds, fails with an error that is not transient, the backoff steps are exhausted or
+// the context is done. Returns the last error from fn, or the context error if the context is done.
+func (c *retryingAWSClient) retry(call string, fn func() error) error {
+	backoff := c.backoff
+	if backoff.Steps < 1 {
+		// Always make the call at least once.
+		backoff.Steps = 1
+	}
+	var lastErr error
+	err := wait.ExponentialBackoffWithContext(c.ctx, backoff, func() (bool, error) {
+		lastErr = fn()
+		switch {
+		case lastErr == nil:
+			return true, nil
+		case isTransientAWSError(lastErr):
+			c.logger.WithError(lastErr).WithField("call", call).Debug("transient AWS error, retrying")
+			return false, nil
+		default:
+			return false, lastErr
+		}
+	})
+	if err == wait.ErrWaitTimeout {
+		return lastErr
+	}
+	return err
+}
+
+// isTransientAWSError returns true if the error is from AWS throttling the request or from a server error.
+func isTransientAWSError(err error) bool {
+	if request.IsErrorThrottle(err) {
+		return true
+	}
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		return reqErr.StatusCode() >= 500
+	}
+	return false
+}
+
+// getAWSRetryBackoff returns the backoff for retrying AWS describe calls, as configured by the
+// MACHINEPOOL_AWS_RETRIES and MACHINEPOOL_AWS_RETRY_INTERVAL environment variables.
+func getAWSRetryBackoff() (wait.Backoff, error) {
+	retries := defaultAWSRetries
+	if value, ok := os.LookupEnv(constants.MachinePoolAWSRetriesEnvVar); ok {
+		var err error
+		if retries, err = strconv.Atoi(value); err != nil || retries < 0 {
+			return wait.Backoff{}, errors.Errorf("invalid %s: %q", constants.MachinePoolAWSRetriesEnvVar, value)
+		}
+	}
+	interval := defaultAWSRetryInterval
+	if value, ok := os.LookupEnv(constants.MachinePoolAWSRetryIntervalEnvVar); ok {
+		var err error
+		if interval, err = time.ParseDuration(value); err != nil || interval <= 0 {
+			return wait.Backoff{}, errors.Errorf("invalid %s: %q", constants.MachinePoolAWSRetryIntervalEnvVar, value)
+		}
+	}
+	return wait.Backoff{
+		// The first step is the initial call.
+		Steps:    retries + 1,
+		Duration: interval,
+		Factor:   2,
+		Jitter:   0.1,
+	}, nil
+}
@@ -0,0 +1,201 @@
+package machinepool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	mockaws "github.com/openshift/hive/pkg/awsclient/mock"
+	"github.com/openshift/hive/pkg/constants"
+)
+
+func TestRetryingAWSClient(t *testing.T) {
+	throttleErr := awserr.New("Throttling", "Rate exceeded", nil)
+	serverErr := awserr.NewRequestFailure(awserr.New("InternalError", "An internal error has occurred", nil), 500, "request-id")
+	invalidErr := awserr.NewRequestFailure(awserr.New("InvalidSubnetID.NotFound", "The subnet ID 'subnet-1' does not exist", nil), 400, "request-id")
+	output := &ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{testSubnet("subnet-1", "zone1", "vpc-1", false)}}
+
+	tests := []struct {
+		name           string
+		failures       int
+		failureErr     error
+		noSuccess      bool
+		cancelled      bool
+		expectedErr    error
+		expectedOutput *ec2.DescribeSubnetsOutput
+	}{
+		{
+			name:           "success",
+			expectedOutput: output,
+		},
+		{
+			name:           "success after throttling",
+			failures:       2,
+			failureErr:     throttleErr,
+			expectedOutput: output,
+		},
+		{
+			name:           "success after server errors",
+			failures:       3,
+			failureErr:     serverErr,
+			expectedOutput: output,
+		},
+		{
+			name:        "retries exhausted",
+			failures:    4,
+			failureErr:  throttleErr,
+			noSuccess:   true,
+			expectedErr: throttleErr,
+		},
+		{
+			name:        "error not retried",
+			failures:    1,
+			failureErr:  invalidErr,
+			noSuccess:   true,
+			expectedErr: invalidErr,
+		},
+		{
+			name:        "context cancelled",
+			cancelled:   true,
+			noSuccess:   true,
+			expectedErr: context.Canceled,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			input := &ec2.DescribeSubnetsInput{SubnetIds: []*string{aws.String("subnet-1")}}
+			if test.failures > 0 {
+				awsClient.EXPECT().DescribeSubnets(input).Return(nil, test.failureErr).Times(test.failures)
+			}
+			if !test.noSuccess {
+				awsClient.EXPECT().DescribeSubnets(input).Return(output, nil)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancelled {
+				cancel()
+			}
+			backoff := wait.Backoff{Steps: 4, Duration: time.Millisecond, Factor: 2}
+			client := newRetryingAWSClient(ctx, awsClient, backoff, log.WithField("test", test.name))
+
+			actualOutput, err := client.DescribeSubnets(input)
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr, err, "unexpected error")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+			}
+			assert.Equal(t, test.expectedOutput, actualOutput, "unexpected output")
+		})
+	}
+}
+
+func Test_isTransientAWSError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "throttling",
+			err:      awserr.New("RequestLimitExceeded", "Request limit exceeded.", nil),
+			expected: true,
+		},
+		{
+			name:     "service unavailable",
+			err:      awserr.NewRequestFailure(awserr.New("Unavailable", "Service unavailable", nil), 503, "request-id"),
+			expected: true,
+		},
+		{
+			name: "client error",
+			err:  awserr.NewRequestFailure(awserr.New("InvalidVpcID.NotFound", "The vpc ID 'vpc-1' does not exist", nil), 400, "request-id"),
+		},
+		{
+			name: "other error",
+			err:  errors.New("InvalidSubnets"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isTransientAWSError(tc.err), "unexpected result")
+		})
+	}
+}
+
+func Test_getAWSRetryBackoff(t *testing.T) {
+	cases := []struct {
+		name             string
+		retries          string
+		interval         string
+		expectErr        bool
+		expectedSteps    int
+		expectedInterval time.Duration
+	}{
+		{
+			name:             "defaults",
+			expectedSteps:    defaultAWSRetries + 1,
+			expectedInterval: defaultAWSRetryInterval,
+		},
+		{
+			name:             "configured",
+			retries:          "5",
+			interval:         "2s",
+			expectedSteps:    6,
+			expectedInterval: 2 * time.Second,
+		},
+		{
+			name:             "retries disabled",
+			retries:          "0",
+			expectedSteps:    1,
+			expectedInterval: defaultAWSRetryInterval,
+		},
+		{
+			name:      "invalid retries",
+			retries:   "-1",
+			expectErr: true,
+		},
+		{
+			name:      "invalid interval",
+			interval:  "soon",
+			expectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for envVar, value := range map[string]string{
+				constants.MachinePoolAWSRetriesEnvVar:       tc.retries,
+				constants.MachinePoolAWSRetryIntervalEnvVar: tc.interval,
+			} {
+				if value != "" {
+					os.Setenv(envVar, value)
+					defer os.Unsetenv(envVar)
+				}
+			}
+
+			backoff, err := getAWSRetryBackoff()
+			if tc.expectErr {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, tc.expectedSteps, backoff.Steps, "unexpected steps")
+			assert.Equal(t, tc.expectedInterval, backoff.Duration, "unexpected interval")
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package machinepool
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/openshift/hive/pkg/awsclient"
+)
+
+// awsSingleflight collapses the identical AWS describe calls made concurrently by the actuators of the MachinePools of
+// the same cluster, such as the DescribeAvailabilityZones and DescribeRouteTables calls each pool of the cluster
+// makes, into a single call whose result is shared by all of them.
+type awsSingleflight struct {
+	group singleflight.Group
+}
+
+func newAWSSingleflight() *awsSingleflight {
+	return &awsSingleflight{}
+}
+
+// wrap returns a client whose DescribeAvailabilityZones, DescribeSubnets and DescribeRouteTables calls share their
+// results with the identical calls in flight for the same cluster and credentials. The shared outputs must not be
+// modified. A nil awsSingleflight does not collapse the calls.
+func (s *awsSingleflight) wrap(client awsclient.Client, namespace, clusterName string, credentials awsclient.CredentialsSource) awsclient.Client {
+	if s == nil {
+		return client
+	}
+	var secret, role string
+	if ref := credentials.Secret; ref != nil && ref.Ref != nil && ref.Ref.Name != "" {
+		secret = ref.Ref.Name
+	}
+	if assumeRole := credentials.AssumeRole; assumeRole != nil && assumeRole.Role != nil {
+		role = assumeRole.Role.RoleARN
+	}
+	return &singleflightAWSClient{
+		Client: client,
+		group:  &s.group,
+		scope:  fmt.Sprintf("%s/%s secret=%s role=%s", namespace, clusterName, secret, role),
+	}
+}
+
+// singleflightAWSClient collapses its describe calls with the identical calls in flight in the same scope.
+type singleflightAWSClient struct {
+	awsclient.Client
+
+	group *singleflight.Group
+	// scope identifies the cluster and the credentials of the client, as the calls of other clusters or with other
+	// credentials may return different results.
+	scope string
+}
+
+// do calls fn, unless an identical call is already in flight in the scope of the client, in which case it waits for
+// that call and returns its result instead.
+func (c *singleflightAWSClient) do(call string, input fmt.Stringer, fn func() (interface{}, error)) (interface{}, error) {
+	output, err, _ := c.group.Do(fmt.Sprintf("%s %s %s", c.scope, call, input), fn)
+	return output, err
+}
+
+func (c *singleflightAWSClient) DescribeAvailabilityZones(input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	output, err := c.do("DescribeAvailabilityZones", input, func() (interface{}, error) {
+		return c.Client.DescribeAvailabilityZones(input)
+	})
+	zones, _ := output.(*ec2.DescribeAvailabilityZonesOutput)
+	return zones, err
+}
+
+func (c *singleflightAWSClient) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	output, err := c.do("DescribeSubnets", input, func() (interface{}, error) {
+		return c.Client.DescribeSubnets(input)
+	})
+	subnets, _ := output.(*ec2.DescribeSubnetsOutput)
+	return subnets, err
+}
+
+func (c *singleflightAWSClient) DescribeRouteTables(input *ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error) {
+	output, err := c.do("DescribeRouteTables", input, func() (interface{}, error) {
+		return c.Client.DescribeRouteTables(input)
+	})
+	routeTables, _ := output.(*ec2.DescribeRouteTablesOutput)
+	return routeTables, err
+}
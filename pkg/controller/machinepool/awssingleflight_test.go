@@ -0,0 +1,116 @@
+package machinepool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/hive/pkg/awsclient"
+	mockaws "github.com/openshift/hive/pkg/awsclient/mock"
+)
+
+func TestAWSSingleflight(t *testing.T) {
+	type caller struct {
+		cluster     string
+		credentials awsclient.CredentialsSource
+		zoneNames   []string
+	}
+	sameCaller := caller{cluster: "cluster1", credentials: secretCredentials("ns1", "creds")}
+	tests := []struct {
+		name          string
+		singleflight  *awsSingleflight
+		callers       []caller
+		expectedCalls int32
+	}{
+		{
+			name:          "same cluster",
+			singleflight:  newAWSSingleflight(),
+			callers:       []caller{sameCaller, sameCaller, sameCaller, sameCaller},
+			expectedCalls: 1,
+		},
+		{
+			name:         "different clusters",
+			singleflight: newAWSSingleflight(),
+			callers: []caller{
+				sameCaller,
+				{cluster: "cluster2", credentials: secretCredentials("ns1", "creds")},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:         "different credentials",
+			singleflight: newAWSSingleflight(),
+			callers: []caller{
+				sameCaller,
+				{cluster: "cluster1", credentials: roleCredentials("arn:aws:iam::123456789012:role/pool")},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:         "different parameters",
+			singleflight: newAWSSingleflight(),
+			callers: []caller{
+				sameCaller,
+				{cluster: "cluster1", credentials: secretCredentials("ns1", "creds"), zoneNames: []string{"zone1"}},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:          "no singleflight",
+			callers:       []caller{sameCaller, sameCaller},
+			expectedCalls: 2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockClient := mockaws.NewMockClient(mockCtrl)
+
+			var calls int32
+			release := make(chan struct{})
+			mockClient.EXPECT().DescribeAvailabilityZones(gomock.Any()).DoAndReturn(
+				func(*ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+					atomic.AddInt32(&calls, 1)
+					<-release
+					return &ec2.DescribeAvailabilityZonesOutput{
+						AvailabilityZones: []*ec2.AvailabilityZone{{ZoneName: aws.String("zone1")}},
+					}, nil
+				}).Times(int(test.expectedCalls))
+
+			var started, done sync.WaitGroup
+			outputs := make([]*ec2.DescribeAvailabilityZonesOutput, len(test.callers))
+			errs := make([]error, len(test.callers))
+			for i, c := range test.callers {
+				client := test.singleflight.wrap(mockClient, "ns1", c.cluster, c.credentials)
+				input := &ec2.DescribeAvailabilityZonesInput{ZoneNames: aws.StringSlice(c.zoneNames)}
+				started.Add(1)
+				done.Add(1)
+				go func(i int) {
+					defer done.Done()
+					started.Done()
+					outputs[i], errs[i] = client.DescribeAvailabilityZones(input)
+				}(i)
+			}
+			// Give the calls time to be in flight together before they return.
+			started.Wait()
+			time.Sleep(100 * time.Millisecond)
+			close(release)
+			done.Wait()
+
+			assert.Equal(t, test.expectedCalls, atomic.LoadInt32(&calls), "unexpected number of calls of the underlying client")
+			for i := range test.callers {
+				assert.NoError(t, errs[i], "unexpected error")
+				if assert.NotNil(t, outputs[i], "missing output") {
+					assert.Equal(t, "zone1", aws.StringValue(outputs[i].AvailabilityZones[0].ZoneName), "unexpected output")
+				}
+			}
+		})
+	}
+}
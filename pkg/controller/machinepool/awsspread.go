@@ -0,0 +1,68 @@
+package machinepool
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hivev1aws "github.com/openshift/hive/apis/hive/v1/aws"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+// setZoneSpreadCondition sets the UnevenZoneSpread condition according to whether the replicas of the MachinePool
+// split evenly across the given availability zones, describing the replicas of each zone. The replicas of pools with
+// the StrictEven spread policy must split evenly, so an uneven split is returned as a *ValidationError, as no
+// MachineSets can be generated for the pool. Otherwise the first zones get the replicas which cannot be split evenly,
+// as the installer splits them.
+func setZoneSpreadCondition(pool *hivev1.MachinePool, zones []string, logger log.FieldLogger) error {
+	minReplicas, maxReplicas := zoneReplicas(pool, zones, false), zoneReplicas(pool, zones, true)
+	spread := make([]string, len(zones))
+	for i, zone := range zones {
+		spread[i] = fmt.Sprintf("%s (%d)", zone, minReplicas[zone])
+		if minReplicas[zone] != maxReplicas[zone] {
+			spread[i] = fmt.Sprintf("%s (%d-%d)", zone, minReplicas[zone], maxReplicas[zone])
+		}
+	}
+	status, reason := corev1.ConditionFalse, "EvenSpread"
+	message := fmt.Sprintf("The replicas are spread evenly across the availability zones: %s", strings.Join(spread, ", "))
+	if !evenlySpread(zones, minReplicas) || !evenlySpread(zones, maxReplicas) {
+		reason = "BestEffortSpread"
+		message = fmt.Sprintf("The replicas are spread as evenly as possible across the availability zones: %s", strings.Join(spread, ", "))
+		if pool.Spec.Platform.AWS.SpreadPolicy == hivev1aws.SpreadPolicyStrictEven {
+			logger.WithField("zones", len(zones)).Info("replicas cannot be spread evenly across the availability zones")
+			status, reason = corev1.ConditionTrue, "ReplicasNotDivisibleByZones"
+			message = fmt.Sprintf("The StrictEven spread policy requires the same replicas in each of the %d availability zones: %s",
+				len(zones), strings.Join(spread, ", "))
+		}
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.UnevenZoneSpreadMachinePoolCondition,
+		status,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	if status == corev1.ConditionTrue {
+		return &ValidationError{
+			Type:    hivev1.UnevenZoneSpreadMachinePoolCondition,
+			Reason:  reason,
+			Message: message,
+		}
+	}
+	return nil
+}
+
+// evenlySpread returns whether the given availability zones all have the same replicas.
+func evenlySpread(zones []string, replicas map[string]int64) bool {
+	for _, zone := range zones {
+		if replicas[zone] != replicas[zones[0]] {
+			return false
+		}
+	}
+	return true
+}
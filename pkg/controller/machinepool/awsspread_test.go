@@ -0,0 +1,116 @@
+package machinepool
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hivev1aws "github.com/openshift/hive/apis/hive/v1/aws"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+func TestSetZoneSpreadCondition(t *testing.T) {
+	cases := []struct {
+		name            string
+		spreadPolicy    hivev1aws.SpreadPolicy
+		replicas        *int64
+		autoscaling     *hivev1.MachinePoolAutoscaling
+		expectErr       bool
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:            "even replicas",
+			replicas:        pointer.Int64(6),
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  "EvenSpread",
+			expectedMessage: "The replicas are spread evenly across the availability zones: zone1 (2), zone2 (2), zone3 (2)",
+		},
+		{
+			name:            "uneven replicas with best-effort spread",
+			replicas:        pointer.Int64(4),
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  "BestEffortSpread",
+			expectedMessage: "The replicas are spread as evenly as possible across the availability zones: zone1 (2), zone2 (1), zone3 (1)",
+		},
+		{
+			name:           "even replicas with strict spread",
+			spreadPolicy:   hivev1aws.SpreadPolicyStrictEven,
+			replicas:       pointer.Int64(3),
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "EvenSpread",
+		},
+		{
+			name:            "uneven replicas with strict spread",
+			spreadPolicy:    hivev1aws.SpreadPolicyStrictEven,
+			replicas:        pointer.Int64(4),
+			expectErr:       true,
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "ReplicasNotDivisibleByZones",
+			expectedMessage: "The StrictEven spread policy requires the same replicas in each of the 3 availability zones: zone1 (2), zone2 (1), zone3 (1)",
+		},
+		{
+			name:            "even autoscaling with strict spread",
+			spreadPolicy:    hivev1aws.SpreadPolicyStrictEven,
+			autoscaling:     &hivev1.MachinePoolAutoscaling{MinReplicas: 3, MaxReplicas: 9},
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  "EvenSpread",
+			expectedMessage: "The replicas are spread evenly across the availability zones: zone1 (1-3), zone2 (1-3), zone3 (1-3)",
+		},
+		{
+			name:           "uneven maximum replicas with strict spread",
+			spreadPolicy:   hivev1aws.SpreadPolicyStrictEven,
+			autoscaling:    &hivev1.MachinePoolAutoscaling{MinReplicas: 3, MaxReplicas: 10},
+			expectErr:      true,
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "ReplicasNotDivisibleByZones",
+		},
+		{
+			name:         "uneven zone override with strict spread",
+			spreadPolicy: hivev1aws.SpreadPolicyStrictEven,
+			autoscaling: &hivev1.MachinePoolAutoscaling{
+				MinReplicas: 3,
+				MaxReplicas: 9,
+				ZoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+					"zone2": {MinReplicas: 1, MaxReplicas: 5},
+				},
+			},
+			expectErr:      true,
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "ReplicasNotDivisibleByZones",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.SpreadPolicy = tc.spreadPolicy
+			pool.Spec.Replicas = tc.replicas
+			pool.Spec.Autoscaling = tc.autoscaling
+
+			err := setZoneSpreadCondition(pool, []string{"zone1", "zone2", "zone3"}, log.StandardLogger())
+			if tc.expectErr {
+				var validationErr *ValidationError
+				if assert.ErrorAs(t, err, &validationErr, "expected validation error") {
+					assert.Equal(t, hivev1.UnevenZoneSpreadMachinePoolCondition, validationErr.Type, "unexpected validation error type")
+				}
+			} else {
+				assert.NoError(t, err, "unexpected error")
+			}
+
+			cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.UnevenZoneSpreadMachinePoolCondition)
+			require.NotNil(t, cond, "missing UnevenZoneSpread condition")
+			assert.Equal(t, tc.expectedStatus, cond.Status, "unexpected condition status")
+			assert.Equal(t, tc.expectedReason, cond.Reason, "unexpected condition reason")
+			if tc.expectedMessage != "" {
+				assert.Equal(t, tc.expectedMessage, cond.Message, "unexpected condition message")
+			}
+		})
+	}
+}
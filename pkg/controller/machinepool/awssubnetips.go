@@ -0,0 +1,160 @@
+package machinepool
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/hive/pkg/constants"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+// getAWSSubnetIPCheck returns whether the available IP addresses of the private subnets of AWS MachinePools are
+// checked, as configured by the MACHINEPOOL_AWS_SUBNET_IP_CHECK environment variable.
+func getAWSSubnetIPCheck() (bool, error) {
+	value, ok := os.LookupEnv(constants.MachinePoolAWSSubnetIPCheckEnvVar)
+	if !ok || value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.Errorf("invalid %s: %q", constants.MachinePoolAWSSubnetIPCheckEnvVar, value)
+	}
+	return enabled, nil
+}
+
+// poolZoneMachines returns the number of machines of the MachineSets of the MachinePool in the remote cluster by
+// availability zone. MachineSets whose provider spec cannot be decoded are skipped.
+func poolZoneMachines(pool *hivev1.MachinePool, remoteMachineSets []machineapi.MachineSet, scheme *runtime.Scheme, logger log.FieldLogger) map[string]int64 {
+	machines := map[string]int64{}
+	for i, ms := range remoteMachineSets {
+		if ms.Labels[machinePoolNameLabel] != pool.Spec.Name {
+			continue
+		}
+		providerConfig, err := machineSetAWSProviderConfig(&remoteMachineSets[i], scheme)
+		if err != nil {
+			logger.WithError(err).WithField("machineset", ms.Name).Warn("could not decode the provider spec of the machineset")
+			continue
+		}
+		machines[providerConfig.Placement.AvailabilityZone] += int64(ms.Status.Replicas)
+	}
+	return machines
+}
+
+// setSubnetIPsCondition sets the InsufficientSubnetIPs condition according to whether the machines the MachinePool
+// adds in the given availability zones exceed the available IP addresses of the private subnets of those zones: the
+// subnets of the zones by ID, or else the subnets named by the resource names of the pool. The condition is left
+// unchanged when the subnets cannot be described, as the machines can be generated regardless.
+func (a *AWSActuator) setSubnetIPsCondition(pool *hivev1.MachinePool, infraID string, zones []string, subnets map[string]string, logger log.FieldLogger) {
+	status, reason, message := corev1.ConditionFalse, "SubnetIPCheckDisabled", "The available IP addresses of the subnets are not checked"
+	if a.subnetIPCheck {
+		exceeded, err := a.exceededSubnetIPs(pool, infraID, zones, subnets, logger)
+		if err != nil {
+			logger.WithError(err).Warn("could not check the available IP addresses of the subnets")
+			return
+		}
+		status, reason, message = corev1.ConditionFalse, "SufficientSubnetIPs", "The subnets have enough available IP addresses for the machines"
+		if len(exceeded) > 0 {
+			logger.WithField("subnets", exceeded).Info("machines likely exceed the available IP addresses of the subnets")
+			status, reason = corev1.ConditionTrue, "SubnetIPsExceeded"
+			message = fmt.Sprintf("The machines likely exceed the available IP addresses of the subnets: %s", strings.Join(exceeded, "; "))
+		}
+	}
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.InsufficientSubnetIPsMachinePoolCondition,
+		status,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+}
+
+// exceededSubnetIPs returns a description of each availability zone where the machines the MachinePool adds, beyond
+// those it already has there, exceed the available IP addresses of the private subnet of the zone. The maximum
+// replicas of auto-scaling pools are used. Zones whose subnet is not found are skipped, as missing subnets are
+// reported by other conditions.
+func (a *AWSActuator) exceededSubnetIPs(pool *hivev1.MachinePool, infraID string, zones []string, subnets map[string]string, logger log.FieldLogger) ([]string, error) {
+	zoneSubnets, err := a.describeZoneSubnets(pool, infraID, zones, subnets, logger)
+	if err != nil {
+		return nil, err
+	}
+	replicas := zoneMaxReplicas(pool, zones)
+	var exceeded []string
+	for _, zone := range zones {
+		subnet, ok := zoneSubnets[zone]
+		if !ok {
+			continue
+		}
+		added := replicas[zone] - a.zoneMachines[zone]
+		if available := aws.Int64Value(subnet.AvailableIpAddressCount); added > available {
+			exceeded = append(exceeded, fmt.Sprintf("%d machines added in %s, which has %d available IP addresses",
+				added, aws.StringValue(subnet.SubnetId), available))
+		}
+	}
+	return exceeded, nil
+}
+
+// describeZoneSubnets describes the private subnets of the given availability zones of the MachinePool: the subnets of
+// the zones by ID, or else the subnets named by the resource names of the pool. Returns the subnets by zone, without the
+// zones whose subnet is not found.
+func (a *AWSActuator) describeZoneSubnets(pool *hivev1.MachinePool, infraID string, zones []string, subnets map[string]string, logger log.FieldLogger) (map[string]*ec2.Subnet, error) {
+	var subnetIDs []string
+	names := sets.NewString()
+	template := resourceNameTemplates(pool).PrivateSubnet
+	for _, zone := range zones {
+		if id, ok := subnets[zone]; ok {
+			subnetIDs = append(subnetIDs, id)
+		} else {
+			names.Insert(expandResourceName(template, infraID, zone))
+		}
+	}
+	described, err := describeSubnets(a.awsClient, subnetIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "describing subnets")
+	}
+	if names.Len() > 0 {
+		resp, err := a.awsClient.DescribeSubnets(&ec2.DescribeSubnetsInput{
+			Filters: []*ec2.Filter{{Name: aws.String("tag:Name"), Values: aws.StringSlice(names.List())}},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "describing subnets by name")
+		}
+		described = append(described, resp.Subnets...)
+	}
+	byID := make(map[string]*ec2.Subnet, len(described))
+	byName := make(map[string]*ec2.Subnet, len(described))
+	for _, subnet := range described {
+		byID[aws.StringValue(subnet.SubnetId)] = subnet
+		if name, ok := findTag(subnet.Tags, "Name"); ok {
+			byName[name] = subnet
+		}
+	}
+
+	zoneSubnets := make(map[string]*ec2.Subnet, len(zones))
+	for _, zone := range zones {
+		subnet, ok := byID[subnets[zone]]
+		if !ok {
+			subnet, ok = byName[expandResourceName(template, infraID, zone)]
+		}
+		if !ok {
+			logger.WithField("zone", zone).Debug("no subnet found for availability zone")
+			continue
+		}
+		zoneSubnets[zone] = subnet
+	}
+	return zoneSubnets, nil
+}
@@ -0,0 +1,154 @@
+package machinepool
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	awsprovider "sigs.k8s.io/cluster-api-provider-aws/pkg/apis/awsprovider/v1beta1"
+
+	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	mockaws "github.com/openshift/hive/pkg/awsclient/mock"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+func TestPoolZoneMachines(t *testing.T) {
+	machineSet := func(name, poolName, zone string, replicas int32) machineapi.MachineSet {
+		ms := testMachineSet(name, poolName, false, int(replicas), 0)
+		providerSpec := testAWSProviderSpec()
+		providerSpec.Placement.AvailabilityZone = zone
+		rawProviderSpec, err := encodeAWSMachineProviderSpec(providerSpec, scheme.Scheme)
+		require.NoError(t, err)
+		ms.Spec.Template.Spec.ProviderSpec.Value = rawProviderSpec
+		ms.Status.Replicas = replicas
+		return *ms
+	}
+	machineSets := []machineapi.MachineSet{
+		machineSet("foo-12345-worker-zone1", testPoolName, "zone1", 2),
+		machineSet("foo-12345-worker-zone2", testPoolName, "zone2", 1),
+		machineSet("foo-12345-infra-zone1", "infra", "zone1", 3),
+	}
+
+	providerScheme := runtime.NewScheme()
+	awsprovider.SchemeBuilder.AddToScheme(providerScheme)
+	actual := poolZoneMachines(testMachinePool(), machineSets, providerScheme, log.StandardLogger())
+
+	assert.Equal(t, map[string]int64{"zone1": 2, "zone2": 1}, actual)
+}
+
+func TestSetSubnetIPsCondition(t *testing.T) {
+	zones := []string{"zone1", "zone2", "zone3"}
+	subnetsByID := map[string]string{"zone1": "subnet-zone1", "zone2": "subnet-zone2", "zone3": "subnet-zone3"}
+	testSubnetWithIPs := func(zone string, available int64) *ec2.Subnet {
+		subnet := testSubnet("subnet-"+zone, zone, "vpc-1", false)
+		subnet.AvailableIpAddressCount = aws.Int64(available)
+		subnet.Tags = []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String(fmt.Sprintf("%s-private-%s", testInfraID, zone))}}
+		return subnet
+	}
+	cases := []struct {
+		name            string
+		subnetIPCheck   bool
+		replicas        int64
+		subnets         map[string]string
+		zoneMachines    map[string]int64
+		mockAWSClient   func(*mockaws.MockClient)
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "subnet IP check disabled",
+			replicas:       3,
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "SubnetIPCheckDisabled",
+		},
+		{
+			name:          "sufficient IP addresses in subnets by ID",
+			subnetIPCheck: true,
+			replicas:      3,
+			subnets:       subnetsByID,
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeSubnets(&ec2.DescribeSubnetsInput{
+					SubnetIds: aws.StringSlice([]string{"subnet-zone1", "subnet-zone2", "subnet-zone3"}),
+				}).Return(&ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{
+					testSubnetWithIPs("zone1", 1),
+					testSubnetWithIPs("zone2", 1),
+					testSubnetWithIPs("zone3", 1),
+				}}, nil)
+			},
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "SufficientSubnetIPs",
+		},
+		{
+			name:          "IP addresses exceeded in subnets by name",
+			subnetIPCheck: true,
+			replicas:      9,
+			zoneMachines:  map[string]int64{"zone2": 2},
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeSubnets(&ec2.DescribeSubnetsInput{
+					Filters: []*ec2.Filter{{
+						Name:   aws.String("tag:Name"),
+						Values: aws.StringSlice([]string{"foo-12345-private-zone1", "foo-12345-private-zone2", "foo-12345-private-zone3"}),
+					}},
+				}).Return(&ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{
+					testSubnetWithIPs("zone1", 2),
+					testSubnetWithIPs("zone2", 1),
+				}}, nil)
+			},
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "SubnetIPsExceeded",
+			expectedMessage: "The machines likely exceed the available IP addresses of the subnets: 3 machines added in subnet-zone1, which has 2 available IP addresses",
+		},
+		{
+			name:          "describe error leaves condition unchanged",
+			subnetIPCheck: true,
+			replicas:      3,
+			subnets:       subnetsByID,
+			mockAWSClient: func(client *mockaws.MockClient) {
+				client.EXPECT().DescribeSubnets(gomock.Any()).Return(nil, awserr.New("UnauthorizedOperation", "not authorized", nil))
+			},
+			expectedStatus: corev1.ConditionUnknown,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			awsClient := mockaws.NewMockClient(mockCtrl)
+			if tc.mockAWSClient != nil {
+				tc.mockAWSClient(awsClient)
+			}
+
+			pool := testMachinePool()
+			pool.Spec.Replicas = pointer.Int64Ptr(tc.replicas)
+			actuator := &AWSActuator{
+				awsClient:     awsClient,
+				subnetIPCheck: tc.subnetIPCheck,
+				zoneMachines:  tc.zoneMachines,
+			}
+
+			actuator.setSubnetIPsCondition(pool, testInfraID, zones, tc.subnets, log.StandardLogger())
+
+			cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.InsufficientSubnetIPsMachinePoolCondition)
+			require.NotNil(t, cond, "missing InsufficientSubnetIPs condition")
+			assert.Equal(t, tc.expectedStatus, cond.Status, "unexpected condition status")
+			assert.Equal(t, tc.expectedReason, cond.Reason, "unexpected condition reason")
+			if tc.expectedMessage != "" {
+				assert.Equal(t, tc.expectedMessage, cond.Message, "unexpected condition message")
+			}
+		})
+	}
+}
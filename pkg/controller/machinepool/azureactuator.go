@@ -3,42 +3,87 @@ package machinepool
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
 	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	azureprovider "sigs.k8s.io/cluster-api-provider-azure/pkg/apis/azureprovider/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	installazure "github.com/openshift/installer/pkg/asset/machines/azure"
 	installertypes "github.com/openshift/installer/pkg/types"
 	installertypesazure "github.com/openshift/installer/pkg/types/azure"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hivev1azure "github.com/openshift/hive/apis/hive/v1/azure"
 	"github.com/openshift/hive/pkg/azureclient"
+	"github.com/openshift/hive/pkg/constants"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
 )
 
 // AzureActuator encapsulates the pieces necessary to be able to generate
 // a list of MachineSets to sync to the remote cluster.
 type AzureActuator struct {
-	client azureclient.Client
-	logger log.FieldLogger
+	noopValidator
+	noopCleaner
+	noCapabilities
+
+	client     azureclient.Client
+	kubeClient client.Client
+	logger     log.FieldLogger
 }
 
 var _ Actuator = &AzureActuator{}
 
+func init() {
+	registerActuator(constants.PlatformAzure, newAzureActuatorForCluster)
+}
+
+// newAzureActuatorForCluster is the registered actuatorConstructor of Azure.
+func newAzureActuatorForCluster(
+	ctx context.Context,
+	r *ReconcileMachinePool,
+	cd *hivev1.ClusterDeployment,
+	pool *hivev1.MachinePool,
+	masterMachine *machineapi.Machine,
+	remoteMachineSets []machineapi.MachineSet,
+	remoteClusterAPIClient client.Client,
+	logger log.FieldLogger,
+) (Actuator, error) {
+	creds := &corev1.Secret{}
+	if err := r.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      cd.Spec.Platform.Azure.CredentialsSecretRef.Name,
+			Namespace: cd.Namespace,
+		},
+		creds,
+	); err != nil {
+		return nil, err
+	}
+	return NewAzureActuator(creds, cd.Spec.Platform.Azure.CloudName.Name(), r.actuatorClient(), logger)
+}
+
 // NewAzureActuator is the constructor for building a AzureActuator
-func NewAzureActuator(azureCreds *corev1.Secret, cloudName string, logger log.FieldLogger) (*AzureActuator, error) {
+func NewAzureActuator(azureCreds *corev1.Secret, cloudName string, kubeClient client.Client, logger log.FieldLogger) (*AzureActuator, error) {
 	azureClient, err := azureclient.NewClientFromSecret(azureCreds, cloudName)
 	if err != nil {
 		logger.WithError(err).Warn("failed to create Azure client with creds in clusterDeployment's secret")
 		return nil, err
 	}
 	actuator := &AzureActuator{
-		client: azureClient,
-		logger: logger,
+		client:     azureClient,
+		kubeClient: kubeClient,
+		logger:     logger,
 	}
 	return actuator, nil
 }
@@ -56,6 +101,11 @@ func (a *AzureActuator) GenerateMachineSets(cd *hivev1.ClusterDeployment, pool *
 		return nil, false, errors.New("MachinePool is not for Azure")
 	}
 
+	zones, err := a.checkConfiguration(cd.Spec.Platform.Azure.Region, cd.Spec.Platform.Azure.CloudName, pool, logger)
+	if err != nil {
+		return nil, false, err
+	}
+
 	ic := &installertypes.InstallConfig{
 		Platform: installertypes.Platform{
 			Azure: &installertypesazure.Platform{
@@ -66,24 +116,13 @@ func (a *AzureActuator) GenerateMachineSets(cd *hivev1.ClusterDeployment, pool *
 
 	computePool := baseMachinePool(pool)
 	computePool.Platform.Azure = &installertypesazure.MachinePool{
-		Zones:        pool.Spec.Platform.Azure.Zones,
+		Zones:        zones,
 		InstanceType: pool.Spec.Platform.Azure.InstanceType,
 		OSDisk: installertypesazure.OSDisk{
 			DiskSizeGB: pool.Spec.Platform.Azure.OSDisk.DiskSizeGB,
 		},
 	}
 
-	if len(computePool.Platform.Azure.Zones) == 0 {
-		zones, err := a.getZones(cd.Spec.Platform.Azure.Region, pool.Spec.Platform.Azure.InstanceType)
-		if err != nil {
-			return nil, false, errors.Wrap(err, "compute pool not providing list of zones and failed to fetch list of zones")
-		}
-		if len(zones) == 0 {
-			return nil, false, fmt.Errorf("zero zones returned for region %s", cd.Spec.Platform.Azure.Region)
-		}
-		computePool.Platform.Azure.Zones = zones
-	}
-
 	// The imageID parameter is not used. The image is determined by the infraID.
 	const imageID = ""
 
@@ -93,12 +132,229 @@ func (a *AzureActuator) GenerateMachineSets(cd *hivev1.ClusterDeployment, pool *
 		computePool,
 		imageID,
 		workerRole,
-		workerUserDataName,
+		workerUserData(pool),
+	)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to generate machinesets")
+	}
+	for _, ms := range installerMachineSets {
+		providerSpec := ms.Spec.Template.Spec.ProviderSpec.Value.Object.(*azureprovider.AzureMachineProviderSpec)
+		if len(zones) == 0 {
+			// The installer generates a single MachineSet with an empty zone for a pool without zones. Its machines
+			// are placed in an availability set by the machine API when they have no zone at all.
+			providerSpec.Zone = nil
+		}
+		if spot := pool.Spec.Platform.Azure.SpotVMOptions; spot != nil {
+			providerSpec.SpotVMOptions = &azureprovider.SpotVMOptions{
+				MaxPrice: spot.MaxPrice,
+			}
+		}
+	}
+	return installerMachineSets, true, nil
+}
+
+// checkConfiguration sets conditions on the MachinePool when it requests a placement, an availability set, boot
+// diagnostics, accelerated networking or a spot VM eviction policy that cannot be used. Availability sets cannot be
+// combined with zones, zones require a region that supports them for the instance type, accelerated networking
+// requires an instance type that supports it, boot diagnostics in a user-managed storage account require the URI of
+// a storage account of the cloud environment, and the machine API Azure provider spec has no way to reference an
+// availability set, to enable boot diagnostics or accelerated networking or to set an eviction policy other than
+// Deallocate, so MachineSets cannot be generated for a pool that requests any of them. Returns a *ValidationError
+// describing the problem in that case, and otherwise the zones of the MachineSets: the zones of the pool, or else
+// those of the region, or none for availability set placement.
+func (a *AzureActuator) checkConfiguration(region string, cloudName hivev1azure.CloudEnvironment, pool *hivev1.MachinePool, logger log.FieldLogger) ([]string, error) {
+	platform := pool.Spec.Platform.Azure
+	availabilitySet := platform.AvailabilitySet
+	instanceType := platform.InstanceType
+	zonesRequested := len(platform.Zones) > 0 || platform.Placement == hivev1azure.PlacementZones
+	availabilitySetRequested := availabilitySet != "" || platform.Placement == hivev1azure.PlacementAvailabilitySet
+
+	// The resource SKU of the instance type is looked up once for both the availability zones of the region and
+	// whether the instance type supports accelerated networking.
+	var regionZones []string
+	acceleratedNetworkingSupported := true
+	if !availabilitySetRequested || platform.AcceleratedNetworking {
+		sku, locationInfo, err := a.getResourceSKU(region, instanceType)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to look up the resource SKU of the instance type")
+		}
+		if sku == nil && !availabilitySetRequested {
+			return nil, fmt.Errorf("instance type %s is not available in region %s", instanceType, region)
+		}
+		regionZones = skuZones(locationInfo)
+		if platform.AcceleratedNetworking {
+			acceleratedNetworkingSupported = skuSupportsAcceleratedNetworking(sku)
+		}
+	}
+
+	invalidStatus, invalidReason, invalidMessage := corev1.ConditionFalse, "ValidConfiguration", "The configuration is valid"
+	unsupportedStatus, unsupportedReason, unsupportedMessage := corev1.ConditionFalse, "ConfigurationSupported", "The configuration is supported"
+	invalidCheck, unsupportedCheck := controllerutils.UpdateConditionNever, controllerutils.UpdateConditionNever
+	switch {
+	case !isValidPlacement(platform.Placement):
+		logger.WithField("placement", platform.Placement).Warn("invalid placement")
+		invalidStatus, invalidReason = corev1.ConditionTrue, "InvalidPlacement"
+		invalidMessage = fmt.Sprintf("Placement %s is not one of %s, %s or %s", platform.Placement,
+			hivev1azure.PlacementAuto, hivev1azure.PlacementZones, hivev1azure.PlacementAvailabilitySet)
+		invalidCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+	case zonesRequested && availabilitySetRequested:
+		logger.WithField("availabilitySet", availabilitySet).Warn("availability set requested together with zones")
+		invalidStatus, invalidReason = corev1.ConditionTrue, "ZonesAndAvailabilitySet"
+		invalidMessage = "Availability set placement cannot be used together with zones"
+		if availabilitySet != "" {
+			invalidMessage = fmt.Sprintf("Availability set %s cannot be used together with zones", availabilitySet)
+		}
+		invalidCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+	case availabilitySet != "":
+		logger.WithField("availabilitySet", availabilitySet).Warn("availability sets are not supported by the machine API")
+		unsupportedStatus, unsupportedReason = corev1.ConditionTrue, "UnsupportedAvailabilitySet"
+		unsupportedMessage = "The machine API Azure provider does not support availability sets"
+		unsupportedCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+	case !isValidEvictionPolicy(pool.Spec.Platform.Azure.SpotVMOptions):
+		evictionPolicy := pool.Spec.Platform.Azure.SpotVMOptions.EvictionPolicy
+		logger.WithField("evictionPolicy", evictionPolicy).Warn("invalid spot VM eviction policy")
+		invalidStatus, invalidReason = corev1.ConditionTrue, "InvalidEvictionPolicy"
+		invalidMessage = fmt.Sprintf("Spot VM eviction policy %s is not one of %s or %s",
+			evictionPolicy, hivev1azure.EvictionPolicyDeallocate, hivev1azure.EvictionPolicyDelete)
+		invalidCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+	case pool.Spec.Platform.Azure.SpotVMOptions != nil &&
+		pool.Spec.Platform.Azure.SpotVMOptions.EvictionPolicy == hivev1azure.EvictionPolicyDelete:
+		logger.Warn("spot VM eviction policy Delete is not supported by the machine API")
+		unsupportedStatus, unsupportedReason = corev1.ConditionTrue, "UnsupportedEvictionPolicy"
+		unsupportedMessage = fmt.Sprintf("The machine API Azure provider only supports the %s spot VM eviction policy",
+			hivev1azure.EvictionPolicyDeallocate)
+		unsupportedCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+	case bootDiagnosticsStorageAccountError(platform.BootDiagnostics, cloudName) != "":
+		logger.WithField("bootDiagnostics", platform.BootDiagnostics).Warn("invalid boot diagnostics storage account")
+		invalidStatus, invalidReason = corev1.ConditionTrue, "InvalidBootDiagnosticsStorageAccount"
+		invalidMessage = bootDiagnosticsStorageAccountError(platform.BootDiagnostics, cloudName)
+		invalidCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+	case pool.Spec.BootDiagnostics || platform.BootDiagnostics != nil:
+		logger.Warn("boot diagnostics are not supported by the machine API")
+		unsupportedStatus, unsupportedReason = corev1.ConditionTrue, "UnsupportedBootDiagnostics"
+		unsupportedMessage = "The machine API Azure provider does not support boot diagnostics"
+		unsupportedCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+	case zonesRequested && len(regionZones) == 0:
+		logger.WithField("instanceType", instanceType).Warn("zones requested in a region without availability zones")
+		unsupportedStatus, unsupportedReason = corev1.ConditionTrue, "ZonesNotSupported"
+		unsupportedMessage = fmt.Sprintf("Region %s does not support availability zones for instance type %s", region, instanceType)
+		unsupportedCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+	case !acceleratedNetworkingSupported:
+		logger.WithField("instanceType", instanceType).Warn("accelerated networking is not supported by the instance type")
+		unsupportedStatus, unsupportedReason = corev1.ConditionTrue, "AcceleratedNetworkingNotSupported"
+		unsupportedMessage = fmt.Sprintf("Instance type %s does not support accelerated networking", instanceType)
+		unsupportedCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+	case pool.Spec.Platform.Azure.AcceleratedNetworking:
+		logger.Warn("accelerated networking is not supported by the machine API")
+		unsupportedStatus, unsupportedReason = corev1.ConditionTrue, "UnsupportedAcceleratedNetworking"
+		unsupportedMessage = "The machine API Azure provider does not support accelerated networking"
+		unsupportedCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+	}
+
+	conds, invalidChanged := controllerutils.SetMachinePoolConditionWithChangeCheck(
+		pool.Status.Conditions,
+		hivev1.InvalidConfigurationMachinePoolCondition,
+		invalidStatus,
+		invalidReason,
+		invalidMessage,
+		invalidCheck,
 	)
-	return installerMachineSets, err == nil, errors.Wrap(err, "failed to generate machinesets")
+	conds, unsupportedChanged := controllerutils.SetMachinePoolConditionWithChangeCheck(
+		conds,
+		hivev1.UnsupportedConfigurationMachinePoolCondition,
+		unsupportedStatus,
+		unsupportedReason,
+		unsupportedMessage,
+		unsupportedCheck,
+	)
+	if invalidChanged || unsupportedChanged {
+		pool.Status.Conditions = conds
+		if err := a.kubeClient.Status().Update(context.Background(), pool); err != nil {
+			return nil, errors.Wrap(err, "could not update MachinePool status")
+		}
+	}
+	switch {
+	case invalidStatus == corev1.ConditionTrue:
+		return nil, &ValidationError{
+			Type:    hivev1.InvalidConfigurationMachinePoolCondition,
+			Reason:  invalidReason,
+			Message: invalidMessage,
+		}
+	case unsupportedStatus == corev1.ConditionTrue:
+		return nil, &ValidationError{
+			Type:    hivev1.UnsupportedConfigurationMachinePoolCondition,
+			Reason:  unsupportedReason,
+			Message: unsupportedMessage,
+		}
+	}
+	if len(platform.Zones) > 0 || availabilitySetRequested {
+		return platform.Zones, nil
+	}
+	return regionZones, nil
+}
+
+// isValidPlacement returns true if the placement is empty, which defaults to Auto, or a known placement.
+func isValidPlacement(placement hivev1azure.Placement) bool {
+	switch placement {
+	case "", hivev1azure.PlacementAuto, hivev1azure.PlacementZones, hivev1azure.PlacementAvailabilitySet:
+		return true
+	}
+	return false
+}
+
+// isValidEvictionPolicy returns true if the spot VM options are not set or have a known eviction policy. An empty
+// eviction policy defaults to Deallocate.
+func isValidEvictionPolicy(spot *hivev1azure.SpotVMOptions) bool {
+	if spot == nil {
+		return true
+	}
+	switch spot.EvictionPolicy {
+	case "", hivev1azure.EvictionPolicyDeallocate, hivev1azure.EvictionPolicyDelete:
+		return true
+	}
+	return false
 }
 
-func (a *AzureActuator) getZones(region string, instanceType string) ([]string, error) {
+// storageAccountNameRegexp matches the names of Azure storage accounts.
+var storageAccountNameRegexp = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+
+// bootDiagnosticsStorageAccountError returns why the storage account of the boot diagnostics cannot be used in the
+// cloud environment, or an empty string if it can or no boot diagnostics are configured. A user-managed storage
+// account is referenced by the URI of its blob service endpoint, https://<account>.blob.<storage endpoint suffix>/.
+func bootDiagnosticsStorageAccountError(diagnostics *hivev1azure.BootDiagnostics, cloudName hivev1azure.CloudEnvironment) string {
+	switch {
+	case diagnostics == nil:
+		return ""
+	case diagnostics.StorageAccountType == hivev1azure.BootDiagnosticsStorageAccountManaged && diagnostics.StorageAccountURI != "":
+		return "A storage account URI cannot be set for boot diagnostics in a managed storage account"
+	case diagnostics.StorageAccountType == hivev1azure.BootDiagnosticsStorageAccountManaged:
+		return ""
+	case diagnostics.StorageAccountType != hivev1azure.BootDiagnosticsStorageAccountUserManaged:
+		return fmt.Sprintf("Boot diagnostics storage account type %s is not one of %s or %s", diagnostics.StorageAccountType,
+			hivev1azure.BootDiagnosticsStorageAccountManaged, hivev1azure.BootDiagnosticsStorageAccountUserManaged)
+	case diagnostics.StorageAccountURI == "":
+		return "Boot diagnostics in a user-managed storage account require a storage account URI"
+	}
+	if cloudName == "" {
+		cloudName = hivev1azure.PublicCloud
+	}
+	env, err := azure.EnvironmentFromName(cloudName.Name())
+	if err != nil {
+		return fmt.Sprintf("Unknown cloud environment %s", cloudName)
+	}
+	suffix := ".blob." + env.StorageEndpointSuffix
+	u, err := url.Parse(diagnostics.StorageAccountURI)
+	if err != nil || u.Scheme != "https" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" ||
+		!strings.HasSuffix(u.Host, suffix) || !storageAccountNameRegexp.MatchString(strings.TrimSuffix(u.Host, suffix)) {
+		return fmt.Sprintf("Storage account URI %s is not the blob service endpoint of a storage account in %s, https://<account>%s/",
+			diagnostics.StorageAccountURI, cloudName, suffix)
+	}
+	return ""
+}
+
+// getResourceSKU returns the resource SKU of the instance type and its location info in the region, or nil if the
+// instance type is not available in the region.
+func (a *AzureActuator) getResourceSKU(region string, instanceType string) (*compute.ResourceSku, *compute.ResourceSkuLocationInfo, error) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
 	defer cancel()
 
@@ -106,15 +362,39 @@ func (a *AzureActuator) getZones(region string, instanceType string) ([]string,
 	var err error
 	for res, err = a.client.ListResourceSKUs(ctx, ""); err == nil && res.NotDone(); err = res.NextWithContext(ctx) {
 		for _, resSku := range res.Values() {
-			if strings.EqualFold(to.String(resSku.Name), instanceType) {
-				for _, locationInfo := range *resSku.LocationInfo {
-					if strings.EqualFold(to.String(locationInfo.Location), region) {
-						return *locationInfo.Zones, nil
-					}
+			if !strings.EqualFold(to.String(resSku.Name), instanceType) || resSku.LocationInfo == nil {
+				continue
+			}
+			for _, locationInfo := range *resSku.LocationInfo {
+				if strings.EqualFold(to.String(locationInfo.Location), region) {
+					return &resSku, &locationInfo, nil
 				}
 			}
 		}
 	}
 
-	return nil, err
+	return nil, nil, err
+}
+
+// skuZones returns the availability zones of a resource SKU in a region, which are none if the region does not
+// support availability zones or the SKU is not available in the region.
+func skuZones(locationInfo *compute.ResourceSkuLocationInfo) []string {
+	if locationInfo == nil || locationInfo.Zones == nil {
+		return nil
+	}
+	return *locationInfo.Zones
+}
+
+// skuSupportsAcceleratedNetworking returns whether the resource SKU has the AcceleratedNetworkingEnabled capability.
+// Returns false if the SKU is not available.
+func skuSupportsAcceleratedNetworking(resSku *compute.ResourceSku) bool {
+	if resSku == nil || resSku.Capabilities == nil {
+		return false
+	}
+	for _, capability := range *resSku.Capabilities {
+		if strings.EqualFold(to.String(capability.Name), "AcceleratedNetworkingEnabled") {
+			return strings.EqualFold(to.String(capability.Value), "True")
+		}
+	}
+	return false
 }
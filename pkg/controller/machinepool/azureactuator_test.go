@@ -1,6 +1,8 @@
 package machinepool
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -8,15 +10,22 @@ import (
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 	azureprovider "sigs.k8s.io/cluster-api-provider-azure/pkg/apis/azureprovider/v1beta1"
 
+	"github.com/openshift/hive/apis"
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
 	hivev1azure "github.com/openshift/hive/apis/hive/v1/azure"
 	mockazure "github.com/openshift/hive/pkg/azureclient/mock"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
 )
 
 func TestAzureActuator(t *testing.T) {
@@ -26,6 +35,9 @@ func TestAzureActuator(t *testing.T) {
 		clusterDeployment          *hivev1.ClusterDeployment
 		pool                       *hivev1.MachinePool
 		expectedMachineSetReplicas map[string]int64
+		expectedSpotVMOptions      *azureprovider.SpotVMOptions
+		expectedNoZone             bool
+		expectedCondition          *hivev1.MachinePoolCondition
 		expectedErr                bool
 	}{
 		{
@@ -60,7 +72,9 @@ func TestAzureActuator(t *testing.T) {
 				pool.Spec.Platform.Azure.Zones = []string{"zone1", "zone2", "zone3"}
 				return pool
 			}(),
-			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {},
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{"zone1", "zone2", "zone3"})
+			},
 			expectedMachineSetReplicas: map[string]int64{
 				generateAzureMachineSetName("zone1"): 1,
 				generateAzureMachineSetName("zone2"): 1,
@@ -100,17 +114,377 @@ func TestAzureActuator(t *testing.T) {
 			},
 		},
 		{
-			name:              "list zones returns zero",
+			name:              "region without zones",
 			clusterDeployment: testAzureClusterDeployment(),
 			pool:              testAzurePool(),
 			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
 				mockListResourceSKUs(mockCtrl, client, []string{})
 			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAzureMachineSetName(""): 3,
+			},
+			expectedNoZone: true,
+		},
+		{
+			name:              "instance type not available in region",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.InstanceType = "Standard_Unavailable"
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				page := mockazure.NewMockResourceSKUsPage(mockCtrl)
+				client.EXPECT().ListResourceSKUs(gomock.Any(), "").Return(page, nil)
+				gomock.InOrder(
+					page.EXPECT().NotDone().Return(true),
+					page.EXPECT().NotDone().Return(false),
+				)
+				page.EXPECT().Values().Return([]compute.ResourceSku{})
+				page.EXPECT().NextWithContext(gomock.Any()).Return(nil)
+			},
+			expectedErr: true,
+		},
+		{
+			name:              "zones placement",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.Placement = hivev1azure.PlacementZones
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{"zone1", "zone2", "zone3"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAzureMachineSetName("zone1"): 1,
+				generateAzureMachineSetName("zone2"): 1,
+				generateAzureMachineSetName("zone3"): 1,
+			},
+		},
+		{
+			name:              "zones placement in region without zones",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.Placement = hivev1azure.PlacementZones
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{})
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "ZonesNotSupported",
+			},
+		},
+		{
+			name:              "specified zones in region without zones",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.Zones = []string{"zone1"}
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{})
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "ZonesNotSupported",
+			},
+		},
+		{
+			name:              "availability set placement",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.Placement = hivev1azure.PlacementAvailabilitySet
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAzureMachineSetName(""): 3,
+			},
+			expectedNoZone: true,
+		},
+		{
+			name:              "availability set placement with zones",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.Zones = []string{"zone1"}
+				p.Spec.Platform.Azure.Placement = hivev1azure.PlacementAvailabilitySet
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "ZonesAndAvailabilitySet",
+			},
+		},
+		{
+			name:              "invalid placement",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.Placement = "ScaleSet"
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{"zone1"})
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InvalidPlacement",
+			},
+		},
+		{
+			name:              "availability set with zones",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.Zones = []string{"zone1"}
+				p.Spec.Platform.Azure.AvailabilitySet = "test-availability-set"
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "ZonesAndAvailabilitySet",
+			},
+		},
+		{
+			name:              "availability set",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.AvailabilitySet = "test-availability-set"
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnsupportedAvailabilitySet",
+			},
+		},
+		{
+			name:              "spot VMs",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.Zones = []string{"zone1"}
+				p.Spec.Platform.Azure.SpotVMOptions = &hivev1azure.SpotVMOptions{
+					MaxPrice: resource.NewMilliQuantity(150, resource.DecimalSI),
+				}
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAzureMachineSetName("zone1"): 3,
+			},
+			expectedSpotVMOptions: &azureprovider.SpotVMOptions{
+				MaxPrice: resource.NewMilliQuantity(150, resource.DecimalSI),
+			},
+		},
+		{
+			name:              "spot VMs with deallocate eviction policy",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.Zones = []string{"zone1"}
+				p.Spec.Platform.Azure.SpotVMOptions = &hivev1azure.SpotVMOptions{
+					EvictionPolicy: hivev1azure.EvictionPolicyDeallocate,
+				}
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{"zone1"})
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateAzureMachineSetName("zone1"): 3,
+			},
+			expectedSpotVMOptions: &azureprovider.SpotVMOptions{},
+		},
+		{
+			name:              "spot VMs with delete eviction policy",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.SpotVMOptions = &hivev1azure.SpotVMOptions{
+					EvictionPolicy: hivev1azure.EvictionPolicyDelete,
+				}
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{"zone1"})
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnsupportedEvictionPolicy",
+			},
+		},
+		{
+			name:              "spot VMs with invalid eviction policy",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.SpotVMOptions = &hivev1azure.SpotVMOptions{
+					EvictionPolicy: "Hibernate",
+				}
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{"zone1"})
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InvalidEvictionPolicy",
+			},
+		},
+		{
+			name:              "boot diagnostics",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.BootDiagnostics = true
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{"zone1"})
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnsupportedBootDiagnostics",
+			},
+		},
+		{
+			name:              "boot diagnostics in managed storage account",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.BootDiagnostics = &hivev1azure.BootDiagnostics{
+					StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountManaged,
+				}
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{"zone1"})
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnsupportedBootDiagnostics",
+			},
+		},
+		{
+			name:              "boot diagnostics in invalid user-managed storage account",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.BootDiagnostics = &hivev1azure.BootDiagnostics{
+					StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountUserManaged,
+					StorageAccountURI:  "https://diagnostics.blob.core.chinacloudapi.cn/",
+				}
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{"zone1"})
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InvalidBootDiagnosticsStorageAccount",
+			},
+		},
+		{
+			name: "boot diagnostics in user-managed storage account",
+			clusterDeployment: func() *hivev1.ClusterDeployment {
+				cd := testAzureClusterDeployment()
+				cd.Spec.Platform.Azure.CloudName = hivev1azure.ChinaCloud
+				return cd
+			}(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.BootDiagnostics = &hivev1azure.BootDiagnostics{
+					StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountUserManaged,
+					StorageAccountURI:  "https://diagnostics.blob.core.chinacloudapi.cn/",
+				}
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUs(mockCtrl, client, []string{"zone1"})
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnsupportedBootDiagnostics",
+			},
+		},
+		{
+			name:              "accelerated networking",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.Zones = []string{"zone1"}
+				p.Spec.Platform.Azure.AcceleratedNetworking = true
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUsWithAcceleratedNetworking(mockCtrl, client, "True")
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnsupportedAcceleratedNetworking",
+			},
+		},
+		{
+			name:              "accelerated networking not supported by instance type",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.Zones = []string{"zone1"}
+				p.Spec.Platform.Azure.AcceleratedNetworking = true
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				mockListResourceSKUsWithAcceleratedNetworking(mockCtrl, client, "False")
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "AcceleratedNetworkingNotSupported",
+			},
+		},
+		{
+			name:              "accelerated networking lookup error",
+			clusterDeployment: testAzureClusterDeployment(),
+			pool: func() *hivev1.MachinePool {
+				p := testAzurePool()
+				p.Spec.Platform.Azure.AcceleratedNetworking = true
+				return p
+			}(),
+			mockAzureClient: func(mockCtrl *gomock.Controller, client *mockazure.MockClient) {
+				client.EXPECT().ListResourceSKUs(gomock.Any(), "").Return(nil, errors.New("failed to list SKUs"))
+			},
 			expectedErr: true,
 		},
 	}
 
 	for _, test := range tests {
+		apis.AddToScheme(scheme.Scheme)
 		t.Run(test.name, func(t *testing.T) {
 
 			mockCtrl := gomock.NewController(t)
@@ -122,22 +496,129 @@ func TestAzureActuator(t *testing.T) {
 			test.mockAzureClient(mockCtrl, aClient)
 
 			actuator := &AzureActuator{
-				client: aClient,
-				logger: log.WithField("actuator", "azureactuator"),
+				client:     aClient,
+				kubeClient: fake.NewFakeClient(test.pool),
+				logger:     log.WithField("actuator", "azureactuator"),
 			}
 
-			generatedMachineSets, _, err := actuator.GenerateMachineSets(test.clusterDeployment, test.pool, actuator.logger)
+			generatedMachineSets, proceed, err := actuator.GenerateMachineSets(test.clusterDeployment, test.pool, actuator.logger)
 
-			if test.expectedErr {
+			switch {
+			case test.expectedErr:
 				assert.Error(t, err, "expected error for test case")
-			} else {
-				validateAzureMachineSets(t, generatedMachineSets, test.expectedMachineSetReplicas)
+			case test.expectedCondition != nil:
+				var validationErr *ValidationError
+				if assert.True(t, errors.As(err, &validationErr), "expected a validation error") {
+					assert.Equal(t, test.expectedCondition.Reason, validationErr.Reason, "unexpected validation error reason")
+				}
+				assert.False(t, proceed, "expected not to proceed")
+				assert.Empty(t, generatedMachineSets, "expected no machinesets")
+				pool := &hivev1.MachinePool{}
+				err := actuator.kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: test.pool.Namespace, Name: test.pool.Name}, pool)
+				require.NoError(t, err, "unexpected error getting machinepool")
+				cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, test.expectedCondition.Type)
+				if assert.NotNil(t, cond, "missing expected condition") {
+					assert.Equal(t, test.expectedCondition.Status, cond.Status, "unexpected condition status")
+					assert.Equal(t, test.expectedCondition.Reason, cond.Reason, "unexpected condition reason")
+				}
+			default:
+				require.NoError(t, err, "unexpected error for test case")
+				assert.True(t, proceed, "expected to proceed")
+				validateAzureMachineSets(t, generatedMachineSets, test.expectedMachineSetReplicas, test.expectedSpotVMOptions, test.expectedNoZone)
 			}
 		})
 	}
 }
 
-func validateAzureMachineSets(t *testing.T, mSets []*machineapi.MachineSet, expectedMSReplicas map[string]int64) {
+func Test_bootDiagnosticsStorageAccountError(t *testing.T) {
+	cases := []struct {
+		name        string
+		diagnostics *hivev1azure.BootDiagnostics
+		cloudName   hivev1azure.CloudEnvironment
+		expected    string
+	}{
+		{
+			name: "no boot diagnostics",
+		},
+		{
+			name:        "managed",
+			diagnostics: &hivev1azure.BootDiagnostics{StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountManaged},
+		},
+		{
+			name: "managed with storage account URI",
+			diagnostics: &hivev1azure.BootDiagnostics{
+				StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountManaged,
+				StorageAccountURI:  "https://diagnostics.blob.core.windows.net/",
+			},
+			expected: "A storage account URI cannot be set for boot diagnostics in a managed storage account",
+		},
+		{
+			name:        "unknown storage account type",
+			diagnostics: &hivev1azure.BootDiagnostics{StorageAccountType: "Shared"},
+			expected:    "Boot diagnostics storage account type Shared is not one of Managed or UserManaged",
+		},
+		{
+			name:        "user-managed without storage account URI",
+			diagnostics: &hivev1azure.BootDiagnostics{StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountUserManaged},
+			expected:    "Boot diagnostics in a user-managed storage account require a storage account URI",
+		},
+		{
+			name: "user-managed in public cloud",
+			diagnostics: &hivev1azure.BootDiagnostics{
+				StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountUserManaged,
+				StorageAccountURI:  "https://diagnostics.blob.core.windows.net/",
+			},
+		},
+		{
+			name: "user-managed without trailing slash",
+			diagnostics: &hivev1azure.BootDiagnostics{
+				StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountUserManaged,
+				StorageAccountURI:  "https://diagnostics.blob.core.usgovcloudapi.net",
+			},
+			cloudName: hivev1azure.USGovernmentCloud,
+		},
+		{
+			name: "user-managed in other cloud",
+			diagnostics: &hivev1azure.BootDiagnostics{
+				StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountUserManaged,
+				StorageAccountURI:  "https://diagnostics.blob.core.windows.net/",
+			},
+			cloudName: hivev1azure.ChinaCloud,
+			expected:  "Storage account URI https://diagnostics.blob.core.windows.net/ is not the blob service endpoint of a storage account in AzureChinaCloud, https://<account>.blob.core.chinacloudapi.cn/",
+		},
+		{
+			name: "user-managed with invalid account name",
+			diagnostics: &hivev1azure.BootDiagnostics{
+				StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountUserManaged,
+				StorageAccountURI:  "https://Boot-Diagnostics.blob.core.windows.net/",
+			},
+			expected: "Storage account URI https://Boot-Diagnostics.blob.core.windows.net/ is not the blob service endpoint of a storage account in AzurePublicCloud, https://<account>.blob.core.windows.net/",
+		},
+		{
+			name: "user-managed with container",
+			diagnostics: &hivev1azure.BootDiagnostics{
+				StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountUserManaged,
+				StorageAccountURI:  "https://diagnostics.blob.core.windows.net/bootdiagnostics",
+			},
+			expected: "Storage account URI https://diagnostics.blob.core.windows.net/bootdiagnostics is not the blob service endpoint of a storage account in AzurePublicCloud, https://<account>.blob.core.windows.net/",
+		},
+		{
+			name: "user-managed over http",
+			diagnostics: &hivev1azure.BootDiagnostics{
+				StorageAccountType: hivev1azure.BootDiagnosticsStorageAccountUserManaged,
+				StorageAccountURI:  "http://diagnostics.blob.core.windows.net/",
+			},
+			expected: "Storage account URI http://diagnostics.blob.core.windows.net/ is not the blob service endpoint of a storage account in AzurePublicCloud, https://<account>.blob.core.windows.net/",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, bootDiagnosticsStorageAccountError(tc.diagnostics, tc.cloudName))
+		})
+	}
+}
+
+func validateAzureMachineSets(t *testing.T, mSets []*machineapi.MachineSet, expectedMSReplicas map[string]int64, expectedSpotVMOptions *azureprovider.SpotVMOptions, expectedNoZone bool) {
 	assert.Equal(t, len(expectedMSReplicas), len(mSets), "different number of machine sets generated than expected")
 
 	for _, ms := range mSets {
@@ -149,6 +630,12 @@ func validateAzureMachineSets(t *testing.T, mSets []*machineapi.MachineSet, expe
 		azureProvider, ok := ms.Spec.Template.Spec.ProviderSpec.Value.Object.(*azureprovider.AzureMachineProviderSpec)
 		if assert.True(t, ok, "failed to convert to azureProviderSpec") {
 			assert.Equal(t, testInstanceType, azureProvider.VMSize, "unexpected instance type")
+			assert.Equal(t, expectedSpotVMOptions, azureProvider.SpotVMOptions, "unexpected spot VM options")
+			if expectedNoZone {
+				assert.Nil(t, azureProvider.Zone, "expected no zone")
+			} else {
+				assert.NotNil(t, azureProvider.Zone, "expected a zone")
+			}
 		}
 	}
 }
@@ -172,6 +659,31 @@ func mockListResourceSKUs(mockCtrl *gomock.Controller, client *mockazure.MockCli
 	)
 }
 
+func mockListResourceSKUsWithAcceleratedNetworking(mockCtrl *gomock.Controller, client *mockazure.MockClient, acceleratedNetworking string) {
+	page := mockazure.NewMockResourceSKUsPage(mockCtrl)
+	client.EXPECT().ListResourceSKUs(gomock.Any(), "").Return(page, nil)
+	page.EXPECT().NotDone().Return(true)
+	page.EXPECT().Values().Return(
+		[]compute.ResourceSku{
+			{
+				Name: pointer.StringPtr(testInstanceType),
+				LocationInfo: &[]compute.ResourceSkuLocationInfo{
+					{
+						Location: pointer.StringPtr(testRegion),
+						Zones:    &[]string{"zone1"},
+					},
+				},
+				Capabilities: &[]compute.ResourceSkuCapabilities{
+					{
+						Name:  pointer.StringPtr("AcceleratedNetworkingEnabled"),
+						Value: pointer.StringPtr(acceleratedNetworking),
+					},
+				},
+			},
+		},
+	)
+}
+
 func generateAzureMachineSetName(zone string) string {
 	return fmt.Sprintf("%s-%s-%s%s", testInfraID, testPoolName, testRegion, zone)
 }
@@ -3,4 +3,7 @@ package machinepool
 const (
 	// workerRole is used to locate installer created cloud resources such as subnets.
 	workerRole = "worker"
+
+	// machineAPINamespace is the namespace in the remote cluster containing the machine API resources.
+	machineAPINamespace = "openshift-machine-api"
 )
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"regexp"
 	"strings"
 
 	"github.com/blang/semver/v4"
@@ -17,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -26,6 +28,7 @@ import (
 	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hivev1gcp "github.com/openshift/hive/apis/hive/v1/gcp"
 	"github.com/openshift/hive/pkg/constants"
 	controllerutils "github.com/openshift/hive/pkg/controller/utils"
 	"github.com/openshift/hive/pkg/gcpclient"
@@ -42,11 +45,24 @@ const (
 
 var (
 	versionsSupportingFullNames = semver.MustParseRange(">=4.4.7")
+
+	gcpServiceAccountEmailRegex = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+	// gcpNetworkTagRegex matches valid GCP network tags: 1-63 lowercase letters, digits and dashes, starting with a
+	// letter and not ending with a dash.
+	gcpNetworkTagRegex = regexp.MustCompile(`^[a-z](?:[-a-z0-9]{0,61}[a-z0-9])?$`)
+
+	// gcpConfidentialComputeMachineFamilies are the machine families supporting confidential computing.
+	gcpConfidentialComputeMachineFamilies = sets.NewString("n2d", "c2d", "c3d")
 )
 
 // GCPActuator encapsulates the pieces necessary to be able to generate
 // a list of MachineSets to sync to the remote cluster.
 type GCPActuator struct {
+	noopValidator
+	noopCleaner
+	noCapabilities
+
 	client    client.Client
 	gcpClient gcpclient.Client
 	logger    log.FieldLogger
@@ -59,6 +75,8 @@ type GCPActuator struct {
 	// expects to see.
 	expectations   controllerutils.ExpectationsInterface
 	leasesRequired bool
+	// imageSource is where imageID comes from.
+	imageSource hivev1.BootImageSource
 }
 
 var _ Actuator = &GCPActuator{}
@@ -67,11 +85,46 @@ func addGCPProviderToScheme(scheme *runtime.Scheme) error {
 	return gcpprovider.AddToScheme(scheme)
 }
 
+func init() {
+	registerActuator(constants.PlatformGCP, newGCPActuatorForCluster)
+}
+
+// newGCPActuatorForCluster is the registered actuatorConstructor of GCP.
+func newGCPActuatorForCluster(
+	ctx context.Context,
+	r *ReconcileMachinePool,
+	cd *hivev1.ClusterDeployment,
+	pool *hivev1.MachinePool,
+	masterMachine *machineapi.Machine,
+	remoteMachineSets []machineapi.MachineSet,
+	remoteClusterAPIClient client.Client,
+	logger log.FieldLogger,
+) (Actuator, error) {
+	creds := &corev1.Secret{}
+	if err := r.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      cd.Spec.Platform.GCP.CredentialsSecretRef.Name,
+			Namespace: cd.Namespace,
+		},
+		creds,
+	); err != nil {
+		return nil, err
+	}
+	// Full machine names are version-gated, so leases are required when the version of the cluster is not known.
+	clusterVersion, err := getClusterVersion(cd, pool)
+	if err != nil {
+		logger.WithError(err).Warn("could not determine the cluster version, disabling version-gated features")
+	}
+	return NewGCPActuator(r.actuatorClient(), creds, clusterVersion, pool, masterMachine, remoteMachineSets, r.scheme, r.expectations, logger)
+}
+
 // NewGCPActuator is the constructor for building a GCPActuator
 func NewGCPActuator(
 	client client.Client,
 	gcpCreds *corev1.Secret,
 	clusterVersion string,
+	pool *hivev1.MachinePool,
 	masterMachine *machineapi.Machine,
 	remoteMachineSets []machineapi.MachineSet,
 	scheme *runtime.Scheme,
@@ -90,7 +143,9 @@ func NewGCPActuator(
 		return nil, err
 	}
 
-	imageID, err := getGCPImageID(masterMachine, scheme, logger)
+	imageID, imageSource, err := poolBootImage(pool, func() (string, error) {
+		return getGCPImageID(masterMachine, scheme, logger)
+	})
 	if err != nil {
 		logger.WithError(err).Error("error getting image ID from master machine")
 		return nil, err
@@ -110,6 +165,7 @@ func NewGCPActuator(
 		expectations:   expectations,
 		projectID:      projectID,
 		imageID:        imageID,
+		imageSource:    imageSource,
 		network:        network,
 		subnet:         subnet,
 		leasesRequired: requireLeases(clusterVersion, remoteMachineSets, logger),
@@ -130,6 +186,89 @@ func (a *GCPActuator) GenerateMachineSets(cd *hivev1.ClusterDeployment, pool *hi
 		return nil, false, errors.New("MachinePool is not for GCP")
 	}
 
+	reason, msg := "", ""
+	if msg = validateGCPServiceAccount(pool.Spec.Platform.GCP.ServiceAccount); msg != "" {
+		reason = "InvalidServiceAccount"
+	} else if msg = validateGCPNetworkTags(pool.Spec.Platform.GCP.NetworkTags); msg != "" {
+		reason = "InvalidNetworkTags"
+	} else if msg = validateGCPShieldedInstanceConfig(pool.Spec.Platform.GCP.ShieldedInstanceConfig); msg != "" {
+		reason = "InvalidShieldedInstanceConfig"
+	}
+	if msg != "" {
+		logger.WithField("reason", msg).Warn("invalid GCP configuration")
+		conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+			pool.Status.Conditions,
+			hivev1.InvalidConfigurationMachinePoolCondition,
+			corev1.ConditionTrue,
+			reason,
+			msg,
+			controllerutils.UpdateConditionIfReasonOrMessageChange,
+		)
+		if changed {
+			pool.Status.Conditions = conds
+			if err := a.client.Status().Update(context.Background(), pool); err != nil {
+				return nil, false, errors.Wrap(err, "could not update MachinePool status")
+			}
+		}
+		return nil, false, &ValidationError{
+			Type:    hivev1.InvalidConfigurationMachinePoolCondition,
+			Reason:  reason,
+			Message: msg,
+		}
+	}
+	conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+		pool.Status.Conditions,
+		hivev1.InvalidConfigurationMachinePoolCondition,
+		corev1.ConditionFalse,
+		"ValidConfiguration",
+		"The configuration is valid",
+		controllerutils.UpdateConditionNever,
+	)
+	if changed {
+		pool.Status.Conditions = conds
+		if err := a.client.Status().Update(context.Background(), pool); err != nil {
+			return nil, false, errors.Wrap(err, "could not update MachinePool status")
+		}
+	}
+
+	unsupportedReason, unsupportedMessage := gcpUnsupportedConfiguration(pool.Spec.Platform.GCP)
+	if unsupportedReason != "" {
+		logger.WithField("reason", unsupportedMessage).Warn("unsupported GCP configuration")
+		conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+			pool.Status.Conditions,
+			hivev1.UnsupportedConfigurationMachinePoolCondition,
+			corev1.ConditionTrue,
+			unsupportedReason,
+			unsupportedMessage,
+			controllerutils.UpdateConditionIfReasonOrMessageChange,
+		)
+		if changed {
+			pool.Status.Conditions = conds
+			if err := a.client.Status().Update(context.Background(), pool); err != nil {
+				return nil, false, errors.Wrap(err, "could not update MachinePool status")
+			}
+		}
+		return nil, false, &ValidationError{
+			Type:    hivev1.UnsupportedConfigurationMachinePoolCondition,
+			Reason:  unsupportedReason,
+			Message: unsupportedMessage,
+		}
+	}
+	conds, changed = controllerutils.SetMachinePoolConditionWithChangeCheck(
+		pool.Status.Conditions,
+		hivev1.UnsupportedConfigurationMachinePoolCondition,
+		corev1.ConditionFalse,
+		"ConfigurationSupported",
+		"The configuration is supported",
+		controllerutils.UpdateConditionNever,
+	)
+	if changed {
+		pool.Status.Conditions = conds
+		if err := a.client.Status().Update(context.Background(), pool); err != nil {
+			return nil, false, errors.Wrap(err, "could not update MachinePool status")
+		}
+	}
+
 	leases := &hivev1.MachinePoolNameLeaseList{}
 	if err := a.client.List(
 		context.TODO(),
@@ -234,9 +373,100 @@ func (a *GCPActuator) GenerateMachineSets(cd *hivev1.ClusterDeployment, pool *hi
 		computePool,
 		a.imageID,
 		workerRole,
-		workerUserDataName,
+		workerUserData(pool),
 	)
-	return installerMachineSets, err == nil, errors.Wrap(err, "failed to generate machinesets")
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to generate machinesets")
+	}
+
+	for _, ms := range installerMachineSets {
+		providerSpec := ms.Spec.Template.Spec.ProviderSpec.Value.Object.(*gcpproviderv1beta1.GCPMachineProviderSpec)
+		if sa := poolGCP.ServiceAccount; sa != nil {
+			providerSpec.ServiceAccounts = []gcpproviderv1beta1.GCPServiceAccount{{
+				Email:  sa.Email,
+				Scopes: sa.Scopes,
+			}}
+		}
+		providerSpec.Tags = appendGCPNetworkTags(providerSpec.Tags, poolGCP.NetworkTags)
+	}
+
+	setBootImageStatus(pool, a.imageID, a.imageSource)
+	return installerMachineSets, true, nil
+}
+
+// validateGCPServiceAccount returns a message describing the problem with the service account, or an empty string
+// if the service account is valid or not set.
+func validateGCPServiceAccount(sa *hivev1gcp.ServiceAccount) string {
+	if sa == nil {
+		return ""
+	}
+	if !gcpServiceAccountEmailRegex.MatchString(sa.Email) {
+		return fmt.Sprintf("The service account email %q is not a valid email address", sa.Email)
+	}
+	if len(sa.Scopes) == 0 {
+		return "The service account must have at least one scope"
+	}
+	return ""
+}
+
+// validateGCPNetworkTags returns a message describing the first invalid network tag, or an empty string if all the
+// network tags are valid.
+func validateGCPNetworkTags(tags []string) string {
+	for _, tag := range tags {
+		if !gcpNetworkTagRegex.MatchString(tag) {
+			return fmt.Sprintf("The network tag %q is invalid: tags must be 1-63 characters long, start with a lowercase letter, contain only lowercase letters, digits and dashes, and not end with a dash", tag)
+		}
+	}
+	return ""
+}
+
+// validateGCPShieldedInstanceConfig returns a message describing the problem with the shielded VM options, or an
+// empty string if they are valid or not set.
+func validateGCPShieldedInstanceConfig(config *hivev1gcp.ShieldedInstanceConfig) string {
+	if config == nil {
+		return ""
+	}
+	if config.IntegrityMonitoring == hivev1gcp.FeatureStateEnabled && config.VirtualizedTrustedPlatformModule == hivev1gcp.FeatureStateDisabled {
+		return "Integrity monitoring requires the virtualized trusted platform module"
+	}
+	return ""
+}
+
+// gcpUnsupportedConfiguration returns the reason and message for the UnsupportedConfiguration condition when the
+// pool uses options that cannot be applied to its machines, or empty strings when the configuration is supported.
+func gcpUnsupportedConfiguration(pool *hivev1gcp.MachinePool) (string, string) {
+	switch {
+	case pool.ConfidentialCompute == hivev1gcp.FeatureStateEnabled && !gcpConfidentialComputeMachineFamilies.Has(gcpMachineFamily(pool.InstanceType)):
+		return "UnsupportedConfidentialComputeMachineType",
+			fmt.Sprintf("Confidential computing is not supported on machine type %s; supported machine families: %s",
+				pool.InstanceType, strings.Join(gcpConfidentialComputeMachineFamilies.List(), ", "))
+	case pool.ConfidentialCompute == hivev1gcp.FeatureStateEnabled:
+		// The GCP provider has no confidential instance config, so instances would be launched without it.
+		return "UnsupportedConfidentialCompute", "The machine API does not support confidential computing on GCP"
+	case pool.ShieldedInstanceConfig != nil:
+		// The GCP provider has no shielded instance config, so instances would be launched with the image defaults.
+		return "UnsupportedShieldedInstanceConfig", "The machine API does not support configuring shielded VM options on GCP"
+	}
+	return "", ""
+}
+
+// gcpMachineFamily returns the family of a GCP machine type, e.g. n2d for n2d-standard-4.
+func gcpMachineFamily(machineType string) string {
+	return strings.SplitN(machineType, "-", 2)[0]
+}
+
+// appendGCPNetworkTags appends the network tags of the pool to the tags set by the installer, skipping any tag that is
+// already present.
+func appendGCPNetworkTags(tags, networkTags []string) []string {
+	existing := sets.NewString(tags...)
+	for _, tag := range networkTags {
+		if existing.Has(tag) {
+			continue
+		}
+		existing.Insert(tag)
+		tags = append(tags, tag)
+	}
+	return tags
 }
 
 func (a *GCPActuator) getZones(region string) ([]string, error) {
@@ -417,11 +647,14 @@ func (a *GCPActuator) findAvailableLeaseChars(cd *hivev1.ClusterDeployment, leas
 
 func requireLeases(clusterVersion string, remoteMachineSets []machineapi.MachineSet, logger log.FieldLogger) bool {
 	logger = logger.WithField("clusterVersion", clusterVersion)
-	if v, err := semver.ParseTolerant(clusterVersion); err == nil {
-		if !versionsSupportingFullNames(v) {
-			logger.Debug("leases are required since cluster does not support full machine names")
-			return true
-		}
+	v, err := semver.ParseTolerant(clusterVersion)
+	if err != nil {
+		logger.Debug("leases are required since the version of the cluster is not known")
+		return true
+	}
+	if !versionsSupportingFullNames(v) {
+		logger.Debug("leases are required since cluster does not support full machine names")
+		return true
 	}
 	poolNames := make(map[string]bool)
 	for _, ms := range remoteMachineSets {
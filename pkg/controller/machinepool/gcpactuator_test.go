@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -48,6 +49,9 @@ func TestGCPActuator(t *testing.T) {
 		setupPendingCreationExpectation bool
 
 		expectedMachineSetReplicas map[string]int64
+		expectedServiceAccount     *gcpprovider.GCPServiceAccount
+		expectedTags               []string
+		expectedCondition          *hivev1.MachinePoolCondition
 		expectedErr                bool
 	}{
 		{
@@ -171,6 +175,173 @@ func TestGCPActuator(t *testing.T) {
 				generateGCPMachineSetName("worker", "zone1"): 3,
 			},
 		},
+		{
+			name: "generate machinesets with service account",
+			pool: func() *hivev1.MachinePool {
+				pool := testGCPPool(testPoolName)
+				pool.Spec.Platform.GCP.ServiceAccount = &hivev1gcp.ServiceAccount{
+					Email:  "custom-sa@test-gcp-project-id.iam.gserviceaccount.com",
+					Scopes: []string{"https://www.googleapis.com/auth/compute"},
+				}
+				return pool
+			}(),
+			mockGCPClient: func(client *mockgcp.MockClient) {
+				mockListComputeZones(client, []string{"zone1"}, testRegion)
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateGCPMachineSetName("worker", "zone1"): 3,
+			},
+			expectedServiceAccount: &gcpprovider.GCPServiceAccount{
+				Email:  "custom-sa@test-gcp-project-id.iam.gserviceaccount.com",
+				Scopes: []string{"https://www.googleapis.com/auth/compute"},
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "ValidConfiguration",
+			},
+		},
+		{
+			name: "invalid service account email",
+			pool: func() *hivev1.MachinePool {
+				pool := testGCPPool(testPoolName)
+				pool.Spec.Platform.GCP.ServiceAccount = &hivev1gcp.ServiceAccount{
+					Email:  "not-an-email",
+					Scopes: []string{"https://www.googleapis.com/auth/compute"},
+				}
+				return pool
+			}(),
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InvalidServiceAccount",
+			},
+		},
+		{
+			name: "service account without scopes",
+			pool: func() *hivev1.MachinePool {
+				pool := testGCPPool(testPoolName)
+				pool.Spec.Platform.GCP.ServiceAccount = &hivev1gcp.ServiceAccount{
+					Email: "custom-sa@test-gcp-project-id.iam.gserviceaccount.com",
+				}
+				return pool
+			}(),
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InvalidServiceAccount",
+			},
+		},
+		{
+			name: "generate machinesets with network tags",
+			pool: func() *hivev1.MachinePool {
+				pool := testGCPPool(testPoolName)
+				pool.Spec.Platform.GCP.NetworkTags = []string{"allow-ssh", testInfraID + "-worker", "allow-ssh", "x"}
+				return pool
+			}(),
+			mockGCPClient: func(client *mockgcp.MockClient) {
+				mockListComputeZones(client, []string{"zone1"}, testRegion)
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateGCPMachineSetName("worker", "zone1"): 3,
+			},
+			expectedTags: []string{testInfraID + "-worker", "allow-ssh", "x"},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "ValidConfiguration",
+			},
+		},
+		{
+			name: "invalid network tag",
+			pool: func() *hivev1.MachinePool {
+				pool := testGCPPool(testPoolName)
+				pool.Spec.Platform.GCP.NetworkTags = []string{"allow-ssh", "Allow-HTTP"}
+				return pool
+			}(),
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InvalidNetworkTags",
+			},
+		},
+		{
+			name: "integrity monitoring without vTPM",
+			pool: func() *hivev1.MachinePool {
+				pool := testGCPPool(testPoolName)
+				pool.Spec.Platform.GCP.ShieldedInstanceConfig = &hivev1gcp.ShieldedInstanceConfig{
+					VirtualizedTrustedPlatformModule: hivev1gcp.FeatureStateDisabled,
+					IntegrityMonitoring:              hivev1gcp.FeatureStateEnabled,
+				}
+				return pool
+			}(),
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InvalidShieldedInstanceConfig",
+			},
+		},
+		{
+			name: "unsupported shielded instance config",
+			pool: func() *hivev1.MachinePool {
+				pool := testGCPPool(testPoolName)
+				pool.Spec.Platform.GCP.ShieldedInstanceConfig = &hivev1gcp.ShieldedInstanceConfig{
+					SecureBoot: hivev1gcp.FeatureStateEnabled,
+				}
+				return pool
+			}(),
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnsupportedShieldedInstanceConfig",
+			},
+		},
+		{
+			name: "confidential compute on unsupported machine type",
+			pool: func() *hivev1.MachinePool {
+				pool := testGCPPool(testPoolName)
+				pool.Spec.Platform.GCP.ConfidentialCompute = hivev1gcp.FeatureStateEnabled
+				return pool
+			}(),
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnsupportedConfidentialComputeMachineType",
+			},
+		},
+		{
+			name: "unsupported confidential compute",
+			pool: func() *hivev1.MachinePool {
+				pool := testGCPPool(testPoolName)
+				pool.Spec.Platform.GCP.InstanceType = "n2d-standard-4"
+				pool.Spec.Platform.GCP.ConfidentialCompute = hivev1gcp.FeatureStateEnabled
+				return pool
+			}(),
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UnsupportedConfidentialCompute",
+			},
+		},
+		{
+			name: "confidential compute disabled",
+			pool: func() *hivev1.MachinePool {
+				pool := testGCPPool(testPoolName)
+				pool.Spec.Platform.GCP.ConfidentialCompute = hivev1gcp.FeatureStateDisabled
+				return pool
+			}(),
+			mockGCPClient: func(client *mockgcp.MockClient) {
+				mockListComputeZones(client, []string{"zone1"}, testRegion)
+			},
+			expectedMachineSetReplicas: map[string]int64{
+				generateGCPMachineSetName("worker", "zone1"): 3,
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "ConfigurationSupported",
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -192,7 +363,7 @@ func TestGCPActuator(t *testing.T) {
 				}.String(), 1)
 			}
 
-			test.existing = append(test.existing, clusterDeployment)
+			test.existing = append(test.existing, clusterDeployment, test.pool)
 			fakeClient := fake.NewFakeClient(test.existing...)
 
 			// set up mock expectations
@@ -256,8 +427,73 @@ func TestGCPActuator(t *testing.T) {
 						assert.Equal(t, encKey.KMSKey.Location, gcpProvider.Disks[0].EncryptionKey.KMSKey.Location)
 					}
 
+					// Ensure the GCP service account override made it to the resulting MachineSet (if specified):
+					if test.expectedServiceAccount != nil {
+						assert.Equal(t, []gcpprovider.GCPServiceAccount{*test.expectedServiceAccount}, gcpProvider.ServiceAccounts)
+					}
+
+					// Ensure the network tags of the pool were added to those set by the installer (if specified):
+					if test.expectedTags != nil {
+						assert.Equal(t, test.expectedTags, gcpProvider.Tags, "unexpected network tags")
+					}
 				}
 			}
+
+			if test.expectedCondition != nil {
+				cond := controllerutils.FindMachinePoolCondition(test.pool.Status.Conditions, test.expectedCondition.Type)
+				if assert.NotNil(t, cond, "did not find expected condition type: %v", test.expectedCondition.Type) {
+					assert.Equal(t, test.expectedCondition.Status, cond.Status, "condition found with unexpected status")
+					assert.Equal(t, test.expectedCondition.Reason, cond.Reason, "condition found with unexpected reason")
+				}
+			}
+		})
+	}
+}
+
+func Test_validateGCPNetworkTags(t *testing.T) {
+	cases := []struct {
+		name        string
+		tags        []string
+		expectValid bool
+	}{
+		{
+			name:        "no tags",
+			expectValid: true,
+		},
+		{
+			name:        "valid tags",
+			tags:        []string{"a", "allow-ssh", "tag1", strings.Repeat("a", 63)},
+			expectValid: true,
+		},
+		{
+			name: "uppercase",
+			tags: []string{"Allow-ssh"},
+		},
+		{
+			name: "starts with digit",
+			tags: []string{"1tag"},
+		},
+		{
+			name: "ends with dash",
+			tags: []string{"tag-"},
+		},
+		{
+			name: "too long",
+			tags: []string{strings.Repeat("a", 64)},
+		},
+		{
+			name: "empty",
+			tags: []string{""},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := validateGCPNetworkTags(tc.tags)
+			if tc.expectValid {
+				assert.Empty(t, msg, "unexpected validation message")
+			} else {
+				assert.NotEmpty(t, msg, "expected a validation message")
+			}
 		})
 	}
 }
@@ -650,7 +886,11 @@ func TestRequireLeases(t *testing.T) {
 		{
 			name:           "invalid version",
 			clusterVersion: "bad-version",
-			expectedResult: false,
+			expectedResult: true,
+		},
+		{
+			name:           "unknown version",
+			expectedResult: true,
 		},
 		{
 			name:            "worker machine pool",
@@ -0,0 +1,73 @@
+package machinepool
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/hive/pkg/awsclient"
+)
+
+const (
+	// imageCacheTTL is how long an AMI found to be available is not described again. Like the KMS keys of aliases,
+	// the AMIs of pools rarely go away, so it is as long as the TTL of the KMS keys.
+	imageCacheTTL = 10 * time.Minute
+)
+
+// imageCache is a cache of the AMIs found to be available to an AWS account in a region. It is shared by the actuators
+// created for each reconcile so that the AMI overrides of pools are not described on every reconcile of every
+// MachinePool. It is safe for concurrent use.
+type imageCache struct {
+	store cache.Store
+}
+
+// availableImage is the entry cached for an AMI available to an account in a region.
+type availableImage struct {
+	key string
+}
+
+func newImageCache(ttl time.Duration, clk clock.Clock) *imageCache {
+	return &imageCache{
+		store: cache.NewExpirationStore(
+			func(obj interface{}) (string, error) {
+				return obj.(*availableImage).key, nil
+			},
+			&cache.TTLPolicy{TTL: ttl, Clock: clk},
+		),
+	}
+}
+
+// imageAvailable returns whether the given AMI exists in the region of the given AWS client, is accessible to the
+// account and is available, describing the AMI with the client when it is not cached. Only available AMIs are
+// cached; errors, including those for AMIs that do not exist, are not. A nil imageCache always describes the AMI.
+func (c *imageCache) imageAvailable(awsClient awsclient.Client, account, region, imageID string) (bool, error) {
+	key := fmt.Sprintf("%s/%s/%s", account, region, imageID)
+	if c != nil {
+		if _, exists, _ := c.store.GetByKey(key); exists {
+			return true, nil
+		}
+	}
+	output, err := awsClient.DescribeImages(&ec2.DescribeImagesInput{
+		ImageIds: aws.StringSlice([]string{imageID}),
+	})
+	if err != nil {
+		return false, err
+	}
+	available := false
+	for _, image := range output.Images {
+		if aws.StringValue(image.ImageId) == imageID && aws.StringValue(image.State) == ec2.ImageStateAvailable {
+			available = true
+		}
+	}
+	if available && c != nil {
+		if err := c.store.Add(&availableImage{key: key}); err != nil {
+			return false, err
+		}
+	}
+	return available, nil
+}
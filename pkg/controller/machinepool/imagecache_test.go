@@ -0,0 +1,127 @@
+package machinepool
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	mockaws "github.com/openshift/hive/pkg/awsclient/mock"
+)
+
+const (
+	testImageAccount = "123456789012"
+	testImageID      = "ami-override"
+)
+
+func expectDescribeImages(client *mockaws.MockClient, imageID, state string) *gomock.Call {
+	return client.EXPECT().DescribeImages(&ec2.DescribeImagesInput{
+		ImageIds: aws.StringSlice([]string{imageID}),
+	}).Return(&ec2.DescribeImagesOutput{
+		Images: []*ec2.Image{{ImageId: aws.String(imageID), State: aws.String(state)}},
+	}, nil)
+}
+
+func TestImageCache(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockAWSClient func(*mockaws.MockClient)
+		lookups       func(t *testing.T, c *imageCache, client *mockaws.MockClient, fakeClock *clock.FakeClock)
+	}{
+		{
+			name: "cached within TTL",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeImages(client, testImageID, ec2.ImageStateAvailable).Times(1)
+			},
+			lookups: func(t *testing.T, c *imageCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				for i := 0; i < 3; i++ {
+					available, err := c.imageAvailable(client, testImageAccount, "us-east-1", testImageID)
+					assert.NoError(t, err, "unexpected error")
+					assert.True(t, available, "expected image to be available")
+					fakeClock.Step(time.Minute)
+				}
+			},
+		},
+		{
+			name: "described again after TTL",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeImages(client, testImageID, ec2.ImageStateAvailable).Times(2)
+			},
+			lookups: func(t *testing.T, c *imageCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				_, err := c.imageAvailable(client, testImageAccount, "us-east-1", testImageID)
+				assert.NoError(t, err, "unexpected error")
+				fakeClock.Step(imageCacheTTL + time.Second)
+				_, err = c.imageAvailable(client, testImageAccount, "us-east-1", testImageID)
+				assert.NoError(t, err, "unexpected error")
+			},
+		},
+		{
+			name: "cached per account and region",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeImages(client, testImageID, ec2.ImageStateAvailable).Times(3)
+			},
+			lookups: func(t *testing.T, c *imageCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				for i := 0; i < 2; i++ {
+					for _, lookup := range []struct{ account, region string }{
+						{testImageAccount, "us-east-1"},
+						{"210987654321", "us-east-1"},
+						{testImageAccount, "us-west-2"},
+					} {
+						available, err := c.imageAvailable(client, lookup.account, lookup.region, testImageID)
+						assert.NoError(t, err, "unexpected error")
+						assert.True(t, available, "expected image to be available")
+					}
+				}
+			},
+		},
+		{
+			name: "unavailable images are not cached",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				gomock.InOrder(
+					expectDescribeImages(client, testImageID, ec2.ImageStatePending),
+					expectDescribeImages(client, testImageID, ec2.ImageStateAvailable),
+				)
+			},
+			lookups: func(t *testing.T, c *imageCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				available, err := c.imageAvailable(client, testImageAccount, "us-east-1", testImageID)
+				assert.NoError(t, err, "unexpected error")
+				assert.False(t, available, "expected image to be unavailable")
+				available, err = c.imageAvailable(client, testImageAccount, "us-east-1", testImageID)
+				assert.NoError(t, err, "unexpected error")
+				assert.True(t, available, "expected image to be available")
+			},
+		},
+		{
+			name: "errors are not cached",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				gomock.InOrder(
+					client.EXPECT().DescribeImages(gomock.Any()).Return(nil, errors.New("InvalidAMIID.NotFound")),
+					expectDescribeImages(client, testImageID, ec2.ImageStateAvailable),
+				)
+			},
+			lookups: func(t *testing.T, c *imageCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				_, err := c.imageAvailable(client, testImageAccount, "us-east-1", testImageID)
+				assert.Error(t, err, "expected error")
+				available, err := c.imageAvailable(client, testImageAccount, "us-east-1", testImageID)
+				assert.NoError(t, err, "unexpected error")
+				assert.True(t, available, "expected image to be available")
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockaws.NewMockClient(mockCtrl)
+			test.mockAWSClient(client)
+			fakeClock := clock.NewFakeClock(time.Now())
+			test.lookups(t, newImageCache(imageCacheTTL, fakeClock), client, fakeClock)
+		})
+	}
+}
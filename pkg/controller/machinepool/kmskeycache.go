@@ -0,0 +1,73 @@
+package machinepool
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/hive/pkg/awsclient"
+)
+
+const (
+	// kmsKeyCacheTTL is how long the key resolved for a KMS key alias is re-used. Aliases are rarely pointed at a
+	// different key, so it is longer than the TTL of the route tables.
+	kmsKeyCacheTTL = 10 * time.Minute
+)
+
+// kmsKeyCache is a cache of the KMS keys that aliases resolve to. It is shared by the actuators created for each
+// reconcile so that the aliases of the root volume keys are not described on every reconcile of every MachinePool.
+// It is safe for concurrent use.
+type kmsKeyCache struct {
+	store cache.Store
+}
+
+// kmsAliasKey is the entry cached for a KMS key alias.
+type kmsAliasKey struct {
+	aliasARN string
+	keyARN   string
+}
+
+func newKMSKeyCache(ttl time.Duration, clk clock.Clock) *kmsKeyCache {
+	return &kmsKeyCache{
+		store: cache.NewExpirationStore(
+			func(obj interface{}) (string, error) {
+				return obj.(*kmsAliasKey).aliasARN, nil
+			},
+			&cache.TTLPolicy{TTL: ttl, Clock: clk},
+		),
+	}
+}
+
+// getKeyARN returns the ARN of the KMS key that the given alias ARN resolves to, describing the key with the given
+// AWS client when it is not cached. Errors, including those for aliases that do not exist, are not cached. A nil
+// kmsKeyCache always describes the key.
+func (c *kmsKeyCache) getKeyARN(awsClient awsclient.Client, aliasARN string) (string, error) {
+	if c != nil {
+		if obj, exists, _ := c.store.GetByKey(aliasARN); exists {
+			return obj.(*kmsAliasKey).keyARN, nil
+		}
+	}
+	output, err := awsClient.DescribeKey(&awsclient.DescribeKeyInput{
+		KeyId: aws.String(aliasARN),
+	})
+	if err != nil {
+		return "", err
+	}
+	var keyARN string
+	if output.KeyMetadata != nil {
+		keyARN = aws.StringValue(output.KeyMetadata.Arn)
+	}
+	if keyARN == "" {
+		// EC2 resolves alias ARNs itself, so fall back to the alias if KMS did not return the ARN of the key.
+		keyARN = aliasARN
+	}
+	if c != nil {
+		if err := c.store.Add(&kmsAliasKey{aliasARN: aliasARN, keyARN: keyARN}); err != nil {
+			return "", err
+		}
+	}
+	return keyARN, nil
+}
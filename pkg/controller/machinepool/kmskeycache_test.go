@@ -0,0 +1,108 @@
+package machinepool
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/openshift/hive/pkg/awsclient"
+	mockaws "github.com/openshift/hive/pkg/awsclient/mock"
+)
+
+const (
+	testKMSAliasARN = "arn:aws:kms:us-east-1:123456789012:alias/ebs-worker"
+	testKMSKeyARN   = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+)
+
+func expectDescribeKey(client *mockaws.MockClient, aliasARN, keyARN string) *gomock.Call {
+	return client.EXPECT().DescribeKey(&awsclient.DescribeKeyInput{
+		KeyId: aws.String(aliasARN),
+	}).Return(&awsclient.DescribeKeyOutput{
+		KeyMetadata: &awsclient.KeyMetadata{Arn: aws.String(keyARN)},
+	}, nil)
+}
+
+func TestKMSKeyCache(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockAWSClient func(*mockaws.MockClient)
+		lookups       func(t *testing.T, c *kmsKeyCache, client *mockaws.MockClient, fakeClock *clock.FakeClock)
+	}{
+		{
+			name: "cached within TTL",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeKey(client, testKMSAliasARN, testKMSKeyARN).Times(1)
+			},
+			lookups: func(t *testing.T, c *kmsKeyCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				for i := 0; i < 3; i++ {
+					keyARN, err := c.getKeyARN(client, testKMSAliasARN)
+					assert.NoError(t, err, "unexpected error")
+					assert.Equal(t, testKMSKeyARN, keyARN, "unexpected key ARN")
+					fakeClock.Step(time.Minute)
+				}
+			},
+		},
+		{
+			name: "described again after TTL",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeKey(client, testKMSAliasARN, testKMSKeyARN).Times(2)
+			},
+			lookups: func(t *testing.T, c *kmsKeyCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				_, err := c.getKeyARN(client, testKMSAliasARN)
+				assert.NoError(t, err, "unexpected error")
+				fakeClock.Step(kmsKeyCacheTTL + time.Second)
+				_, err = c.getKeyARN(client, testKMSAliasARN)
+				assert.NoError(t, err, "unexpected error")
+			},
+		},
+		{
+			name: "cached per alias",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeKey(client, testKMSAliasARN, testKMSKeyARN).Times(1)
+				expectDescribeKey(client, "arn:aws:kms:us-east-1:123456789012:alias/other", "arn:aws:kms:us-east-1:123456789012:key/other").Times(1)
+			},
+			lookups: func(t *testing.T, c *kmsKeyCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				for i := 0; i < 2; i++ {
+					keyARN, err := c.getKeyARN(client, testKMSAliasARN)
+					assert.NoError(t, err, "unexpected error")
+					assert.Equal(t, testKMSKeyARN, keyARN, "unexpected key ARN")
+					keyARN, err = c.getKeyARN(client, "arn:aws:kms:us-east-1:123456789012:alias/other")
+					assert.NoError(t, err, "unexpected error")
+					assert.Equal(t, "arn:aws:kms:us-east-1:123456789012:key/other", keyARN, "unexpected key ARN")
+				}
+			},
+		},
+		{
+			name: "errors are not cached",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				gomock.InOrder(
+					client.EXPECT().DescribeKey(gomock.Any()).Return(nil, errors.New("NotFoundException")),
+					expectDescribeKey(client, testKMSAliasARN, testKMSKeyARN),
+				)
+			},
+			lookups: func(t *testing.T, c *kmsKeyCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				_, err := c.getKeyARN(client, testKMSAliasARN)
+				assert.Error(t, err, "expected error")
+				keyARN, err := c.getKeyARN(client, testKMSAliasARN)
+				assert.NoError(t, err, "unexpected error")
+				assert.Equal(t, testKMSKeyARN, keyARN, "unexpected key ARN")
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockaws.NewMockClient(mockCtrl)
+			test.mockAWSClient(client)
+			fakeClock := clock.NewFakeClock(time.Now())
+			test.lookups(t, newKMSKeyCache(kmsKeyCacheTTL, fakeClock), client, fakeClock)
+		})
+	}
+}
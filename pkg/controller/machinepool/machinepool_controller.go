@@ -3,9 +3,12 @@ package machinepool
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,7 +20,9 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,7 +41,6 @@ import (
 	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
-	"github.com/openshift/hive/pkg/awsclient"
 	"github.com/openshift/hive/pkg/constants"
 	hivemetrics "github.com/openshift/hive/pkg/controller/metrics"
 	controllerutils "github.com/openshift/hive/pkg/controller/utils"
@@ -48,6 +52,16 @@ const (
 	machinePoolNameLabel       = "hive.openshift.io/machine-pool"
 	finalizer                  = "hive.openshift.io/remotemachineset"
 	masterMachineLabelSelector = "machine.openshift.io/cluster-api-machine-type=master"
+	// edgeNodeRoleLabel is the node role label of the machines of edge pools, which is also the key of their taint.
+	edgeNodeRoleLabel = "node-role.kubernetes.io/edge"
+	// providerSpecHashAnnotation records on the remote MachineSets the hash of the generated provider spec they were
+	// last created or updated with, to find the MachineSets to update according to the update strategy of the pool.
+	providerSpecHashAnnotation = "hive.openshift.io/provider-spec-hash"
+
+	defaultConfigurationErrorRequeueInterval = time.Hour
+	// userDataSecretRequeueInterval is how soon a machine pool whose user data secret is missing is reconciled again.
+	// The secrets are not watched, so they are looked for again periodically until they are created.
+	userDataSecretRequeueInterval = time.Minute
 )
 
 var (
@@ -60,6 +74,27 @@ var (
 		hivev1.NoMachinePoolNameLeasesAvailable,
 		hivev1.InvalidSubnetsMachinePoolCondition,
 		hivev1.UnsupportedConfigurationMachinePoolCondition,
+		hivev1.InvalidConfigurationMachinePoolCondition,
+		hivev1.UserDataSecretNotFoundMachinePoolCondition,
+		hivev1.LabelsAndTaintsNotAppliedMachinePoolCondition,
+		hivev1.NoUsableZonesMachinePoolCondition,
+		hivev1.InstanceTypeNotResolvedMachinePoolCondition,
+		hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition,
+		hivev1.InstanceStoreVolumesNotManagedMachinePoolCondition,
+		hivev1.SpotMaxPriceTooLowMachinePoolCondition,
+		hivev1.SpotFallbackUnavailableMachinePoolCondition,
+		hivev1.UnevenZoneSpreadMachinePoolCondition,
+		hivev1.UnauthorizedCredentialsMachinePoolCondition,
+		hivev1.AMIResolutionFailedMachinePoolCondition,
+		hivev1.AMIUpdateInProgressMachinePoolCondition,
+		hivev1.InsufficientQuotaMachinePoolCondition,
+		hivev1.InsufficientSubnetIPsMachinePoolCondition,
+		hivev1.NoOutboundConnectivityMachinePoolCondition,
+		hivev1.MachineSetDriftCorrectedMachinePoolCondition,
+		hivev1.VersionGatedFeaturesUnavailableMachinePoolCondition,
+		hivev1.ResourcesNotFoundMachinePoolCondition,
+		hivev1.InvalidUpdateStrategyMachinePoolCondition,
+		hivev1.InvalidPlatformMachinePoolCondition,
 	}
 )
 
@@ -90,14 +125,82 @@ func Add(mgr manager.Manager) error {
 		return err
 	}
 
-	r := &ReconcileMachinePool{
-		Client:       controllerutils.NewClientWithMetricsOrDie(mgr, ControllerName, &clientRateLimiter),
-		scheme:       mgr.GetScheme(),
-		logger:       logger,
-		expectations: controllerutils.NewExpectations(logger),
+	awsRetryBackoff, err := getAWSRetryBackoff()
+	if err != nil {
+		logger.WithError(err).Error("could not get AWS retry configuration")
+		return err
+	}
+
+	awsRateLimiters, err := getAWSRateLimiters()
+	if err != nil {
+		logger.WithError(err).Error("could not get AWS rate limit configuration")
+		return err
+	}
+
+	configurationErrorRequeueInterval, err := getConfigurationErrorRequeueInterval()
+	if err != nil {
+		logger.WithError(err).Error("could not get configuration error requeue interval")
+		return err
+	}
+
+	additionalTags, err := getAdditionalTags()
+	if err != nil {
+		logger.WithError(err).Error("could not get additional tags")
+		return err
+	}
+
+	readOnlyStatus, err := getReadOnlyStatus()
+	if err != nil {
+		logger.WithError(err).Error("could not get read-only status configuration")
+		return err
+	}
+
+	awsQuotaCheck, err := getAWSQuotaCheck()
+	if err != nil {
+		logger.WithError(err).Error("could not get AWS quota check configuration")
+		return err
 	}
-	r.actuatorBuilder = func(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, masterMachine *machineapi.Machine, remoteMachineSets []machineapi.MachineSet, logger log.FieldLogger) (Actuator, error) {
-		return r.createActuator(cd, pool, masterMachine, remoteMachineSets, logger)
+
+	awsSubnetIPCheck, err := getAWSSubnetIPCheck()
+	if err != nil {
+		logger.WithError(err).Error("could not get AWS subnet IP check configuration")
+		return err
+	}
+
+	awsAMISources, err := getAWSAMISources()
+	if err != nil {
+		logger.WithError(err).Error("could not get AWS AMI sources")
+		return err
+	}
+
+	versionGatedFeatures, err := getVersionGatedFeatures()
+	if err != nil {
+		logger.WithError(err).Error("could not get version-gated features")
+		return err
+	}
+
+	r := &ReconcileMachinePool{
+		Client:          controllerutils.NewClientWithMetricsOrDie(mgr, ControllerName, &clientRateLimiter),
+		scheme:          mgr.GetScheme(),
+		logger:          logger,
+		expectations:    controllerutils.NewExpectations(logger),
+		routeTables:     newRouteTableCache(routeTableCacheTTL, clock.RealClock{}),
+		kmsKeys:         newKMSKeyCache(kmsKeyCacheTTL, clock.RealClock{}),
+		images:          newImageCache(imageCacheTTL, clock.RealClock{}),
+		awsRetryBackoff: awsRetryBackoff,
+		awsRateLimiters: awsRateLimiters,
+		awsSingleflight: newAWSSingleflight(),
+
+		configurationErrorRequeueInterval: configurationErrorRequeueInterval,
+		additionalTags:                    additionalTags,
+		readOnlyStatus:                    readOnlyStatus,
+		awsQuotaCheck:                     awsQuotaCheck,
+		awsSubnetIPCheck:                  awsSubnetIPCheck,
+		awsAMISources:                     awsAMISources,
+		versionGatedFeatures:              versionGatedFeatures,
+	}
+	r.actuatorBuilder = func(ctx context.Context, cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, masterMachine *machineapi.Machine, remoteMachineSets []machineapi.MachineSet, remoteClusterAPIClient client.Client, logger log.FieldLogger) (Actuator, error) {
+		return r.createActuator(ctx, cd, pool, masterMachine, remoteMachineSets, remoteClusterAPIClient, logger)
 	}
 	r.remoteClusterAPIClientBuilder = func(cd *hivev1.ClusterDeployment) remoteclient.Builder {
 		return remoteclient.NewBuilder(r.Client, cd, ControllerName)
@@ -187,16 +290,60 @@ type ReconcileMachinePool struct {
 
 	// actuatorBuilder is a function pointer to the function that builds the actuator
 	actuatorBuilder func(
+		ctx context.Context,
 		cd *hivev1.ClusterDeployment,
 		pool *hivev1.MachinePool,
 		masterMachine *machineapi.Machine,
 		remoteMachineSets []machineapi.MachineSet,
+		remoteClusterAPIClient client.Client,
 		logger log.FieldLogger,
 	) (Actuator, error)
 
 	// A TTLCache of machinepoolnamelease creates each machinepool expects to see. Note that not all actuators make use
 	// of expectations.
 	expectations controllerutils.ExpectationsInterface
+
+	// routeTables is a short-lived cache of AWS route tables by VPC, shared by the AWS actuators.
+	routeTables *routeTableCache
+	// kmsKeys is a cache of the KMS keys resolved from aliases, shared by the AWS actuators.
+	kmsKeys *kmsKeyCache
+	// images is a cache of the AMIs found to be available, shared by the AWS actuators.
+	images *imageCache
+
+	// awsRetryBackoff is the backoff with which the AWS actuators retry describe calls failing with transient errors.
+	awsRetryBackoff wait.Backoff
+
+	// awsRateLimiters limit the rate of the AWS describe calls made by the AWS actuators in each account. Nil when the
+	// calls are not rate limited.
+	awsRateLimiters *awsRateLimiters
+	// awsSingleflight collapses the identical AWS describe calls made concurrently by the AWS actuators of the same
+	// cluster. Nil when the calls are not collapsed.
+	awsSingleflight *awsSingleflight
+
+	// configurationErrorRequeueInterval is how long to wait before reconciling a MachinePool again when its MachineSets
+	// cannot be generated because of a configuration error. Zero only reconciles the pool when it changes.
+	configurationErrorRequeueInterval time.Duration
+
+	// additionalTags are the tags added to the machines of every MachinePool, as configured in HiveConfig.
+	additionalTags map[string]string
+
+	// readOnlyStatus prevents the actuators from persisting the status of MachinePools, as for a reconcile which only
+	// observes the pools. The conditions computed by the actuators are left on the MachinePool for the caller to write.
+	// It is configured by the MACHINEPOOL_READ_ONLY_STATUS environment variable.
+	readOnlyStatus bool
+
+	// awsQuotaCheck enables checking the machines of AWS MachinePools against the quotas of their account.
+	awsQuotaCheck bool
+
+	// awsSubnetIPCheck enables checking the available IP addresses of the private subnets of AWS MachinePools.
+	awsSubnetIPCheck bool
+
+	// awsAMISources are the sources of the AMIs of AWS MachinePools without a boot image, in the order they are tried.
+	awsAMISources []hivev1.BootImageSource
+
+	// versionGatedFeatures are the features gated by the version of the cluster, with the range of versions supporting
+	// spot instances as configured in HiveConfig. Nil for the default ones.
+	versionGatedFeatures []versionGatedFeature
 }
 
 // Reconcile reads that state of the cluster for a MachinePool object and makes changes to the
@@ -273,9 +420,12 @@ func (r *ReconcileMachinePool) Reconcile(ctx context.Context, request reconcile.
 		return reconcile.Result{}, nil
 	}
 
-	if cd.Spec.ClusterMetadata == nil {
-		logger.Error("installed cluster with no cluster metadata")
-		return reconcile.Result{}, nil
+	switch result, err := r.ensureValidPlatform(cd, pool, logger); {
+	case err != nil:
+		logger.WithError(err).Log(controllerutils.LogLevel(err), "could not ensureValidPlatform")
+		return reconcile.Result{}, err
+	case result != nil:
+		return *result, nil
 	}
 
 	if !controllerutils.HasFinalizer(pool, finalizer) {
@@ -292,6 +442,11 @@ func (r *ReconcileMachinePool) Reconcile(ctx context.Context, request reconcile.
 		return reconcile.Result{}, nil
 	}
 
+	if err := r.setVersionGatedFeaturesCondition(cd, pool, logger); err != nil {
+		logger.WithError(err).Log(controllerutils.LogLevel(err), "could not setVersionGatedFeaturesCondition")
+		return reconcile.Result{}, err
+	}
+
 	remoteClusterAPIClient, unreachable, requeue := remoteclient.ConnectToRemoteCluster(
 		cd,
 		r.remoteClusterAPIClientBuilder(cd),
@@ -315,7 +470,13 @@ func (r *ReconcileMachinePool) Reconcile(ctx context.Context, request reconcile.
 		return reconcile.Result{}, err
 	}
 
-	generatedMachineSets, proceed, err := r.generateMachineSets(pool, cd, masterMachine, remoteMachineSets, logger)
+	generatedMachineSets, proceed, err := r.generateMachineSets(ctx, pool, cd, masterMachine, remoteMachineSets, remoteClusterAPIClient, logger)
+	if isConfigurationError(err) {
+		// Retrying cannot help until the user fixes the configuration, which triggers a reconcile.
+		logger.WithError(err).WithField("requeueAfter", r.configurationErrorRequeueInterval).
+			Info("MachineSets cannot be generated because of a configuration error")
+		return reconcile.Result{RequeueAfter: r.configurationErrorRequeueInterval}, nil
+	}
 	if err != nil {
 		logger.WithError(err).Log(controllerutils.LogLevel(err), "could not generateMachineSets")
 		return reconcile.Result{}, err
@@ -324,6 +485,14 @@ func (r *ReconcileMachinePool) Reconcile(ctx context.Context, request reconcile.
 		return reconcile.Result{}, nil
 	}
 
+	switch result, err := r.ensureUserDataSecret(cd, pool, remoteClusterAPIClient, logger); {
+	case err != nil:
+		logger.WithError(err).Log(controllerutils.LogLevel(err), "could not ensureUserDataSecret")
+		return reconcile.Result{}, err
+	case result != nil:
+		return *result, nil
+	}
+
 	switch result, err := r.ensureEnoughReplicas(pool, generatedMachineSets, cd, logger); {
 	case err != nil:
 		logger.WithError(err).Log(controllerutils.LogLevel(err), "could not ensureEnoughReplicas")
@@ -332,7 +501,15 @@ func (r *ReconcileMachinePool) Reconcile(ctx context.Context, request reconcile.
 		return *result, nil
 	}
 
-	machineSets, err := r.syncMachineSets(pool, cd, generatedMachineSets, remoteMachineSets, remoteClusterAPIClient, logger)
+	switch result, err := r.ensureValidUpdateStrategy(pool, logger); {
+	case err != nil:
+		logger.WithError(err).Log(controllerutils.LogLevel(err), "could not ensureValidUpdateStrategy")
+		return reconcile.Result{}, err
+	case result != nil:
+		return *result, nil
+	}
+
+	machineSets, prunedMachineSets, driftedMachineSets, err := r.syncMachineSets(pool, cd, generatedMachineSets, remoteMachineSets, remoteClusterAPIClient, logger)
 	if err != nil {
 		logger.WithError(err).Log(controllerutils.LogLevel(err), "could not syncMachineSets")
 		return reconcile.Result{}, err
@@ -349,10 +526,18 @@ func (r *ReconcileMachinePool) Reconcile(ctx context.Context, request reconcile.
 	}
 
 	if pool.DeletionTimestamp != nil {
+		if err := r.deleteMergedUserDataSecret(pool, remoteClusterAPIClient, logger); err != nil {
+			logger.WithError(err).Log(controllerutils.LogLevel(err), "could not deleteMergedUserDataSecret")
+			return reconcile.Result{}, err
+		}
+		if err := r.cleanupResources(ctx, pool, cd, masterMachine, remoteMachineSets, remoteClusterAPIClient, logger); err != nil {
+			logger.WithError(err).Log(controllerutils.LogLevel(err), "could not cleanupResources")
+			return reconcile.Result{}, err
+		}
 		return r.removeFinalizer(pool, logger)
 	}
 
-	return r.updatePoolStatusForMachineSets(pool, machineSets, remoteClusterAPIClient, logger)
+	return r.updatePoolStatusForMachineSets(pool, cd, generatedMachineSets, machineSets, prunedMachineSets, driftedMachineSets, remoteClusterAPIClient, logger)
 }
 
 func (r *ReconcileMachinePool) getMasterMachine(
@@ -403,24 +588,33 @@ func (r *ReconcileMachinePool) getRemoteMachineSets(
 }
 
 func (r *ReconcileMachinePool) generateMachineSets(
+	ctx context.Context,
 	pool *hivev1.MachinePool,
 	cd *hivev1.ClusterDeployment,
 	masterMachine *machineapi.Machine,
 	remoteMachineSets *machineapi.MachineSetList,
+	remoteClusterAPIClient client.Client,
 	logger log.FieldLogger,
 ) ([]*machineapi.MachineSet, bool, error) {
 	if pool.DeletionTimestamp != nil {
 		return nil, true, nil
 	}
 
-	actuator, err := r.actuatorBuilder(cd, pool, masterMachine, remoteMachineSets.Items, logger)
+	actuator, err := r.actuatorBuilder(ctx, cd, pool, masterMachine, remoteMachineSets.Items, remoteClusterAPIClient, logger)
 	if err != nil {
 		logger.WithError(err).Error("unable to create actuator")
 		return nil, false, err
 	}
 
 	// Generate expected MachineSets for Platform from InstallConfig
+	platform := getMachinePoolPlatform(pool)
+	origBootImage := pool.Status.BootImage.DeepCopy()
+	start := time.Now()
 	generatedMachineSets, proceed, err := actuator.GenerateMachineSets(cd, pool, logger)
+	metricGenerateMachineSetsDuration.WithLabelValues(platform).Observe(time.Since(start).Seconds())
+	if reason := generationFailureReason(err); reason != "" {
+		metricGenerateMachineSetsErrors.WithLabelValues(platform, reason).Inc()
+	}
 	if err != nil {
 		return nil, false, errors.Wrap(err, "could not generate machinesets")
 	} else if !proceed {
@@ -428,6 +622,23 @@ func (r *ReconcileMachinePool) generateMachineSets(
 		return nil, false, nil
 	}
 
+	bootImageChanged := !reflect.DeepEqual(origBootImage, pool.Status.BootImage)
+	if bootImageChanged {
+		logger.WithField("bootImage", pool.Status.BootImage.Image).WithField("source", pool.Status.BootImage.Source).
+			Info("boot image of machinesets changed")
+	}
+	capabilities := actuator.Capabilities(cd, pool)
+	capabilitiesChanged := !reflect.DeepEqual(capabilities, pool.Status.Capabilities)
+	if capabilitiesChanged {
+		logger.Info("capabilities of machine pool changed")
+		pool.Status.Capabilities = capabilities
+	}
+	if bootImageChanged || capabilitiesChanged {
+		if err := r.Status().Update(context.Background(), pool); err != nil {
+			return nil, false, errors.Wrap(err, "could not update MachinePool status")
+		}
+	}
+
 	for i, ms := range generatedMachineSets {
 		if pool.Spec.Autoscaling != nil {
 			min, _ := getMinMaxReplicasForMachineSet(pool, generatedMachineSets, i)
@@ -441,14 +652,11 @@ func (r *ReconcileMachinePool) generateMachineSets(
 		// Add the managed-by-Hive label:
 		ms.Labels[constants.HiveManagedLabel] = "true"
 
-		// Apply hive MachinePool labels to MachineSet MachineSpec.
-		ms.Spec.Template.Spec.ObjectMeta.Labels = make(map[string]string, len(pool.Spec.Labels))
-		for key, value := range pool.Spec.Labels {
-			ms.Spec.Template.Spec.ObjectMeta.Labels[key] = value
-		}
+		applyLabelsAndTaints(pool, ms)
 
-		// Apply hive MachinePool taints to MachineSet MachineSpec.
-		ms.Spec.Template.Spec.Taints = pool.Spec.Taints
+		if pool.Spec.DeletePolicy != "" {
+			ms.Spec.DeletePolicy = string(pool.Spec.DeletePolicy)
+		}
 	}
 
 	logger.Infof("generated %v worker machine sets", len(generatedMachineSets))
@@ -456,6 +664,308 @@ func (r *ReconcileMachinePool) generateMachineSets(
 	return generatedMachineSets, true, nil
 }
 
+// isConfigurationError returns true when the MachineSets of the MachinePool could not be generated because of a
+// configuration error which requires user action, which actuators return as a *ValidationError. Other errors, such as
+// failing to update the status of the pool or to reach AWS, are not configuration errors, even if a condition still
+// reports a configuration error from an earlier attempt.
+func isConfigurationError(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
+
+// getConfigurationErrorRequeueInterval returns the interval after which to reconcile a MachinePool with a configuration
+// error again, as configured by the MACHINEPOOL_CONFIGURATION_ERROR_REQUEUE_INTERVAL environment variable.
+func getConfigurationErrorRequeueInterval() (time.Duration, error) {
+	value, ok := os.LookupEnv(constants.MachinePoolConfigurationErrorRequeueIntervalEnvVar)
+	if !ok {
+		return defaultConfigurationErrorRequeueInterval, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		return 0, errors.Errorf("invalid %s: %q", constants.MachinePoolConfigurationErrorRequeueIntervalEnvVar, value)
+	}
+	return interval, nil
+}
+
+// getAdditionalTags returns the tags to add to the machines of every MachinePool, as configured by the
+// MACHINEPOOL_ADDITIONAL_TAGS environment variable.
+func getAdditionalTags() (map[string]string, error) {
+	value, ok := os.LookupEnv(constants.MachinePoolAdditionalTagsEnvVar)
+	if !ok || value == "" {
+		return nil, nil
+	}
+	tags := map[string]string{}
+	if err := json.Unmarshal([]byte(value), &tags); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", constants.MachinePoolAdditionalTagsEnvVar)
+	}
+	return tags, nil
+}
+
+// getReadOnlyStatus returns whether the actuators must not persist the status of MachinePools, as configured by the
+// MACHINEPOOL_READ_ONLY_STATUS environment variable.
+func getReadOnlyStatus() (bool, error) {
+	value, ok := os.LookupEnv(constants.MachinePoolReadOnlyStatusEnvVar)
+	if !ok || value == "" {
+		return false, nil
+	}
+	readOnly, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.Errorf("invalid %s: %q", constants.MachinePoolReadOnlyStatusEnvVar, value)
+	}
+	return readOnly, nil
+}
+
+// withAdditionalTags returns the user tags of a cluster with the additional tags configured for all MachinePools
+// added. The additional tags take precedence over user tags with the same key.
+func withAdditionalTags(userTags, additionalTags map[string]string) map[string]string {
+	if len(additionalTags) == 0 {
+		return userTags
+	}
+	tags := make(map[string]string, len(userTags)+len(additionalTags))
+	for key, value := range userTags {
+		tags[key] = value
+	}
+	for key, value := range additionalTags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// poolLabelsAndTaints returns the labels and taints for the machines of the MachinePool: those of the pool, plus the
+// edge node role label and, unless the pool has its own taint with that key, a NoSchedule taint for AWS edge pools.
+func poolLabelsAndTaints(pool *hivev1.MachinePool) (map[string]string, []corev1.Taint) {
+	labels := make(map[string]string, len(pool.Spec.Labels)+1)
+	for key, value := range pool.Spec.Labels {
+		labels[key] = value
+	}
+	var taints []corev1.Taint
+	if pool.Spec.Taints != nil {
+		taints = make([]corev1.Taint, len(pool.Spec.Taints))
+		copy(taints, pool.Spec.Taints)
+	}
+	if aws := pool.Spec.Platform.AWS; aws != nil && aws.Edge {
+		if _, ok := labels[edgeNodeRoleLabel]; !ok {
+			labels[edgeNodeRoleLabel] = ""
+		}
+		hasEdgeTaint := false
+		for _, taint := range taints {
+			if taint.Key == edgeNodeRoleLabel {
+				hasEdgeTaint = true
+				break
+			}
+		}
+		if !hasEdgeTaint {
+			taints = append(taints, corev1.Taint{Key: edgeNodeRoleLabel, Effect: corev1.TaintEffectNoSchedule})
+		}
+	}
+	return labels, taints
+}
+
+// applyLabelsAndTaints copies the labels and taints of the MachinePool into the MachineSpec of the MachineSet
+// template. This is done here rather than in each actuator so that every platform is handled the same way.
+func applyLabelsAndTaints(pool *hivev1.MachinePool, ms *machineapi.MachineSet) {
+	ms.Spec.Template.Spec.ObjectMeta.Labels, ms.Spec.Template.Spec.Taints = poolLabelsAndTaints(pool)
+}
+
+// hasLabelsAndTaints returns true if the MachineSpec of the MachineSet template carries all of the labels and taints
+// of the MachinePool.
+func hasLabelsAndTaints(pool *hivev1.MachinePool, ms *machineapi.MachineSet) bool {
+	labels, taints := poolLabelsAndTaints(pool)
+	for key, value := range labels {
+		if v, ok := ms.Spec.Template.Spec.Labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	for _, taint := range taints {
+		found := false
+		for _, t := range ms.Spec.Template.Spec.Taints {
+			if t.MatchTaint(&taint) && t.Value == taint.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ensureUserDataSecret ensures that the user data secret named in the machine pool exists in the remote cluster, and
+// that the merged user data secret is up to date when the machine pool references an Ignition config to merge. The
+// default user data secret is managed by the machine-config-operator and is only checked when it is merged. If the
+// reconcile.Result returned is non-nil, then the reconciliation loop should stop, returning that result.
+func (r *ReconcileMachinePool) ensureUserDataSecret(
+	cd *hivev1.ClusterDeployment,
+	pool *hivev1.MachinePool,
+	remoteClusterAPIClient client.Client,
+	logger log.FieldLogger,
+) (*reconcile.Result, error) {
+	if pool.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	if pool.Spec.UserDataSecretName != "" || pool.Spec.MergeIgnitionSecretRef != nil {
+		secret := &corev1.Secret{}
+		switch err := remoteClusterAPIClient.Get(
+			context.Background(),
+			client.ObjectKey{Namespace: machineAPINamespace, Name: baseUserData(pool)},
+			secret,
+		); {
+		case apierrors.IsNotFound(err):
+			logger.WithField("secret", baseUserData(pool)).Warning("user data secret does not exist in the remote cluster")
+			return r.setUserDataSecretNotFoundCondition(
+				pool,
+				"UserDataSecretNotFound",
+				fmt.Sprintf("The user data secret %s/%s does not exist in the cluster", machineAPINamespace, baseUserData(pool)),
+				logger,
+			)
+		case err != nil:
+			logger.WithError(err).Error("unable to fetch user data secret")
+			return &reconcile.Result{}, err
+		}
+		if pool.Spec.MergeIgnitionSecretRef != nil {
+			if result, err := r.ensureMergedUserDataSecret(cd, pool, secret, remoteClusterAPIClient, logger); result != nil || err != nil {
+				return result, err
+			}
+		}
+	}
+	conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+		pool.Status.Conditions,
+		hivev1.UserDataSecretNotFoundMachinePoolCondition,
+		corev1.ConditionFalse,
+		"UserDataSecretFound",
+		"The user data secret exists in the cluster",
+		controllerutils.UpdateConditionNever,
+	)
+	if changed {
+		pool.Status.Conditions = conds
+		if err := r.Status().Update(context.Background(), pool); err != nil {
+			logger.WithError(err).Error("failed to update MachinePool conditions")
+			return &reconcile.Result{}, err
+		}
+	}
+	return nil, nil
+}
+
+// ensureMergedUserDataSecret creates or updates the secret in the remote cluster whose user data merges the given base
+// user data secret with the Ignition config referenced by the machine pool. If the reconcile.Result returned is
+// non-nil, then the reconciliation loop should stop, returning that result.
+func (r *ReconcileMachinePool) ensureMergedUserDataSecret(
+	cd *hivev1.ClusterDeployment,
+	pool *hivev1.MachinePool,
+	baseSecret *corev1.Secret,
+	remoteClusterAPIClient client.Client,
+	logger log.FieldLogger,
+) (*reconcile.Result, error) {
+	ignitionSecret := &corev1.Secret{}
+	ref := pool.Spec.MergeIgnitionSecretRef
+	switch err := r.Get(context.Background(), client.ObjectKey{Namespace: pool.Namespace, Name: ref.Name}, ignitionSecret); {
+	case apierrors.IsNotFound(err):
+		logger.WithField("secret", ref.Name).Warning("merge ignition secret does not exist")
+		return r.setUserDataSecretNotFoundCondition(
+			pool,
+			"MergeIgnitionSecretNotFound",
+			fmt.Sprintf("The merge ignition secret %s/%s does not exist", pool.Namespace, ref.Name),
+			logger,
+		)
+	case err != nil:
+		logger.WithError(err).Error("unable to fetch merge ignition secret")
+		return &reconcile.Result{}, err
+	}
+	// The version is only used to pick the Ignition spec of the merged config, so an unknown version is not an error.
+	clusterVersion, _ := getClusterVersion(cd, pool)
+	userData, err := mergedUserData(baseSecret.Data[userDataSecretKey], ignitionSecret.Data[mergeIgnitionSecretKey], clusterVersion)
+	if err != nil {
+		logger.WithError(err).WithField("secret", ref.Name).Warning("invalid merge ignition secret")
+		return r.setUserDataSecretNotFoundCondition(
+			pool,
+			"InvalidMergeIgnitionSecret",
+			fmt.Sprintf("The merge ignition secret %s/%s does not hold an Ignition config under the %s key: %v",
+				pool.Namespace, ref.Name, mergeIgnitionSecretKey, err),
+			logger,
+		)
+	}
+
+	// Keep the other keys of the base secret, such as disableTemplating, which also apply to the merged user data.
+	data := make(map[string][]byte, len(baseSecret.Data))
+	for k, v := range baseSecret.Data {
+		data[k] = v
+	}
+	data[userDataSecretKey] = userData
+
+	mergedSecret := &corev1.Secret{}
+	switch err := remoteClusterAPIClient.Get(
+		context.Background(),
+		client.ObjectKey{Namespace: machineAPINamespace, Name: mergedUserDataName(pool)},
+		mergedSecret,
+	); {
+	case apierrors.IsNotFound(err):
+		mergedSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: machineAPINamespace,
+				Name:      mergedUserDataName(pool),
+				Labels: map[string]string{
+					machinePoolNameLabel:       pool.Spec.Name,
+					constants.HiveManagedLabel: "true",
+				},
+			},
+			Data: data,
+		}
+		logger.WithField("secret", mergedSecret.Name).Info("creating merged user data secret")
+		if err := remoteClusterAPIClient.Create(context.Background(), mergedSecret); err != nil {
+			logger.WithError(err).Log(controllerutils.LogLevel(err), "unable to create merged user data secret")
+			return &reconcile.Result{}, err
+		}
+	case err != nil:
+		logger.WithError(err).Error("unable to fetch merged user data secret")
+		return &reconcile.Result{}, err
+	case !reflect.DeepEqual(mergedSecret.Data, data):
+		mergedSecret.Data = data
+		logger.WithField("secret", mergedSecret.Name).Info("updating merged user data secret")
+		if err := remoteClusterAPIClient.Update(context.Background(), mergedSecret); err != nil {
+			logger.WithError(err).Log(controllerutils.LogLevel(err), "unable to update merged user data secret")
+			return &reconcile.Result{}, err
+		}
+	}
+	return nil, nil
+}
+
+// setUserDataSecretNotFoundCondition sets the UserDataSecretNotFound condition of the machine pool and returns the
+// result stopping the reconciliation loop, requeued to check for the secret again shortly.
+func (r *ReconcileMachinePool) setUserDataSecretNotFoundCondition(pool *hivev1.MachinePool, reason, message string, logger log.FieldLogger) (*reconcile.Result, error) {
+	conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+		pool.Status.Conditions,
+		hivev1.UserDataSecretNotFoundMachinePoolCondition,
+		corev1.ConditionTrue,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	if changed {
+		pool.Status.Conditions = conds
+		if err := r.Status().Update(context.Background(), pool); err != nil {
+			logger.WithError(err).Error("failed to update MachinePool conditions")
+			return &reconcile.Result{}, err
+		}
+	}
+	return &reconcile.Result{RequeueAfter: userDataSecretRequeueInterval}, nil
+}
+
+// deleteMergedUserDataSecret deletes the merged user data secret of the machine pool from the remote cluster once its
+// machine sets are deleted.
+func (r *ReconcileMachinePool) deleteMergedUserDataSecret(pool *hivev1.MachinePool, remoteClusterAPIClient client.Client, logger log.FieldLogger) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: machineAPINamespace,
+			Name:      mergedUserDataName(pool),
+		},
+	}
+	if err := remoteClusterAPIClient.Delete(context.Background(), secret); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "could not delete merged user data secret")
+	}
+	return nil
+}
+
 // ensureEnoughReplicas ensures that the min replicas in the machine pool is
 // large enough to cover all of the zones for the machine pool. When using
 // auto-scaling for some platforms, every machineset needs to have a minimum replicas of 1.
@@ -473,16 +983,34 @@ func (r *ReconcileMachinePool) ensureEnoughReplicas(
 	if pool.Spec.Autoscaling == nil {
 		return nil, nil
 	}
-	if pool.Spec.Autoscaling.MinReplicas < int32(len(generatedMachineSets)) && !platformAllowsZeroAutoscalingMinReplicas(cd) {
+	// The MachineSets in zones with replica overrides get the minimum replicas of their zone, and every other
+	// MachineSet needs at least one of the remaining minimum replicas of the pool.
+	requiredMinReplicas := int32(len(generatedMachineSets))
+	var zonesWithoutReplicas []string
+	for _, ms := range generatedMachineSets {
+		zone := machineSetZone(ms)
+		if replicas, ok := pool.Spec.Autoscaling.ZoneReplicas[zone]; ok {
+			requiredMinReplicas += replicas.MinReplicas - 1
+			if replicas.MinReplicas < 1 {
+				zonesWithoutReplicas = append(zonesWithoutReplicas, zone)
+			}
+		}
+	}
+	if (pool.Spec.Autoscaling.MinReplicas < requiredMinReplicas || len(zonesWithoutReplicas) > 0) && !platformAllowsZeroAutoscalingMinReplicas(cd) {
 		logger.WithField("machinesets", len(generatedMachineSets)).
 			WithField("minReplicas", pool.Spec.Autoscaling.MinReplicas).
+			WithField("zonesWithoutReplicas", zonesWithoutReplicas).
 			Warning("when auto-scaling, the MachinePool must have at least one replica for each MachineSet")
+		message := fmt.Sprintf("When auto-scaling, the MachinePool must have at least one replica for each MachineSet. The minReplicas must be at least %d", requiredMinReplicas)
+		if len(zonesWithoutReplicas) > 0 {
+			message = fmt.Sprintf("When auto-scaling, the MachinePool must have at least one replica for each MachineSet. The minReplicas of availability zones %s must be at least 1", strings.Join(zonesWithoutReplicas, ", "))
+		}
 		conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
 			pool.Status.Conditions,
 			hivev1.NotEnoughReplicasMachinePoolCondition,
 			corev1.ConditionTrue,
 			"MinReplicasTooSmall",
-			fmt.Sprintf("When auto-scaling, the MachinePool must have at least one replica for each MachineSet. The minReplicas must be at least %d", len(generatedMachineSets)),
+			message,
 			controllerutils.UpdateConditionIfReasonOrMessageChange,
 		)
 		if changed {
@@ -513,6 +1041,82 @@ func (r *ReconcileMachinePool) ensureEnoughReplicas(
 	return nil, nil
 }
 
+// ensureValidPlatform sets the InvalidPlatform condition of the MachinePool according to whether MachineSets can be
+// generated for it on the platform of its ClusterDeployment, and returns a result when they cannot so that the pool is
+// not reconciled further. Retrying cannot help: the platform of the pool must match that of the ClusterDeployment, which
+// must have the cluster metadata of the installed cluster, and the pool is reconciled again when either of them changes.
+func (r *ReconcileMachinePool) ensureValidPlatform(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, logger log.FieldLogger) (*reconcile.Result, error) {
+	cdPlatform, poolPlatform := clusterPlatform(cd), getMachinePoolPlatform(pool)
+	status, reason, message := corev1.ConditionFalse, "ValidPlatform",
+		fmt.Sprintf("The MachinePool and its ClusterDeployment are both for %s", cdPlatform)
+	switch {
+	case cd.Spec.ClusterMetadata == nil:
+		status, reason = corev1.ConditionTrue, "MissingClusterMetadata"
+		message = fmt.Sprintf("The installed ClusterDeployment %s has no cluster metadata", cd.Name)
+	case poolPlatform == constants.PlatformUnknown || poolPlatform != cdPlatform:
+		status, reason = corev1.ConditionTrue, "PlatformMismatch"
+		message = fmt.Sprintf("The MachinePool is for %s but its ClusterDeployment %s is for %s", poolPlatform, cd.Name, cdPlatform)
+	}
+	conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+		pool.Status.Conditions,
+		hivev1.InvalidPlatformMachinePoolCondition,
+		status,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	if changed {
+		pool.Status.Conditions = conds
+		if err := r.Status().Update(context.Background(), pool); err != nil {
+			logger.WithError(err).Error("failed to update MachinePool conditions")
+			return &reconcile.Result{}, err
+		}
+	}
+	if status == corev1.ConditionTrue {
+		logger.WithField("reason", reason).Warn(message)
+		return &reconcile.Result{}, nil
+	}
+	return nil, nil
+}
+
+// ensureValidUpdateStrategy sets the InvalidUpdateStrategy condition of the MachinePool according to whether its update
+// strategy is valid, and returns a result when it is not so that the MachineSets of the pool are not synced until the
+// strategy is fixed. The webhook rejects invalid strategies, but MachinePools may have been created without it.
+func (r *ReconcileMachinePool) ensureValidUpdateStrategy(pool *hivev1.MachinePool, logger log.FieldLogger) (*reconcile.Result, error) {
+	status, reason, message := corev1.ConditionFalse, "ValidUpdateStrategy", "The update strategy is valid"
+	if strategy := pool.Spec.UpdateStrategy; strategy != nil {
+		if _, err := maxUnavailableMachineSets(strategy, 0); err != nil {
+			status, reason, message = corev1.ConditionTrue, "InvalidMaxUnavailable", err.Error()
+		} else if strategy.MaxUnavailable != nil && strategy.Type != hivev1.RollingMachinePoolUpdateStrategyType {
+			status, reason, message = corev1.ConditionTrue, "MaxUnavailableWithoutRollingStrategy",
+				fmt.Sprintf("maxUnavailable is only used by the %s update strategy, not %s", hivev1.RollingMachinePoolUpdateStrategyType, strategy.Type)
+		}
+	}
+	conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+		pool.Status.Conditions,
+		hivev1.InvalidUpdateStrategyMachinePoolCondition,
+		status,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	if changed {
+		pool.Status.Conditions = conds
+		if err := r.Status().Update(context.Background(), pool); err != nil {
+			logger.WithError(err).Error("failed to update MachinePool conditions")
+			return &reconcile.Result{}, err
+		}
+	}
+	if status == corev1.ConditionTrue {
+		logger.WithField("reason", reason).Warn(message)
+		return &reconcile.Result{}, nil
+	}
+	return nil, nil
+}
+
+// syncMachineSets creates, updates and deletes the MachineSets in the remote cluster so that the MachineSets controlled
+// by the MachinePool match the generated MachineSets. Returns the resulting MachineSets and the names of the MachineSets
+// that were deleted because they are no longer generated for the MachinePool.
 func (r *ReconcileMachinePool) syncMachineSets(
 	pool *hivev1.MachinePool,
 	cd *hivev1.ClusterDeployment,
@@ -520,15 +1124,22 @@ func (r *ReconcileMachinePool) syncMachineSets(
 	remoteMachineSets *machineapi.MachineSetList,
 	remoteClusterAPIClient client.Client,
 	logger log.FieldLogger,
-) ([]*machineapi.MachineSet, error) {
+) ([]*machineapi.MachineSet, []string, []string, error) {
 	result := make([]*machineapi.MachineSet, len(generatedMachineSets))
+	var driftedMachineSets []string
 
 	machineSetsToDelete := []*machineapi.MachineSet{}
 	machineSetsToCreate := []*machineapi.MachineSet{}
 	machineSetsToUpdate := []*machineapi.MachineSet{}
 
+	providerSpecUpdates := providerSpecUpdateBudget(pool, generatedMachineSets, remoteMachineSets, logger)
+
 	// Find MachineSets that need updating/creating
 	for i, ms := range generatedMachineSets {
+		specHash, err := controllerutils.GetChecksumOfObject(ms.Spec.Template.Spec.ProviderSpec.Value)
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "could not hash provider spec")
+		}
 		found := false
 		for _, rMS := range remoteMachineSets.Items {
 			if ms.Name == rMS.Name {
@@ -587,6 +1198,41 @@ func (r *ReconcileMachinePool) syncMachineSets(
 					objectModified = true
 				}
 
+				// The machine API defaults the delete policy, so the remote policy is left as it is when the pool does
+				// not set one.
+				if ms.Spec.DeletePolicy != "" && rMS.Spec.DeletePolicy != ms.Spec.DeletePolicy {
+					msLog.WithField("desired", ms.Spec.DeletePolicy).WithField("observed", rMS.Spec.DeletePolicy).Info("delete policy out of sync")
+					rMS.Spec.DeletePolicy = ms.Spec.DeletePolicy
+					objectModified = true
+				}
+
+				if observedHash := rMS.Annotations[providerSpecHashAnnotation]; observedHash != specHash && providerSpecUpdates != 0 {
+					msLog.WithField("desired", specHash).WithField("observed", observedHash).Info("provider spec out of sync")
+					rMS.Spec.Template.Spec.ProviderSpec = ms.Spec.Template.Spec.ProviderSpec
+					setProviderSpecHash(&rMS, specHash)
+					objectModified = true
+					providerSpecUpdates--
+				} else if observedHash != specHash && pool.Spec.Platform.AWS != nil {
+					switch modified, err := syncAWSMachineSetTags(ms, &rMS, r.scheme); {
+					case err != nil:
+						msLog.WithError(err).Warn("could not sync tags of machineset")
+					case modified:
+						msLog.Info("tags out of sync")
+						objectModified = true
+					}
+				} else if observedHash == specHash && pool.Spec.Platform.AWS != nil {
+					// The provider spec of the remote MachineSet was generated from the current spec of the pool, so
+					// any difference in the access of its machines was made in the remote cluster.
+					switch modified, err := syncAWSMachineSetAccess(ms, &rMS, r.scheme); {
+					case err != nil:
+						msLog.WithError(err).Warn("could not sync IAM instance profile and security groups of machineset")
+					case modified:
+						msLog.Info("IAM instance profile or security groups modified in remote cluster, restoring them")
+						driftedMachineSets = append(driftedMachineSets, rMS.Name)
+						objectModified = true
+					}
+				}
+
 				if objectMetaModified || objectModified {
 					rMS.Generation++
 					machineSetsToUpdate = append(machineSetsToUpdate, &rMS)
@@ -598,14 +1244,21 @@ func (r *ReconcileMachinePool) syncMachineSets(
 		}
 
 		if !found {
+			setProviderSpecHash(ms, specHash)
 			machineSetsToCreate = append(machineSetsToCreate, ms)
 			result[i] = ms
 		}
 	}
 
-	// Find MachineSets that need deleting
+	// Find MachineSets that need deleting. The generated MachineSets are the source of truth for which MachineSets
+	// should exist, but an empty list while the pool is not being deleted is taken as a failure to generate them
+	// rather than a request to delete every MachineSet of the pool.
+	skipPruning := pool.DeletionTimestamp == nil && len(generatedMachineSets) == 0
+	if skipPruning {
+		logger.Warn("no machinesets generated for machine pool, not deleting existing machinesets")
+	}
 	for i, rMS := range remoteMachineSets.Items {
-		if !isControlledByMachinePool(cd, pool, &rMS) {
+		if skipPruning || !isControlledByMachinePool(cd, pool, &rMS) {
 			continue
 		}
 		delete := true
@@ -626,7 +1279,7 @@ func (r *ReconcileMachinePool) syncMachineSets(
 		logger.WithField("machineset", ms.Name).Info("creating machineset")
 		if err := remoteClusterAPIClient.Create(context.Background(), ms); err != nil {
 			logger.WithError(err).Error("unable to create machine set")
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
 
@@ -634,20 +1287,112 @@ func (r *ReconcileMachinePool) syncMachineSets(
 		logger.WithField("machineset", ms.Name).Info("updating machineset")
 		if err := remoteClusterAPIClient.Update(context.Background(), ms); err != nil {
 			logger.WithError(err).Error("unable to update machine set")
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
 
+	var prunedMachineSets []string
 	for _, ms := range machineSetsToDelete {
 		logger.WithField("machineset", ms.Name).Info("deleting machineset")
 		if err := remoteClusterAPIClient.Delete(context.Background(), ms); err != nil {
 			logger.WithError(err).Error("unable to delete machine set")
-			return nil, err
+			return nil, nil, nil, err
+		}
+		if pool.DeletionTimestamp == nil {
+			prunedMachineSets = append(prunedMachineSets, ms.Name)
 		}
 	}
+	sort.Strings(prunedMachineSets)
 
 	logger.Info("done reconciling machine sets for machine pool")
-	return result, nil
+	return result, prunedMachineSets, driftedMachineSets, nil
+}
+
+// updatesProviderSpecs returns whether the update strategy of the pool updates the provider specs of the existing
+// MachineSets to the generated provider specs.
+func updatesProviderSpecs(pool *hivev1.MachinePool) bool {
+	if pool.Spec.UpdateStrategy == nil {
+		return false
+	}
+	switch pool.Spec.UpdateStrategy.Type {
+	case hivev1.ImmediateMachinePoolUpdateStrategyType, hivev1.RollingMachinePoolUpdateStrategyType:
+		return true
+	default:
+		return false
+	}
+}
+
+// providerSpecUpdateBudget returns the number of existing MachineSets whose provider spec may be updated to the
+// generated provider spec according to the update strategy of the pool, or a negative number if there is no limit.
+// With the Rolling strategy, the updated MachineSets which do not have all their replicas ready count against the
+// maximum number of unavailable MachineSets.
+func providerSpecUpdateBudget(pool *hivev1.MachinePool, generatedMachineSets []*machineapi.MachineSet, remoteMachineSets *machineapi.MachineSetList, logger log.FieldLogger) int {
+	if !updatesProviderSpecs(pool) {
+		return 0
+	}
+	if pool.Spec.UpdateStrategy.Type == hivev1.ImmediateMachinePoolUpdateStrategyType {
+		return -1
+	}
+	maxUnavailable, err := maxUnavailableMachineSets(pool.Spec.UpdateStrategy, len(generatedMachineSets))
+	if err != nil {
+		// ensureValidUpdateStrategy keeps pools with an invalid update strategy from being synced.
+		logger.WithError(err).Warn("invalid update strategy")
+		return 0
+	}
+	generated := sets.NewString()
+	for _, ms := range generatedMachineSets {
+		generated.Insert(ms.Name)
+	}
+	var unavailable []string
+	for _, rMS := range remoteMachineSets.Items {
+		if !generated.Has(rMS.Name) || rMS.Annotations[providerSpecHashAnnotation] == "" {
+			continue
+		}
+		if rMS.Status.ObservedGeneration < rMS.Generation ||
+			(rMS.Spec.Replicas != nil && rMS.Status.ReadyReplicas < *rMS.Spec.Replicas) {
+			unavailable = append(unavailable, rMS.Name)
+		}
+	}
+	budget := maxUnavailable - len(unavailable)
+	if budget <= 0 {
+		logger.WithField("unavailable", unavailable).WithField("maxUnavailable", maxUnavailable).
+			Debug("waiting for updated machinesets to be ready before updating more")
+		return 0
+	}
+	return budget
+}
+
+// maxUnavailableMachineSets returns the maximum number of updated MachineSets which do not have all their replicas
+// ready for the Rolling update strategy of a pool with the given number of MachineSets. A percentage of the MachineSets
+// is rounded down, but allows at least one MachineSet. Returns an error if the maximum is invalid.
+func maxUnavailableMachineSets(strategy *hivev1.MachinePoolUpdateStrategy, machineSets int) (int, error) {
+	if strategy.MaxUnavailable == nil {
+		return 1, nil
+	}
+	maxUnavailable := strategy.MaxUnavailable
+	if maxUnavailable.Type == intstr.Int {
+		if maxUnavailable.IntVal < 1 {
+			return 0, errors.Errorf("maxUnavailable must be at least 1, not %d", maxUnavailable.IntVal)
+		}
+		return int(maxUnavailable.IntVal), nil
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(maxUnavailable.StrVal, "%"))
+	if err != nil || !strings.HasSuffix(maxUnavailable.StrVal, "%") || percent < 1 || percent > 100 {
+		return 0, errors.Errorf("maxUnavailable must be a number or a percentage from 1%% to 100%%, not %q", maxUnavailable.StrVal)
+	}
+	if scaled := machineSets * percent / 100; scaled > 1 {
+		return scaled, nil
+	}
+	return 1, nil
+}
+
+// setProviderSpecHash records the hash of the provider spec of a MachineSet created or updated from a generated
+// MachineSet.
+func setProviderSpecHash(ms *machineapi.MachineSet, specHash string) {
+	if ms.Annotations == nil {
+		ms.Annotations = map[string]string{}
+	}
+	ms.Annotations[providerSpecHashAnnotation] = specHash
 }
 
 func (r *ReconcileMachinePool) syncMachineAutoscalers(
@@ -679,6 +1424,11 @@ func (r *ReconcileMachinePool) syncMachineAutoscalers(
 		// Find MachineAutoscalers that need updating/creating
 		for i, ms := range machineSets {
 			minReplicas, maxReplicas := getMinMaxReplicasForMachineSet(pool, machineSets, i)
+			// A MachineAutoscaler must allow at least one replica, so MachineSets whose share of the maximum replicas of
+			// the pool is zero are left at zero replicas without one.
+			if maxReplicas == 0 {
+				continue
+			}
 			found := false
 			for _, rMA := range remoteMachineAutoscalers.Items {
 				if ms.Name == rMA.Name {
@@ -741,9 +1491,10 @@ func (r *ReconcileMachinePool) syncMachineAutoscalers(
 		}
 		delete := true
 		if pool.DeletionTimestamp == nil && pool.Spec.Autoscaling != nil {
-			for _, ms := range machineSets {
+			for j, ms := range machineSets {
 				if rMA.Name == ms.Name {
-					delete = false
+					_, maxReplicas := getMinMaxReplicasForMachineSet(pool, machineSets, j)
+					delete = maxReplicas == 0
 					break
 				}
 			}
@@ -843,12 +1594,46 @@ func (r *ReconcileMachinePool) syncClusterAutoscaler(
 
 func (r *ReconcileMachinePool) updatePoolStatusForMachineSets(
 	pool *hivev1.MachinePool,
+	cd *hivev1.ClusterDeployment,
+	generatedMachineSets []*machineapi.MachineSet,
 	machineSets []*machineapi.MachineSet,
+	prunedMachineSets []string,
+	driftedMachineSets []string,
 	remoteClusterAPIClient client.Client,
 	logger log.FieldLogger,
 ) (reconcile.Result, error) {
 	origPool := pool.DeepCopy()
 
+	pool.Status.GeneratedMachineSets = int32(len(generatedMachineSets))
+	pool.Status.GeneratedReplicas = 0
+	pool.Status.GeneratedZoneReplicas = nil
+	for _, ms := range generatedMachineSets {
+		if ms.Spec.Replicas == nil {
+			continue
+		}
+		pool.Status.GeneratedReplicas += *ms.Spec.Replicas
+		if zone := machineSetZone(ms); zone != "" {
+			if pool.Status.GeneratedZoneReplicas == nil {
+				pool.Status.GeneratedZoneReplicas = make(map[string]int32, len(generatedMachineSets))
+			}
+			pool.Status.GeneratedZoneReplicas[zone] += *ms.Spec.Replicas
+		}
+	}
+
+	if len(generatedMachineSets) > 0 {
+		userDataSecret := userDataSecretStatus(cd, pool)
+		if !reflect.DeepEqual(pool.Status.UserDataSecret, userDataSecret) {
+			logger.WithField("userDataSecret", userDataSecret.Name).WithField("clusterVersion", userDataSecret.ClusterVersion).
+				Info("user data secret selected for machinesets changed")
+			pool.Status.UserDataSecret = userDataSecret
+		}
+	}
+
+	// Keep reporting the most recently pruned MachineSets until more are pruned.
+	if len(prunedMachineSets) > 0 {
+		pool.Status.PrunedMachineSets = prunedMachineSets
+	}
+
 	pool.Status.MachineSets = make([]hivev1.MachineSetStatus, len(machineSets))
 	pool.Status.Replicas = 0
 	for i, ms := range machineSets {
@@ -878,6 +1663,93 @@ func (r *ReconcileMachinePool) updatePoolStatusForMachineSets(
 		pool.Status.Replicas += *ms.Spec.Replicas
 	}
 
+	var missingLabelsOrTaints []string
+	for _, ms := range machineSets {
+		if !hasLabelsAndTaints(pool, ms) {
+			missingLabelsOrTaints = append(missingLabelsOrTaints, ms.Name)
+		}
+	}
+	if len(missingLabelsOrTaints) > 0 {
+		logger.WithField("machinesets", missingLabelsOrTaints).Warn("labels or taints not applied to machinesets")
+		pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+			pool.Status.Conditions,
+			hivev1.LabelsAndTaintsNotAppliedMachinePoolCondition,
+			corev1.ConditionTrue,
+			"LabelsAndTaintsNotApplied",
+			fmt.Sprintf("Labels or taints have not been applied to MachineSets: %s", strings.Join(missingLabelsOrTaints, ", ")),
+			controllerutils.UpdateConditionIfReasonOrMessageChange,
+		)
+	} else {
+		pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+			pool.Status.Conditions,
+			hivev1.LabelsAndTaintsNotAppliedMachinePoolCondition,
+			corev1.ConditionFalse,
+			"LabelsAndTaintsApplied",
+			"Labels and taints have been applied to all MachineSets",
+			controllerutils.UpdateConditionIfReasonOrMessageChange,
+		)
+	}
+
+	if pool.Spec.Platform.AWS != nil && len(generatedMachineSets) > 0 {
+		staleAMI := machineSetsWithStaleAMI(generatedMachineSets, machineSets, r.scheme, logger)
+		switch {
+		case len(staleAMI) > 0 && !updatesProviderSpecs(pool):
+			// The existing MachineSets are never updated to the new AMI, so no update is in progress.
+			logger.WithField("machinesets", staleAMI).Info("machinesets do not use the AMI of the machine pool and are not updated")
+			pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+				pool.Status.Conditions,
+				hivev1.AMIUpdateInProgressMachinePoolCondition,
+				corev1.ConditionFalse,
+				"AMIUpdatePending",
+				fmt.Sprintf("MachineSets do not use the AMI of the MachinePool and keep their AMI until they are deleted: %s", strings.Join(staleAMI, ", ")),
+				controllerutils.UpdateConditionIfReasonOrMessageChange,
+			)
+		case len(staleAMI) > 0:
+			logger.WithField("machinesets", staleAMI).Info("machinesets do not use the AMI of the machine pool")
+			pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+				pool.Status.Conditions,
+				hivev1.AMIUpdateInProgressMachinePoolCondition,
+				corev1.ConditionTrue,
+				"MachineSetsUseOtherAMI",
+				fmt.Sprintf("MachineSets do not use the AMI of the MachinePool yet: %s", strings.Join(staleAMI, ", ")),
+				controllerutils.UpdateConditionIfReasonOrMessageChange,
+			)
+		default:
+			pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+				pool.Status.Conditions,
+				hivev1.AMIUpdateInProgressMachinePoolCondition,
+				corev1.ConditionFalse,
+				"AMIUpToDate",
+				"All MachineSets use the AMI of the MachinePool",
+				controllerutils.UpdateConditionIfReasonOrMessageChange,
+			)
+		}
+	}
+
+	if pool.Spec.Platform.AWS != nil {
+		// Keep reporting the most recently restored MachineSets until more are restored.
+		if len(driftedMachineSets) > 0 {
+			sort.Strings(driftedMachineSets)
+			pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+				pool.Status.Conditions,
+				hivev1.MachineSetDriftCorrectedMachinePoolCondition,
+				corev1.ConditionTrue,
+				"MachineSetsRestored",
+				fmt.Sprintf("Restored the IAM instance profile or security groups of MachineSets modified in the cluster: %s", strings.Join(driftedMachineSets, ", ")),
+				controllerutils.UpdateConditionIfReasonOrMessageChange,
+			)
+		} else if cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.MachineSetDriftCorrectedMachinePoolCondition); cond == nil || cond.Status != corev1.ConditionTrue {
+			pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+				pool.Status.Conditions,
+				hivev1.MachineSetDriftCorrectedMachinePoolCondition,
+				corev1.ConditionFalse,
+				"NoDrift",
+				"The IAM instance profile and security groups of the MachineSets have not been modified in the cluster",
+				controllerutils.UpdateConditionIfReasonOrMessageChange,
+			)
+		}
+	}
+
 	var requeueAfter time.Duration
 	for _, ms := range pool.Status.MachineSets {
 		if ms.Replicas != ms.ReadyReplicas {
@@ -889,7 +1761,7 @@ func (r *ReconcileMachinePool) updatePoolStatusForMachineSets(
 		}
 	}
 
-	if (len(origPool.Status.MachineSets) == 0 && len(pool.Status.MachineSets) == 0) ||
+	if (len(origPool.Status.MachineSets) == 0 && len(pool.Status.MachineSets) == 0 && len(prunedMachineSets) == 0) ||
 		reflect.DeepEqual(origPool.Status, pool.Status) {
 		return reconcile.Result{RequeueAfter: requeueAfter}, nil
 	}
@@ -956,67 +1828,28 @@ func summarizeMachinesError(remoteClusterAPIClient client.Client, machineSet *ma
 }
 
 func (r *ReconcileMachinePool) createActuator(
+	ctx context.Context,
 	cd *hivev1.ClusterDeployment,
 	pool *hivev1.MachinePool,
 	masterMachine *machineapi.Machine,
 	remoteMachineSets []machineapi.MachineSet,
+	remoteClusterAPIClient client.Client,
 	logger log.FieldLogger,
 ) (Actuator, error) {
-	switch {
-	case cd.Spec.Platform.AWS != nil:
-		creds := awsclient.CredentialsSource{
-			Secret: &awsclient.SecretCredentialsSource{
-				Ref:       &cd.Spec.Platform.AWS.CredentialsSecretRef,
-				Namespace: cd.Namespace,
-			},
-			AssumeRole: &awsclient.AssumeRoleCredentialsSource{
-				SecretRef: corev1.SecretReference{
-					Namespace: controllerutils.GetHiveNamespace(),
-					Name:      os.Getenv(constants.HiveAWSServiceProviderCredentialsSecretRefEnvVar),
-				},
-				Role: cd.Spec.Platform.AWS.CredentialsAssumeRole,
-			},
-		}
-		return NewAWSActuator(r.Client, creds, cd.Spec.Platform.AWS.Region, pool, masterMachine, r.scheme, logger)
-	case cd.Spec.Platform.GCP != nil:
-		creds := &corev1.Secret{}
-		if err := r.Get(
-			context.TODO(),
-			types.NamespacedName{
-				Name:      cd.Spec.Platform.GCP.CredentialsSecretRef.Name,
-				Namespace: cd.Namespace,
-			},
-			creds,
-		); err != nil {
-			return nil, err
-		}
-		clusterVersion, err := getClusterVersion(cd)
-		if err != nil {
-			return nil, err
-		}
-		return NewGCPActuator(r.Client, creds, clusterVersion, masterMachine, remoteMachineSets, r.scheme, r.expectations, logger)
-	case cd.Spec.Platform.Azure != nil:
-		creds := &corev1.Secret{}
-		if err := r.Get(
-			context.TODO(),
-			types.NamespacedName{
-				Name:      cd.Spec.Platform.Azure.CredentialsSecretRef.Name,
-				Namespace: cd.Namespace,
-			},
-			creds,
-		); err != nil {
-			return nil, err
-		}
-		return NewAzureActuator(creds, cd.Spec.Platform.Azure.CloudName.Name(), logger)
-	case cd.Spec.Platform.OpenStack != nil:
-		return NewOpenStackActuator(masterMachine, r.scheme, r.Client, logger)
-	case cd.Spec.Platform.VSphere != nil:
-		return NewVSphereActuator(masterMachine, r.scheme, logger)
-	case cd.Spec.Platform.Ovirt != nil:
-		return NewOvirtActuator(masterMachine, r.scheme, logger)
-	default:
+	constructor, ok := actuatorConstructors[clusterPlatform(cd)]
+	if !ok {
 		return nil, errors.New("unsupported platform")
 	}
+	return constructor(ctx, r, cd, pool, masterMachine, remoteMachineSets, remoteClusterAPIClient, logger)
+}
+
+// actuatorClient returns the client for the actuators, which discards their status writes when the reconcile must
+// not modify the MachinePool status.
+func (r *ReconcileMachinePool) actuatorClient() client.Client {
+	if r.readOnlyStatus {
+		return readOnlyStatusClient{Client: r.Client}
+	}
+	return r.Client
 }
 
 func baseMachinePool(pool *hivev1.MachinePool) *installertypes.MachinePool {
@@ -1032,6 +1865,30 @@ func isControlledByMachinePool(cd *hivev1.ClusterDeployment, pool *hivev1.Machin
 		obj.GetLabels()[machinePoolNameLabel] == pool.Spec.Name
 }
 
+// cleanupResources removes the cloud resources created by the actuator for a MachinePool that is being deleted. The
+// resources of a MachinePool whose ClusterDeployment is being deleted are left to the deprovision of the cluster.
+// Building an actuator looks up cloud state the cleanup may not need, so a failure to build one is logged and the
+// cleanup skipped rather than blocking the removal of the finalizer. Only a failure of the cleanup itself is returned.
+func (r *ReconcileMachinePool) cleanupResources(
+	ctx context.Context,
+	pool *hivev1.MachinePool,
+	cd *hivev1.ClusterDeployment,
+	masterMachine *machineapi.Machine,
+	remoteMachineSets *machineapi.MachineSetList,
+	remoteClusterAPIClient client.Client,
+	logger log.FieldLogger,
+) error {
+	actuator, err := r.actuatorBuilder(ctx, cd, pool, masterMachine, remoteMachineSets.Items, remoteClusterAPIClient, logger)
+	if err != nil {
+		logger.WithError(err).Warn("skipping cleanup of cloud resources because the actuator could not be created")
+		return nil
+	}
+	if err := actuator.CleanupResources(cd, pool, logger); err != nil {
+		return errors.Wrap(err, "could not clean up cloud resources")
+	}
+	return nil
+}
+
 func (r *ReconcileMachinePool) removeFinalizer(pool *hivev1.MachinePool, logger log.FieldLogger) (reconcile.Result, error) {
 	if !controllerutils.HasFinalizer(pool, finalizer) {
 		return reconcile.Result{}, nil
@@ -1044,14 +1901,39 @@ func (r *ReconcileMachinePool) removeFinalizer(pool *hivev1.MachinePool, logger
 	return reconcile.Result{}, err
 }
 
+// getMinMaxReplicasForMachineSet returns the minimum and maximum replicas of a MachineSet of an auto-scaling MachinePool.
+// MachineSets in a zone with an override in the zone replicas of the pool get the replicas of the override. The rest of
+// the replicas of the pool are split as evenly as possible across the other MachineSets.
 func getMinMaxReplicasForMachineSet(pool *hivev1.MachinePool, machineSets []*machineapi.MachineSet, machineSetIndex int) (min, max int32) {
-	noOfMachineSets := int32(len(machineSets))
-	min = pool.Spec.Autoscaling.MinReplicas / noOfMachineSets
-	if int32(machineSetIndex) < pool.Spec.Autoscaling.MinReplicas%noOfMachineSets {
+	zoneReplicas := pool.Spec.Autoscaling.ZoneReplicas
+	if replicas, ok := zoneReplicas[machineSetZone(machineSets[machineSetIndex])]; ok {
+		return replicas.MinReplicas, replicas.MaxReplicas
+	}
+	minReplicas, maxReplicas := pool.Spec.Autoscaling.MinReplicas, pool.Spec.Autoscaling.MaxReplicas
+	var noOfMachineSets, index int32
+	for i, ms := range machineSets {
+		if replicas, ok := zoneReplicas[machineSetZone(ms)]; ok {
+			minReplicas -= replicas.MinReplicas
+			maxReplicas -= replicas.MaxReplicas
+			continue
+		}
+		if i < machineSetIndex {
+			index++
+		}
+		noOfMachineSets++
+	}
+	if minReplicas < 0 {
+		minReplicas = 0
+	}
+	if maxReplicas < 0 {
+		maxReplicas = 0
+	}
+	min = minReplicas / noOfMachineSets
+	if index < minReplicas%noOfMachineSets {
 		min++
 	}
-	max = pool.Spec.Autoscaling.MaxReplicas / noOfMachineSets
-	if int32(machineSetIndex) < pool.Spec.Autoscaling.MaxReplicas%noOfMachineSets {
+	max = maxReplicas / noOfMachineSets
+	if index < maxReplicas%noOfMachineSets {
 		max++
 	}
 	if max < min {
@@ -1060,12 +1942,60 @@ func getMinMaxReplicasForMachineSet(pool *hivev1.MachinePool, machineSets []*mac
 	return
 }
 
-func getClusterVersion(cd *hivev1.ClusterDeployment) (string, error) {
-	version, versionPresent := cd.Labels[constants.VersionMajorMinorPatchLabel]
-	if !versionPresent {
-		return "", errors.New("cluster version not set in clusterdeployment")
+// machineSetZone returns the availability zone of the machines of a MachineSet, as set in the placement of AWS provider
+// specs or the zone of GCP and Azure provider specs. Returns an empty string when the zone is not known.
+func machineSetZone(ms *machineapi.MachineSet) string {
+	value := ms.Spec.Template.Spec.ProviderSpec.Value
+	if value == nil {
+		return ""
+	}
+	raw := value.Raw
+	if raw == nil && value.Object != nil {
+		var err error
+		if raw, err = json.Marshal(value.Object); err != nil {
+			return ""
+		}
+	}
+	var providerSpec struct {
+		Placement struct {
+			AvailabilityZone string `json:"availabilityZone"`
+		} `json:"placement"`
+		Zone *string `json:"zone"`
+	}
+	if err := json.Unmarshal(raw, &providerSpec); err != nil {
+		return ""
+	}
+	if providerSpec.Zone != nil {
+		return *providerSpec.Zone
+	}
+	return providerSpec.Placement.AvailabilityZone
+}
+
+// getClusterVersion returns the version of the cluster used by the actuators for feature gating. The version reported
+// for the ClusterDeployment can be overridden for the MachinePool with the cluster version override annotation.
+// Otherwise the desired version of the ClusterVersion of the cluster, as recorded in the version-major-minor-patch
+// label, is preferred, falling back to the version of the release image the cluster was installed with for clusters
+// whose ClusterVersion has not been read yet. Returns an error when no valid version is known, in which case
+// version-gated features are disabled.
+func getClusterVersion(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool) (string, error) {
+	if version, ok := pool.Annotations[hivev1.MachinePoolClusterVersionOverrideAnnotation]; ok {
+		if _, err := semver.ParseTolerant(version); err != nil {
+			return "", errors.Wrapf(err, "invalid cluster version in %s annotation", hivev1.MachinePoolClusterVersionOverrideAnnotation)
+		}
+		return version, nil
 	}
-	return version, nil
+	if version := cd.Labels[constants.VersionMajorMinorPatchLabel]; version != "" {
+		if _, err := semver.ParseTolerant(version); err == nil {
+			return version, nil
+		}
+	}
+	if version := cd.Status.InstallVersion; version != nil && *version != "" {
+		if _, err := semver.ParseTolerant(*version); err != nil {
+			return "", errors.Wrap(err, "invalid install version of clusterdeployment")
+		}
+		return *version, nil
+	}
+	return "", errors.New("cluster version not set in clusterdeployment")
 }
 
 func platformAllowsZeroAutoscalingMinReplicas(cd *hivev1.ClusterDeployment) bool {
@@ -1088,17 +2018,7 @@ func platformAllowsZeroAutoscalingMinReplicas(cd *hivev1.ClusterDeployment) bool
 			return false
 		}
 
-		minimumOpenStackVersion, err := semver.Make("4.7.0")
-		if err != nil {
-			// something terrible has happened
-			return false
-		}
-
-		if currentVersion.GTE(minimumOpenStackVersion) {
-			return true
-		}
-
-		return false
+		return versionsSupportingOpenStackScaleToZero(currentVersion)
 	}
 
 	return false
@@ -1187,6 +2107,18 @@ func IsErrorUpdateEvent(evt event.UpdateEvent) bool {
 	errorConds := []hivev1.MachinePoolConditionType{
 		hivev1.InvalidSubnetsMachinePoolCondition,
 		hivev1.UnsupportedConfigurationMachinePoolCondition,
+		hivev1.InvalidConfigurationMachinePoolCondition,
+		hivev1.UserDataSecretNotFoundMachinePoolCondition,
+		hivev1.LabelsAndTaintsNotAppliedMachinePoolCondition,
+		hivev1.NoUsableZonesMachinePoolCondition,
+		hivev1.InstanceTypeNotResolvedMachinePoolCondition,
+		hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition,
+		hivev1.SpotMaxPriceTooLowMachinePoolCondition,
+		hivev1.SpotFallbackUnavailableMachinePoolCondition,
+		hivev1.UnevenZoneSpreadMachinePoolCondition,
+		hivev1.UnauthorizedCredentialsMachinePoolCondition,
+		hivev1.AMIResolutionFailedMachinePoolCondition,
+		hivev1.InvalidPlatformMachinePoolCondition,
 	}
 
 	for _, cond := range errorConds {
@@ -3,22 +3,29 @@ package machinepool
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	controllerutils "github.com/openshift/hive/pkg/controller/utils"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/golang/mock/gomock"
+	pkgerrors "github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
 	awsproviderapis "sigs.k8s.io/cluster-api-provider-aws/pkg/apis"
@@ -34,6 +41,7 @@ import (
 	"github.com/openshift/hive/apis"
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
 	hivev1aws "github.com/openshift/hive/apis/hive/v1/aws"
+	hivev1gcp "github.com/openshift/hive/apis/hive/v1/gcp"
 	"github.com/openshift/hive/pkg/constants"
 	"github.com/openshift/hive/pkg/controller/machinepool/mock"
 	"github.com/openshift/hive/pkg/remoteclient"
@@ -41,15 +49,16 @@ import (
 )
 
 const (
-	testName            = "foo"
-	testNamespace       = "default"
-	testClusterID       = "foo-12345-uuid"
-	testInfraID         = "foo-12345"
-	machineAPINamespace = "openshift-machine-api"
-	testAMI             = "ami-totallyfake"
-	testRegion          = "test-region"
-	testPoolName        = "worker"
-	testInstanceType    = "test-instance-type"
+	testName         = "foo"
+	testNamespace    = "default"
+	testClusterID    = "foo-12345-uuid"
+	testInfraID      = "foo-12345"
+	testAMI          = "ami-totallyfake"
+	testRegion       = "test-region"
+	testPoolName     = "worker"
+	testInstanceType = "test-instance-type"
+
+	testExtraIgnition = `{"ignition":{"version":"3.1.0"},"systemd":{"units":[{"name":"extra.service","enabled":true}]}}`
 )
 
 func init() {
@@ -109,16 +118,32 @@ func TestRemoteMachineSetReconcile(t *testing.T) {
 		name                 string
 		clusterDeployment    *hivev1.ClusterDeployment
 		machinePool          *hivev1.MachinePool
+		existing             []runtime.Object
 		remoteExisting       []runtime.Object
 		generatedMachineSets []*machineapi.MachineSet
 		actuatorDoNotProceed bool
+		generateErr          error
+		actuatorBuildErr     error
+		cleanupErr           error
+		expectCleanup        bool
 		expectErr            bool
+		// expectedRequeueAfter is the requeue interval expected in the result of the reconcile
+		expectedRequeueAfter time.Duration
 		expectNoFinalizer    bool
 		// expectPoolPresent is ignored if expectNoFinalizer is false
 		expectPoolPresent                bool
 		expectedRemoteMachineSets        []*machineapi.MachineSet
 		expectedRemoteMachineAutoscalers []autoscalingv1beta1.MachineAutoscaler
 		expectedRemoteClusterAutoscalers []autoscalingv1.ClusterAutoscaler
+		expectedCondition                *hivev1.MachinePoolCondition
+		expectedPrunedMachineSets        []string
+		expectedGeneratedMachineSets     *int32
+		expectedGeneratedReplicas        *int32
+		expectedGeneratedZoneReplicas    map[string]int32
+		expectedUserDataSecret           *hivev1.UserDataSecretStatus
+		// expectedMergedUserData is the user data expected in the merged user data secret of the pool, if any
+		expectedMergedUserData []byte
+		expectNoMergedUserData bool
 	}{
 		{
 			name: "Cluster not installed yet",
@@ -149,364 +174,983 @@ func TestRemoteMachineSetReconcile(t *testing.T) {
 				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
 				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
 			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.LabelsAndTaintsNotAppliedMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "LabelsAndTaintsApplied",
+			},
 		},
 		{
-			name:                 "No-op when actuator says not to proceed",
-			clusterDeployment:    testClusterDeployment(),
-			machinePool:          testMachinePool(),
-			actuatorDoNotProceed: true,
+			name:              "Replicas of generated machine sets reported by zone",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testMachinePool(),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+				withZone(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 2, 0), "us-east-1a"),
+				withZone(testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0), "us-east-1b"),
 			},
 			generatedMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+				withZone(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 2, 0), "us-east-1a"),
+				withZone(testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0), "us-east-1b"),
 			},
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+				withZone(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 2, 0), "us-east-1a"),
+				withZone(testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0), "us-east-1b"),
 			},
+			expectedGeneratedReplicas:     pointer.Int32Ptr(3),
+			expectedGeneratedZoneReplicas: map[string]int32{"us-east-1a": 2, "us-east-1b": 1},
 		},
 		{
-			name:              "Update machine set replicas",
+			name:              "AMI update pending with OnDelete update strategy",
 			clusterDeployment: testClusterDeployment(),
-			machinePool:       testMachinePool(),
+			machinePool:       withUpdateStrategy(testMachinePool(), hivev1.OnDeleteMachinePoolUpdateStrategyType, nil),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
 				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
 				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 0, 0),
 			},
 			generatedMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "ami-new"),
 				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
 			},
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
 				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
 				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 1),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.AMIUpdateInProgressMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "AMIUpdatePending",
 			},
 		},
 		{
-			name:              "Create missing machine set",
+			name:              "OnDelete update strategy keeps provider spec",
 			clusterDeployment: testClusterDeployment(),
-			machinePool:       testMachinePool(),
+			machinePool:       withUpdateStrategy(testMachinePool(), hivev1.OnDeleteMachinePoolUpdateStrategyType, nil),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
 				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
 			},
 			generatedMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "ami-new"),
 			},
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
 				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
 			},
 		},
 		{
-			name: "Skip create missing machine set when clusterDeployment has annotation hive.openshift.io/syncset-pause: true ",
-			clusterDeployment: func() *hivev1.ClusterDeployment {
-				cd := testClusterDeployment()
-				cd.Annotations = map[string]string{}
-				cd.Annotations[constants.SyncsetPauseAnnotation] = "true"
-				return cd
-			}(),
-			machinePool: testMachinePool(),
-		},
-		{
-			name: "Skip create missing machine set when cluster is unreachable",
-			clusterDeployment: func() *hivev1.ClusterDeployment {
-				cd := testClusterDeployment()
-				cond := controllerutils.FindClusterDeploymentCondition(cd.Status.Conditions, hivev1.UnreachableCondition)
-				cond.Status = corev1.ConditionTrue
-				return cd
-			}(),
-			machinePool: testMachinePool(),
+			name:              "Immediate update strategy updates provider spec",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       withUpdateStrategy(testMachinePool(), hivev1.ImmediateMachinePoolUpdateStrategyType, nil),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "ami-new"),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0), "ami-new"),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 1), "ami-new"),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 1), "ami-new"),
+			},
 		},
 		{
-			name:              "Delete extra machine set",
+			name:              "Rolling update strategy updates up to maxUnavailable machinesets",
 			clusterDeployment: testClusterDeployment(),
-			machinePool:       testMachinePool(),
+			machinePool:       withUpdateStrategy(testMachinePool(), hivev1.RollingMachinePoolUpdateStrategyType, intstrPtr(intstr.FromInt(2))),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
 				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
 				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
 				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1d", "worker", true, 1, 0),
 			},
 			generatedMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "ami-new"),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0), "ami-new"),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0), "ami-new"),
 			},
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 1), "ami-new"),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 1), "ami-new"),
 				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
 			},
 		},
 		{
-			name:              "Other machinesets ignored",
+			name:              "Rolling update strategy updates up to a percentage of machinesets",
 			clusterDeployment: testClusterDeployment(),
-			machinePool:       testMachinePool(),
+			machinePool:       withUpdateStrategy(testMachinePool(), hivev1.RollingMachinePoolUpdateStrategyType, intstrPtr(intstr.FromString("50%"))),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 3, 0),
-				testMachineSet("foo-12345-other-us-east-1b", "other", true, 3, 0),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
 			},
 			generatedMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 3, 0),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "ami-new"),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0), "ami-new"),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0), "ami-new"),
 			},
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 3, 0),
-				testMachineSet("foo-12345-other-us-east-1b", "other", true, 3, 0),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 1), "ami-new"),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
 			},
 		},
 		{
-			name:              "Create additional machinepool machinesets",
+			name:              "maxUnavailable without Rolling update strategy",
 			clusterDeployment: testClusterDeployment(),
-			machinePool:       testMachinePool(),
+			machinePool:       withUpdateStrategy(testMachinePool(), hivev1.ImmediateMachinePoolUpdateStrategyType, intstrPtr(intstr.FromString("50%"))),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
-				testMachineSet("foo-12345-other-us-east-1a", "other", true, 1, 0),
-				testMachineSet("foo-12345-other-us-east-1b", "other", true, 1, 0),
-				testMachineSet("foo-12345-other-us-east-1c", "other", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
 			},
 			generatedMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "ami-new"),
 			},
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
-				testMachineSet("foo-12345-other-us-east-1a", "other", true, 1, 0),
-				testMachineSet("foo-12345-other-us-east-1b", "other", true, 1, 0),
-				testMachineSet("foo-12345-other-us-east-1c", "other", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidUpdateStrategyMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "MaxUnavailableWithoutRollingStrategy",
 			},
 		},
 		{
-			name:              "Delete machinepool machinesets",
+			name:              "Invalid maxUnavailable percentage",
 			clusterDeployment: testClusterDeployment(),
-			machinePool: func() *hivev1.MachinePool {
-				mp := testMachinePool()
-				now := metav1.Now()
-				mp.DeletionTimestamp = &now
-				return mp
-			}(),
+			machinePool:       withUpdateStrategy(testMachinePool(), hivev1.RollingMachinePoolUpdateStrategyType, intstrPtr(intstr.FromString("150%"))),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
-				testMachineSet("foo-12345-other-us-east-1a", "other", true, 1, 0),
-				testMachineSet("foo-12345-other-us-east-1b", "other", true, 1, 0),
-				testMachineSet("foo-12345-other-us-east-1c", "other", true, 1, 0),
 				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
 			},
-			expectNoFinalizer: true,
+			generatedMachineSets: []*machineapi.MachineSet{
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "ami-new"),
+			},
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-other-us-east-1a", "other", true, 1, 0),
-				testMachineSet("foo-12345-other-us-east-1b", "other", true, 1, 0),
-				testMachineSet("foo-12345-other-us-east-1c", "other", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidUpdateStrategyMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InvalidMaxUnavailable",
 			},
 		},
 		{
-			name:        "No cluster deployment",
+			name: "AWS pool for GCP cluster",
+			clusterDeployment: func() *hivev1.ClusterDeployment {
+				cd := testClusterDeployment()
+				cd.Spec.Platform = hivev1.Platform{GCP: &hivev1gcp.Platform{Region: testRegion}}
+				return cd
+			}(),
 			machinePool: testMachinePool(),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
 				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
 			},
-			expectNoFinalizer: true,
-			expectPoolPresent: true,
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
 				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
 			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidPlatformMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "PlatformMismatch",
+			},
+			expectNoFinalizer: true,
+			expectPoolPresent: true,
 		},
 		{
-			name: "Deleted cluster deployment",
+			name:              "GCP pool for AWS cluster",
+			clusterDeployment: testClusterDeployment(),
+			machinePool: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.Platform = hivev1.MachinePoolPlatform{GCP: &hivev1gcp.MachinePool{InstanceType: testInstanceType}}
+				return pool
+			}(),
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidPlatformMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "PlatformMismatch",
+			},
+			expectNoFinalizer: true,
+			expectPoolPresent: true,
+		},
+		{
+			name: "Installed cluster without cluster metadata",
 			clusterDeployment: func() *hivev1.ClusterDeployment {
 				cd := testClusterDeployment()
-				now := metav1.Now()
-				cd.DeletionTimestamp = &now
+				cd.Spec.ClusterMetadata = nil
 				return cd
 			}(),
 			machinePool: testMachinePool(),
-			remoteExisting: []runtime.Object{
-				testMachine("master1", "master"),
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.InvalidPlatformMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "MissingClusterMetadata",
 			},
 			expectNoFinalizer: true,
 			expectPoolPresent: true,
-			expectedRemoteMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
-			},
 		},
 		{
-			name:              "No-op with auto-scaling",
+			name:              "Delete policy",
 			clusterDeployment: testClusterDeployment(),
-			machinePool:       testAutoscalingMachinePool(3, 5),
+			machinePool: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.DeletePolicy = hivev1.OldestMachinePoolDeletePolicy
+				return pool
+			}(),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
 				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
-				testClusterAutoscaler("3"),
-				testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
-				testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
-				testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
 			},
 			generatedMachineSets: []*machineapi.MachineSet{
 				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
 			},
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
-			},
-			expectedRemoteMachineAutoscalers: []autoscalingv1beta1.MachineAutoscaler{
-				*testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
-				*testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
-				*testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
-			},
-			expectedRemoteClusterAutoscalers: []autoscalingv1.ClusterAutoscaler{
-				*testClusterAutoscaler("3"),
+				func() *machineapi.MachineSet {
+					ms := testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 1)
+					ms.Spec.DeletePolicy = string(machineapi.OldestMachineSetDeletePolicy)
+					return ms
+				}(),
 			},
 		},
 		{
-			name:              "Create cluster autoscaler",
+			name:              "Rolling update strategy waits for updated machinesets to be ready",
 			clusterDeployment: testClusterDeployment(),
-			machinePool:       testAutoscalingMachinePool(3, 5),
+			machinePool:       withUpdateStrategy(testMachinePool(), hivev1.RollingMachinePoolUpdateStrategyType, nil),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				withProviderSpecHash(withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0), "ami-new")),
 				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
-				testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
-				testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
-				testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
 			},
 			generatedMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "ami-new"),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0), "ami-new"),
 			},
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0), "ami-new"),
 				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
-			},
-			expectedRemoteMachineAutoscalers: []autoscalingv1beta1.MachineAutoscaler{
-				*testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
-				*testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
-				*testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
 			},
-			expectedRemoteClusterAutoscalers: []autoscalingv1.ClusterAutoscaler{
-				*testClusterAutoscaler("1"),
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.AMIUpdateInProgressMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "MachineSetsUseOtherAMI",
 			},
 		},
 		{
-			name:              "Update cluster autoscaler when missing scale down",
+			name:              "Rolling update strategy updates after updated machinesets are ready",
 			clusterDeployment: testClusterDeployment(),
-			machinePool:       testAutoscalingMachinePool(3, 5),
+			machinePool:       withUpdateStrategy(testMachinePool(), hivev1.RollingMachinePoolUpdateStrategyType, nil),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				withReadyReplicas(withProviderSpecHash(withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0), "ami-new")), 1),
 				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
-				func() runtime.Object {
-					a := testClusterAutoscaler("1")
-					a.Spec.ScaleDown = nil
-					return a
-				}(),
-				testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
-				testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
-				testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
 			},
 			generatedMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "ami-new"),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0), "ami-new"),
 			},
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0), "ami-new"),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 1), "ami-new"),
 			},
-			expectedRemoteMachineAutoscalers: []autoscalingv1beta1.MachineAutoscaler{
-				*testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
-				*testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
-				*testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
+		},
+		{
+			name:              "OnDelete update strategy applies tag changes",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       withUpdateStrategy(testMachinePool(), hivev1.OnDeleteMachinePoolUpdateStrategyType, nil),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				withTags(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0), awsprovider.TagSpecification{Name: "cost-center", Value: "old"}),
 			},
-			expectedRemoteClusterAutoscalers: []autoscalingv1.ClusterAutoscaler{
-				*testClusterAutoscaler("2"),
+			generatedMachineSets: []*machineapi.MachineSet{
+				withTags(withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "ami-new"), awsprovider.TagSpecification{Name: "cost-center", Value: "new"}),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				withTags(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 1), awsprovider.TagSpecification{Name: "cost-center", Value: "new"}),
 			},
 		},
 		{
-			name:              "Update cluster autoscaler when scale down disabled",
+			name:              "IAM instance profile and security groups modified in remote cluster",
 			clusterDeployment: testClusterDeployment(),
-			machinePool:       testAutoscalingMachinePool(3, 5),
+			machinePool:       testMachinePool(),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
-				func() runtime.Object {
-					a := testClusterAutoscaler("1")
-					a.Spec.ScaleDown.Enabled = false
-					return a
-				}(),
-				testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
-				testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
-				testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
+				withAccess(withProviderSpecHash(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0)), "other-profile", "other-sg"),
+				withProviderSpecHash(testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0)),
 			},
 			generatedMachineSets: []*machineapi.MachineSet{
 				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
 				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
 			},
 			expectedRemoteMachineSets: []*machineapi.MachineSet{
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 1),
 				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
-				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
-			},
-			expectedRemoteMachineAutoscalers: []autoscalingv1beta1.MachineAutoscaler{
-				*testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
-				*testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
-				*testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
 			},
-			expectedRemoteClusterAutoscalers: []autoscalingv1.ClusterAutoscaler{
-				*testClusterAutoscaler("2"),
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.MachineSetDriftCorrectedMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "MachineSetsRestored",
 			},
 		},
 		{
-			name:              "Create machine autoscalers",
+			name:              "IAM instance profile and security groups of outdated provider spec not restored",
 			clusterDeployment: testClusterDeployment(),
-			machinePool:       testAutoscalingMachinePool(3, 5),
+			machinePool:       withUpdateStrategy(testMachinePool(), hivev1.OnDeleteMachinePoolUpdateStrategyType, nil),
 			remoteExisting: []runtime.Object{
 				testMachine("master1", "master"),
-				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				withAccess(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0), "other-profile", "other-sg"),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				withAccess(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0), "other-profile", "other-sg"),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.MachineSetDriftCorrectedMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "NoDrift",
+			},
+		},
+		{
+			name:              "AMI up to date",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testMachinePool(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0), "ami-new"),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "ami-new"),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				withAMI(testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0), "ami-new"),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.AMIUpdateInProgressMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "AMIUpToDate",
+			},
+		},
+		{
+			name:              "User data secret override",
+			clusterDeployment: testClusterDeployment(),
+			machinePool: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.UserDataSecretName = "custom-user-data"
+				return pool
+			}(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testUserDataSecret("custom-user-data"),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UserDataSecretNotFoundMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "UserDataSecretFound",
+			},
+			expectedUserDataSecret: &hivev1.UserDataSecretStatus{Name: "custom-user-data", ClusterVersion: "4.4.0"},
+		},
+		{
+			name:              "Merge ignition secret",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       withMergeIgnitionSecret(testMachinePool(), "extra-ignition"),
+			existing: []runtime.Object{
+				testMergeIgnitionSecret("extra-ignition", testExtraIgnition),
+			},
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testUserDataSecret("worker-user-data"),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UserDataSecretNotFoundMachinePoolCondition,
+				Status: corev1.ConditionFalse,
+				Reason: "UserDataSecretFound",
+			},
+			expectedUserDataSecret: &hivev1.UserDataSecretStatus{Name: "worker-merged-user-data", ClusterVersion: "4.4.0"},
+			expectedMergedUserData: func() []byte {
+				userData, err := mergedUserData([]byte("{}"), []byte(testExtraIgnition), "4.4.0")
+				if err != nil {
+					t.Fatalf("could not merge user data: %v", err)
+				}
+				return userData
+			}(),
+		},
+		{
+			name:              "Merge ignition secret missing",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       withMergeIgnitionSecret(testMachinePool(), "extra-ignition"),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testUserDataSecret("worker-user-data"),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UserDataSecretNotFoundMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "MergeIgnitionSecretNotFound",
+			},
+			expectedRequeueAfter:   userDataSecretRequeueInterval,
+			expectNoMergedUserData: true,
+		},
+		{
+			name:              "Invalid merge ignition secret",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       withMergeIgnitionSecret(testMachinePool(), "extra-ignition"),
+			existing: []runtime.Object{
+				testMergeIgnitionSecret("extra-ignition", "systemd:"),
+			},
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testUserDataSecret("worker-user-data"),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UserDataSecretNotFoundMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "InvalidMergeIgnitionSecret",
+			},
+			expectedRequeueAfter:   userDataSecretRequeueInterval,
+			expectNoMergedUserData: true,
+		},
+		{
+			name:              "Merge ignition secret without default user data secret",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       withMergeIgnitionSecret(testMachinePool(), "extra-ignition"),
+			existing: []runtime.Object{
+				testMergeIgnitionSecret("extra-ignition", testExtraIgnition),
+			},
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UserDataSecretNotFoundMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UserDataSecretNotFound",
+			},
+			expectedRequeueAfter:   userDataSecretRequeueInterval,
+			expectNoMergedUserData: true,
+		},
+		{
+			name:              "User data secret override missing",
+			clusterDeployment: testClusterDeployment(),
+			machinePool: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.UserDataSecretName = "custom-user-data"
+				return pool
+			}(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testUserDataSecret("other-user-data"),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 2, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+			},
+			expectedCondition: &hivev1.MachinePoolCondition{
+				Type:   hivev1.UserDataSecretNotFoundMachinePoolCondition,
+				Status: corev1.ConditionTrue,
+				Reason: "UserDataSecretNotFound",
+			},
+			expectedRequeueAfter: userDataSecretRequeueInterval,
+		},
+		{
+			name:                 "No-op when actuator says not to proceed",
+			clusterDeployment:    testClusterDeployment(),
+			machinePool:          testMachinePool(),
+			actuatorDoNotProceed: true,
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+		},
+		{
+			name:              "Update machine set replicas",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testMachinePool(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 0, 0),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 1),
+			},
+		},
+		{
+			name:              "Create missing machine set",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testMachinePool(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+			},
+		},
+		{
+			name: "Skip create missing machine set when clusterDeployment has annotation hive.openshift.io/syncset-pause: true ",
+			clusterDeployment: func() *hivev1.ClusterDeployment {
+				cd := testClusterDeployment()
+				cd.Annotations = map[string]string{}
+				cd.Annotations[constants.SyncsetPauseAnnotation] = "true"
+				return cd
+			}(),
+			machinePool: testMachinePool(),
+		},
+		{
+			name: "Skip create missing machine set when cluster is unreachable",
+			clusterDeployment: func() *hivev1.ClusterDeployment {
+				cd := testClusterDeployment()
+				cond := controllerutils.FindClusterDeploymentCondition(cd.Status.Conditions, hivev1.UnreachableCondition)
+				cond.Status = corev1.ConditionTrue
+				return cd
+			}(),
+			machinePool: testMachinePool(),
+		},
+		{
+			name:              "Delete extra machine set",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testMachinePool(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1d", "worker", true, 1, 0),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+			expectedPrunedMachineSets:    []string{"foo-12345-worker-us-east-1d"},
+			expectedGeneratedMachineSets: pointer.Int32Ptr(3),
+			expectedGeneratedReplicas:    pointer.Int32Ptr(3),
+			expectedUserDataSecret:       &hivev1.UserDataSecretStatus{Name: "worker-user-data", ClusterVersion: "4.4.0"},
+		},
+		{
+			name:              "Delete machine sets for removed zones",
+			clusterDeployment: testClusterDeployment(),
+			machinePool: func() *hivev1.MachinePool {
+				mp := testMachinePool()
+				mp.Status.PrunedMachineSets = []string{"foo-12345-worker-us-east-1e"}
+				return mp
+			}(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+			},
+			expectedPrunedMachineSets:    []string{"foo-12345-worker-us-east-1a", "foo-12345-worker-us-east-1c"},
+			expectedGeneratedMachineSets: pointer.Int32Ptr(1),
+			expectedGeneratedReplicas:    pointer.Int32Ptr(1),
+		},
+		{
+			name:              "Previously pruned machine sets still reported",
+			clusterDeployment: testClusterDeployment(),
+			machinePool: func() *hivev1.MachinePool {
+				mp := testMachinePool()
+				mp.Status.PrunedMachineSets = []string{"foo-12345-worker-us-east-1d"}
+				return mp
+			}(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+			},
+			expectedPrunedMachineSets: []string{"foo-12345-worker-us-east-1d"},
+		},
+		{
+			name:              "No machine sets deleted when none generated",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testMachinePool(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+			},
+		},
+		{
+			name:              "Other machinesets ignored",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testMachinePool(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 3, 0),
+				testMachineSet("foo-12345-other-us-east-1b", "other", true, 3, 0),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 3, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 3, 0),
+				testMachineSet("foo-12345-other-us-east-1b", "other", true, 3, 0),
+			},
+		},
+		{
+			name:              "Create additional machinepool machinesets",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testMachinePool(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-other-us-east-1a", "other", true, 1, 0),
+				testMachineSet("foo-12345-other-us-east-1b", "other", true, 1, 0),
+				testMachineSet("foo-12345-other-us-east-1c", "other", true, 1, 0),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+				testMachineSet("foo-12345-other-us-east-1a", "other", true, 1, 0),
+				testMachineSet("foo-12345-other-us-east-1b", "other", true, 1, 0),
+				testMachineSet("foo-12345-other-us-east-1c", "other", true, 1, 0),
+			},
+		},
+		{
+			name:              "Delete machinepool machinesets",
+			clusterDeployment: testClusterDeployment(),
+			machinePool: func() *hivev1.MachinePool {
+				mp := testMachinePool()
+				now := metav1.Now()
+				mp.DeletionTimestamp = &now
+				return mp
+			}(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-other-us-east-1a", "other", true, 1, 0),
+				testMachineSet("foo-12345-other-us-east-1b", "other", true, 1, 0),
+				testMachineSet("foo-12345-other-us-east-1c", "other", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+			expectCleanup:     true,
+			expectNoFinalizer: true,
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-other-us-east-1a", "other", true, 1, 0),
+				testMachineSet("foo-12345-other-us-east-1b", "other", true, 1, 0),
+				testMachineSet("foo-12345-other-us-east-1c", "other", true, 1, 0),
+			},
+		},
+		{
+			name:              "Delete machinepool merged user data secret",
+			clusterDeployment: testClusterDeployment(),
+			machinePool: func() *hivev1.MachinePool {
+				mp := withMergeIgnitionSecret(testMachinePool(), "extra-ignition")
+				now := metav1.Now()
+				mp.DeletionTimestamp = &now
+				return mp
+			}(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testUserDataSecret("worker-merged-user-data"),
+			},
+			expectCleanup:          true,
+			expectNoFinalizer:      true,
+			expectNoMergedUserData: true,
+		},
+		{
+			name:              "Delete machinepool with failing cleanup of cloud resources",
+			clusterDeployment: testClusterDeployment(),
+			machinePool: func() *hivev1.MachinePool {
+				mp := testMachinePool()
+				now := metav1.Now()
+				mp.DeletionTimestamp = &now
+				return mp
+			}(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+			},
+			cleanupErr:    errors.New("cleanup failed"),
+			expectCleanup: true,
+			expectErr:     true,
+		},
+		{
+			name:              "Delete machinepool when the actuator cannot be created",
+			clusterDeployment: testClusterDeployment(),
+			machinePool: func() *hivev1.MachinePool {
+				mp := testMachinePool()
+				now := metav1.Now()
+				mp.DeletionTimestamp = &now
+				return mp
+			}(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+			},
+			actuatorBuildErr:  errors.New("could not describe availability zones"),
+			expectNoFinalizer: true,
+		},
+		{
+			name:        "No cluster deployment",
+			machinePool: testMachinePool(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+			expectNoFinalizer: true,
+			expectPoolPresent: true,
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+		},
+		{
+			name: "Deleted cluster deployment",
+			clusterDeployment: func() *hivev1.ClusterDeployment {
+				cd := testClusterDeployment()
+				now := metav1.Now()
+				cd.DeletionTimestamp = &now
+				return cd
+			}(),
+			machinePool: testMachinePool(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+			expectNoFinalizer: true,
+			expectPoolPresent: true,
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+		},
+		{
+			name:              "No-op with auto-scaling",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testAutoscalingMachinePool(3, 5),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+				testClusterAutoscaler("3"),
+				testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
+				testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
+				testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+			expectedRemoteMachineAutoscalers: []autoscalingv1beta1.MachineAutoscaler{
+				*testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
+				*testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
+				*testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
+			},
+			expectedRemoteClusterAutoscalers: []autoscalingv1.ClusterAutoscaler{
+				*testClusterAutoscaler("3"),
+			},
+		},
+		{
+			name:              "Create cluster autoscaler",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testAutoscalingMachinePool(3, 5),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+				testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
+				testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
+				testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+			expectedRemoteMachineAutoscalers: []autoscalingv1beta1.MachineAutoscaler{
+				*testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
+				*testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
+				*testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
+			},
+			expectedRemoteClusterAutoscalers: []autoscalingv1.ClusterAutoscaler{
+				*testClusterAutoscaler("1"),
+			},
+		},
+		{
+			name:              "Update cluster autoscaler when missing scale down",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testAutoscalingMachinePool(3, 5),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+				func() runtime.Object {
+					a := testClusterAutoscaler("1")
+					a.Spec.ScaleDown = nil
+					return a
+				}(),
+				testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
+				testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
+				testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+			expectedRemoteMachineAutoscalers: []autoscalingv1beta1.MachineAutoscaler{
+				*testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
+				*testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
+				*testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
+			},
+			expectedRemoteClusterAutoscalers: []autoscalingv1.ClusterAutoscaler{
+				*testClusterAutoscaler("2"),
+			},
+		},
+		{
+			name:              "Update cluster autoscaler when scale down disabled",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testAutoscalingMachinePool(3, 5),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+				func() runtime.Object {
+					a := testClusterAutoscaler("1")
+					a.Spec.ScaleDown.Enabled = false
+					return a
+				}(),
+				testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
+				testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
+				testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+			expectedRemoteMachineAutoscalers: []autoscalingv1beta1.MachineAutoscaler{
+				*testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 1, 2),
+				*testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
+				*testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
+			},
+			expectedRemoteClusterAutoscalers: []autoscalingv1.ClusterAutoscaler{
+				*testClusterAutoscaler("2"),
+			},
+		},
+		{
+			name:              "Create machine autoscalers",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testAutoscalingMachinePool(3, 5),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
 				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
 				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
 				testClusterAutoscaler("1"),
@@ -616,6 +1260,7 @@ func TestRemoteMachineSetReconcile(t *testing.T) {
 				testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 1, 2),
 				testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 1, 1),
 			},
+			expectCleanup:     true,
 			expectNoFinalizer: true,
 			expectedRemoteClusterAutoscalers: []autoscalingv1.ClusterAutoscaler{
 				*testClusterAutoscaler("1"),
@@ -648,6 +1293,98 @@ func TestRemoteMachineSetReconcile(t *testing.T) {
 				*testClusterAutoscaler("1"),
 			},
 		},
+		{
+			name:              "No machine autoscalers for machinesets with zero maxReplicas",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testAutoscalingMachinePool(0, 2),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testClusterAutoscaler("1"),
+				testMachineAutoscaler("foo-12345-worker-us-east-1c", "1", 0, 1),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 0, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 0, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 0, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 0, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 0, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 0, 0),
+			},
+			expectedRemoteMachineAutoscalers: []autoscalingv1beta1.MachineAutoscaler{
+				*testMachineAutoscaler("foo-12345-worker-us-east-1a", "1", 0, 1),
+				*testMachineAutoscaler("foo-12345-worker-us-east-1b", "1", 0, 1),
+			},
+			expectedRemoteClusterAutoscalers: []autoscalingv1.ClusterAutoscaler{
+				*testClusterAutoscaler("1"),
+			},
+		},
+		{
+			name:              "Configuration error",
+			clusterDeployment: testClusterDeployment(),
+			machinePool:       testMachinePool(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+			},
+			actuatorDoNotProceed: true,
+			generateErr: &ValidationError{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Reason:  "SubnetsNotFound",
+				Message: "subnets not found",
+			},
+			expectedRequeueAfter: time.Hour,
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+			},
+		},
+		{
+			name:              "Error with stale configuration error condition",
+			clusterDeployment: testClusterDeployment(),
+			machinePool: func() *hivev1.MachinePool {
+				mp := testMachinePool()
+				mp.Status.Conditions = append(mp.Status.Conditions, hivev1.MachinePoolCondition{
+					Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+					Status:  corev1.ConditionTrue,
+					Reason:  "SubnetsNotFound",
+					Message: "subnets not found",
+				})
+				return mp
+			}(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+			},
+			actuatorDoNotProceed: true,
+			generateErr:          errors.New("could not update MachinePool status"),
+			expectErr:            true,
+		},
+		{
+			name:              "Scale to zero replicas",
+			clusterDeployment: testClusterDeployment(),
+			machinePool: func() *hivev1.MachinePool {
+				mp := testMachinePool()
+				mp.Spec.Replicas = pointer.Int64Ptr(0)
+				return mp
+			}(),
+			remoteExisting: []runtime.Object{
+				testMachine("master1", "master"),
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 1, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 1, 0),
+			},
+			generatedMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 0, 0),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 0, 0),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 0, 0),
+			},
+			expectedRemoteMachineSets: []*machineapi.MachineSet{
+				testMachineSet("foo-12345-worker-us-east-1a", "worker", true, 0, 1),
+				testMachineSet("foo-12345-worker-us-east-1b", "worker", true, 0, 1),
+				testMachineSet("foo-12345-worker-us-east-1c", "worker", true, 0, 1),
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -656,7 +1393,7 @@ func TestRemoteMachineSetReconcile(t *testing.T) {
 		autoscalingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
 		autoscalingv1beta1.SchemeBuilder.AddToScheme(scheme.Scheme)
 		t.Run(test.name, func(t *testing.T) {
-			localExisting := []runtime.Object{}
+			localExisting := append([]runtime.Object{}, test.existing...)
 			if test.clusterDeployment != nil {
 				localExisting = append(localExisting, test.clusterDeployment)
 			}
@@ -670,10 +1407,16 @@ func TestRemoteMachineSetReconcile(t *testing.T) {
 			defer mockCtrl.Finish()
 
 			mockActuator := mock.NewMockActuator(mockCtrl)
-			if test.generatedMachineSets != nil {
+			if test.generatedMachineSets != nil || test.generateErr != nil {
 				mockActuator.EXPECT().
-					GenerateMachineSets(test.clusterDeployment, test.machinePool, gomock.Any()).
-					Return(test.generatedMachineSets, !test.actuatorDoNotProceed, nil)
+					GenerateMachineSets(test.clusterDeployment, gomock.Any(), gomock.Any()).
+					Return(test.generatedMachineSets, !test.actuatorDoNotProceed, test.generateErr)
+			}
+			mockActuator.EXPECT().Capabilities(test.clusterDeployment, gomock.Any()).Return(nil).AnyTimes()
+			if test.expectCleanup {
+				mockActuator.EXPECT().
+					CleanupResources(test.clusterDeployment, gomock.Any(), gomock.Any()).
+					Return(test.cleanupErr)
 			}
 
 			mockRemoteClientBuilder := remoteclientmock.NewMockBuilder(mockCtrl)
@@ -687,12 +1430,16 @@ func TestRemoteMachineSetReconcile(t *testing.T) {
 				scheme:                        scheme.Scheme,
 				logger:                        logger,
 				remoteClusterAPIClientBuilder: func(*hivev1.ClusterDeployment) remoteclient.Builder { return mockRemoteClientBuilder },
-				actuatorBuilder: func(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, masterMachine *machineapi.Machine, remoteMachineSets []machineapi.MachineSet, cdLog log.FieldLogger) (Actuator, error) {
+				actuatorBuilder: func(ctx context.Context, cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, masterMachine *machineapi.Machine, remoteMachineSets []machineapi.MachineSet, remoteClusterAPIClient client.Client, cdLog log.FieldLogger) (Actuator, error) {
+					if test.actuatorBuildErr != nil {
+						return nil, test.actuatorBuildErr
+					}
 					return mockActuator, nil
 				},
-				expectations: controllerExpectations,
+				expectations:                      controllerExpectations,
+				configurationErrorRequeueInterval: time.Hour,
 			}
-			_, err := rcd.Reconcile(context.TODO(), reconcile.Request{
+			result, err := rcd.Reconcile(context.TODO(), reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name:      fmt.Sprintf("%s-worker", testName),
 					Namespace: testNamespace,
@@ -710,6 +1457,9 @@ func TestRemoteMachineSetReconcile(t *testing.T) {
 				t.Errorf("unexpected error: %v", err)
 				return
 			}
+			if test.expectedRequeueAfter != 0 {
+				assert.Equal(t, test.expectedRequeueAfter, result.RequeueAfter, "unexpected requeue interval")
+			}
 
 			pool := getPool(fakeClient, "worker")
 			if test.expectNoFinalizer {
@@ -732,11 +1482,18 @@ func TestRemoteMachineSetReconcile(t *testing.T) {
 							found = true
 							assert.Equal(t, *eMS.Spec.Replicas, *rMS.Spec.Replicas)
 							assert.Equal(t, eMS.Generation, rMS.Generation)
+							assert.Equal(t, eMS.Spec.DeletePolicy, rMS.Spec.DeletePolicy, "%s delete policy does not match", eMS.Name)
 							if !reflect.DeepEqual(eMS.ObjectMeta.Labels, rMS.ObjectMeta.Labels) {
 								t.Errorf("machineset %v has unexpected labels:\nexpected: %v\nactual: %v", eMS.Name, eMS.Labels, rMS.Labels)
 							}
-							if !reflect.DeepEqual(eMS.ObjectMeta.Annotations, rMS.ObjectMeta.Annotations) {
-								t.Errorf("machineset %v has unexpected annotations:\nexpected: %v\nactual: %v", eMS.Name, eMS.Annotations, rMS.Annotations)
+							rAnnotations := map[string]string{}
+							for k, v := range rMS.Annotations {
+								if k != providerSpecHashAnnotation {
+									rAnnotations[k] = v
+								}
+							}
+							if (len(eMS.Annotations) > 0 || len(rAnnotations) > 0) && !reflect.DeepEqual(eMS.Annotations, rAnnotations) {
+								t.Errorf("machineset %v has unexpected annotations:\nexpected: %v\nactual: %v", eMS.Name, eMS.Annotations, rAnnotations)
 							}
 							if !reflect.DeepEqual(eMS.Spec.Template.Spec.Labels, rMS.Spec.Template.Spec.Labels) {
 								t.Errorf("machineset %v machinespec has unexpected labels:\nexpected: %v\nactual: %v", eMS.Name, eMS.Spec.Template.Spec.Labels, rMS.Spec.Template.Spec.Labels)
@@ -755,6 +1512,9 @@ func TestRemoteMachineSetReconcile(t *testing.T) {
 							log.Debugf("expected AWS: %v", printAWSMachineProviderConfig(eAWSProviderSpec))
 							assert.NotNil(t, eAWSProviderSpec)
 							assert.Equal(t, eAWSProviderSpec.AMI, rAWSProviderSpec.AMI, "%s AMI does not match", eMS.Name)
+							assert.Equal(t, eAWSProviderSpec.Tags, rAWSProviderSpec.Tags, "%s tags do not match", eMS.Name)
+							assert.Equal(t, eAWSProviderSpec.IAMInstanceProfile, rAWSProviderSpec.IAMInstanceProfile, "%s IAM instance profile does not match", eMS.Name)
+							assert.Equal(t, eAWSProviderSpec.SecurityGroups, rAWSProviderSpec.SecurityGroups, "%s security groups do not match", eMS.Name)
 
 						}
 					}
@@ -775,6 +1535,41 @@ func TestRemoteMachineSetReconcile(t *testing.T) {
 				}
 			}
 
+			if pool != nil {
+				assert.Equal(t, test.expectedPrunedMachineSets, pool.Status.PrunedMachineSets, "unexpected pruned machinesets")
+				if test.expectedGeneratedMachineSets != nil {
+					assert.Equal(t, *test.expectedGeneratedMachineSets, pool.Status.GeneratedMachineSets, "unexpected number of generated machinesets")
+				}
+				if test.expectedGeneratedReplicas != nil {
+					assert.Equal(t, *test.expectedGeneratedReplicas, pool.Status.GeneratedReplicas, "unexpected number of generated replicas")
+				}
+				if test.expectedGeneratedZoneReplicas != nil {
+					assert.Equal(t, test.expectedGeneratedZoneReplicas, pool.Status.GeneratedZoneReplicas, "unexpected generated replicas by zone")
+				}
+				if test.expectedUserDataSecret != nil {
+					assert.Equal(t, test.expectedUserDataSecret, pool.Status.UserDataSecret, "unexpected user data secret")
+				}
+			}
+
+			if test.expectedCondition != nil {
+				cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, test.expectedCondition.Type)
+				if assert.NotNilf(t, cond, "did not find expected condition type: %v", test.expectedCondition.Type) {
+					assert.Equal(t, test.expectedCondition.Status, cond.Status, "condition found with unexpected status")
+					assert.Equal(t, test.expectedCondition.Reason, cond.Reason, "condition found with unexpected reason")
+				}
+			}
+
+			mergedSecret := &corev1.Secret{}
+			err = remoteFakeClient.Get(context.TODO(), client.ObjectKey{Namespace: machineAPINamespace, Name: "worker-merged-user-data"}, mergedSecret)
+			switch {
+			case test.expectNoMergedUserData:
+				assert.True(t, apierrors.IsNotFound(err), "unexpected merged user data secret")
+			case test.expectedMergedUserData != nil:
+				if assert.NoError(t, err, "error getting merged user data secret") {
+					assert.Equal(t, string(test.expectedMergedUserData), string(mergedSecret.Data[userDataSecretKey]), "unexpected merged user data")
+				}
+			}
+
 			if rMAL, err := getRMAL(remoteFakeClient); assert.NoError(t, err, "error getting machine autoscalers") {
 				assert.ElementsMatch(t, test.expectedRemoteMachineAutoscalers, rMAL.Items, "unexpected remote machine autoscalers")
 			}
@@ -864,6 +1659,152 @@ Machine machine-3 failed (InsufficientResources): No available quota,
 	}
 }
 
+func Test_hasLabelsAndTaints(t *testing.T) {
+	cases := []struct {
+		name     string
+		labels   map[string]string
+		taints   []corev1.Taint
+		expected bool
+	}{{
+		name:     "labels and taints applied",
+		labels:   testMachinePool().Spec.Labels,
+		taints:   testMachinePool().Spec.Taints,
+		expected: true,
+	}, {
+		name: "additional labels and taints",
+		labels: func() map[string]string {
+			l := testMachinePool().Spec.Labels
+			l["extra"] = "label"
+			return l
+		}(),
+		taints: append(testMachinePool().Spec.Taints, corev1.Taint{
+			Key:    "extra",
+			Effect: corev1.TaintEffectNoExecute,
+		}),
+		expected: true,
+	}, {
+		name:   "missing label",
+		labels: map[string]string{},
+		taints: testMachinePool().Spec.Taints,
+	}, {
+		name: "different label value",
+		labels: func() map[string]string {
+			l := testMachinePool().Spec.Labels
+			l["machine.openshift.io/cluster-api-cluster"] = "other"
+			return l
+		}(),
+		taints: testMachinePool().Spec.Taints,
+	}, {
+		name:   "missing taint",
+		labels: testMachinePool().Spec.Labels,
+	}, {
+		name:   "different taint value",
+		labels: testMachinePool().Spec.Labels,
+		taints: []corev1.Taint{{
+			Key:    "foo",
+			Value:  "other",
+			Effect: corev1.TaintEffectNoSchedule,
+		}},
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0)
+			ms.Spec.Template.Spec.Labels = tc.labels
+			ms.Spec.Template.Spec.Taints = tc.taints
+			assert.Equal(t, tc.expected, hasLabelsAndTaints(testMachinePool(), ms))
+		})
+	}
+}
+
+func Test_poolLabelsAndTaints(t *testing.T) {
+	edgeTaint := corev1.Taint{Key: edgeNodeRoleLabel, Effect: corev1.TaintEffectNoSchedule}
+	cases := []struct {
+		name           string
+		edge           bool
+		taints         []corev1.Taint
+		expectedLabels map[string]string
+		expectedTaints []corev1.Taint
+	}{{
+		name:           "not an edge pool",
+		taints:         testMachinePool().Spec.Taints,
+		expectedLabels: testMachinePool().Spec.Labels,
+		expectedTaints: testMachinePool().Spec.Taints,
+	}, {
+		name:   "edge pool",
+		edge:   true,
+		taints: testMachinePool().Spec.Taints,
+		expectedLabels: func() map[string]string {
+			l := testMachinePool().Spec.Labels
+			l[edgeNodeRoleLabel] = ""
+			return l
+		}(),
+		expectedTaints: append(testMachinePool().Spec.Taints, edgeTaint),
+	}, {
+		name: "edge pool with its own edge taint",
+		edge: true,
+		taints: []corev1.Taint{{
+			Key:    edgeNodeRoleLabel,
+			Effect: corev1.TaintEffectNoExecute,
+		}},
+		expectedLabels: func() map[string]string {
+			l := testMachinePool().Spec.Labels
+			l[edgeNodeRoleLabel] = ""
+			return l
+		}(),
+		expectedTaints: []corev1.Taint{{
+			Key:    edgeNodeRoleLabel,
+			Effect: corev1.TaintEffectNoExecute,
+		}},
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := testMachinePool()
+			pool.Spec.Platform.AWS.Edge = tc.edge
+			pool.Spec.Taints = tc.taints
+			labels, taints := poolLabelsAndTaints(pool)
+			assert.Equal(t, tc.expectedLabels, labels, "unexpected labels")
+			assert.Equal(t, tc.expectedTaints, taints, "unexpected taints")
+
+			ms := testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0)
+			applyLabelsAndTaints(pool, ms)
+			assert.True(t, hasLabelsAndTaints(pool, ms), "expected the labels and taints to be applied")
+		})
+	}
+}
+
+func TestGenerateMachineSetsNodeLabels(t *testing.T) {
+	pool := testMachinePool()
+	pool.Spec.Labels = map[string]string{
+		"node-role.kubernetes.io/infra": "",
+		"team":                          "hive",
+	}
+	ms := testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0)
+	machineLabels := ms.Spec.Template.ObjectMeta.Labels
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockActuator := mock.NewMockActuator(mockCtrl)
+	mockActuator.EXPECT().GenerateMachineSets(gomock.Any(), pool, gomock.Any()).
+		Return([]*machineapi.MachineSet{ms}, true, nil)
+	mockActuator.EXPECT().Capabilities(gomock.Any(), pool).Return(nil)
+	logger := log.WithField("controller", "machinepool")
+	r := &ReconcileMachinePool{
+		logger: logger,
+		actuatorBuilder: func(ctx context.Context, cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, masterMachine *machineapi.Machine, remoteMachineSets []machineapi.MachineSet, remoteClusterAPIClient client.Client, logger log.FieldLogger) (Actuator, error) {
+			return mockActuator, nil
+		},
+	}
+
+	generatedMachineSets, proceed, err := r.generateMachineSets(context.Background(), pool, testClusterDeployment(), nil, &machineapi.MachineSetList{}, nil, logger)
+	require.NoError(t, err, "unexpected error generating machinesets")
+	require.True(t, proceed, "expected to proceed")
+	require.Len(t, generatedMachineSets, 1, "unexpected number of machinesets")
+	// The labels of the MachineSpec are copied to the Node of each Machine by the machine API, whereas those of the
+	// template are only set on the Machines.
+	assert.Equal(t, pool.Spec.Labels, generatedMachineSets[0].Spec.Template.Spec.ObjectMeta.Labels, "unexpected node labels")
+	assert.Equal(t, machineLabels, generatedMachineSets[0].Spec.Template.ObjectMeta.Labels, "unexpected machine labels")
+}
+
 func testMachinePool() *hivev1.MachinePool {
 	return &hivev1.MachinePool{
 		TypeMeta: metav1.TypeMeta{
@@ -911,11 +1852,95 @@ func testMachinePool() *hivev1.MachinePool {
 				},
 				{
 					Status: corev1.ConditionUnknown,
-					Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+					Type:   hivev1.InvalidSubnetsMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.InvalidConfigurationMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.UserDataSecretNotFoundMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.LabelsAndTaintsNotAppliedMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.NoUsableZonesMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.InstanceTypeNotResolvedMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.InstanceTypeNotOfferedInZonesMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.InstanceStoreVolumesNotManagedMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.SpotMaxPriceTooLowMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.SpotFallbackUnavailableMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.UnevenZoneSpreadMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.UnauthorizedCredentialsMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.AMIResolutionFailedMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.AMIUpdateInProgressMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.InsufficientQuotaMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.InsufficientSubnetIPsMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.NoOutboundConnectivityMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.MachineSetDriftCorrectedMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.VersionGatedFeaturesUnavailableMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.ResourcesNotFoundMachinePoolCondition,
+				},
+				{
+					Status: corev1.ConditionUnknown,
+					Type:   hivev1.InvalidUpdateStrategyMachinePoolCondition,
 				},
 				{
 					Status: corev1.ConditionUnknown,
-					Type:   hivev1.UnsupportedConfigurationMachinePoolCondition,
+					Type:   hivev1.InvalidPlatformMachinePoolCondition,
 				},
 			},
 		},
@@ -992,6 +2017,77 @@ func testMachine(name string, machineType string) *machineapi.Machine {
 	}
 }
 
+func withAMI(ms *machineapi.MachineSet, ami string) *machineapi.MachineSet {
+	providerSpec := testAWSProviderSpec()
+	providerSpec.AMI.ID = aws.String(ami)
+	rawProviderSpec, err := encodeAWSMachineProviderSpec(providerSpec, scheme.Scheme)
+	if err != nil {
+		log.WithError(err).Fatal("error encoding AWS machine provider spec")
+	}
+	ms.Spec.Template.Spec.ProviderSpec.Value = rawProviderSpec
+	return ms
+}
+
+func withTags(ms *machineapi.MachineSet, tags ...awsprovider.TagSpecification) *machineapi.MachineSet {
+	providerSpec, err := decodeAWSMachineProviderSpec(ms.Spec.Template.Spec.ProviderSpec.Value, scheme.Scheme)
+	if err != nil {
+		log.WithError(err).Fatal("error decoding AWS machine provider spec")
+	}
+	providerSpec.TypeMeta = testAWSProviderSpec().TypeMeta
+	providerSpec.Tags = tags
+	rawProviderSpec, err := encodeAWSMachineProviderSpec(providerSpec, scheme.Scheme)
+	if err != nil {
+		log.WithError(err).Fatal("error encoding AWS machine provider spec")
+	}
+	ms.Spec.Template.Spec.ProviderSpec.Value = rawProviderSpec
+	return ms
+}
+
+func withAccess(ms *machineapi.MachineSet, iamInstanceProfile string, securityGroups ...string) *machineapi.MachineSet {
+	providerSpec, err := decodeAWSMachineProviderSpec(ms.Spec.Template.Spec.ProviderSpec.Value, scheme.Scheme)
+	if err != nil {
+		log.WithError(err).Fatal("error decoding AWS machine provider spec")
+	}
+	providerSpec.TypeMeta = testAWSProviderSpec().TypeMeta
+	providerSpec.IAMInstanceProfile = &awsprovider.AWSResourceReference{ID: aws.String(iamInstanceProfile)}
+	providerSpec.SecurityGroups = nil
+	for _, securityGroup := range securityGroups {
+		providerSpec.SecurityGroups = append(providerSpec.SecurityGroups, awsprovider.AWSResourceReference{ID: aws.String(securityGroup)})
+	}
+	rawProviderSpec, err := encodeAWSMachineProviderSpec(providerSpec, scheme.Scheme)
+	if err != nil {
+		log.WithError(err).Fatal("error encoding AWS machine provider spec")
+	}
+	ms.Spec.Template.Spec.ProviderSpec.Value = rawProviderSpec
+	return ms
+}
+
+func withUpdateStrategy(pool *hivev1.MachinePool, strategyType hivev1.MachinePoolUpdateStrategyType, maxUnavailable *intstr.IntOrString) *hivev1.MachinePool {
+	pool.Spec.UpdateStrategy = &hivev1.MachinePoolUpdateStrategy{
+		Type:           strategyType,
+		MaxUnavailable: maxUnavailable,
+	}
+	return pool
+}
+
+func intstrPtr(value intstr.IntOrString) *intstr.IntOrString {
+	return &value
+}
+
+func withProviderSpecHash(ms *machineapi.MachineSet) *machineapi.MachineSet {
+	specHash, err := controllerutils.GetChecksumOfObject(ms.Spec.Template.Spec.ProviderSpec.Value)
+	if err != nil {
+		log.WithError(err).Fatal("error hashing provider spec")
+	}
+	setProviderSpecHash(ms, specHash)
+	return ms
+}
+
+func withReadyReplicas(ms *machineapi.MachineSet, readyReplicas int32) *machineapi.MachineSet {
+	ms.Status.ReadyReplicas = readyReplicas
+	return ms
+}
+
 func testMachineSetMachine(name string, machineType string, machineSetName string) *machineapi.Machine {
 	m := testMachine(name, machineType)
 	m.ObjectMeta.Labels["machine.openshift.io/cluster-api-machineset"] = machineSetName
@@ -1043,6 +2139,35 @@ func testMachineSet(name string, machineType string, unstompedAnnotation bool, r
 	return &ms
 }
 
+func testUserDataSecret(name string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: machineAPINamespace,
+			Name:      name,
+		},
+		Data: map[string][]byte{
+			"userData": []byte("{}"),
+		},
+	}
+}
+
+func testMergeIgnitionSecret(name, ignition string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      name,
+		},
+		Data: map[string][]byte{
+			"ignition": []byte(ignition),
+		},
+	}
+}
+
+func withMergeIgnitionSecret(pool *hivev1.MachinePool, name string) *hivev1.MachinePool {
+	pool.Spec.MergeIgnitionSecretRef = &corev1.LocalObjectReference{Name: name}
+	return pool
+}
+
 func testMachineAutoscaler(name string, resourceVersion string, min, max int) *autoscalingv1beta1.MachineAutoscaler {
 	return &autoscalingv1beta1.MachineAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1135,3 +2260,518 @@ func withClusterVersion(cd *hivev1.ClusterDeployment, version string) *hivev1.Cl
 	cd.Labels[constants.VersionMajorMinorPatchLabel] = version
 	return cd
 }
+
+func Test_getClusterVersion(t *testing.T) {
+	cases := []struct {
+		name            string
+		cd              *hivev1.ClusterDeployment
+		annotations     map[string]string
+		expectedVersion string
+		expectErr       bool
+	}{
+		{
+			name:            "version from cluster deployment",
+			cd:              withClusterVersion(testClusterDeployment(), "4.6.1"),
+			expectedVersion: "4.6.1",
+		},
+		{
+			name: "version not set",
+			cd: func() *hivev1.ClusterDeployment {
+				cd := testClusterDeployment()
+				delete(cd.Labels, constants.VersionMajorMinorPatchLabel)
+				return cd
+			}(),
+			expectErr: true,
+		},
+		{
+			name: "version from install version",
+			cd: func() *hivev1.ClusterDeployment {
+				cd := testClusterDeployment()
+				delete(cd.Labels, constants.VersionMajorMinorPatchLabel)
+				cd.Status.InstallVersion = pointer.StringPtr("4.8.2")
+				return cd
+			}(),
+			expectedVersion: "4.8.2",
+		},
+		{
+			name: "cluster version preferred to install version",
+			cd: func() *hivev1.ClusterDeployment {
+				cd := withClusterVersion(testClusterDeployment(), "4.9.0")
+				cd.Status.InstallVersion = pointer.StringPtr("4.8.2")
+				return cd
+			}(),
+			expectedVersion: "4.9.0",
+		},
+		{
+			name: "invalid cluster version falls back to install version",
+			cd: func() *hivev1.ClusterDeployment {
+				cd := withClusterVersion(testClusterDeployment(), "unknown")
+				cd.Status.InstallVersion = pointer.StringPtr("4.8.2")
+				return cd
+			}(),
+			expectedVersion: "4.8.2",
+		},
+		{
+			name: "invalid install version",
+			cd: func() *hivev1.ClusterDeployment {
+				cd := testClusterDeployment()
+				delete(cd.Labels, constants.VersionMajorMinorPatchLabel)
+				cd.Status.InstallVersion = pointer.StringPtr("latest")
+				return cd
+			}(),
+			expectErr: true,
+		},
+		{
+			name:            "version overridden",
+			cd:              withClusterVersion(testClusterDeployment(), "4.6.1"),
+			annotations:     map[string]string{hivev1.MachinePoolClusterVersionOverrideAnnotation: "4.7.0"},
+			expectedVersion: "4.7.0",
+		},
+		{
+			name: "version overridden when not set",
+			cd: func() *hivev1.ClusterDeployment {
+				cd := testClusterDeployment()
+				delete(cd.Labels, constants.VersionMajorMinorPatchLabel)
+				return cd
+			}(),
+			annotations:     map[string]string{hivev1.MachinePoolClusterVersionOverrideAnnotation: "4.7.0"},
+			expectedVersion: "4.7.0",
+		},
+		{
+			name:        "invalid version override",
+			cd:          withClusterVersion(testClusterDeployment(), "4.6.1"),
+			annotations: map[string]string{hivev1.MachinePoolClusterVersionOverrideAnnotation: "latest"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := testMachinePool()
+			pool.Annotations = tc.annotations
+			version, err := getClusterVersion(tc.cd, pool)
+			if tc.expectErr {
+				assert.Error(t, err, "expected error")
+				return
+			}
+			if assert.NoError(t, err, "unexpected error") {
+				assert.Equal(t, tc.expectedVersion, version, "unexpected cluster version")
+			}
+		})
+	}
+}
+
+func Test_generationFailureReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedReason string
+	}{
+		{
+			name: "success or waiting",
+		},
+		{
+			name:           "error",
+			err:            errors.New("boom"),
+			expectedReason: "GenerationFailed",
+		},
+		{
+			name: "configuration error",
+			err: pkgerrors.Wrap(&ValidationError{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Reason:  "SubnetsNotFound",
+				Message: "subnets not found",
+			}, "describing subnets"),
+			expectedReason: "SubnetsNotFound",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedReason, generationFailureReason(test.err))
+		})
+	}
+}
+
+func Test_isConfigurationError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name: "error",
+			err:  errors.New("boom"),
+		},
+		{
+			name: "configuration error",
+			err: &ValidationError{
+				Type:    hivev1.UnsupportedConfigurationMachinePoolCondition,
+				Reason:  "UnsupportedSpotMarketOptions",
+				Message: "spot market options are not supported",
+			},
+			expected: true,
+		},
+		{
+			name: "wrapped configuration error",
+			err: pkgerrors.Wrap(&ValidationError{
+				Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+				Reason:  "SubnetsNotFound",
+				Message: "subnets not found",
+			}, "describing subnets"),
+			expected: true,
+		},
+		{
+			name: "throttled",
+			err:  pkgerrors.Wrap(awserr.New("Throttling", "Rate exceeded", nil), "describing subnets"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, isConfigurationError(test.err))
+		})
+	}
+}
+
+func Test_getConfigurationErrorRequeueInterval(t *testing.T) {
+	cases := []struct {
+		name             string
+		value            string
+		expectErr        bool
+		expectedInterval time.Duration
+	}{
+		{
+			name:             "default",
+			expectedInterval: defaultConfigurationErrorRequeueInterval,
+		},
+		{
+			name:             "configured",
+			value:            "10m",
+			expectedInterval: 10 * time.Minute,
+		},
+		{
+			name:             "disabled",
+			value:            "0s",
+			expectedInterval: 0,
+		},
+		{
+			name:      "negative",
+			value:     "-1m",
+			expectErr: true,
+		},
+		{
+			name:      "invalid",
+			value:     "later",
+			expectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				os.Setenv(constants.MachinePoolConfigurationErrorRequeueIntervalEnvVar, tc.value)
+				defer os.Unsetenv(constants.MachinePoolConfigurationErrorRequeueIntervalEnvVar)
+			}
+
+			interval, err := getConfigurationErrorRequeueInterval()
+			if tc.expectErr {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, tc.expectedInterval, interval, "unexpected interval")
+		})
+	}
+}
+
+func Test_getAdditionalTags(t *testing.T) {
+	cases := []struct {
+		name         string
+		value        string
+		expectErr    bool
+		expectedTags map[string]string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:         "configured",
+			value:        `{"cost-center":"engineering","environment":"prod"}`,
+			expectedTags: map[string]string{"cost-center": "engineering", "environment": "prod"},
+		},
+		{
+			name:      "invalid",
+			value:     "cost-center=engineering",
+			expectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				os.Setenv(constants.MachinePoolAdditionalTagsEnvVar, tc.value)
+				defer os.Unsetenv(constants.MachinePoolAdditionalTagsEnvVar)
+			}
+
+			tags, err := getAdditionalTags()
+			if tc.expectErr {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, tc.expectedTags, tags, "unexpected tags")
+		})
+	}
+}
+
+func Test_getReadOnlyStatus(t *testing.T) {
+	cases := []struct {
+		name      string
+		value     string
+		expectErr bool
+		expected  bool
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:     "enabled",
+			value:    "true",
+			expected: true,
+		},
+		{
+			name:  "disabled",
+			value: "false",
+		},
+		{
+			name:      "invalid",
+			value:     "observe",
+			expectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				os.Setenv(constants.MachinePoolReadOnlyStatusEnvVar, tc.value)
+				defer os.Unsetenv(constants.MachinePoolReadOnlyStatusEnvVar)
+			}
+
+			readOnly, err := getReadOnlyStatus()
+			if tc.expectErr {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, tc.expected, readOnly, "unexpected read-only status")
+		})
+	}
+}
+
+func TestCreateActuatorReadOnlyStatus(t *testing.T) {
+	apis.AddToScheme(scheme.Scheme)
+	awsproviderapis.AddToScheme(scheme.Scheme)
+
+	cases := []struct {
+		name            string
+		readOnlyStatus  bool
+		expectPersisted bool
+	}{
+		{
+			name:            "status persisted",
+			expectPersisted: true,
+		},
+		{
+			name:           "read-only status",
+			readOnlyStatus: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewFakeClient(testMachinePool())
+			r := &ReconcileMachinePool{
+				Client:         fakeClient,
+				scheme:         scheme.Scheme,
+				readOnlyStatus: tc.readOnlyStatus,
+			}
+			pool := &hivev1.MachinePool{}
+			err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: testMachinePool().Name}, pool)
+			require.NoError(t, err)
+			// The cluster is not in the region of the ClusterDeployment, which the AWS actuator reports as invalid.
+			masterMachine := testMachine("master1", "master")
+			providerSpec := testAWSProviderSpec()
+			providerSpec.Placement.Region = "other-region"
+			masterMachine.Spec.ProviderSpec.Value, err = encodeAWSMachineProviderSpec(providerSpec, scheme.Scheme)
+			require.NoError(t, err)
+
+			_, err = r.createActuator(context.TODO(), testClusterDeployment(), pool, masterMachine, nil, fake.NewFakeClient(), log.StandardLogger())
+			assert.True(t, isConfigurationError(err), "expected a configuration error")
+			cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.InvalidConfigurationMachinePoolCondition)
+			if assert.NotNil(t, cond, "expected the condition to be set on the pool") {
+				assert.Equal(t, "RegionMismatch", cond.Reason, "unexpected condition reason")
+			}
+
+			persisted := &hivev1.MachinePool{}
+			err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: testMachinePool().Name}, persisted)
+			require.NoError(t, err)
+			persistedCond := controllerutils.FindMachinePoolCondition(persisted.Status.Conditions, hivev1.InvalidConfigurationMachinePoolCondition)
+			assert.Equal(t, tc.expectPersisted, persistedCond != nil && persistedCond.Reason == "RegionMismatch", "unexpected persisted condition")
+		})
+	}
+}
+
+func Test_withAdditionalTags(t *testing.T) {
+	cases := []struct {
+		name           string
+		userTags       map[string]string
+		additionalTags map[string]string
+		expected       map[string]string
+	}{
+		{
+			name:     "no additional tags",
+			userTags: map[string]string{"team": "hive"},
+			expected: map[string]string{"team": "hive"},
+		},
+		{
+			name:           "no user tags",
+			additionalTags: map[string]string{"cost-center": "engineering"},
+			expected:       map[string]string{"cost-center": "engineering"},
+		},
+		{
+			name:           "additional tags override user tags",
+			userTags:       map[string]string{"team": "hive", "cost-center": "research"},
+			additionalTags: map[string]string{"cost-center": "engineering"},
+			expected:       map[string]string{"team": "hive", "cost-center": "engineering"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			userTags := map[string]string{}
+			for key, value := range tc.userTags {
+				userTags[key] = value
+			}
+			actual := withAdditionalTags(tc.userTags, tc.additionalTags)
+			assert.Equal(t, tc.expected, actual, "unexpected tags")
+			if tc.userTags != nil {
+				assert.Equal(t, userTags, tc.userTags, "user tags were modified")
+			}
+		})
+	}
+}
+
+func Test_getMinMaxReplicasForMachineSet(t *testing.T) {
+	machineSets := []*machineapi.MachineSet{
+		withZone(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "us-east-1a"),
+		withZone(testMachineSet("foo-12345-worker-us-east-1b", "worker", false, 1, 0), "us-east-1b"),
+		withZone(testMachineSet("foo-12345-worker-us-east-1c", "worker", false, 1, 0), "us-east-1c"),
+	}
+	cases := []struct {
+		name         string
+		min, max     int
+		zoneReplicas map[string]hivev1.MachinePoolZoneAutoscaling
+		expectedMin  []int32
+		expectedMax  []int32
+	}{
+		{
+			name:        "split evenly",
+			min:         4,
+			max:         8,
+			expectedMin: []int32{2, 1, 1},
+			expectedMax: []int32{3, 3, 2},
+		},
+		{
+			name: "zone override",
+			min:  4,
+			max:  8,
+			zoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+				"us-east-1b": {MinReplicas: 2, MaxReplicas: 5},
+			},
+			expectedMin: []int32{1, 2, 1},
+			expectedMax: []int32{2, 5, 1},
+		},
+		{
+			name: "override for every zone",
+			min:  3,
+			max:  6,
+			zoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+				"us-east-1a": {MinReplicas: 0, MaxReplicas: 1},
+				"us-east-1b": {MinReplicas: 1, MaxReplicas: 2},
+				"us-east-1c": {MinReplicas: 2, MaxReplicas: 3},
+			},
+			expectedMin: []int32{0, 1, 2},
+			expectedMax: []int32{1, 2, 3},
+		},
+		{
+			name: "override for unused zone",
+			min:  3,
+			max:  3,
+			zoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+				"us-east-1d": {MinReplicas: 2, MaxReplicas: 2},
+			},
+			expectedMin: []int32{1, 1, 1},
+			expectedMax: []int32{1, 1, 1},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := testAutoscalingMachinePool(tc.min, tc.max)
+			pool.Spec.Autoscaling.ZoneReplicas = tc.zoneReplicas
+			for i := range machineSets {
+				min, max := getMinMaxReplicasForMachineSet(pool, machineSets, i)
+				assert.Equal(t, tc.expectedMin[i], min, "unexpected min replicas for machineset %d", i)
+				assert.Equal(t, tc.expectedMax[i], max, "unexpected max replicas for machineset %d", i)
+			}
+		})
+	}
+}
+
+func Test_machineSetZone(t *testing.T) {
+	ms := withZone(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0), "us-east-1a")
+	assert.Equal(t, "us-east-1a", machineSetZone(ms), "unexpected zone of encoded provider spec")
+	ms.Spec.Template.Spec.ProviderSpec.Value.Raw = nil
+	assert.Equal(t, "us-east-1a", machineSetZone(ms), "unexpected zone of provider spec object")
+	assert.Equal(t, "", machineSetZone(testMachineSet("foo-12345-worker-us-east-1a", "worker", false, 1, 0)),
+		"unexpected zone of provider spec without placement")
+}
+
+func withZone(ms *machineapi.MachineSet, zone string) *machineapi.MachineSet {
+	providerSpec := testAWSProviderSpec()
+	providerSpec.Placement.AvailabilityZone = zone
+	rawProviderSpec, err := encodeAWSMachineProviderSpec(providerSpec, scheme.Scheme)
+	if err != nil {
+		log.WithError(err).Fatal("error encoding AWS machine provider spec")
+	}
+	rawProviderSpec.Object = providerSpec
+	ms.Spec.Template.Spec.ProviderSpec.Value = rawProviderSpec
+	return ms
+}
+
+func Test_maxUnavailableMachineSets(t *testing.T) {
+	cases := []struct {
+		name           string
+		maxUnavailable *intstr.IntOrString
+		machineSets    int
+		expected       int
+		expectErr      bool
+	}{
+		{name: "default", machineSets: 3, expected: 1},
+		{name: "number", maxUnavailable: intstrPtr(intstr.FromInt(2)), machineSets: 3, expected: 2},
+		{name: "zero", maxUnavailable: intstrPtr(intstr.FromInt(0)), machineSets: 3, expectErr: true},
+		{name: "percentage", maxUnavailable: intstrPtr(intstr.FromString("50%")), machineSets: 6, expected: 3},
+		{name: "percentage rounded down", maxUnavailable: intstrPtr(intstr.FromString("50%")), machineSets: 5, expected: 2},
+		{name: "percentage of at least one", maxUnavailable: intstrPtr(intstr.FromString("10%")), machineSets: 3, expected: 1},
+		{name: "percentage over 100%", maxUnavailable: intstrPtr(intstr.FromString("101%")), machineSets: 3, expectErr: true},
+		{name: "zero percentage", maxUnavailable: intstrPtr(intstr.FromString("0%")), machineSets: 3, expectErr: true},
+		{name: "not a percentage", maxUnavailable: intstrPtr(intstr.FromString("2")), machineSets: 3, expectErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			strategy := &hivev1.MachinePoolUpdateStrategy{Type: hivev1.RollingMachinePoolUpdateStrategyType, MaxUnavailable: tc.maxUnavailable}
+			maxUnavailable, err := maxUnavailableMachineSets(strategy, tc.machineSets)
+			if tc.expectErr {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, tc.expected, maxUnavailable, "unexpected maximum of unavailable machinesets")
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package machinepool
+
+import (
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/hive/pkg/constants"
+)
+
+const (
+	// generationFailedReason is the reason reported when generating MachineSets fails for a reason other than a
+	// configuration error.
+	generationFailedReason = "GenerationFailed"
+)
+
+var (
+	metricGenerateMachineSetsDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "hive_machinepool_generate_machinesets_duration_seconds",
+			Help:    "Time taken by the actuator to generate the MachineSets for a MachinePool, labeled by platform.",
+			Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60},
+		},
+		[]string{"platform"},
+	)
+	metricGenerateMachineSetsErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hive_machinepool_generate_machinesets_errors_total",
+		Help: "Counter incremented every time the actuator fails to generate the MachineSets for a MachinePool, labeled by platform and the reason of the failure condition.",
+	},
+		[]string{"platform", "reason"},
+	)
+	metricAWSRateLimitWaitSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "hive_machinepool_aws_rate_limit_wait_seconds",
+			Help:    "Time spent waiting for the per-account AWS API rate limit before a describe call, labeled by call.",
+			Buckets: []float64{0.01, 0.1, 0.5, 1, 2, 5, 10, 30},
+		},
+		[]string{"call"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricGenerateMachineSetsDuration)
+	metrics.Registry.MustRegister(metricGenerateMachineSetsErrors)
+	metrics.Registry.MustRegister(metricAWSRateLimitWaitSeconds)
+}
+
+// getMachinePoolPlatform returns the platform of a given MachinePool
+func getMachinePoolPlatform(pool *hivev1.MachinePool) string {
+	switch {
+	case pool.Spec.Platform.AWS != nil:
+		return constants.PlatformAWS
+	case pool.Spec.Platform.Azure != nil:
+		return constants.PlatformAzure
+	case pool.Spec.Platform.GCP != nil:
+		return constants.PlatformGCP
+	case pool.Spec.Platform.OpenStack != nil:
+		return constants.PlatformOpenStack
+	case pool.Spec.Platform.VSphere != nil:
+		return constants.PlatformVSphere
+	case pool.Spec.Platform.Ovirt != nil:
+		return constants.PlatformOvirt
+	}
+	return constants.PlatformUnknown
+}
+
+// generationFailureReason returns the reason to report for an attempt to generate the MachineSets for a MachinePool,
+// or an empty string if the attempt did not fail. Configuration errors are reported with the reason of the condition
+// describing them. Not proceeding without an error is not a failure, since actuators may decline to proceed while
+// waiting (e.g. for a name lease).
+func generationFailureReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Reason
+	}
+	return generationFailedReason
+}
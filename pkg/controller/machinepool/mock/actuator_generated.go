@@ -50,3 +50,46 @@ func (mr *MockActuatorMockRecorder) GenerateMachineSets(arg0, arg1, arg2 interfa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateMachineSets", reflect.TypeOf((*MockActuator)(nil).GenerateMachineSets), arg0, arg1, arg2)
 }
+
+// Validate mocks base method
+func (m *MockActuator) Validate(arg0 *v1.ClusterDeployment, arg1 *v1.MachinePool) ([]v1.MachinePoolCondition, []error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", arg0, arg1)
+	ret0, _ := ret[0].([]v1.MachinePoolCondition)
+	ret1, _ := ret[1].([]error)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate
+func (mr *MockActuatorMockRecorder) Validate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockActuator)(nil).Validate), arg0, arg1)
+}
+
+// CleanupResources mocks base method
+func (m *MockActuator) CleanupResources(arg0 *v1.ClusterDeployment, arg1 *v1.MachinePool, arg2 logrus.FieldLogger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanupResources", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CleanupResources indicates an expected call of CleanupResources
+func (mr *MockActuatorMockRecorder) CleanupResources(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupResources", reflect.TypeOf((*MockActuator)(nil).CleanupResources), arg0, arg1, arg2)
+}
+
+// Capabilities mocks base method
+func (m *MockActuator) Capabilities(arg0 *v1.ClusterDeployment, arg1 *v1.MachinePool) []v1.MachinePoolCapability {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capabilities", arg0, arg1)
+	ret0, _ := ret[0].([]v1.MachinePoolCapability)
+	return ret0
+}
+
+// Capabilities indicates an expected call of Capabilities
+func (mr *MockActuatorMockRecorder) Capabilities(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capabilities", reflect.TypeOf((*MockActuator)(nil).Capabilities), arg0, arg1)
+}
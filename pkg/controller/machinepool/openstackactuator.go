@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
 	"github.com/gophercloud/utils/openstack/clientconfig"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -31,9 +34,19 @@ import (
 // OpenStackActuator encapsulates the pieces necessary to be able to generate
 // a list of MachineSets to sync to the remote cluster.
 type OpenStackActuator struct {
+	noopValidator
+	noopCleaner
+	noCapabilities
+
 	logger     log.FieldLogger
 	osImage    string
 	kubeClient client.Client
+
+	// osImageSource is where osImage comes from.
+	osImageSource hivev1.BootImageSource
+
+	// networkExists reports whether the network with the given ID exists in the cloud. Replaced in tests.
+	networkExists func(clientOptions *clientconfig.ClientOpts, networkID string) (bool, error)
 }
 
 var _ Actuator = &OpenStackActuator{}
@@ -42,17 +55,39 @@ func addOpenStackProviderToScheme(scheme *runtime.Scheme) error {
 	return openstackprovider.AddToScheme(scheme)
 }
 
+func init() {
+	registerActuator(constants.PlatformOpenStack, newOpenStackActuatorForCluster)
+}
+
+// newOpenStackActuatorForCluster is the registered actuatorConstructor of OpenStack.
+func newOpenStackActuatorForCluster(
+	ctx context.Context,
+	r *ReconcileMachinePool,
+	cd *hivev1.ClusterDeployment,
+	pool *hivev1.MachinePool,
+	masterMachine *machineapi.Machine,
+	remoteMachineSets []machineapi.MachineSet,
+	remoteClusterAPIClient client.Client,
+	logger log.FieldLogger,
+) (Actuator, error) {
+	return NewOpenStackActuator(pool, masterMachine, r.scheme, r.actuatorClient(), logger)
+}
+
 // NewOpenStackActuator is the constructor for building a OpenStackActuator
-func NewOpenStackActuator(masterMachine *machineapi.Machine, scheme *runtime.Scheme, kubeClient client.Client, logger log.FieldLogger) (*OpenStackActuator, error) {
-	osImage, err := getOpenStackOSImage(masterMachine, scheme, logger)
+func NewOpenStackActuator(pool *hivev1.MachinePool, masterMachine *machineapi.Machine, scheme *runtime.Scheme, kubeClient client.Client, logger log.FieldLogger) (*OpenStackActuator, error) {
+	osImage, osImageSource, err := poolBootImage(pool, func() (string, error) {
+		return getOpenStackOSImage(masterMachine, scheme, logger)
+	})
 	if err != nil {
 		logger.WithError(err).Error("error getting os image from master machine")
 		return nil, err
 	}
 	actuator := &OpenStackActuator{
-		logger:     logger,
-		osImage:    osImage,
-		kubeClient: kubeClient,
+		logger:        logger,
+		osImage:       osImage,
+		osImageSource: osImageSource,
+		kubeClient:    kubeClient,
+		networkExists: openStackNetworkExists,
 	}
 	return actuator, nil
 }
@@ -125,22 +160,89 @@ func (a *OpenStackActuator) GenerateMachineSets(cd *hivev1.ClusterDeployment, po
 		clientOptions.YAMLOpts = yamlOpts
 	}
 
+	if err := a.validateAdditionalNetworks(pool, clientOptions, logger); err != nil {
+		return nil, false, err
+	}
+	computePool.Platform.OpenStack.AdditionalNetworkIDs = pool.Spec.Platform.OpenStack.AdditionalNetworkIDs
+
 	installerMachineSets, err := installosp.MachineSets(
 		cd.Spec.ClusterMetadata.InfraID,
 		ic,
 		computePool,
 		a.osImage,
 		workerRole,
-		workerUserDataName,
+		workerUserData(pool),
 		clientOptions,
 	)
 	if err != nil {
 		return nil, false, errors.Wrap(err, "failed to generate machinesets")
 	}
 
+	setBootImageStatus(pool, a.osImage, a.osImageSource)
 	return installerMachineSets, true, nil
 }
 
+// validateAdditionalNetworks checks that the additional networks of the MachinePool exist in the cloud, and sets the
+// InvalidConfiguration condition on the MachinePool accordingly. Returns an error if any of the networks are missing.
+func (a *OpenStackActuator) validateAdditionalNetworks(pool *hivev1.MachinePool, clientOptions *clientconfig.ClientOpts, logger log.FieldLogger) error {
+	var missingNetworks []string
+	for _, networkID := range pool.Spec.Platform.OpenStack.AdditionalNetworkIDs {
+		exists, err := a.networkExists(clientOptions, networkID)
+		if err != nil {
+			logger.WithError(err).WithField("network", networkID).Error("could not look up additional network")
+			return errors.Wrapf(err, "could not look up additional network %s", networkID)
+		}
+		if !exists {
+			missingNetworks = append(missingNetworks, networkID)
+		}
+	}
+
+	var validationErr error
+	status, reason, message := corev1.ConditionFalse, "ValidConfiguration", "The configuration is valid"
+	updateCheck := controllerutils.UpdateConditionNever
+	if len(missingNetworks) > 0 {
+		logger.WithField("networks", missingNetworks).Warn("additional networks not found")
+		status, reason = corev1.ConditionTrue, "AdditionalNetworksNotFound"
+		message = fmt.Sprintf("additional networks not found: %s", strings.Join(missingNetworks, ", "))
+		updateCheck = controllerutils.UpdateConditionIfReasonOrMessageChange
+		validationErr = &ValidationError{
+			Type:    hivev1.InvalidConfigurationMachinePoolCondition,
+			Reason:  reason,
+			Message: message,
+		}
+	}
+	conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+		pool.Status.Conditions,
+		hivev1.InvalidConfigurationMachinePoolCondition,
+		status,
+		reason,
+		message,
+		updateCheck,
+	)
+	if changed {
+		pool.Status.Conditions = conds
+		if err := a.kubeClient.Status().Update(context.Background(), pool); err != nil {
+			return errors.Wrap(err, "could not update MachinePool status")
+		}
+	}
+	return validationErr
+}
+
+// openStackNetworkExists looks up the network with the given ID using the OpenStack networking service.
+func openStackNetworkExists(clientOptions *clientconfig.ClientOpts, networkID string) (bool, error) {
+	conn, err := clientconfig.NewServiceClient("network", clientOptions)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create OpenStack network client")
+	}
+	if err := networks.Get(conn, networkID).Err; err != nil {
+		if _, ok := err.(gophercloud.ErrDefault404); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // Get the OS image from an existing master machine.
 func getOpenStackOSImage(masterMachine *machineapi.Machine, scheme *runtime.Scheme, logger log.FieldLogger) (string, error) {
 	providerSpec, err := decodeOpenStackMachineProviderSpec(masterMachine.Spec.ProviderSpec.Value, scheme)
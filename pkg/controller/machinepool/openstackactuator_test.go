@@ -1,21 +1,29 @@
 package machinepool
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/gophercloud/utils/openstack/clientconfig"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
 	ospprovider "sigs.k8s.io/cluster-api-provider-openstack/pkg/apis/openstackproviderconfig/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 
+	"github.com/openshift/hive/apis"
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
 	hivev1osp "github.com/openshift/hive/apis/hive/v1/openstack"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
 )
 
 // This test is broken! The installer now checks for trunk support by querying the OpenStack service.
@@ -59,6 +67,86 @@ func BROKEN__TestOpenStackActuator(t *testing.T) {
 	}
 }
 
+func TestOpenStackActuatorValidateAdditionalNetworks(t *testing.T) {
+	apis.AddToScheme(scheme.Scheme)
+	tests := []struct {
+		name           string
+		networks       []string
+		existing       []string
+		lookupErr      error
+		expectedErr    bool
+		expectedStatus corev1.ConditionStatus
+		expectedReason string
+	}{
+		{
+			name:           "no additional networks",
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "ValidConfiguration",
+		},
+		{
+			name:           "additional networks exist",
+			networks:       []string{"storage-net", "backup-net"},
+			existing:       []string{"storage-net", "backup-net"},
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: "ValidConfiguration",
+		},
+		{
+			name:           "additional network missing",
+			networks:       []string{"storage-net", "backup-net"},
+			existing:       []string{"storage-net"},
+			expectedErr:    true,
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "AdditionalNetworksNotFound",
+		},
+		{
+			name:        "network lookup fails",
+			networks:    []string{"storage-net"},
+			lookupErr:   errors.New("connection refused"),
+			expectedErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pool := testOSPPool()
+			pool.Spec.Platform.OpenStack.AdditionalNetworkIDs = test.networks
+			actuator := &OpenStackActuator{
+				logger:     log.WithField("actuator", "openstackactuator"),
+				kubeClient: fake.NewFakeClient(pool),
+				networkExists: func(_ *clientconfig.ClientOpts, networkID string) (bool, error) {
+					if test.lookupErr != nil {
+						return false, test.lookupErr
+					}
+					for _, n := range test.existing {
+						if n == networkID {
+							return true, nil
+						}
+					}
+					return false, nil
+				},
+			}
+
+			err := actuator.validateAdditionalNetworks(pool, &clientconfig.ClientOpts{}, actuator.logger)
+			if test.expectedErr {
+				assert.Error(t, err, "expected error for test case")
+			} else {
+				assert.NoError(t, err, "unexpected error for test case")
+			}
+
+			if test.expectedStatus == "" {
+				return
+			}
+			updatedPool := &hivev1.MachinePool{}
+			err = actuator.kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: pool.Namespace, Name: pool.Name}, updatedPool)
+			require.NoError(t, err, "unexpected error getting machinepool")
+			cond := controllerutils.FindMachinePoolCondition(updatedPool.Status.Conditions, hivev1.InvalidConfigurationMachinePoolCondition)
+			if assert.NotNil(t, cond, "missing expected condition") {
+				assert.Equal(t, test.expectedStatus, cond.Status, "unexpected condition status")
+				assert.Equal(t, test.expectedReason, cond.Reason, "unexpected condition reason")
+			}
+		})
+	}
+}
+
 func validateOSPMachineSets(t *testing.T, mSets []*machineapi.MachineSet, expectedMSReplicas map[string]int64) {
 	assert.Equal(t, len(expectedMSReplicas), len(mSets), "different number of machine sets generated than expected")
 
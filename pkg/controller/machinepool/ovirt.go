@@ -1,6 +1,7 @@
 package machinepool
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -9,6 +10,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ovirtprovider "github.com/openshift/cluster-api-provider-ovirt/pkg/apis"
 	ovirtproviderv1beta1 "github.com/openshift/cluster-api-provider-ovirt/pkg/apis/ovirtprovider/v1beta1"
@@ -18,13 +20,20 @@ import (
 	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/hive/pkg/constants"
 )
 
 // OvirtActuator encapsulates the pieces necessary to be able to generate
 // a list of MachineSets to sync to the remote cluster
 type OvirtActuator struct {
+	noopValidator
+	noopCleaner
+	noCapabilities
+
 	logger  log.FieldLogger
 	osImage string
+	// osImageSource is where osImage comes from.
+	osImageSource hivev1.BootImageSource
 }
 
 var _ Actuator = &OvirtActuator{}
@@ -33,16 +42,37 @@ func addOvirtProviderToScheme(scheme *runtime.Scheme) error {
 	return ovirtprovider.AddToScheme(scheme)
 }
 
+func init() {
+	registerActuator(constants.PlatformOvirt, newOvirtActuatorForCluster)
+}
+
+// newOvirtActuatorForCluster is the registered actuatorConstructor of oVirt.
+func newOvirtActuatorForCluster(
+	ctx context.Context,
+	r *ReconcileMachinePool,
+	cd *hivev1.ClusterDeployment,
+	pool *hivev1.MachinePool,
+	masterMachine *machineapi.Machine,
+	remoteMachineSets []machineapi.MachineSet,
+	remoteClusterAPIClient client.Client,
+	logger log.FieldLogger,
+) (Actuator, error) {
+	return NewOvirtActuator(pool, masterMachine, r.scheme, logger)
+}
+
 // NewOvirtActuator is the constructor for building a OvirtActuator
-func NewOvirtActuator(masterMachine *machineapi.Machine, scheme *runtime.Scheme, logger log.FieldLogger) (*OvirtActuator, error) {
-	osImage, err := getOvirtOSImage(masterMachine, scheme, logger)
+func NewOvirtActuator(pool *hivev1.MachinePool, masterMachine *machineapi.Machine, scheme *runtime.Scheme, logger log.FieldLogger) (*OvirtActuator, error) {
+	osImage, osImageSource, err := poolBootImage(pool, func() (string, error) {
+		return getOvirtOSImage(masterMachine, scheme, logger)
+	})
 	if err != nil {
 		logger.WithError(err).Error("error getting os image from master machine")
 		return nil, err
 	}
 	actuator := &OvirtActuator{
-		logger:  logger,
-		osImage: osImage,
+		logger:        logger,
+		osImage:       osImage,
+		osImageSource: osImageSource,
 	}
 	return actuator, nil
 }
@@ -93,13 +123,14 @@ func (a *OvirtActuator) GenerateMachineSets(cd *hivev1.ClusterDeployment, pool *
 		computePool,
 		a.osImage,
 		workerRole,
-		workerUserDataName,
+		workerUserData(pool),
 	)
 	if err != nil {
 		return nil, false, errors.Wrap(err, "failed to generate machinesets")
 	}
 	installerMachineSets = preserveOvirtMachineSetNameSuffix(installerMachineSets)
 
+	setBootImageStatus(pool, a.osImage, a.osImageSource)
 	return installerMachineSets, true, nil
 }
 
@@ -0,0 +1,77 @@
+package machinepool
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/hive/pkg/awsclient"
+)
+
+const (
+	// routeTableCacheTTL is how long the route tables described for a VPC are re-used. It is kept short so that
+	// changes to the route tables (e.g. a new route to an internet gateway) are noticed promptly.
+	routeTableCacheTTL = time.Minute
+)
+
+// routeTableCache is a short-lived cache of the route tables in each VPC. It is shared by the actuators created
+// for each reconcile so that MachinePools in the same VPC re-use the route tables rather than each describing
+// them again. It is safe for concurrent use.
+type routeTableCache struct {
+	store cache.Store
+}
+
+// vpcRouteTables is the entry cached for a VPC.
+type vpcRouteTables struct {
+	vpcID       string
+	routeTables []*ec2.RouteTable
+}
+
+func newRouteTableCache(ttl time.Duration, clk clock.Clock) *routeTableCache {
+	return &routeTableCache{
+		store: cache.NewExpirationStore(
+			func(obj interface{}) (string, error) {
+				return obj.(*vpcRouteTables).vpcID, nil
+			},
+			&cache.TTLPolicy{TTL: ttl, Clock: clk},
+		),
+	}
+}
+
+// getRouteTables returns the route tables in the given VPC, describing them with the given AWS client, across all the
+// pages of the response, when they are not cached. A nil routeTableCache always describes the route tables.
+func (c *routeTableCache) getRouteTables(awsClient awsclient.Client, vpcID string) ([]*ec2.RouteTable, error) {
+	if c != nil {
+		if obj, exists, _ := c.store.GetByKey(vpcID); exists {
+			return obj.(*vpcRouteTables).routeTables, nil
+		}
+	}
+	input := &ec2.DescribeRouteTablesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("vpc-id"),
+			Values: []*string{aws.String(vpcID)},
+		}},
+	}
+	var routeTables []*ec2.RouteTable
+	for {
+		output, err := awsClient.DescribeRouteTables(input)
+		if err != nil {
+			return nil, err
+		}
+		routeTables = append(routeTables, output.RouteTables...)
+		if aws.StringValue(output.NextToken) == "" {
+			break
+		}
+		input = &ec2.DescribeRouteTablesInput{Filters: input.Filters, NextToken: output.NextToken}
+	}
+	if c != nil {
+		if err := c.store.Add(&vpcRouteTables{vpcID: vpcID, routeTables: routeTables}); err != nil {
+			return nil, err
+		}
+	}
+	return routeTables, nil
+}
@@ -0,0 +1,163 @@
+package machinepool
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	mockaws "github.com/openshift/hive/pkg/awsclient/mock"
+)
+
+func expectDescribeRouteTables(client *mockaws.MockClient, vpcID string, routeTables []*ec2.RouteTable) *gomock.Call {
+	return client.EXPECT().DescribeRouteTables(&ec2.DescribeRouteTablesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("vpc-id"),
+			Values: []*string{aws.String(vpcID)},
+		}},
+	}).Return(&ec2.DescribeRouteTablesOutput{RouteTables: routeTables}, nil)
+}
+
+func TestRouteTableCache(t *testing.T) {
+	vpc1Tables := []*ec2.RouteTable{{RouteTableId: aws.String("rtb-vpc1")}}
+	vpc2Tables := []*ec2.RouteTable{{RouteTableId: aws.String("rtb-vpc2")}}
+
+	tests := []struct {
+		name          string
+		mockAWSClient func(*mockaws.MockClient)
+		lookups       func(t *testing.T, c *routeTableCache, client *mockaws.MockClient, fakeClock *clock.FakeClock)
+	}{
+		{
+			name: "cached within TTL",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeRouteTables(client, "vpc-1", vpc1Tables).Times(1)
+			},
+			lookups: func(t *testing.T, c *routeTableCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				for i := 0; i < 3; i++ {
+					routeTables, err := c.getRouteTables(client, "vpc-1")
+					assert.NoError(t, err, "unexpected error")
+					assert.Equal(t, vpc1Tables, routeTables, "unexpected route tables")
+					fakeClock.Step(10 * time.Second)
+				}
+			},
+		},
+		{
+			name: "described again after TTL",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeRouteTables(client, "vpc-1", vpc1Tables).Times(2)
+			},
+			lookups: func(t *testing.T, c *routeTableCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				_, err := c.getRouteTables(client, "vpc-1")
+				assert.NoError(t, err, "unexpected error")
+				fakeClock.Step(routeTableCacheTTL + time.Second)
+				_, err = c.getRouteTables(client, "vpc-1")
+				assert.NoError(t, err, "unexpected error")
+			},
+		},
+		{
+			name: "cached per VPC",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeRouteTables(client, "vpc-1", vpc1Tables).Times(1)
+				expectDescribeRouteTables(client, "vpc-2", vpc2Tables).Times(1)
+			},
+			lookups: func(t *testing.T, c *routeTableCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				for _, vpc := range []string{"vpc-1", "vpc-2", "vpc-1", "vpc-2"} {
+					routeTables, err := c.getRouteTables(client, vpc)
+					assert.NoError(t, err, "unexpected error")
+					expected := vpc1Tables
+					if vpc == "vpc-2" {
+						expected = vpc2Tables
+					}
+					assert.Equal(t, expected, routeTables, "unexpected route tables for %s", vpc)
+				}
+			},
+		},
+		{
+			name: "paginated route tables",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				filters := []*ec2.Filter{{
+					Name:   aws.String("vpc-id"),
+					Values: []*string{aws.String("vpc-1")},
+				}}
+				gomock.InOrder(
+					client.EXPECT().DescribeRouteTables(&ec2.DescribeRouteTablesInput{Filters: filters}).
+						Return(&ec2.DescribeRouteTablesOutput{RouteTables: vpc1Tables, NextToken: aws.String("page-2")}, nil),
+					client.EXPECT().DescribeRouteTables(&ec2.DescribeRouteTablesInput{Filters: filters, NextToken: aws.String("page-2")}).
+						Return(&ec2.DescribeRouteTablesOutput{RouteTables: vpc2Tables}, nil),
+				)
+			},
+			lookups: func(t *testing.T, c *routeTableCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				for i := 0; i < 2; i++ {
+					routeTables, err := c.getRouteTables(client, "vpc-1")
+					assert.NoError(t, err, "unexpected error")
+					assert.Equal(t, append(append([]*ec2.RouteTable{}, vpc1Tables...), vpc2Tables...), routeTables, "unexpected route tables")
+				}
+			},
+		},
+		{
+			name: "errors are not cached",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				gomock.InOrder(
+					client.EXPECT().DescribeRouteTables(gomock.Any()).Return(nil, errors.New("RequestLimitExceeded")),
+					expectDescribeRouteTables(client, "vpc-1", vpc1Tables),
+				)
+			},
+			lookups: func(t *testing.T, c *routeTableCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				_, err := c.getRouteTables(client, "vpc-1")
+				assert.Error(t, err, "expected error")
+				routeTables, err := c.getRouteTables(client, "vpc-1")
+				assert.NoError(t, err, "unexpected error")
+				assert.Equal(t, vpc1Tables, routeTables, "unexpected route tables")
+			},
+		},
+		{
+			name: "concurrent lookups",
+			mockAWSClient: func(client *mockaws.MockClient) {
+				expectDescribeRouteTables(client, "vpc-1", vpc1Tables).MinTimes(1)
+			},
+			lookups: func(t *testing.T, c *routeTableCache, client *mockaws.MockClient, fakeClock *clock.FakeClock) {
+				var wg sync.WaitGroup
+				for i := 0; i < 10; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						routeTables, err := c.getRouteTables(client, "vpc-1")
+						assert.NoError(t, err, "unexpected error")
+						assert.Equal(t, vpc1Tables, routeTables, "unexpected route tables")
+					}()
+				}
+				wg.Wait()
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockaws.NewMockClient(mockCtrl)
+			test.mockAWSClient(client)
+			fakeClock := clock.NewFakeClock(time.Now())
+			test.lookups(t, newRouteTableCache(routeTableCacheTTL, fakeClock), client, fakeClock)
+		})
+	}
+}
+
+func TestNilRouteTableCache(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client := mockaws.NewMockClient(mockCtrl)
+	expectDescribeRouteTables(client, "vpc-1", nil).Times(2)
+
+	var c *routeTableCache
+	for i := 0; i < 2; i++ {
+		_, err := c.getRouteTables(client, "vpc-1")
+		assert.NoError(t, err, "unexpected error")
+	}
+}
@@ -1,6 +1,113 @@
 package machinepool
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+)
+
 const (
 	// workerUserDataName is the name of a secret in the cluster used for obtaining user data from MCO.
 	workerUserDataName = "worker-user-data"
+	// userDataSecretKey is the key of the user data in the user data secrets referenced by machine sets.
+	userDataSecretKey = "userData"
+	// mergeIgnitionSecretKey is the key of the Ignition config in the secret referenced by the merge ignition secret
+	// ref of a machine pool.
+	mergeIgnitionSecretKey = "ignition"
+	// mergedIgnitionVersion is the Ignition spec version of the merged user data, supported since OpenShift 4.6.
+	mergedIgnitionVersion = "3.1.0"
+	// mergedIgnitionV2Version is the Ignition spec version of the merged user data for clusters older than OpenShift
+	// 4.6, whose machines only support Ignition spec 2.
+	mergedIgnitionV2Version = "2.2.0"
 )
+
+var versionsSupportingIgnitionV3 = semver.MustParseRange(">=4.6.0")
+
+// workerUserData returns the name of the secret in the remote cluster containing the user data for the machines in
+// the pool. The merged user data secret is used when the pool references an Ignition config to merge, otherwise the
+// base user data secret is used.
+func workerUserData(pool *hivev1.MachinePool) string {
+	if pool.Spec.MergeIgnitionSecretRef != nil {
+		return mergedUserDataName(pool)
+	}
+	return baseUserData(pool)
+}
+
+// baseUserData returns the name of the secret in the remote cluster containing the user data which configures the
+// machines in the pool as workers. The secret named in the MachinePool is used when set, otherwise the secret managed
+// by MCO is used.
+func baseUserData(pool *hivev1.MachinePool) string {
+	if pool.Spec.UserDataSecretName != "" {
+		return pool.Spec.UserDataSecretName
+	}
+	return workerUserDataName
+}
+
+// mergedUserDataName returns the name of the secret in the remote cluster containing the base user data of the pool
+// merged with the Ignition config referenced by the pool.
+func mergedUserDataName(pool *hivev1.MachinePool) string {
+	return fmt.Sprintf("%s-merged-user-data", pool.Spec.Name)
+}
+
+type ignitionConfig struct {
+	Ignition ignitionSection `json:"ignition"`
+}
+
+type ignitionSection struct {
+	Config  ignitionConfigReferences `json:"config"`
+	Version string                   `json:"version"`
+}
+
+type ignitionConfigReferences struct {
+	// Merge lists the configs to merge with Ignition spec 3.
+	Merge []ignitionResource `json:"merge,omitempty"`
+	// Append lists the configs to merge with Ignition spec 2.
+	Append []ignitionResource `json:"append,omitempty"`
+}
+
+type ignitionResource struct {
+	Source string `json:"source"`
+}
+
+// mergedUserData returns user data whose Ignition config merges the base user data with the given Ignition config,
+// in that order, so that the given config can add to or override the base config. Both are embedded as data URLs, so
+// the machines do not fetch anything beyond what the base user data references. The merged config uses Ignition spec
+// 3 for clusters from OpenShift 4.6 and spec 2 otherwise, including when the cluster version is not known, as for the
+// other version-gated features.
+func mergedUserData(baseUserData, ignition []byte, clusterVersion string) ([]byte, error) {
+	if !json.Valid(ignition) {
+		return nil, errors.New("the Ignition config is not valid JSON")
+	}
+	sources := []ignitionResource{
+		{Source: "data:;base64," + base64.StdEncoding.EncodeToString(baseUserData)},
+		{Source: "data:;base64," + base64.StdEncoding.EncodeToString(ignition)},
+	}
+	config := ignitionConfig{
+		Ignition: ignitionSection{
+			Config:  ignitionConfigReferences{Merge: sources},
+			Version: mergedIgnitionVersion,
+		},
+	}
+	if version, err := semver.ParseTolerant(clusterVersion); err != nil || !versionsSupportingIgnitionV3(version) {
+		config.Ignition = ignitionSection{
+			Config:  ignitionConfigReferences{Append: sources},
+			Version: mergedIgnitionV2Version,
+		}
+	}
+	return json.Marshal(config)
+}
+
+// userDataSecretStatus returns the status recording the user data secret selected for the machine sets of the pool
+// and the version of the cluster at the time. The version is left empty when it cannot be determined.
+func userDataSecretStatus(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool) *hivev1.UserDataSecretStatus {
+	clusterVersion, _ := getClusterVersion(cd, pool)
+	return &hivev1.UserDataSecretStatus{
+		Name:           workerUserData(pool),
+		ClusterVersion: clusterVersion,
+	}
+}
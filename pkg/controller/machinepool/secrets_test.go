@@ -0,0 +1,79 @@
+package machinepool
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergedUserData(t *testing.T) {
+	baseUserData := `{"ignition":{"config":{"merge":[{"source":"https://api-int.test:22623/config/worker"}]},"version":"3.1.0"}}`
+	cases := []struct {
+		name            string
+		ignition        string
+		clusterVersion  string
+		expectErr       bool
+		expectedVersion string
+	}{
+		{
+			name:            "extra systemd unit",
+			ignition:        testExtraIgnition,
+			clusterVersion:  "4.6.0",
+			expectedVersion: mergedIgnitionVersion,
+		},
+		{
+			name:            "cluster without Ignition spec 3",
+			ignition:        testExtraIgnition,
+			clusterVersion:  "4.5.9",
+			expectedVersion: mergedIgnitionV2Version,
+		},
+		{
+			name:            "unknown cluster version",
+			ignition:        testExtraIgnition,
+			expectedVersion: mergedIgnitionV2Version,
+		},
+		{
+			name:      "missing ignition",
+			expectErr: true,
+		},
+		{
+			name:      "invalid JSON",
+			ignition:  "systemd:\n  units: []",
+			expectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			userData, err := mergedUserData([]byte(baseUserData), []byte(tc.ignition), tc.clusterVersion)
+			if tc.expectErr {
+				assert.Error(t, err, "expected error merging user data")
+				return
+			}
+			require.NoError(t, err, "unexpected error merging user data")
+
+			config := ignitionConfig{}
+			require.NoError(t, json.Unmarshal(userData, &config), "merged user data is not valid JSON")
+			assert.Equal(t, tc.expectedVersion, config.Ignition.Version, "unexpected Ignition version")
+			sources := config.Ignition.Config.Merge
+			if tc.expectedVersion == mergedIgnitionV2Version {
+				assert.Empty(t, sources, "unexpected Ignition spec 3 merge")
+				sources = config.Ignition.Config.Append
+			} else {
+				assert.Empty(t, config.Ignition.Config.Append, "unexpected Ignition spec 2 append")
+			}
+			var merged []string
+			for _, resource := range sources {
+				if assert.True(t, strings.HasPrefix(resource.Source, "data:;base64,"), "merged config is not a data URL") {
+					data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(resource.Source, "data:;base64,"))
+					require.NoError(t, err, "could not decode merged config")
+					merged = append(merged, string(data))
+				}
+			}
+			assert.Equal(t, []string{baseUserData, tc.ignition}, merged, "unexpected merged configs")
+		})
+	}
+}
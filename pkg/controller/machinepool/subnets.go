@@ -0,0 +1,163 @@
+package machinepool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+// cloudSubnet is a subnet of the cloud network of a cluster, in the terms shared by the platforms: its ID and the zone
+// it is in.
+type cloudSubnet struct {
+	id   string
+	zone string
+}
+
+// subnetLister looks up the subnets of a MachinePool on its platform.
+type subnetLister interface {
+	// listSubnets returns the subnets with the given IDs. When some of them do not exist, it returns a
+	// *subnetsNotFoundError.
+	listSubnets(ids []string) ([]cloudSubnet, error)
+}
+
+// subnetsNotFoundError is returned by a subnetLister when some of the subnets it is asked for do not exist. Its message
+// is reported in the InvalidSubnets condition of the MachinePool.
+type subnetsNotFoundError struct {
+	message string
+}
+
+func (e *subnetsNotFoundError) Error() string {
+	return e.message
+}
+
+// subnetSelector returns the ID of the subnet to use in a zone with several subnets, given in the order of their IDs.
+type subnetSelector func(zone string, candidates []cloudSubnet) string
+
+// resolveSubnets returns the subnets with the given IDs, as listed by the lister. Sets the InvalidSubnets condition
+// when some of them do not exist.
+func resolveSubnets(lister subnetLister, pool *hivev1.MachinePool, ids []string) ([]cloudSubnet, error) {
+	subnets, err := lister.listSubnets(ids)
+	if notFound, ok := err.(*subnetsNotFoundError); ok {
+		return nil, invalidSubnetsError(pool, "SubnetsNotFound", notFound.message)
+	}
+	return subnets, err
+}
+
+// mapSubnetsToZones returns the subnet of each zone of the given subnets, and a description of each subnet selected by
+// selectSubnet from several in a zone. Without a selector, several subnets in a zone conflict, and the InvalidSubnets
+// condition is set listing the conflicting subnets.
+func mapSubnetsToZones(pool *hivev1.MachinePool, subnets []cloudSubnet, selectSubnet subnetSelector) (map[string]string, []string, error) {
+	listed := sets.NewString()
+	subnetsForZone := map[string][]cloudSubnet{}
+	for _, s := range subnets {
+		if listed.Has(s.id) {
+			continue
+		}
+		listed.Insert(s.id)
+		subnetsForZone[s.zone] = append(subnetsForZone[s.zone], s)
+	}
+
+	conflictingSubnets := sets.NewString()
+	var selections []string
+	subnetsByZone := make(map[string]string, len(subnetsForZone))
+	for zone, zoneSubnets := range subnetsForZone {
+		if len(zoneSubnets) == 1 {
+			subnetsByZone[zone] = zoneSubnets[0].id
+			continue
+		}
+		sort.Slice(zoneSubnets, func(i, j int) bool { return zoneSubnets[i].id < zoneSubnets[j].id })
+		zoneSubnetIDs := make([]string, len(zoneSubnets))
+		for i, s := range zoneSubnets {
+			zoneSubnetIDs[i] = s.id
+		}
+		if selectSubnet != nil {
+			subnetID := selectSubnet(zone, zoneSubnets)
+			subnetsByZone[zone] = subnetID
+			selections = append(selections, fmt.Sprintf("%s: %s (of %s)", zone, subnetID, strings.Join(zoneSubnetIDs, ", ")))
+			continue
+		}
+		conflictingSubnets.Insert(zoneSubnetIDs...)
+	}
+
+	if len(conflictingSubnets) > 0 {
+		return nil, nil, invalidSubnetsError(pool, "MoreThanOneSubnetForZone",
+			fmt.Sprintf("more than one subnet found for some availability zones, conflicting subnets: %s", strings.Join(conflictingSubnets.List(), ", ")))
+	}
+	sort.Strings(selections)
+	return subnetsByZone, selections, nil
+}
+
+// mapFailureDomainSubnets returns the zones of the given failure domains, each a zone with the ID of the subnet of its
+// machines, and their subnets by zone, after checking with the lister that the subnet of each failure domain exists in
+// its zone. Sets the InvalidSubnets condition when they do not.
+func mapFailureDomainSubnets(lister subnetLister, pool *hivev1.MachinePool, domains []cloudSubnet) ([]string, map[string]string, error) {
+	subnetIDs := make([]string, len(domains))
+	for i, domain := range domains {
+		subnetIDs[i] = domain.id
+	}
+	listed, err := resolveSubnets(lister, pool, subnetIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	subnetZones := make(map[string]string, len(listed))
+	for _, s := range listed {
+		subnetZones[s.id] = s.zone
+	}
+
+	zones := make([]string, 0, len(domains))
+	subnets := make(map[string]string, len(domains))
+	var misplaced []string
+	for _, domain := range domains {
+		if zone := subnetZones[domain.id]; zone != domain.zone {
+			misplaced = append(misplaced, fmt.Sprintf("%s is in %s, not %s", domain.id, zone, domain.zone))
+			continue
+		}
+		zones = append(zones, domain.zone)
+		subnets[domain.zone] = domain.id
+	}
+	if len(misplaced) > 0 {
+		return nil, nil, invalidSubnetsError(pool, "SubnetsNotInFailureDomainZones",
+			fmt.Sprintf("subnets of failure domains not in their availability zones: %s", strings.Join(misplaced, ", ")))
+	}
+	return zones, subnets, nil
+}
+
+// checkSubnetsForZones checks that there is a subnet for every zone used by the MachinePool, and sets the
+// InvalidSubnets condition when there is not.
+func checkSubnetsForZones(pool *hivev1.MachinePool, zones []string, subnetsByZone map[string]string) error {
+	var zonesMissingSubnet []string
+	for _, zone := range zones {
+		if _, ok := subnetsByZone[zone]; !ok {
+			zonesMissingSubnet = append(zonesMissingSubnet, zone)
+		}
+	}
+	if len(zonesMissingSubnet) == 0 {
+		return nil
+	}
+	return invalidSubnetsError(pool, "NoSubnetForAvailabilityZone",
+		fmt.Sprintf("no private subnet provided for availability zones: %s", strings.Join(zonesMissingSubnet, ", ")))
+}
+
+// invalidSubnetsError sets the InvalidSubnets condition of the MachinePool with the reason and message, and returns the
+// *ValidationError reporting it.
+func invalidSubnetsError(pool *hivev1.MachinePool, reason, message string) error {
+	pool.Status.Conditions = controllerutils.SetMachinePoolCondition(
+		pool.Status.Conditions,
+		hivev1.InvalidSubnetsMachinePoolCondition,
+		corev1.ConditionTrue,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	return &ValidationError{
+		Type:    hivev1.InvalidSubnetsMachinePoolCondition,
+		Reason:  reason,
+		Message: message,
+	}
+}
@@ -0,0 +1,166 @@
+package machinepool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+// fakeSubnetLister lists the subnets it knows, and reports the others as not found.
+type fakeSubnetLister map[string]string
+
+func (l fakeSubnetLister) listSubnets(ids []string) ([]cloudSubnet, error) {
+	var subnets []cloudSubnet
+	var missing []string
+	for _, id := range ids {
+		zone, ok := l[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		subnets = append(subnets, cloudSubnet{id: id, zone: zone})
+	}
+	if len(missing) > 0 {
+		return nil, &subnetsNotFoundError{message: "subnets not found: " + missing[0]}
+	}
+	return subnets, nil
+}
+
+func TestMapSubnetsToZones(t *testing.T) {
+	tests := []struct {
+		name               string
+		subnets            []cloudSubnet
+		selectSubnet       subnetSelector
+		expectedSubnets    map[string]string
+		expectedSelections []string
+		expectedReason     string
+	}{
+		{
+			name: "one subnet per zone",
+			subnets: []cloudSubnet{
+				{id: "subnet-a", zone: "zone1"},
+				{id: "subnet-b", zone: "zone2"},
+			},
+			expectedSubnets: map[string]string{"zone1": "subnet-a", "zone2": "subnet-b"},
+		},
+		{
+			name: "duplicate subnets",
+			subnets: []cloudSubnet{
+				{id: "subnet-a", zone: "zone1"},
+				{id: "subnet-a", zone: "zone1"},
+			},
+			expectedSubnets: map[string]string{"zone1": "subnet-a"},
+		},
+		{
+			name: "conflicting subnets",
+			subnets: []cloudSubnet{
+				{id: "subnet-b", zone: "zone1"},
+				{id: "subnet-a", zone: "zone1"},
+				{id: "subnet-c", zone: "zone2"},
+			},
+			expectedReason: "MoreThanOneSubnetForZone",
+		},
+		{
+			name: "selected subnet",
+			subnets: []cloudSubnet{
+				{id: "subnet-b", zone: "zone1"},
+				{id: "subnet-a", zone: "zone1"},
+				{id: "subnet-c", zone: "zone2"},
+			},
+			selectSubnet: func(zone string, candidates []cloudSubnet) string {
+				return candidates[len(candidates)-1].id
+			},
+			expectedSubnets:    map[string]string{"zone1": "subnet-b", "zone2": "subnet-c"},
+			expectedSelections: []string{"zone1: subnet-b (of subnet-a, subnet-b)"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pool := &hivev1.MachinePool{}
+			subnets, selections, err := mapSubnetsToZones(pool, test.subnets, test.selectSubnet)
+			assertInvalidSubnets(t, pool, err, test.expectedReason)
+			assert.Equal(t, test.expectedSubnets, subnets, "unexpected subnets")
+			assert.Equal(t, test.expectedSelections, selections, "unexpected selections")
+		})
+	}
+}
+
+func TestMapFailureDomainSubnets(t *testing.T) {
+	lister := fakeSubnetLister{"subnet-a": "zone1", "subnet-b": "zone2"}
+	tests := []struct {
+		name            string
+		domains         []cloudSubnet
+		expectedZones   []string
+		expectedSubnets map[string]string
+		expectedReason  string
+	}{
+		{
+			name: "subnets in their zones",
+			domains: []cloudSubnet{
+				{id: "subnet-b", zone: "zone2"},
+				{id: "subnet-a", zone: "zone1"},
+			},
+			expectedZones:   []string{"zone2", "zone1"},
+			expectedSubnets: map[string]string{"zone1": "subnet-a", "zone2": "subnet-b"},
+		},
+		{
+			name: "subnet in another zone",
+			domains: []cloudSubnet{
+				{id: "subnet-a", zone: "zone1"},
+				{id: "subnet-b", zone: "zone1"},
+			},
+			expectedReason: "SubnetsNotInFailureDomainZones",
+		},
+		{
+			name:           "subnet not found",
+			domains:        []cloudSubnet{{id: "subnet-c", zone: "zone1"}},
+			expectedReason: "SubnetsNotFound",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pool := &hivev1.MachinePool{}
+			zones, subnets, err := mapFailureDomainSubnets(lister, pool, test.domains)
+			assertInvalidSubnets(t, pool, err, test.expectedReason)
+			assert.Equal(t, test.expectedZones, zones, "unexpected zones")
+			assert.Equal(t, test.expectedSubnets, subnets, "unexpected subnets")
+		})
+	}
+}
+
+func TestCheckSubnetsForZones(t *testing.T) {
+	subnets := map[string]string{"zone1": "subnet-a"}
+
+	pool := &hivev1.MachinePool{}
+	assertInvalidSubnets(t, pool, checkSubnetsForZones(pool, []string{"zone1"}, subnets), "")
+
+	pool = &hivev1.MachinePool{}
+	err := checkSubnetsForZones(pool, []string{"zone1", "zone2"}, subnets)
+	assertInvalidSubnets(t, pool, err, "NoSubnetForAvailabilityZone")
+	assert.Contains(t, err.Error(), "zone2", "missing zone in error")
+}
+
+// assertInvalidSubnets asserts that err is the ValidationError with the reason, which is also that of the
+// InvalidSubnets condition of the pool, or that there is neither when the reason is empty.
+func assertInvalidSubnets(t *testing.T, pool *hivev1.MachinePool, err error, reason string) {
+	cond := controllerutils.FindMachinePoolCondition(pool.Status.Conditions, hivev1.InvalidSubnetsMachinePoolCondition)
+	if reason == "" {
+		assert.NoError(t, err, "unexpected error")
+		assert.Nil(t, cond, "unexpected InvalidSubnets condition")
+		return
+	}
+	require.Error(t, err, "expected error")
+	validationErr, ok := err.(*ValidationError)
+	if assert.True(t, ok, "expected ValidationError") {
+		assert.Equal(t, reason, validationErr.Reason, "unexpected error reason")
+	}
+	if assert.NotNil(t, cond, "missing InvalidSubnets condition") {
+		assert.Equal(t, corev1.ConditionTrue, cond.Status, "unexpected condition status")
+		assert.Equal(t, reason, cond.Reason, "unexpected condition reason")
+	}
+}
@@ -0,0 +1,231 @@
+package machinepool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/hive/pkg/constants"
+	controllerutils "github.com/openshift/hive/pkg/controller/utils"
+)
+
+// versionGatedFeature is a feature of MachinePools which is only available on clusters of recent enough versions.
+type versionGatedFeature struct {
+	// description names the feature in the VersionGatedFeaturesUnavailable condition.
+	description string
+	// platform is the platform, as returned by clusterPlatform, whose MachinePools have the feature, or empty when
+	// the MachinePools of all platforms have it.
+	platform string
+	// minVersion is the first version of the clusters supporting the feature.
+	minVersion string
+	// versions is the range of versions of the clusters supporting the feature, which is used to gate it.
+	versions semver.Range
+	// configuredVersions is the configured range of versions of the clusters supporting the feature, as a string, when
+	// it replaces the default range starting at minVersion.
+	configuredVersions string
+	// bypassAnnotation is the annotation of the MachinePools which, when "true", enables the feature regardless of the
+	// version of the cluster, or empty when the gate of the feature cannot be bypassed.
+	bypassAnnotation string
+	// capability is the name of the feature in the capabilities reported by the actuators, or empty when it is not
+	// reported.
+	capability hivev1.MachinePoolCapabilityName
+}
+
+var (
+	versionsSupportingOpenStackScaleToZero = semver.MustParseRange(">=4.7.0")
+
+	// versionGatedFeatures are the features gated by the version of the cluster, which the
+	// VersionGatedFeaturesUnavailable condition reports when the cluster is too old for them.
+	versionGatedFeatures = []versionGatedFeature{
+		{
+			description:      "spot instances",
+			platform:         constants.PlatformAWS,
+			minVersion:       "4.5.0",
+			versions:         versionsSupportingSpotInstances,
+			capability:       hivev1.SpotInstancesCapability,
+			bypassAnnotation: hivev1.MachinePoolBypassSpotInstancesVersionGateAnnotation,
+		},
+		{
+			description: "machine names with the full name of the pool rather than a MachinePoolNameLease",
+			platform:    constants.PlatformGCP,
+			minVersion:  "4.4.7",
+			versions:    versionsSupportingFullNames,
+		},
+		{
+			description: "auto-scaling with zero minimum replicas",
+			platform:    constants.PlatformOpenStack,
+			minVersion:  "4.7.0",
+			versions:    versionsSupportingOpenStackScaleToZero,
+			capability:  hivev1.ScaleToZeroCapability,
+		},
+		{
+			description: "Ignition spec 3 for merged user data",
+			minVersion:  "4.6.0",
+			versions:    versionsSupportingIgnitionV3,
+			capability:  hivev1.IgnitionV3UserDataCapability,
+		},
+	}
+)
+
+// getVersionGatedFeatures returns the version-gated features, with the range of versions of the clusters supporting
+// spot instances configured by the MACHINEPOOL_AWS_SPOT_INSTANCES_VERSIONS environment variable, if any.
+func getVersionGatedFeatures() ([]versionGatedFeature, error) {
+	value := os.Getenv(constants.MachinePoolAWSSpotInstancesVersionsEnvVar)
+	if value == "" {
+		return versionGatedFeatures, nil
+	}
+	versions, err := semver.ParseRange(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", constants.MachinePoolAWSSpotInstancesVersionsEnvVar)
+	}
+	features := make([]versionGatedFeature, len(versionGatedFeatures))
+	copy(features, versionGatedFeatures)
+	for i := range features {
+		if features[i].capability == hivev1.SpotInstancesCapability {
+			features[i].versions, features[i].configuredVersions = versions, value
+		}
+	}
+	return features, nil
+}
+
+// orDefaultVersionGatedFeatures returns the given version-gated features, or the default ones when none are given.
+func orDefaultVersionGatedFeatures(features []versionGatedFeature) []versionGatedFeature {
+	if features == nil {
+		return versionGatedFeatures
+	}
+	return features
+}
+
+// findVersionGatedFeature returns the version-gated feature of the given capability, which must be one of the given
+// features.
+func findVersionGatedFeature(features []versionGatedFeature, capability hivev1.MachinePoolCapabilityName) versionGatedFeature {
+	for _, feature := range orDefaultVersionGatedFeatures(features) {
+		if feature.capability == capability {
+			return feature
+		}
+	}
+	panic(fmt.Sprintf("no version-gated feature for capability %s", capability))
+}
+
+// requiredVersions describes the versions of the clusters supporting the feature, e.g. "from 4.5.0".
+func (f versionGatedFeature) requiredVersions() string {
+	if f.configuredVersions != "" {
+		return fmt.Sprintf("on versions %s", f.configuredVersions)
+	}
+	return fmt.Sprintf("from %s", f.minVersion)
+}
+
+// isBypassed returns whether the MachinePool bypasses the gate of the feature with its bypass annotation.
+func (f versionGatedFeature) isBypassed(pool *hivev1.MachinePool) bool {
+	if f.bypassAnnotation == "" {
+		return false
+	}
+	bypass, err := strconv.ParseBool(pool.Annotations[f.bypassAnnotation])
+	return err == nil && bypass
+}
+
+// setVersionGatedFeaturesCondition sets the VersionGatedFeaturesUnavailable condition of the MachinePool to list the
+// features which are disabled for the version of its cluster, updating the status of the pool when it changes. The
+// features are gated where they are used; the condition only gathers them in one place.
+func (r *ReconcileMachinePool) setVersionGatedFeaturesCondition(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, logger log.FieldLogger) error {
+	status, reason, message := versionGatedFeaturesCondition(r.versionGatedFeatures, cd, pool)
+	conds, changed := controllerutils.SetMachinePoolConditionWithChangeCheck(
+		pool.Status.Conditions,
+		hivev1.VersionGatedFeaturesUnavailableMachinePoolCondition,
+		status,
+		reason,
+		message,
+		controllerutils.UpdateConditionIfReasonOrMessageChange,
+	)
+	if !changed {
+		return nil
+	}
+	logger.WithField("reason", reason).Info("version-gated features of machine pool changed")
+	pool.Status.Conditions = conds
+	return errors.Wrap(r.Status().Update(context.Background(), pool), "could not update MachinePool status")
+}
+
+// versionGatedFeaturesCondition returns the status, reason and message of the VersionGatedFeaturesUnavailable
+// condition of the MachinePool for the given version-gated features, or the default ones when none are given. When the
+// version of the cluster cannot be determined, all of the version-gated features of its platform are disabled, and
+// listed as such. Features whose gate the MachinePool bypasses are never listed.
+func versionGatedFeaturesCondition(features []versionGatedFeature, cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool) (corev1.ConditionStatus, string, string) {
+	clusterVersion, version, versionErr := gatingVersion(cd, pool)
+	platform := clusterPlatform(cd)
+	var unavailable []string
+	for _, feature := range orDefaultVersionGatedFeatures(features) {
+		if (feature.platform == "" || feature.platform == platform) && !feature.isBypassed(pool) && (versionErr != nil || !feature.versions(version)) {
+			unavailable = append(unavailable, fmt.Sprintf("%s (%s)", feature.description, feature.requiredVersions()))
+		}
+	}
+	if versionErr != nil {
+		return corev1.ConditionTrue, "ClusterVersionUnknown",
+			fmt.Sprintf("Features disabled until the version of the cluster is known (%v): %s", versionErr, strings.Join(unavailable, "; "))
+	}
+	if len(unavailable) == 0 {
+		return corev1.ConditionFalse, "AllFeaturesAvailable",
+			fmt.Sprintf("All version-gated features are available on cluster version %s", clusterVersion)
+	}
+	return corev1.ConditionTrue, "ClusterVersionTooOld",
+		fmt.Sprintf("Features unavailable on cluster version %s until it is upgraded: %s", clusterVersion, strings.Join(unavailable, "; "))
+}
+
+// gatingVersion returns the version of the cluster, and the version compared with the ranges of versions of the
+// version-gated features.
+func gatingVersion(cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool) (string, semver.Version, error) {
+	clusterVersion, err := getClusterVersion(cd, pool)
+	if err != nil {
+		return "", semver.Version{}, errors.Wrap(err, "could not determine the version of the cluster")
+	}
+	version, err := semver.ParseTolerant(clusterVersion)
+	if err != nil {
+		return "", semver.Version{}, errors.Wrap(err, "could not parse the version of the cluster")
+	}
+	// Use only major, minor, and patch so that pre-release versions are within ranges such as >=4.5.0.
+	return clusterVersion, semver.Version{Major: version.Major, Minor: version.Minor, Patch: version.Patch}, nil
+}
+
+// versionGatedCapability returns the capability of the given name of MachinePools on the platform of the cluster,
+// which is supported when the version of the cluster is in the range of versions of its feature among the given
+// version-gated features, or the default ones when none are given, or when the MachinePool bypasses the gate of the
+// feature.
+func versionGatedCapability(features []versionGatedFeature, cd *hivev1.ClusterDeployment, pool *hivev1.MachinePool, name hivev1.MachinePoolCapabilityName) hivev1.MachinePoolCapability {
+	platform := clusterPlatform(cd)
+	for _, feature := range orDefaultVersionGatedFeatures(features) {
+		if feature.capability != name || (feature.platform != "" && feature.platform != platform) {
+			continue
+		}
+		if feature.isBypassed(pool) {
+			return hivev1.MachinePoolCapability{
+				Name:      name,
+				Supported: true,
+				Message:   fmt.Sprintf("The version gate is bypassed by the %s annotation", feature.bypassAnnotation),
+			}
+		}
+		clusterVersion, version, err := gatingVersion(cd, pool)
+		if err != nil {
+			return hivev1.MachinePoolCapability{Name: name, Message: err.Error()}
+		}
+		if !feature.versions(version) {
+			required := fmt.Sprintf("cluster version %s or later", feature.minVersion)
+			if feature.configuredVersions != "" {
+				required = fmt.Sprintf("a cluster version in the range %s", feature.configuredVersions)
+			}
+			return hivev1.MachinePoolCapability{
+				Name:    name,
+				Message: fmt.Sprintf("Requires %s; the cluster is at version %s", required, clusterVersion),
+			}
+		}
+		return hivev1.MachinePoolCapability{Name: name, Supported: true}
+	}
+	return hivev1.MachinePoolCapability{Name: name, Supported: true}
+}
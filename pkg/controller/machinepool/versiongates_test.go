@@ -0,0 +1,266 @@
+package machinepool
+
+import (
+	"os"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hivev1gcp "github.com/openshift/hive/apis/hive/v1/gcp"
+	"github.com/openshift/hive/pkg/constants"
+)
+
+func TestVersionGatedFeatures(t *testing.T) {
+	for _, feature := range versionGatedFeatures {
+		minVersion := semver.MustParse(feature.minVersion)
+		assert.True(t, feature.versions(minVersion), "%s: range does not include %s", feature.description, feature.minVersion)
+	}
+}
+
+func Test_versionGatedFeaturesCondition(t *testing.T) {
+	cases := []struct {
+		name            string
+		version         string
+		gcp             bool
+		override        string
+		bypassSpot      bool
+		spotVersions    string
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "old AWS cluster",
+			version:        "4.4.0",
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "ClusterVersionTooOld",
+			expectedMessage: "Features unavailable on cluster version 4.4.0 until it is upgraded: spot instances (from 4.5.0); " +
+				"Ignition spec 3 for merged user data (from 4.6.0)",
+		},
+		{
+			name:            "pre-release AWS cluster",
+			version:         "4.5.0-rc.1",
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "ClusterVersionTooOld",
+			expectedMessage: "Features unavailable on cluster version 4.5.0-rc.1 until it is upgraded: Ignition spec 3 for merged user data (from 4.6.0)",
+		},
+		{
+			name:            "recent AWS cluster",
+			version:         "4.12.3",
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  "AllFeaturesAvailable",
+			expectedMessage: "All version-gated features are available on cluster version 4.12.3",
+		},
+		{
+			name:           "old GCP cluster",
+			version:        "4.4.0",
+			gcp:            true,
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "ClusterVersionTooOld",
+			expectedMessage: "Features unavailable on cluster version 4.4.0 until it is upgraded: " +
+				"machine names with the full name of the pool rather than a MachinePoolNameLease (from 4.4.7); " +
+				"Ignition spec 3 for merged user data (from 4.6.0)",
+		},
+		{
+			name:            "version override",
+			version:         "4.4.0",
+			override:        "4.6.0",
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  "AllFeaturesAvailable",
+			expectedMessage: "All version-gated features are available on cluster version 4.6.0",
+		},
+		{
+			name:            "spot instances gate bypassed",
+			version:         "4.4.0",
+			bypassSpot:      true,
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  "ClusterVersionTooOld",
+			expectedMessage: "Features unavailable on cluster version 4.4.0 until it is upgraded: Ignition spec 3 for merged user data (from 4.6.0)",
+		},
+		{
+			name:           "configured spot instances versions",
+			version:        "4.5.0",
+			spotVersions:   ">=4.10.0",
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "ClusterVersionTooOld",
+			expectedMessage: "Features unavailable on cluster version 4.5.0 until it is upgraded: spot instances (on versions >=4.10.0); " +
+				"Ignition spec 3 for merged user data (from 4.6.0)",
+		},
+		{
+			name:           "unknown version",
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: "ClusterVersionUnknown",
+			expectedMessage: "Features disabled until the version of the cluster is known (could not determine the version of the cluster: " +
+				"cluster version not set in clusterdeployment): spot instances (from 4.5.0); Ignition spec 3 for merged user data (from 4.6.0)",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cd := testClusterDeployment()
+			delete(cd.Labels, constants.VersionMajorMinorPatchLabel)
+			if tc.version != "" {
+				cd.Labels[constants.VersionMajorMinorPatchLabel] = tc.version
+			}
+			if tc.gcp {
+				cd.Spec.Platform = hivev1.Platform{GCP: &hivev1gcp.Platform{}}
+			}
+			pool := testMachinePool()
+			pool.Annotations = map[string]string{}
+			if tc.override != "" {
+				pool.Annotations[hivev1.MachinePoolClusterVersionOverrideAnnotation] = tc.override
+			}
+			if tc.bypassSpot {
+				pool.Annotations[hivev1.MachinePoolBypassSpotInstancesVersionGateAnnotation] = "true"
+			}
+			features := withSpotInstancesVersions(t, tc.spotVersions)
+
+			status, reason, message := versionGatedFeaturesCondition(features, cd, pool)
+			assert.Equal(t, tc.expectedStatus, status, "unexpected condition status")
+			assert.Equal(t, tc.expectedReason, reason, "unexpected condition reason")
+			if tc.expectedMessage != "" {
+				assert.Equal(t, tc.expectedMessage, message, "unexpected condition message")
+			}
+		})
+	}
+}
+
+func Test_versionGatedCapability(t *testing.T) {
+	cases := []struct {
+		name              string
+		version           string
+		capability        hivev1.MachinePoolCapabilityName
+		bypassSpot        bool
+		spotVersions      string
+		gcp               bool
+		expectedSupported bool
+		expectedMessage   string
+	}{
+		{
+			name:            "old cluster",
+			version:         "4.4.0",
+			capability:      hivev1.SpotInstancesCapability,
+			expectedMessage: "Requires cluster version 4.5.0 or later; the cluster is at version 4.4.0",
+		},
+		{
+			name:              "recent cluster",
+			version:           "4.5.0-rc.1",
+			capability:        hivev1.SpotInstancesCapability,
+			expectedSupported: true,
+		},
+		{
+			name:              "old cluster bypassing gate",
+			version:           "4.4.0",
+			capability:        hivev1.SpotInstancesCapability,
+			bypassSpot:        true,
+			expectedSupported: true,
+			expectedMessage:   "The version gate is bypassed by the hive.openshift.io/bypass-spot-instances-version-gate annotation",
+		},
+		{
+			name:            "configured versions",
+			version:         "4.5.0",
+			capability:      hivev1.SpotInstancesCapability,
+			spotVersions:    ">=4.10.0",
+			expectedMessage: "Requires a cluster version in the range >=4.10.0; the cluster is at version 4.5.0",
+		},
+		{
+			name:              "custom build in configured versions",
+			version:           "4.4.0-custom.1",
+			capability:        hivev1.SpotInstancesCapability,
+			spotVersions:      ">=4.4.0",
+			expectedSupported: true,
+		},
+		{
+			name:            "unknown version",
+			capability:      hivev1.IgnitionV3UserDataCapability,
+			expectedMessage: "could not determine the version of the cluster: cluster version not set in clusterdeployment",
+		},
+		{
+			name:              "feature gated on another platform",
+			version:           "4.4.0",
+			capability:        hivev1.ScaleToZeroCapability,
+			expectedSupported: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cd := testClusterDeployment()
+			delete(cd.Labels, constants.VersionMajorMinorPatchLabel)
+			if tc.version != "" {
+				cd.Labels[constants.VersionMajorMinorPatchLabel] = tc.version
+			}
+			pool := testMachinePool()
+			if tc.bypassSpot {
+				pool.Annotations = map[string]string{hivev1.MachinePoolBypassSpotInstancesVersionGateAnnotation: "true"}
+			}
+			capability := versionGatedCapability(withSpotInstancesVersions(t, tc.spotVersions), cd, pool, tc.capability)
+			assert.Equal(t, tc.capability, capability.Name, "unexpected capability name")
+			assert.Equal(t, tc.expectedSupported, capability.Supported, "unexpected support of capability")
+			assert.Equal(t, tc.expectedMessage, capability.Message, "unexpected capability message")
+		})
+	}
+}
+
+func Test_getVersionGatedFeatures(t *testing.T) {
+	cases := []struct {
+		name                 string
+		value                string
+		expectErr            bool
+		expectedSupported    string
+		expectedNotSupported string
+	}{
+		{
+			name:                 "default",
+			expectedSupported:    "4.5.0",
+			expectedNotSupported: "4.4.0",
+		},
+		{
+			name:                 "configured range",
+			value:                ">=4.4.0 <5.0.0",
+			expectedSupported:    "4.4.0",
+			expectedNotSupported: "5.0.0",
+		},
+		{
+			name:      "invalid range",
+			value:     "4.x.y.z",
+			expectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				os.Setenv(constants.MachinePoolAWSSpotInstancesVersionsEnvVar, tc.value)
+				defer os.Unsetenv(constants.MachinePoolAWSSpotInstancesVersionsEnvVar)
+			}
+			features, err := getVersionGatedFeatures()
+			if tc.expectErr {
+				assert.Error(t, err, "expected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			spotInstances := findVersionGatedFeature(features, hivev1.SpotInstancesCapability)
+			assert.True(t, spotInstances.versions(semver.MustParse(tc.expectedSupported)), "spot instances not supported on %s", tc.expectedSupported)
+			assert.False(t, spotInstances.versions(semver.MustParse(tc.expectedNotSupported)), "spot instances supported on %s", tc.expectedNotSupported)
+			ignitionV3 := findVersionGatedFeature(features, hivev1.IgnitionV3UserDataCapability)
+			assert.Equal(t, "4.6.0", ignitionV3.minVersion, "unexpected change of other features")
+		})
+	}
+	assert.Empty(t, findVersionGatedFeature(versionGatedFeatures, hivev1.SpotInstancesCapability).configuredVersions, "default features modified")
+}
+
+// withSpotInstancesVersions returns the version-gated features with the given range of versions supporting spot
+// instances, or nil for the default features when it is empty.
+func withSpotInstancesVersions(t *testing.T, versions string) []versionGatedFeature {
+	if versions == "" {
+		return nil
+	}
+	os.Setenv(constants.MachinePoolAWSSpotInstancesVersionsEnvVar, versions)
+	defer os.Unsetenv(constants.MachinePoolAWSSpotInstancesVersionsEnvVar)
+	features, err := getVersionGatedFeatures()
+	require.NoError(t, err, "unexpected error configuring spot instances versions")
+	return features
+}
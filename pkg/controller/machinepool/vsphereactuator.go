@@ -1,6 +1,7 @@
 package machinepool
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pkg/errors"
@@ -8,6 +9,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	installvsphere "github.com/openshift/installer/pkg/asset/machines/vsphere"
 	installertypes "github.com/openshift/installer/pkg/types"
@@ -17,13 +19,20 @@ import (
 	vsphereproviderv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/vsphereprovider/v1beta1"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/hive/pkg/constants"
 )
 
 // VSphereActuator encapsulates the pieces necessary to be able to generate
 // a list of MachineSets to sync to the remote cluster
 type VSphereActuator struct {
+	noopValidator
+	noopCleaner
+	noCapabilities
+
 	logger  log.FieldLogger
 	osImage string
+	// osImageSource is where osImage comes from.
+	osImageSource hivev1.BootImageSource
 }
 
 var _ Actuator = &VSphereActuator{}
@@ -32,16 +41,37 @@ func addVSphereProviderToScheme(scheme *runtime.Scheme) error {
 	return vsphereprovider.AddToScheme(scheme)
 }
 
+func init() {
+	registerActuator(constants.PlatformVSphere, newVSphereActuatorForCluster)
+}
+
+// newVSphereActuatorForCluster is the registered actuatorConstructor of vSphere.
+func newVSphereActuatorForCluster(
+	ctx context.Context,
+	r *ReconcileMachinePool,
+	cd *hivev1.ClusterDeployment,
+	pool *hivev1.MachinePool,
+	masterMachine *machineapi.Machine,
+	remoteMachineSets []machineapi.MachineSet,
+	remoteClusterAPIClient client.Client,
+	logger log.FieldLogger,
+) (Actuator, error) {
+	return NewVSphereActuator(pool, masterMachine, r.scheme, logger)
+}
+
 // NewVSphereActuator is the constructor for building a VSphereActuator
-func NewVSphereActuator(masterMachine *machineapi.Machine, scheme *runtime.Scheme, logger log.FieldLogger) (*VSphereActuator, error) {
-	osImage, err := getVSphereOSImage(masterMachine, scheme, logger)
+func NewVSphereActuator(pool *hivev1.MachinePool, masterMachine *machineapi.Machine, scheme *runtime.Scheme, logger log.FieldLogger) (*VSphereActuator, error) {
+	osImage, osImageSource, err := poolBootImage(pool, func() (string, error) {
+		return getVSphereOSImage(masterMachine, scheme, logger)
+	})
 	if err != nil {
 		logger.WithError(err).Error("error getting os image from master machine")
 		return nil, err
 	}
 	actuator := &VSphereActuator{
-		logger:  logger,
-		osImage: osImage,
+		logger:        logger,
+		osImage:       osImage,
+		osImageSource: osImageSource,
 	}
 	return actuator, nil
 }
@@ -91,12 +121,13 @@ func (a *VSphereActuator) GenerateMachineSets(cd *hivev1.ClusterDeployment, pool
 		computePool,
 		a.osImage,
 		workerRole,
-		workerUserDataName,
+		workerUserData(pool),
 	)
 	if err != nil {
 		return nil, false, errors.Wrap(err, "failed to generate machinesets")
 	}
 
+	setBootImageStatus(pool, a.osImage, a.osImageSource)
 	return installerMachineSets, true, nil
 }
 
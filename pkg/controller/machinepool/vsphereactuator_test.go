@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
 
 	machineapi "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 	vsphereprovider "github.com/openshift/machine-api-operator/pkg/apis/vsphereprovider/v1beta1"
@@ -58,6 +59,24 @@ func TestVSphereActuator(t *testing.T) {
 	}
 }
 
+func TestVSphereActuatorBootImage(t *testing.T) {
+	pool := testVSpherePool()
+	pool.Spec.BootImage = "rhcos-template"
+	// The master machine is not needed when the pool sets its boot image.
+	actuator, err := NewVSphereActuator(pool, nil, scheme.Scheme, log.WithField("actuator", "vsphereactuator_test"))
+	require.NoError(t, err, "unexpected error creating actuator")
+
+	generatedMachineSets, _, err := actuator.GenerateMachineSets(testVSphereClusterDeployment(), pool, actuator.logger)
+	require.NoError(t, err, "unexpected error generating machinesets")
+	for _, ms := range generatedMachineSets {
+		vsphereProvider, ok := ms.Spec.Template.Spec.ProviderSpec.Value.Object.(*vsphereprovider.VSphereMachineProviderSpec)
+		if assert.True(t, ok, "failed to convert to vsphere provider spec") {
+			assert.Equal(t, "rhcos-template", vsphereProvider.Template, "unexpected template")
+		}
+	}
+	assert.Equal(t, &hivev1.BootImageStatus{Image: "rhcos-template", Source: hivev1.SpecBootImageSource}, pool.Status.BootImage, "unexpected boot image status")
+}
+
 func validateVSphereMachineSets(t *testing.T, mSets []*machineapi.MachineSet, expectedMSReplicas map[string]int64) {
 	assert.Equal(t, len(expectedMSReplicas), len(mSets), "different number of machine sets generated than expected")
 
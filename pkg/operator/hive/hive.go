@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/md5"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
@@ -138,6 +139,79 @@ func (r *ReconcileHiveConfig) deployHive(hLog log.FieldLogger, h resource.Helper
 		hiveContainer.Env = append(hiveContainer.Env, syncsetReapplyIntervalEnvVar)
 	}
 
+	if mpConfig := instance.Spec.MachinePoolConfig; mpConfig != nil {
+		if len(mpConfig.AWSAMISources) > 0 {
+			sources := make([]string, len(mpConfig.AWSAMISources))
+			for i, source := range mpConfig.AWSAMISources {
+				sources[i] = string(source)
+			}
+			hiveContainer.Env = append(hiveContainer.Env, corev1.EnvVar{
+				Name:  constants.MachinePoolAWSAMISourcesEnvVar,
+				Value: strings.Join(sources, ","),
+			})
+		}
+		if mpConfig.AWSAPIRateLimit != nil {
+			hiveContainer.Env = append(hiveContainer.Env, corev1.EnvVar{
+				Name:  constants.MachinePoolAWSAPIQPSEnvVar,
+				Value: strconv.Itoa(int(mpConfig.AWSAPIRateLimit.QPS)),
+			})
+			if burst := mpConfig.AWSAPIRateLimit.Burst; burst > 0 {
+				hiveContainer.Env = append(hiveContainer.Env, corev1.EnvVar{
+					Name:  constants.MachinePoolAWSAPIBurstEnvVar,
+					Value: strconv.Itoa(int(burst)),
+				})
+			}
+		}
+		if mpConfig.AWSQuotaCheck {
+			hiveContainer.Env = append(hiveContainer.Env, corev1.EnvVar{
+				Name:  constants.MachinePoolAWSQuotaCheckEnvVar,
+				Value: "true",
+			})
+		}
+		if mpConfig.AWSSubnetIPCheck {
+			hiveContainer.Env = append(hiveContainer.Env, corev1.EnvVar{
+				Name:  constants.MachinePoolAWSSubnetIPCheckEnvVar,
+				Value: "true",
+			})
+		}
+		if retry := mpConfig.AWSRetry; retry != nil {
+			if retry.Retries != nil {
+				hiveContainer.Env = append(hiveContainer.Env, corev1.EnvVar{
+					Name:  constants.MachinePoolAWSRetriesEnvVar,
+					Value: strconv.Itoa(int(*retry.Retries)),
+				})
+			}
+			if retry.Interval != nil {
+				hiveContainer.Env = append(hiveContainer.Env, corev1.EnvVar{
+					Name:  constants.MachinePoolAWSRetryIntervalEnvVar,
+					Value: retry.Interval.Duration.String(),
+				})
+			}
+		}
+		if versions := mpConfig.AWSSpotInstancesVersions; versions != "" {
+			hiveContainer.Env = append(hiveContainer.Env, corev1.EnvVar{
+				Name:  constants.MachinePoolAWSSpotInstancesVersionsEnvVar,
+				Value: versions,
+			})
+		}
+		if interval := mpConfig.ConfigurationErrorRequeueInterval; interval != nil {
+			hiveContainer.Env = append(hiveContainer.Env, corev1.EnvVar{
+				Name:  constants.MachinePoolConfigurationErrorRequeueIntervalEnvVar,
+				Value: interval.Duration.String(),
+			})
+		}
+		if len(mpConfig.AdditionalTags) > 0 {
+			tags, err := json.Marshal(mpConfig.AdditionalTags)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal machinepool additional tags")
+			}
+			hiveContainer.Env = append(hiveContainer.Env, corev1.EnvVar{
+				Name:  constants.MachinePoolAdditionalTagsEnvVar,
+				Value: string(tags),
+			})
+		}
+	}
+
 	addManagedDomainsVolume(&hiveDeployment.Spec.Template.Spec, mdConfigMap.Name)
 	addAWSPrivateLinkConfigVolume(&hiveDeployment.Spec.Template.Spec)
 
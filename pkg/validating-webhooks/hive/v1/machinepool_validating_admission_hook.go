@@ -3,7 +3,12 @@ package v1
 import (
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	log "github.com/sirupsen/logrus"
 
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
@@ -13,6 +18,8 @@ import (
 	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -36,6 +43,10 @@ const (
 	legacyWorkerPoolName  = "w"
 )
 
+// kmsKeyAliasRegex matches the aliases of KMS keys, which may be set instead of the ARN of the volume KMS keys of
+// an AWS MachinePool.
+var kmsKeyAliasRegex = regexp.MustCompile(`^alias/[a-zA-Z0-9/_-]+$`)
+
 // MachinePoolValidatingAdmissionHook is a struct that is used to reference what code should be run by the generic-admission-server.
 type MachinePoolValidatingAdmissionHook struct {
 	decoder *admission.Decoder
@@ -267,6 +278,8 @@ func validateMachinePoolSpecInvariants(spec *hivev1.MachinePoolSpec, fldPath *fi
 	platformPath := fldPath.Child("platform")
 	platforms := []string{}
 	numberOfMachineSets := 0
+	// zones are the availability zones listed for platforms with zones, which is nil for platforms without zones
+	var zones []string
 
 	// set validZeroSizeAutoscalingMinReplicas to true for any platform where a zero-size minReplicas is allowed with autoscaling
 	validZeroSizeAutoscalingMinReplicas := false
@@ -274,19 +287,26 @@ func validateMachinePoolSpecInvariants(spec *hivev1.MachinePoolSpec, fldPath *fi
 	if p := spec.Platform.AWS; p != nil {
 		platforms = append(platforms, "aws")
 		allErrs = append(allErrs, validateAWSMachinePoolPlatformInvariants(p, platformPath.Child("aws"))...)
-		numberOfMachineSets = len(p.Zones)
+		numberOfMachineSets = len(p.Zones) + len(p.ZoneIDs) + len(p.FailureDomains)
+		// The names of zones listed by ID are only known once resolved in the account of the cluster.
+		zones = append([]string{}, p.Zones...)
+		for _, domain := range p.FailureDomains {
+			zones = append(zones, domain.Zone)
+		}
 		validZeroSizeAutoscalingMinReplicas = true
 	}
 	if p := spec.Platform.Azure; p != nil {
 		platforms = append(platforms, "azure")
 		allErrs = append(allErrs, validateAzureMachinePoolPlatformInvariants(p, platformPath.Child("azure"))...)
 		numberOfMachineSets = len(p.Zones)
+		zones = append([]string{}, p.Zones...)
 		validZeroSizeAutoscalingMinReplicas = true
 	}
 	if p := spec.Platform.GCP; p != nil {
 		platforms = append(platforms, "gcp")
 		allErrs = append(allErrs, validateGCPMachinePoolPlatformInvariants(p, platformPath.Child("gcp"))...)
 		numberOfMachineSets = len(p.Zones)
+		zones = append([]string{}, p.Zones...)
 		validZeroSizeAutoscalingMinReplicas = true
 	}
 	if p := spec.Platform.OpenStack; p != nil {
@@ -325,11 +345,111 @@ func validateMachinePoolSpecInvariants(spec *hivev1.MachinePoolSpec, fldPath *fi
 		if spec.Autoscaling.MinReplicas > spec.Autoscaling.MaxReplicas {
 			allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("minReplicas"), spec.Autoscaling.MinReplicas, "minimum replicas must not be greater than maximum replicas"))
 		}
+		if len(spec.Autoscaling.ZoneReplicas) > 0 {
+			if zones == nil {
+				allErrs = append(allErrs, field.Forbidden(autoscalingPath.Child("zoneReplicas"), "zone replicas are only supported on platforms with availability zones"))
+			} else {
+				allErrs = append(allErrs, validateZoneReplicas(spec.Autoscaling, zones, autoscalingPath)...)
+			}
+		}
+	}
+	if spec.BootImage != "" && spec.Platform.Azure != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("bootImage"), "boot image is not supported on Azure"))
+	}
+	if ref := spec.MergeIgnitionSecretRef; ref != nil && ref.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("mergeIgnitionSecretRef", "name"), "must have the name of the merge ignition secret"))
+	}
+	if strategy := spec.UpdateStrategy; strategy != nil {
+		strategyPath := fldPath.Child("updateStrategy")
+		switch strategy.Type {
+		case hivev1.OnDeleteMachinePoolUpdateStrategyType, hivev1.ImmediateMachinePoolUpdateStrategyType:
+			if strategy.MaxUnavailable != nil {
+				allErrs = append(allErrs, field.Forbidden(strategyPath.Child("maxUnavailable"), "maxUnavailable is only supported with the Rolling update strategy"))
+			}
+		case hivev1.RollingMachinePoolUpdateStrategyType:
+			if strategy.MaxUnavailable != nil {
+				allErrs = append(allErrs, validateMaxUnavailable(*strategy.MaxUnavailable, strategyPath.Child("maxUnavailable"))...)
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(strategyPath.Child("type"), strategy.Type, []string{
+				string(hivev1.OnDeleteMachinePoolUpdateStrategyType),
+				string(hivev1.ImmediateMachinePoolUpdateStrategyType),
+				string(hivev1.RollingMachinePoolUpdateStrategyType),
+			}))
+		}
+	}
+	switch spec.DeletePolicy {
+	case "", hivev1.RandomMachinePoolDeletePolicy, hivev1.NewestMachinePoolDeletePolicy, hivev1.OldestMachinePoolDeletePolicy:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("deletePolicy"), spec.DeletePolicy, []string{
+			string(hivev1.RandomMachinePoolDeletePolicy),
+			string(hivev1.NewestMachinePoolDeletePolicy),
+			string(hivev1.OldestMachinePoolDeletePolicy),
+		}))
 	}
 	allErrs = append(allErrs, metavalidation.ValidateLabels(spec.Labels, fldPath.Child("labels"))...)
 	return allErrs
 }
 
+// validateMaxUnavailable validates the maxUnavailable of a Rolling update strategy, which is either a number of at
+// least 1 or a percentage from 1% to 100%.
+func validateMaxUnavailable(maxUnavailable intstr.IntOrString, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if maxUnavailable.Type == intstr.Int {
+		if maxUnavailable.IntVal < 1 {
+			allErrs = append(allErrs, field.Invalid(fldPath, maxUnavailable.IntVal, "maxUnavailable must be at least 1"))
+		}
+		return allErrs
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(maxUnavailable.StrVal, "%"))
+	if err != nil || !strings.HasSuffix(maxUnavailable.StrVal, "%") || percent < 1 || percent > 100 {
+		allErrs = append(allErrs, field.Invalid(fldPath, maxUnavailable.StrVal, "maxUnavailable must be a number or a percentage from 1% to 100%"))
+	}
+	return allErrs
+}
+
+// validateZoneReplicas validates the per-zone replica overrides of an auto-scaling machine pool against the bounds of
+// the pool. When the pool lists its zones, every override must be for one of them, and overrides for all of them must
+// add up to exactly the bounds of the pool.
+func validateZoneReplicas(autoscaling *hivev1.MachinePoolAutoscaling, zones []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	zoneReplicasPath := fldPath.Child("zoneReplicas")
+	listedZones := sets.NewString(zones...)
+	var minReplicas, maxReplicas int32
+	for _, zone := range sets.StringKeySet(autoscaling.ZoneReplicas).List() {
+		replicas := autoscaling.ZoneReplicas[zone]
+		zonePath := zoneReplicasPath.Key(zone)
+		if zone == "" {
+			allErrs = append(allErrs, field.Invalid(zoneReplicasPath, zone, "zone cannot be an empty string"))
+		} else if listedZones.Len() > 0 && !listedZones.Has(zone) {
+			allErrs = append(allErrs, field.Invalid(zoneReplicasPath, zone, "zone must be one of the zones of the machine pool"))
+		}
+		if replicas.MinReplicas < 0 {
+			allErrs = append(allErrs, field.Invalid(zonePath.Child("minReplicas"), replicas.MinReplicas, "minimum replicas must not be negative"))
+		}
+		if replicas.MinReplicas > replicas.MaxReplicas {
+			allErrs = append(allErrs, field.Invalid(zonePath.Child("minReplicas"), replicas.MinReplicas, "minimum replicas must not be greater than maximum replicas"))
+		}
+		minReplicas += replicas.MinReplicas
+		maxReplicas += replicas.MaxReplicas
+	}
+	if minReplicas > autoscaling.MinReplicas {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), autoscaling.MinReplicas, "minimum replicas must not be less than the minimum replicas of the zones"))
+	}
+	if maxReplicas > autoscaling.MaxReplicas {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReplicas"), autoscaling.MaxReplicas, "maximum replicas must not be less than the maximum replicas of the zones"))
+	}
+	if listedZones.Len() > 0 && listedZones.Equal(sets.StringKeySet(autoscaling.ZoneReplicas)) {
+		if minReplicas < autoscaling.MinReplicas {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), autoscaling.MinReplicas, "minimum replicas must equal the minimum replicas of the zones when every zone has zone replicas"))
+		}
+		if maxReplicas < autoscaling.MaxReplicas {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReplicas"), autoscaling.MaxReplicas, "maximum replicas must equal the maximum replicas of the zones when every zone has zone replicas"))
+		}
+	}
+	return allErrs
+}
+
 func validateAWSMachinePoolPlatformInvariants(platform *hivev1aws.MachinePoolPlatform, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	for i, zone := range platform.Zones {
@@ -337,8 +457,46 @@ func validateAWSMachinePoolPlatformInvariants(platform *hivev1aws.MachinePoolPla
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("zones").Index(i), zone, "zone cannot be an empty string"))
 		}
 	}
-	if platform.InstanceType == "" {
-		allErrs = append(allErrs, field.Required(fldPath.Child("instanceType"), "instance type is required"))
+	for i, zoneID := range platform.ZoneIDs {
+		if zoneID == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("zoneIDs").Index(i), zoneID, "zone ID cannot be an empty string"))
+		}
+	}
+	if len(platform.Zones) > 0 && len(platform.ZoneIDs) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("zoneIDs"), "zone IDs cannot be set together with zones"))
+	}
+	if len(platform.FailureDomains) > 0 {
+		allErrs = append(allErrs, validateAWSFailureDomains(platform, fldPath)...)
+	}
+	subnets := sets.NewString(platform.Subnets...)
+	for i, subnet := range platform.PrivateSubnets {
+		if !subnets.Has(subnet) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("privateSubnets").Index(i), subnet, "subnet is not one of the subnets of the pool"))
+		}
+	}
+	zones := sets.NewString(platform.Zones...)
+	for zone, instanceType := range platform.InstanceTypesByZone {
+		zonePath := fldPath.Child("instanceTypesByZone").Key(zone)
+		switch {
+		case zone == "":
+			allErrs = append(allErrs, field.Invalid(zonePath, zone, "zone cannot be an empty string"))
+		case len(platform.Zones) > 0 && !zones.Has(zone):
+			allErrs = append(allErrs, field.Invalid(zonePath, zone, "zone is not one of the zones of the pool"))
+		}
+		if instanceType == "" {
+			allErrs = append(allErrs, field.Required(zonePath, "instance type is required"))
+		}
+	}
+	if selector := platform.InstanceTypeSelector; platform.InstanceType == "" && selector == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("instanceType"), "instance type or instance type selector is required"))
+	} else if selector != nil {
+		selectorPath := fldPath.Child("instanceTypeSelector")
+		if selector.Family == "" {
+			allErrs = append(allErrs, field.Required(selectorPath.Child("family"), "instance family is required"))
+		}
+		if selector.Size == "" {
+			allErrs = append(allErrs, field.Required(selectorPath.Child("size"), "instance size is required"))
+		}
 	}
 	rootVolume := &platform.EC2RootVolume
 	rootVolumePath := fldPath.Child("ec2RootVolume")
@@ -351,6 +509,164 @@ func validateAWSMachinePoolPlatformInvariants(platform *hivev1aws.MachinePoolPla
 	if rootVolume.Type == "" {
 		allErrs = append(allErrs, field.Required(rootVolumePath.Child("type"), "volume type is required"))
 	}
+	if rootVolume.KMSKeyARN != "" && !arn.IsARN(rootVolume.KMSKeyARN) && !kmsKeyAliasRegex.MatchString(rootVolume.KMSKeyARN) {
+		allErrs = append(allErrs, field.Invalid(rootVolumePath.Child("kmsKeyARN"), rootVolume.KMSKeyARN, "must be the ARN of a KMS key or an alias such as alias/ebs-worker"))
+	}
+	if rootVolume.KMSKeyARN != "" && rootVolume.Encrypted != nil && !*rootVolume.Encrypted {
+		allErrs = append(allErrs, field.Invalid(rootVolumePath.Child("encrypted"), *rootVolume.Encrypted, "volume must be encrypted when a KMS key is set"))
+	}
+	allErrs = append(allErrs, validateAWSAdditionalBlockDevices(platform.AdditionalBlockDevices, fldPath.Child("additionalBlockDevices"))...)
+	if spot := platform.SpotMarketOptions; spot != nil && spot.InstanceInterruptionBehavior != "" &&
+		spot.InstanceInterruptionBehavior != ec2.InstanceInterruptionBehaviorTerminate {
+		// Machines use one-time spot requests, which are always terminated on interruption.
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("spotMarketOptions", "instanceInterruptionBehavior"), spot.InstanceInterruptionBehavior,
+			[]string{ec2.InstanceInterruptionBehaviorTerminate}))
+	}
+	marketTypePath := fldPath.Child("marketType")
+	switch platform.MarketType {
+	case "", hivev1aws.OnDemandMarketType, hivev1aws.SpotMarketType:
+	case hivev1aws.CapacityBlockMarketType:
+		allErrs = append(allErrs, field.Forbidden(marketTypePath, "capacity blocks are not supported by the machine API"))
+	default:
+		allErrs = append(allErrs, field.NotSupported(marketTypePath, platform.MarketType, []string{
+			string(hivev1aws.OnDemandMarketType),
+			string(hivev1aws.SpotMarketType),
+			string(hivev1aws.CapacityBlockMarketType),
+		}))
+	}
+	if platform.SpotMarketOptions != nil && platform.MarketType != "" && platform.MarketType != hivev1aws.SpotMarketType {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("spotMarketOptions"), "spot market options are only valid with the spot market type"))
+	}
+	switch platform.SpreadPolicy {
+	case "", hivev1aws.SpreadPolicyBestEffortEven, hivev1aws.SpreadPolicyStrictEven:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("spreadPolicy"), platform.SpreadPolicy, []string{
+			string(hivev1aws.SpreadPolicyBestEffortEven),
+			string(hivev1aws.SpreadPolicyStrictEven),
+		}))
+	}
+	if platform.SpotFallbackToOnDemand && platform.SpotMarketOptions == nil && platform.MarketType != hivev1aws.SpotMarketType {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("spotFallbackToOnDemand"), "falling back to on-demand instances is only valid for spot instances"))
+	}
+	if platform.CredentialsSecretRef != nil && platform.CredentialsSecretRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("credentialsSecretRef", "name"), "must specify the name of the credentials secret"))
+	}
+	if role := platform.CredentialsAssumeRole; role != nil {
+		if role.RoleARN == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("credentialsAssumeRole", "roleARN"), "must specify the ARN of the role to assume"))
+		}
+		if platform.CredentialsSecretRef != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("credentialsAssumeRole"), "cannot specify assume role when credentials secret is provided"))
+		}
+	}
+	if selection := platform.SubnetSelection; selection != nil {
+		selectionPath := fldPath.Child("subnetSelection")
+		switch selection.Policy {
+		case hivev1aws.SubnetSelectionPolicyLowestID:
+		case hivev1aws.SubnetSelectionPolicyTagPriority:
+			if selection.PriorityTagKey == "" {
+				allErrs = append(allErrs, field.Required(selectionPath.Child("priorityTagKey"), "priority tag key is required for the TagPriority policy"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(selectionPath.Child("policy"), selection.Policy,
+				[]string{string(hivev1aws.SubnetSelectionPolicyLowestID), string(hivev1aws.SubnetSelectionPolicyTagPriority)}))
+		}
+	}
+	if names := platform.ResourceNames; names != nil {
+		// Only the private subnets differ between availability zones.
+		namesPath := fldPath.Child("resourceNames")
+		if strings.Contains(names.IAMInstanceProfile, "{zone}") {
+			allErrs = append(allErrs, field.Invalid(namesPath.Child("iamInstanceProfile"), names.IAMInstanceProfile, "the IAM instance profile cannot depend on the availability zone"))
+		}
+		if strings.Contains(names.SecurityGroup, "{zone}") {
+			allErrs = append(allErrs, field.Invalid(namesPath.Child("securityGroup"), names.SecurityGroup, "the security group cannot depend on the availability zone"))
+		}
+	}
+	if profileARN := platform.IAMInstanceProfileARN; profileARN != "" {
+		profileARNPath := fldPath.Child("iamInstanceProfileARN")
+		if parsed, err := arn.Parse(profileARN); err != nil || parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "instance-profile/") {
+			allErrs = append(allErrs, field.Invalid(profileARNPath, profileARN, "must be the ARN of an IAM instance profile"))
+		}
+		if names := platform.ResourceNames; names != nil && names.IAMInstanceProfile != "" {
+			allErrs = append(allErrs, field.Forbidden(profileARNPath, "the IAM instance profile ARN cannot be set together with the IAM instance profile of the resource names"))
+		}
+	}
+	return allErrs
+}
+
+// validateAWSAdditionalBlockDevices validates the additional block devices of an AWS machine pool like the root volume,
+// with distinct device names and a positive size.
+func validateAWSAdditionalBlockDevices(devices []hivev1aws.BlockDevice, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	deviceNames := sets.NewString()
+	for i, device := range devices {
+		devicePath := fldPath.Index(i)
+		switch {
+		case device.DeviceName == "":
+			allErrs = append(allErrs, field.Required(devicePath.Child("deviceName"), "device name is required"))
+		case deviceNames.Has(device.DeviceName):
+			allErrs = append(allErrs, field.Duplicate(devicePath.Child("deviceName"), device.DeviceName))
+		}
+		deviceNames.Insert(device.DeviceName)
+		if device.IOPS < 0 {
+			allErrs = append(allErrs, field.Invalid(devicePath.Child("iops"), device.IOPS, "volume IOPS must not be negative"))
+		}
+		if device.Size <= 0 {
+			allErrs = append(allErrs, field.Invalid(devicePath.Child("size"), device.Size, "volume size must be positive"))
+		}
+		if device.Type == "" {
+			allErrs = append(allErrs, field.Required(devicePath.Child("type"), "volume type is required"))
+		}
+		if device.KMSKeyARN != "" && !arn.IsARN(device.KMSKeyARN) && !kmsKeyAliasRegex.MatchString(device.KMSKeyARN) {
+			allErrs = append(allErrs, field.Invalid(devicePath.Child("kmsKeyARN"), device.KMSKeyARN, "must be the ARN of a KMS key or an alias such as alias/ebs-data"))
+		}
+		if device.KMSKeyARN != "" && device.Encrypted != nil && !*device.Encrypted {
+			allErrs = append(allErrs, field.Invalid(devicePath.Child("encrypted"), *device.Encrypted, "volume must be encrypted when a KMS key is set"))
+		}
+	}
+	return allErrs
+}
+
+// validateAWSFailureDomains validates the failure domains of an AWS machine pool, which each need a distinct zone and a
+// subnet, and which replace the zones, subnets and zone instance types of the pool.
+func validateAWSFailureDomains(platform *hivev1aws.MachinePoolPlatform, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	domainsPath := fldPath.Child("failureDomains")
+	zones := sets.NewString()
+	for i, domain := range platform.FailureDomains {
+		domainPath := domainsPath.Index(i)
+		switch {
+		case domain.Zone == "":
+			allErrs = append(allErrs, field.Required(domainPath.Child("zone"), "zone is required"))
+		case zones.Has(domain.Zone):
+			allErrs = append(allErrs, field.Duplicate(domainPath.Child("zone"), domain.Zone))
+		}
+		zones.Insert(domain.Zone)
+		if domain.Subnet == "" {
+			allErrs = append(allErrs, field.Required(domainPath.Child("subnet"), "subnet is required"))
+		}
+	}
+	forbidden := func(name string) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child(name), "cannot be set together with failure domains"))
+	}
+	if len(platform.Zones) > 0 {
+		forbidden("zones")
+	}
+	if len(platform.ZoneIDs) > 0 {
+		forbidden("zoneIDs")
+	}
+	if platform.SingleZone {
+		forbidden("singleZone")
+	}
+	if len(platform.Subnets) > 0 {
+		forbidden("subnets")
+	}
+	if platform.Edge {
+		forbidden("edge")
+	}
+	if len(platform.InstanceTypesByZone) > 0 {
+		forbidden("instanceTypesByZone")
+	}
 	return allErrs
 }
 
@@ -382,6 +698,20 @@ func validateAzureMachinePoolPlatformInvariants(platform *hivev1azure.MachinePoo
 	if osDisk.DiskSizeGB <= 0 {
 		allErrs = append(allErrs, field.Invalid(osDiskPath.Child("iops"), osDisk.DiskSizeGB, "disk size must be positive"))
 	}
+	if spot := platform.SpotVMOptions; spot != nil {
+		switch spot.EvictionPolicy {
+		case "", hivev1azure.EvictionPolicyDeallocate, hivev1azure.EvictionPolicyDelete:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("spotVMOptions", "evictionPolicy"), spot.EvictionPolicy,
+				[]string{string(hivev1azure.EvictionPolicyDeallocate), string(hivev1azure.EvictionPolicyDelete)}))
+		}
+	}
+	switch platform.Placement {
+	case "", hivev1azure.PlacementAuto, hivev1azure.PlacementZones, hivev1azure.PlacementAvailabilitySet:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("placement"), platform.Placement,
+			[]string{string(hivev1azure.PlacementAuto), string(hivev1azure.PlacementZones), string(hivev1azure.PlacementAvailabilitySet)}))
+	}
 	return allErrs
 }
 
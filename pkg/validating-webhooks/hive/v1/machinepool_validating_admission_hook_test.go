@@ -11,6 +11,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
@@ -315,68 +316,716 @@ func Test_MachinePoolAdmission_Validate_Create(t *testing.T) {
 				return pool
 			}(),
 		},
+		{
+			name: "zone replicas",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Autoscaling = &hivev1.MachinePoolAutoscaling{
+					MinReplicas: 3,
+					MaxReplicas: 6,
+					ZoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+						"us-east-1a": {MinReplicas: 2, MaxReplicas: 4},
+					},
+				}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "zone replicas for every zone matching the pool",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.Zones = []string{"us-east-1a", "us-east-1b"}
+				pool.Spec.Autoscaling = &hivev1.MachinePoolAutoscaling{
+					MinReplicas: 3,
+					MaxReplicas: 6,
+					ZoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+						"us-east-1a": {MinReplicas: 2, MaxReplicas: 4},
+						"us-east-1b": {MinReplicas: 1, MaxReplicas: 2},
+					},
+				}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "zone replicas for every zone not matching the pool",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.Zones = []string{"us-east-1a", "us-east-1b"}
+				pool.Spec.Autoscaling = &hivev1.MachinePoolAutoscaling{
+					MinReplicas: 3,
+					MaxReplicas: 6,
+					ZoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+						"us-east-1a": {MinReplicas: 1, MaxReplicas: 4},
+						"us-east-1b": {MinReplicas: 1, MaxReplicas: 2},
+					},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "zone replicas for zone not in pool",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.Zones = []string{"us-east-1a", "us-east-1b"}
+				pool.Spec.Autoscaling = &hivev1.MachinePoolAutoscaling{
+					MinReplicas: 3,
+					MaxReplicas: 6,
+					ZoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+						"us-east-1c": {MinReplicas: 1, MaxReplicas: 2},
+					},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "zone replicas exceeding pool min replicas",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Autoscaling = &hivev1.MachinePoolAutoscaling{
+					MinReplicas: 3,
+					MaxReplicas: 6,
+					ZoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+						"us-east-1a": {MinReplicas: 2, MaxReplicas: 2},
+						"us-east-1b": {MinReplicas: 2, MaxReplicas: 2},
+					},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "zone replicas exceeding pool max replicas",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Autoscaling = &hivev1.MachinePoolAutoscaling{
+					MinReplicas: 3,
+					MaxReplicas: 6,
+					ZoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+						"us-east-1a": {MinReplicas: 1, MaxReplicas: 7},
+					},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "zone min replicas greater than zone max replicas",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Autoscaling = &hivev1.MachinePoolAutoscaling{
+					MinReplicas: 3,
+					MaxReplicas: 6,
+					ZoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+						"us-east-1a": {MinReplicas: 2, MaxReplicas: 1},
+					},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "zone replicas on platform without zones",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.Platform = hivev1.MachinePoolPlatform{
+					VSphere: validvSphereMachinePoolPlatform(),
+				}
+				pool.Spec.Autoscaling = &hivev1.MachinePoolAutoscaling{
+					MinReplicas: 3,
+					MaxReplicas: 6,
+					ZoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+						"zone1": {MinReplicas: 1, MaxReplicas: 2},
+					},
+				}
+				return pool
+			}(),
+		},
 		{
 			name: "missing platform",
 			provision: func() *hivev1.MachinePool {
-				pool := testMachinePool()
-				pool.Spec.Platform = hivev1.MachinePoolPlatform{}
+				pool := testMachinePool()
+				pool.Spec.Platform = hivev1.MachinePoolPlatform{}
+				return pool
+			}(),
+		},
+		{
+			name: "multiple platforms",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.GCP = validGCPMachinePoolPlatform()
+				return pool
+			}(),
+		},
+		{
+			name: "explicit AWS zones",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.Zones = []string{"test-zone-1", "test-zone-2"}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "empty AWS zone name",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.Zones = []string{""}
+				return pool
+			}(),
+		},
+		{
+			name: "explicit AWS zone IDs",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.ZoneIDs = []string{"use1-az1", "use1-az2"}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "empty AWS zone ID",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.ZoneIDs = []string{""}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS zones and zone IDs",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.Zones = []string{"us-east-1a"}
+				pool.Spec.Platform.AWS.ZoneIDs = []string{"use1-az1"}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS resource names",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.ResourceNames = &hivev1aws.ResourceNames{
+					IAMInstanceProfile: "{infraID}-compute-profile",
+					PrivateSubnet:      "{infraID}-subnet-private-{zone}",
+					SecurityGroup:      "{infraID}-compute-sg",
+				}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "AWS security group name depending on zone",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.ResourceNames = &hivev1aws.ResourceNames{SecurityGroup: "{infraID}-sg-{zone}"}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS failure domains",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.FailureDomains = []hivev1aws.FailureDomain{
+					{Zone: "zone1", Subnet: "subnet-1"},
+					{Zone: "zone2", Subnet: "subnet-2", InstanceType: "m5.large"},
+				}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "AWS failure domains with duplicate zones",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.FailureDomains = []hivev1aws.FailureDomain{
+					{Zone: "zone1", Subnet: "subnet-1"},
+					{Zone: "zone1", Subnet: "subnet-2"},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS failure domain without subnet",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.FailureDomains = []hivev1aws.FailureDomain{{Zone: "zone1"}}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS failure domains with zones",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.FailureDomains = []hivev1aws.FailureDomain{{Zone: "zone1", Subnet: "subnet-1"}}
+				pool.Spec.Platform.AWS.Zones = []string{"zone1"}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS failure domains with subnets",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.FailureDomains = []hivev1aws.FailureDomain{{Zone: "zone1", Subnet: "subnet-1"}}
+				pool.Spec.Platform.AWS.Subnets = []string{"subnet-1"}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS failure domains with zone replicas",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.FailureDomains = []hivev1aws.FailureDomain{
+					{Zone: "zone1", Subnet: "subnet-1"},
+					{Zone: "zone2", Subnet: "subnet-2"},
+				}
+				pool.Spec.Autoscaling = &hivev1.MachinePoolAutoscaling{
+					MinReplicas: 2,
+					MaxReplicas: 4,
+					ZoneReplicas: map[string]hivev1.MachinePoolZoneAutoscaling{
+						"zone3": {MinReplicas: 1, MaxReplicas: 2},
+					},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS spot market type",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.MarketType = hivev1aws.SpotMarketType
+				pool.Spec.Platform.AWS.SpotMarketOptions = &hivev1aws.SpotMarketOptions{}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "AWS spot market options with on-demand market type",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.MarketType = hivev1aws.OnDemandMarketType
+				pool.Spec.Platform.AWS.SpotMarketOptions = &hivev1aws.SpotMarketOptions{}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS spot fallback to on-demand",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.MarketType = hivev1aws.SpotMarketType
+				pool.Spec.Platform.AWS.SpotFallbackToOnDemand = true
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "AWS spot fallback to on-demand without spot instances",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.SpotFallbackToOnDemand = true
+				return pool
+			}(),
+		},
+		{
+			name: "AWS strict even spread policy",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.SpreadPolicy = hivev1aws.SpreadPolicyStrictEven
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "unknown AWS spread policy",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.SpreadPolicy = "Packed"
+				return pool
+			}(),
+		},
+		{
+			name: "AWS pool credentials secret",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.CredentialsSecretRef = &corev1.LocalObjectReference{Name: "pool-creds"}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "AWS pool credentials secret without name",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.CredentialsSecretRef = &corev1.LocalObjectReference{}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS pool assume role",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.CredentialsAssumeRole = &hivev1aws.AssumeRole{RoleARN: "arn:aws:iam::123456789012:role/workers"}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "AWS pool assume role without role ARN",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.CredentialsAssumeRole = &hivev1aws.AssumeRole{}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS pool assume role with credentials secret",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.CredentialsSecretRef = &corev1.LocalObjectReference{Name: "pool-creds"}
+				pool.Spec.Platform.AWS.CredentialsAssumeRole = &hivev1aws.AssumeRole{RoleARN: "arn:aws:iam::123456789012:role/workers"}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS capacity block market type",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.MarketType = hivev1aws.CapacityBlockMarketType
+				return pool
+			}(),
+		},
+		{
+			name: "unknown AWS market type",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.MarketType = "reserved"
+				return pool
+			}(),
+		},
+		{
+			name: "AWS IAM instance profile ARN",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.IAMInstanceProfileARN = "arn:aws:iam::123456789012:instance-profile/workers"
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "malformed AWS IAM instance profile ARN",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.IAMInstanceProfileARN = "arn:aws:iam::123456789012:role/workers"
+				return pool
+			}(),
+		},
+		{
+			name: "AWS IAM instance profile ARN with IAM instance profile name",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.IAMInstanceProfileARN = "arn:aws:iam::123456789012:instance-profile/workers"
+				pool.Spec.Platform.AWS.ResourceNames = &hivev1aws.ResourceNames{IAMInstanceProfile: "{infraID}-compute-profile"}
+				return pool
+			}(),
+		},
+		{
+			name: "missing AWS instance type",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.InstanceType = ""
+				return pool
+			}(),
+		},
+		{
+			name: "AWS instance type selector",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.InstanceType = ""
+				pool.Spec.Platform.AWS.InstanceTypeSelector = &hivev1aws.InstanceTypeSelector{
+					Family: "c5",
+					Size:   "4xlarge",
+				}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "missing AWS instance type selector size",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.InstanceType = ""
+				pool.Spec.Platform.AWS.InstanceTypeSelector = &hivev1aws.InstanceTypeSelector{
+					Family: "c5",
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "invalid AWS volume IOPS",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.EC2RootVolume.IOPS = -1
+				return pool
+			}(),
+		},
+		{
+			name: "invalid AWS volume size",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.EC2RootVolume.Size = -1
+				return pool
+			}(),
+		},
+		{
+			name: "missing AWS volume type",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.EC2RootVolume.Type = ""
+				return pool
+			}(),
+		},
+		{
+			name: "unencrypted AWS volume",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.EC2RootVolume.Encrypted = pointer.BoolPtr(false)
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "unencrypted AWS volume with KMS key",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.EC2RootVolume.Encrypted = pointer.BoolPtr(false)
+				pool.Spec.Platform.AWS.EC2RootVolume.KMSKeyARN = "arn:aws:kms:us-east-1:123456789012:key/12345678-1234-1234-1234-123456789012"
+				return pool
+			}(),
+		},
+		{
+			name: "AWS private subnets",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.Subnets = []string{"subnet-private", "subnet-public"}
+				pool.Spec.Platform.AWS.PrivateSubnets = []string{"subnet-private"}
 				return pool
 			}(),
+			expectAllowed: true,
 		},
 		{
-			name: "multiple platforms",
+			name: "AWS private subnet not in subnets",
 			provision: func() *hivev1.MachinePool {
 				pool := testAWSMachinePool()
-				pool.Spec.Platform.GCP = validGCPMachinePoolPlatform()
+				pool.Spec.Platform.AWS.Subnets = []string{"subnet-private", "subnet-public"}
+				pool.Spec.Platform.AWS.PrivateSubnets = []string{"subnet-other"}
 				return pool
 			}(),
 		},
 		{
-			name: "explicit AWS zones",
+			name: "AWS single zone pool with one zone",
 			provision: func() *hivev1.MachinePool {
 				pool := testAWSMachinePool()
-				pool.Spec.Platform.AWS.Zones = []string{"test-zone-1", "test-zone-2"}
+				pool.Spec.Platform.AWS.SingleZone = true
+				pool.Spec.Platform.AWS.Zones = []string{"us-east-1a"}
 				return pool
 			}(),
 			expectAllowed: true,
 		},
 		{
-			name: "empty AWS zone name",
+			name: "AWS single zone pool with several zones",
 			provision: func() *hivev1.MachinePool {
 				pool := testAWSMachinePool()
-				pool.Spec.Platform.AWS.Zones = []string{""}
+				pool.Spec.Platform.AWS.SingleZone = true
+				pool.Spec.Platform.AWS.Zones = []string{"us-east-1a", "us-east-1b"}
 				return pool
 			}(),
+			expectAllowed: true,
 		},
 		{
-			name: "missing AWS instance type",
+			name: "AWS volume with KMS key alias",
 			provision: func() *hivev1.MachinePool {
 				pool := testAWSMachinePool()
-				pool.Spec.Platform.AWS.InstanceType = ""
+				pool.Spec.Platform.AWS.EC2RootVolume.KMSKeyARN = "alias/ebs-worker"
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "AWS volume with invalid KMS key",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.EC2RootVolume.KMSKeyARN = "ebs-worker"
 				return pool
 			}(),
 		},
 		{
-			name: "invalid AWS volume IOPS",
+			name: "AWS additional block devices with their own encryption",
 			provision: func() *hivev1.MachinePool {
 				pool := testAWSMachinePool()
-				pool.Spec.Platform.AWS.EC2RootVolume.IOPS = -1
+				pool.Spec.Platform.AWS.AdditionalBlockDevices = []hivev1aws.BlockDevice{
+					{DeviceName: "/dev/xvdb", Size: 100, Type: "gp3", KMSKeyARN: "alias/ebs-data"},
+					{DeviceName: "/dev/xvdc", Size: 200, Type: "io1", IOPS: 1000, KMSKeyARN: "arn:aws:kms:us-east-1:123456789012:key/12345678-1234-1234-1234-123456789012"},
+					{DeviceName: "/dev/xvdd", Size: 50, Type: "gp3", Encrypted: pointer.BoolPtr(false)},
+				}
 				return pool
 			}(),
+			expectAllowed: true,
 		},
 		{
-			name: "invalid AWS volume size",
+			name: "AWS additional block device without device name",
 			provision: func() *hivev1.MachinePool {
 				pool := testAWSMachinePool()
-				pool.Spec.Platform.AWS.EC2RootVolume.Size = -1
+				pool.Spec.Platform.AWS.AdditionalBlockDevices = []hivev1aws.BlockDevice{
+					{Size: 100, Type: "gp3"},
+				}
 				return pool
 			}(),
 		},
 		{
-			name: "missing AWS volume type",
+			name: "AWS additional block devices with duplicate device names",
 			provision: func() *hivev1.MachinePool {
 				pool := testAWSMachinePool()
-				pool.Spec.Platform.AWS.EC2RootVolume.Type = ""
+				pool.Spec.Platform.AWS.AdditionalBlockDevices = []hivev1aws.BlockDevice{
+					{DeviceName: "/dev/xvdb", Size: 100, Type: "gp3"},
+					{DeviceName: "/dev/xvdb", Size: 200, Type: "gp3"},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS additional block device without size",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.AdditionalBlockDevices = []hivev1aws.BlockDevice{
+					{DeviceName: "/dev/xvdb", Type: "gp3"},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS additional block device without volume type",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.AdditionalBlockDevices = []hivev1aws.BlockDevice{
+					{DeviceName: "/dev/xvdb", Size: 100},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS additional block device with negative IOPS",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.AdditionalBlockDevices = []hivev1aws.BlockDevice{
+					{DeviceName: "/dev/xvdb", Size: 100, Type: "io1", IOPS: -1},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "AWS additional block device with invalid KMS key",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.AdditionalBlockDevices = []hivev1aws.BlockDevice{
+					{DeviceName: "/dev/xvdb", Size: 100, Type: "gp3", KMSKeyARN: "ebs-data"},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "unencrypted AWS additional block device with KMS key",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.AdditionalBlockDevices = []hivev1aws.BlockDevice{
+					{DeviceName: "/dev/xvdb", Size: 100, Type: "gp3", Encrypted: pointer.BoolPtr(false), KMSKeyARN: "alias/ebs-data"},
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "valid AWS spot instance interruption behavior",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.SpotMarketOptions = &hivev1aws.SpotMarketOptions{
+					InstanceInterruptionBehavior: "terminate",
+				}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "unsupported AWS spot instance interruption behavior",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.SpotMarketOptions = &hivev1aws.SpotMarketOptions{
+					InstanceInterruptionBehavior: "hibernate",
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "invalid AWS spot instance interruption behavior",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.SpotMarketOptions = &hivev1aws.SpotMarketOptions{
+					InstanceInterruptionBehavior: "pause",
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "valid AWS zone instance types",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.Zones = []string{"us-east-1a", "us-east-1b"}
+				pool.Spec.Platform.AWS.InstanceTypesByZone = map[string]string{"us-east-1b": "m5.large"}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "AWS zone instance type for zone not in pool",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.Zones = []string{"us-east-1a"}
+				pool.Spec.Platform.AWS.InstanceTypesByZone = map[string]string{"us-east-1b": "m5.large"}
+				return pool
+			}(),
+		},
+		{
+			name: "empty AWS zone instance type",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.InstanceTypesByZone = map[string]string{"us-east-1b": ""}
+				return pool
+			}(),
+		},
+		{
+			name: "valid AWS subnet selection",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.SubnetSelection = &hivev1aws.SubnetSelection{
+					Policy:         hivev1aws.SubnetSelectionPolicyTagPriority,
+					PriorityTagKey: "subnet-priority",
+				}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "missing AWS subnet selection priority tag key",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.SubnetSelection = &hivev1aws.SubnetSelection{
+					Policy: hivev1aws.SubnetSelectionPolicyTagPriority,
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "invalid AWS subnet selection policy",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.Platform.AWS.SubnetSelection = &hivev1aws.SubnetSelection{
+					Policy: "Random",
+				}
 				return pool
 			}(),
 		},
@@ -447,6 +1096,44 @@ func Test_MachinePoolAdmission_Validate_Create(t *testing.T) {
 				return pool
 			}(),
 		},
+		{
+			name: "valid Azure spot VM eviction policy",
+			provision: func() *hivev1.MachinePool {
+				pool := testAzureMachinePool()
+				pool.Spec.Platform.Azure.SpotVMOptions = &hivev1azure.SpotVMOptions{
+					EvictionPolicy: hivev1azure.EvictionPolicyDelete,
+				}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "invalid Azure spot VM eviction policy",
+			provision: func() *hivev1.MachinePool {
+				pool := testAzureMachinePool()
+				pool.Spec.Platform.Azure.SpotVMOptions = &hivev1azure.SpotVMOptions{
+					EvictionPolicy: "Hibernate",
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "valid Azure placement",
+			provision: func() *hivev1.MachinePool {
+				pool := testAzureMachinePool()
+				pool.Spec.Platform.Azure.Placement = hivev1azure.PlacementAvailabilitySet
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "invalid Azure placement",
+			provision: func() *hivev1.MachinePool {
+				pool := testAzureMachinePool()
+				pool.Spec.Platform.Azure.Placement = "ScaleSet"
+				return pool
+			}(),
+		},
 		{
 			name: "valid labels",
 			provision: func() *hivev1.MachinePool {
@@ -464,6 +1151,142 @@ func Test_MachinePoolAdmission_Validate_Create(t *testing.T) {
 				return pool
 			}(),
 		},
+		{
+			name: "valid merge ignition secret",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.MergeIgnitionSecretRef = &corev1.LocalObjectReference{Name: "extra-ignition"}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "merge ignition secret without name",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.MergeIgnitionSecretRef = &corev1.LocalObjectReference{}
+				return pool
+			}(),
+		},
+		{
+			name: "boot image",
+			provision: func() *hivev1.MachinePool {
+				pool := testAWSMachinePool()
+				pool.Spec.BootImage = "ami-0123456789abcdef0"
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "boot image on Azure",
+			provision: func() *hivev1.MachinePool {
+				pool := testAzureMachinePool()
+				pool.Spec.BootImage = "rhcos-image"
+				return pool
+			}(),
+		},
+		{
+			name: "valid rolling update strategy",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.UpdateStrategy = &hivev1.MachinePoolUpdateStrategy{
+					Type:           hivev1.RollingMachinePoolUpdateStrategyType,
+					MaxUnavailable: intstrPtr(intstr.FromInt(2)),
+				}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "valid immediate update strategy",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.UpdateStrategy = &hivev1.MachinePoolUpdateStrategy{Type: hivev1.ImmediateMachinePoolUpdateStrategyType}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "invalid update strategy type",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.UpdateStrategy = &hivev1.MachinePoolUpdateStrategy{Type: "Recreate"}
+				return pool
+			}(),
+		},
+		{
+			name: "zero maxUnavailable",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.UpdateStrategy = &hivev1.MachinePoolUpdateStrategy{
+					Type:           hivev1.RollingMachinePoolUpdateStrategyType,
+					MaxUnavailable: intstrPtr(intstr.FromInt(0)),
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "maxUnavailable percentage",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.UpdateStrategy = &hivev1.MachinePoolUpdateStrategy{
+					Type:           hivev1.RollingMachinePoolUpdateStrategyType,
+					MaxUnavailable: intstrPtr(intstr.FromString("25%")),
+				}
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "maxUnavailable percentage over 100%",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.UpdateStrategy = &hivev1.MachinePoolUpdateStrategy{
+					Type:           hivev1.RollingMachinePoolUpdateStrategyType,
+					MaxUnavailable: intstrPtr(intstr.FromString("150%")),
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "malformed maxUnavailable percentage",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.UpdateStrategy = &hivev1.MachinePoolUpdateStrategy{
+					Type:           hivev1.RollingMachinePoolUpdateStrategyType,
+					MaxUnavailable: intstrPtr(intstr.FromString("half")),
+				}
+				return pool
+			}(),
+		},
+		{
+			name: "delete policy",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.DeletePolicy = hivev1.OldestMachinePoolDeletePolicy
+				return pool
+			}(),
+			expectAllowed: true,
+		},
+		{
+			name: "invalid delete policy",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.DeletePolicy = "Biggest"
+				return pool
+			}(),
+		},
+		{
+			name: "maxUnavailable without rolling update strategy",
+			provision: func() *hivev1.MachinePool {
+				pool := testMachinePool()
+				pool.Spec.UpdateStrategy = &hivev1.MachinePoolUpdateStrategy{
+					Type:           hivev1.ImmediateMachinePoolUpdateStrategyType,
+					MaxUnavailable: intstrPtr(intstr.FromInt(1)),
+				}
+				return pool
+			}(),
+		},
 		{
 			name: "zero autoscaling",
 			provision: func() *hivev1.MachinePool {
@@ -621,6 +1444,10 @@ func Test_MachinePoolAdmission_Validate_Update(t *testing.T) {
 	}
 }
 
+func intstrPtr(value intstr.IntOrString) *intstr.IntOrString {
+	return &value
+}
+
 func testMachinePool() *hivev1.MachinePool {
 	cdName := "test-deployment"
 	return &hivev1.MachinePool{